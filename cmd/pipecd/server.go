@@ -301,6 +301,7 @@ func (s *server) run(ctx context.Context, t cli.Telemetry) error {
 		}
 
 		h := httpapi.NewHandler(
+			ctx,
 			signer,
 			s.staticDir,
 			encryptDecrypter,
@@ -310,6 +311,9 @@ func (s *server) run(ctx context.Context, t cli.Telemetry) error {
 			cfg.SharedSSOConfigMap(),
 			datastore.NewProjectStore(ds),
 			!s.insecureCookie,
+			cfg.GitWebhook.Secret,
+			datastore.NewPipedStore(ds),
+			cmds,
 			t.Logger,
 		)
 		httpServer := &http.Server{
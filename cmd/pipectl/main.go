@@ -20,9 +20,11 @@ import (
 
 	"github.com/pipe-cd/pipe/pkg/app/pipectl/cmd/application"
 	"github.com/pipe-cd/pipe/pkg/app/pipectl/cmd/deployment"
+	"github.com/pipe-cd/pipe/pkg/app/pipectl/cmd/deploymentfreeze"
 	"github.com/pipe-cd/pipe/pkg/app/pipectl/cmd/event"
 	"github.com/pipe-cd/pipe/pkg/app/pipectl/cmd/piped"
 	"github.com/pipe-cd/pipe/pkg/app/pipectl/cmd/planpreview"
+	"github.com/pipe-cd/pipe/pkg/app/pipectl/cmd/promotion"
 	"github.com/pipe-cd/pipe/pkg/cli"
 )
 
@@ -35,9 +37,11 @@ func main() {
 	app.AddCommands(
 		application.NewCommand(),
 		deployment.NewCommand(),
+		deploymentfreeze.NewCommand(),
 		event.NewCommand(),
 		planpreview.NewCommand(),
 		piped.NewCommand(),
+		promotion.NewCommand(),
 	)
 
 	if err := app.Run(); err != nil {
@@ -36,13 +36,15 @@ import (
 
 // API implements the behaviors for the gRPC definitions of API.
 type API struct {
-	applicationStore    datastore.ApplicationStore
-	environmentStore    datastore.EnvironmentStore
-	deploymentStore     datastore.DeploymentStore
-	pipedStore          datastore.PipedStore
-	eventStore          datastore.EventStore
-	commandStore        commandstore.Store
-	commandOutputGetter commandOutputGetter
+	applicationStore      datastore.ApplicationStore
+	environmentStore      datastore.EnvironmentStore
+	deploymentStore       datastore.DeploymentStore
+	pipedStore            datastore.PipedStore
+	eventStore            datastore.EventStore
+	deploymentFreezeStore datastore.DeploymentFreezeStore
+	promotionStore        datastore.PromotionStore
+	commandStore          commandstore.Store
+	commandOutputGetter   commandOutputGetter
 
 	webBaseURL string
 	logger     *zap.Logger
@@ -57,15 +59,17 @@ func NewAPI(
 	logger *zap.Logger,
 ) *API {
 	a := &API{
-		applicationStore:    datastore.NewApplicationStore(ds),
-		environmentStore:    datastore.NewEnvironmentStore(ds),
-		deploymentStore:     datastore.NewDeploymentStore(ds),
-		pipedStore:          datastore.NewPipedStore(ds),
-		eventStore:          datastore.NewEventStore(ds),
-		commandStore:        cmds,
-		commandOutputGetter: cog,
-		webBaseURL:          webBaseURL,
-		logger:              logger.Named("api"),
+		applicationStore:      datastore.NewApplicationStore(ds),
+		environmentStore:      datastore.NewEnvironmentStore(ds),
+		deploymentStore:       datastore.NewDeploymentStore(ds),
+		pipedStore:            datastore.NewPipedStore(ds),
+		eventStore:            datastore.NewEventStore(ds),
+		deploymentFreezeStore: datastore.NewDeploymentFreezeStore(ds),
+		promotionStore:        datastore.NewPromotionStore(ds),
+		commandStore:          cmds,
+		commandOutputGetter:   cog,
+		webBaseURL:            webBaseURL,
+		logger:                logger.Named("api"),
 	}
 	return a
 }
@@ -322,6 +326,59 @@ func (a *API) GetDeployment(ctx context.Context, req *apiservice.GetDeploymentRe
 	}, nil
 }
 
+// RetryStage resumes a failed deployment from the specified failed stage.
+func (a *API) RetryStage(ctx context.Context, req *apiservice.RetryStageRequest) (*apiservice.RetryStageResponse, error) {
+	key, err := requireAPIKey(ctx, model.APIKey_READ_WRITE, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	deployment, err := getDeployment(ctx, a.deploymentStore, req.DeploymentId, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	if key.ProjectId != deployment.ProjectId {
+		return nil, status.Error(codes.InvalidArgument, "Requested deployment does not belong to your project")
+	}
+
+	commandID, err := retryStage(ctx, a.deploymentStore, a.applicationStore, a.commandStore, deployment, req.StageId, key.Id, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &apiservice.RetryStageResponse{
+		CommandId: commandID,
+	}, nil
+}
+
+// SkipStage ends the specified running ANALYSIS or WAIT stage
+// to let the deployment move on to the next stages.
+func (a *API) SkipStage(ctx context.Context, req *apiservice.SkipStageRequest) (*apiservice.SkipStageResponse, error) {
+	key, err := requireAPIKey(ctx, model.APIKey_READ_WRITE, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	deployment, err := getDeployment(ctx, a.deploymentStore, req.DeploymentId, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	if key.ProjectId != deployment.ProjectId {
+		return nil, status.Error(codes.InvalidArgument, "Requested deployment does not belong to your project")
+	}
+
+	commandID, err := skipStage(ctx, a.commandStore, deployment, req.StageId, key.Id, nil, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &apiservice.SkipStageResponse{
+		CommandId: commandID,
+	}, nil
+}
+
 func (a *API) GetCommand(ctx context.Context, req *apiservice.GetCommandRequest) (*apiservice.GetCommandResponse, error) {
 	_, err := requireAPIKey(ctx, model.APIKey_READ_ONLY, a.logger)
 	if err != nil {
@@ -409,6 +466,75 @@ func (a *API) RegisterEvent(ctx context.Context, req *apiservice.RegisterEventRe
 	return &apiservice.RegisterEventResponse{}, nil
 }
 
+func (a *API) AddDeploymentFreeze(ctx context.Context, req *apiservice.AddDeploymentFreezeRequest) (*apiservice.AddDeploymentFreezeResponse, error) {
+	key, err := requireAPIKey(ctx, model.APIKey_READ_WRITE, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	f := model.DeploymentFreeze{
+		Id:            uuid.New().String(),
+		ProjectId:     key.ProjectId,
+		ApplicationId: req.ApplicationId,
+		Reason:        req.Reason,
+		StartedAt:     req.StartedAt,
+		EndedAt:       req.EndedAt,
+		Creator:       key.Name,
+	}
+	if err := addDeploymentFreeze(ctx, a.deploymentFreezeStore, a.applicationStore, &f, a.logger); err != nil {
+		return nil, err
+	}
+
+	return &apiservice.AddDeploymentFreezeResponse{
+		Id: f.Id,
+	}, nil
+}
+
+func (a *API) EndDeploymentFreeze(ctx context.Context, req *apiservice.EndDeploymentFreezeRequest) (*apiservice.EndDeploymentFreezeResponse, error) {
+	key, err := requireAPIKey(ctx, model.APIKey_READ_WRITE, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := endDeploymentFreeze(ctx, a.deploymentFreezeStore, req.Id, key.ProjectId, a.logger); err != nil {
+		return nil, err
+	}
+
+	return &apiservice.EndDeploymentFreezeResponse{}, nil
+}
+
+func (a *API) ListDeploymentFreezes(ctx context.Context, req *apiservice.ListDeploymentFreezesRequest) (*apiservice.ListDeploymentFreezesResponse, error) {
+	key, err := requireAPIKey(ctx, model.APIKey_READ_ONLY, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	freezes, err := listDeploymentFreezes(ctx, a.deploymentFreezeStore, key.ProjectId, req.IncludeEnded, time.Now(), a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &apiservice.ListDeploymentFreezesResponse{
+		Freezes: freezes,
+	}, nil
+}
+
+func (a *API) ListPromotions(ctx context.Context, req *apiservice.ListPromotionsRequest) (*apiservice.ListPromotionsResponse, error) {
+	key, err := requireAPIKey(ctx, model.APIKey_READ_ONLY, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	promotions, err := listPromotions(ctx, a.promotionStore, key.ProjectId, req.DeploymentId, req.ApplicationId, req.Limit, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &apiservice.ListPromotionsResponse{
+		Promotions: promotions,
+	}, nil
+}
+
 func (a *API) RequestPlanPreview(ctx context.Context, req *apiservice.RequestPlanPreviewRequest) (*apiservice.RequestPlanPreviewResponse, error) {
 	key, err := requireAPIKey(ctx, model.APIKey_READ_WRITE, a.logger)
 	if err != nil {
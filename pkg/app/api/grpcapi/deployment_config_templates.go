@@ -47,9 +47,11 @@ var (
 		},
 	}
 
-	terraformDeploymentConfigTemplates  = []*webservice.DeploymentConfigTemplate{}
-	crossplaneDeploymentConfigTemplates = []*webservice.DeploymentConfigTemplate{}
-	lambdaDeploymentConfigTemplates     = []*webservice.DeploymentConfigTemplate{}
-	cloudrunDeploymentConfigTemplates   = []*webservice.DeploymentConfigTemplate{}
-	ecsDeploymentConfigTemplates        = []*webservice.DeploymentConfigTemplate{}
+	terraformDeploymentConfigTemplates      = []*webservice.DeploymentConfigTemplate{}
+	crossplaneDeploymentConfigTemplates     = []*webservice.DeploymentConfigTemplate{}
+	lambdaDeploymentConfigTemplates         = []*webservice.DeploymentConfigTemplate{}
+	cloudrunDeploymentConfigTemplates       = []*webservice.DeploymentConfigTemplate{}
+	ecsDeploymentConfigTemplates            = []*webservice.DeploymentConfigTemplate{}
+	cloudFunctionsDeploymentConfigTemplates = []*webservice.DeploymentConfigTemplate{}
+	nomadDeploymentConfigTemplates          = []*webservice.DeploymentConfigTemplate{}
 )
@@ -17,7 +17,10 @@ package grpcapi
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -155,3 +158,235 @@ func getEnvironment(ctx context.Context, store datastore.EnvironmentStore, id st
 
 	return env, nil
 }
+
+func addDeploymentFreeze(ctx context.Context, store datastore.DeploymentFreezeStore, appStore datastore.ApplicationStore, f *model.DeploymentFreeze, logger *zap.Logger) error {
+	if f.EndedAt <= f.StartedAt {
+		return status.Error(codes.InvalidArgument, "The end time of the deployment freeze must be after its start time")
+	}
+	if f.ApplicationId != "" {
+		app, err := getApplication(ctx, appStore, f.ApplicationId, logger)
+		if err != nil {
+			return err
+		}
+		if app.ProjectId != f.ProjectId {
+			return status.Error(codes.InvalidArgument, "Requested application does not belong to your project")
+		}
+	}
+
+	err := store.AddDeploymentFreeze(ctx, f)
+	if errors.Is(err, datastore.ErrAlreadyExists) {
+		return status.Error(codes.AlreadyExists, "The deployment freeze already exists")
+	}
+	if err != nil {
+		logger.Error("failed to add deployment freeze", zap.Error(err))
+		return status.Error(codes.Internal, "Failed to add deployment freeze")
+	}
+	return nil
+}
+
+func endDeploymentFreeze(ctx context.Context, store datastore.DeploymentFreezeStore, id, projectID string, logger *zap.Logger) error {
+	if err := store.EndDeploymentFreeze(ctx, id, projectID); err != nil {
+		switch err {
+		case datastore.ErrNotFound:
+			return status.Error(codes.NotFound, "The deployment freeze is not found")
+		case datastore.ErrInvalidArgument:
+			return status.Error(codes.InvalidArgument, "Invalid value for update")
+		default:
+			logger.Error("failed to end the deployment freeze",
+				zap.String("deployment-freeze-id", id),
+				zap.Error(err),
+			)
+			return status.Error(codes.Internal, "Failed to end the deployment freeze")
+		}
+	}
+	return nil
+}
+
+// listDeploymentFreezes returns the deployment freezes of the given project.
+// The ended ones are excluded unless includeEnded is true.
+func listDeploymentFreezes(ctx context.Context, store datastore.DeploymentFreezeStore, projectID string, includeEnded bool, now time.Time, logger *zap.Logger) ([]*model.DeploymentFreeze, error) {
+	opts := datastore.ListOptions{
+		Filters: []datastore.ListFilter{
+			{
+				Field:    "ProjectId",
+				Operator: datastore.OperatorEqual,
+				Value:    projectID,
+			},
+		},
+	}
+	if !includeEnded {
+		opts.Filters = append(opts.Filters, datastore.ListFilter{
+			Field:    "EndedAt",
+			Operator: datastore.OperatorGreaterThan,
+			Value:    now.Unix(),
+		})
+		opts.Orders = []datastore.Order{
+			{
+				Field:     "EndedAt",
+				Direction: datastore.Asc,
+			},
+		}
+	}
+
+	freezes, err := store.ListDeploymentFreezes(ctx, opts)
+	if err != nil {
+		logger.Error("failed to list deployment freezes", zap.Error(err))
+		return nil, status.Error(codes.Internal, "Failed to list deployment freezes")
+	}
+	return freezes, nil
+}
+
+const defaultListPromotionsLimit = 50
+
+// listPromotions returns the most recent promotions of the specified deployment or application.
+func listPromotions(ctx context.Context, store datastore.PromotionStore, projectID, deploymentID, applicationID string, limit int32, logger *zap.Logger) ([]*model.Promotion, error) {
+	opts := datastore.ListOptions{
+		Filters: []datastore.ListFilter{
+			{
+				Field:    "ProjectId",
+				Operator: datastore.OperatorEqual,
+				Value:    projectID,
+			},
+		},
+		Orders: []datastore.Order{
+			{
+				Field:     "CreatedAt",
+				Direction: datastore.Desc,
+			},
+		},
+		Limit: defaultListPromotionsLimit,
+	}
+	switch {
+	case deploymentID != "" && applicationID != "":
+		return nil, status.Error(codes.InvalidArgument, "Only one of deployment id and application id can be specified")
+	case deploymentID != "":
+		opts.Filters = append(opts.Filters, datastore.ListFilter{
+			Field:    "DeploymentId",
+			Operator: datastore.OperatorEqual,
+			Value:    deploymentID,
+		})
+	case applicationID != "":
+		opts.Filters = append(opts.Filters, datastore.ListFilter{
+			Field:    "ApplicationId",
+			Operator: datastore.OperatorEqual,
+			Value:    applicationID,
+		})
+	default:
+		return nil, status.Error(codes.InvalidArgument, "Either deployment id or application id must be specified")
+	}
+	if limit > 0 {
+		opts.Limit = int(limit)
+	}
+
+	promotions, err := store.ListPromotions(ctx, opts)
+	if err != nil {
+		logger.Error("failed to list promotions", zap.Error(err))
+		return nil, status.Error(codes.Internal, "Failed to list promotions")
+	}
+	return promotions, nil
+}
+
+// skipStage sends a SKIP_STAGE command to let the piped end the specified running stage of the given deployment
+// without waiting for its completion. Only ANALYSIS and WAIT stages can be skipped.
+// The returned value is the ID of the created command.
+func skipStage(ctx context.Context, cmdStore commandstore.Store, d *model.Deployment, stageID, commander string, metadata map[string]string, logger *zap.Logger) (string, error) {
+	if model.IsCompletedDeployment(d.Status) {
+		return "", status.Error(codes.FailedPrecondition, "Could not skip the stage because the deployment was already completed")
+	}
+	stage, ok := d.FindStage(stageID)
+	if !ok {
+		return "", status.Error(codes.FailedPrecondition, "The stage was not found in the deployment")
+	}
+	if model.IsCompletedStage(stage.Status) {
+		return "", status.Error(codes.FailedPrecondition, "Could not skip the stage because it was already completed")
+	}
+	if !model.Stage(stage.Name).IsSkippable() {
+		return "", status.Errorf(codes.FailedPrecondition, "Could not skip %s stage, only %s and %s stages can be skipped", stage.Name, model.StageAnalysis, model.StageWait)
+	}
+
+	cmd := model.Command{
+		Id:            uuid.New().String(),
+		PipedId:       d.PipedId,
+		ApplicationId: d.ApplicationId,
+		ProjectId:     d.ProjectId,
+		DeploymentId:  d.Id,
+		StageId:       stageID,
+		Type:          model.Command_SKIP_STAGE,
+		Commander:     commander,
+		Metadata:      metadata,
+		SkipStage: &model.Command_SkipStage{
+			DeploymentId: d.Id,
+			StageId:      stageID,
+		},
+	}
+	if err := addCommand(ctx, cmdStore, &cmd, logger); err != nil {
+		return "", err
+	}
+	return cmd.Id, nil
+}
+
+// retryStage brings the given failed deployment back to RUNNING from the specified failed stage
+// and sends a RETRY_STAGE command to let its piped resume the deployment.
+// The returned value is the ID of the created command.
+func retryStage(ctx context.Context, store datastore.DeploymentStore, appStore datastore.ApplicationStore, cmdStore commandstore.Store, d *model.Deployment, stageID, commander string, logger *zap.Logger) (string, error) {
+	if d.Status != model.DeploymentStatus_DEPLOYMENT_FAILURE {
+		return "", status.Error(codes.FailedPrecondition, "Could not retry the stage because the deployment is not failed")
+	}
+	stage, ok := d.FindStage(stageID)
+	if !ok {
+		return "", status.Error(codes.FailedPrecondition, "The stage was not found in the deployment")
+	}
+	if stage.Status != model.StageStatus_STAGE_FAILURE {
+		return "", status.Error(codes.FailedPrecondition, "Could not retry the stage because it is not failed")
+	}
+	if stage.Name == model.StageRollback.String() || stage.Name == model.StageScriptRunRollback.String() {
+		return "", status.Error(codes.FailedPrecondition, "Could not retry a rollback stage")
+	}
+
+	app, err := getApplication(ctx, appStore, d.ApplicationId, logger)
+	if err != nil {
+		return "", err
+	}
+	if app.MostRecentlyTriggeredDeployment.GetDeploymentId() != d.Id {
+		return "", status.Error(codes.FailedPrecondition, "Could not retry the stage because a newer deployment of the application has been triggered")
+	}
+	if app.Deploying {
+		return "", status.Error(codes.FailedPrecondition, "Could not retry the stage because the application is deploying")
+	}
+
+	reason := fmt.Sprintf("Retrying stage %s by %s", stageID, commander)
+	if err := store.UpdateDeployment(ctx, d.Id, datastore.DeploymentStageRetriedUpdater(stageID, reason)); err != nil {
+		switch {
+		case errors.Is(err, datastore.ErrNotFound):
+			return "", status.Error(codes.NotFound, "The deployment is not found")
+		case errors.Is(err, datastore.ErrInvalidArgument):
+			return "", status.Error(codes.FailedPrecondition, "Could not retry the stage because the deployment has been changed")
+		default:
+			logger.Error("failed to update deployment to retry stage",
+				zap.String("deployment-id", d.Id),
+				zap.String("stage-id", stageID),
+				zap.Error(err),
+			)
+			return "", status.Error(codes.Internal, "Failed to update deployment to retry stage")
+		}
+	}
+
+	cmd := model.Command{
+		Id:            uuid.New().String(),
+		PipedId:       d.PipedId,
+		ApplicationId: d.ApplicationId,
+		ProjectId:     d.ProjectId,
+		DeploymentId:  d.Id,
+		StageId:       stageID,
+		Type:          model.Command_RETRY_STAGE,
+		Commander:     commander,
+		RetryStage: &model.Command_RetryStage{
+			DeploymentId: d.Id,
+			StageId:      stageID,
+		},
+	}
+	if err := addCommand(ctx, cmdStore, &cmd, logger); err != nil {
+		return "", err
+	}
+	return cmd.Id, nil
+}
@@ -44,6 +44,8 @@ type PipedAPI struct {
 	pipedStore                datastore.PipedStore
 	projectStore              datastore.ProjectStore
 	eventStore                datastore.EventStore
+	deploymentFreezeStore     datastore.DeploymentFreezeStore
+	promotionStore            datastore.PromotionStore
 	stageLogStore             stagelogstore.Store
 	applicationLiveStateStore applicationlivestatestore.Store
 	commandStore              commandstore.Store
@@ -66,6 +68,8 @@ func NewPipedAPI(ctx context.Context, ds datastore.DataStore, sls stagelogstore.
 		pipedStore:                datastore.NewPipedStore(ds),
 		projectStore:              datastore.NewProjectStore(ds),
 		eventStore:                datastore.NewEventStore(ds),
+		deploymentFreezeStore:     datastore.NewDeploymentFreezeStore(ds),
+		promotionStore:            datastore.NewPromotionStore(ds),
 		stageLogStore:             sls,
 		applicationLiveStateStore: alss,
 		commandStore:              cs,
@@ -923,3 +927,50 @@ func (a *PipedAPI) validateEnvBelongsToProject(ctx context.Context, envID, proje
 	}
 	return nil
 }
+
+// ListDeploymentFreezes returns a list of DeploymentFreezes
+// of the project those have not ended yet.
+func (a *PipedAPI) ListDeploymentFreezes(ctx context.Context, req *pipedservice.ListDeploymentFreezesRequest) (*pipedservice.ListDeploymentFreezesResponse, error) {
+	projectID, _, _, err := rpcauth.ExtractPipedToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	freezes, err := listDeploymentFreezes(ctx, a.deploymentFreezeStore, projectID, false, time.Now(), a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pipedservice.ListDeploymentFreezesResponse{
+		Freezes: freezes,
+	}, nil
+}
+
+// ReportPromotion is called to record the result of a promotion
+// done after a successful deployment.
+func (a *PipedAPI) ReportPromotion(ctx context.Context, req *pipedservice.ReportPromotionRequest) (*pipedservice.ReportPromotionResponse, error) {
+	projectID, pipedID, _, err := rpcauth.ExtractPipedToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.validateDeploymentBelongsToPiped(ctx, req.Promotion.DeploymentId, pipedID); err != nil {
+		return nil, err
+	}
+
+	promotion := req.Promotion
+	promotion.ProjectId = projectID
+	promotion.PipedId = pipedID
+
+	err = a.promotionStore.AddPromotion(ctx, promotion)
+	if errors.Is(err, datastore.ErrAlreadyExists) {
+		return nil, status.Error(codes.AlreadyExists, "promotion already exists")
+	}
+	if err != nil {
+		a.logger.Error("failed to add promotion",
+			zap.String("deployment-id", promotion.DeploymentId),
+			zap.Error(err),
+		)
+		return nil, status.Error(codes.Internal, "failed to add promotion")
+	}
+	return &pipedservice.ReportPromotionResponse{}, nil
+}
@@ -58,6 +58,8 @@ type WebAPI struct {
 	pipedStore                datastore.PipedStore
 	projectStore              datastore.ProjectStore
 	apiKeyStore               datastore.APIKeyStore
+	deploymentFreezeStore     datastore.DeploymentFreezeStore
+	promotionStore            datastore.PromotionStore
 	stageLogStore             stagelogstore.Store
 	applicationLiveStateStore applicationlivestatestore.Store
 	commandStore              commandstore.Store
@@ -94,6 +96,8 @@ func NewWebAPI(
 		pipedStore:                datastore.NewPipedStore(ds),
 		projectStore:              datastore.NewProjectStore(ds),
 		apiKeyStore:               datastore.NewAPIKeyStore(ds),
+		deploymentFreezeStore:     datastore.NewDeploymentFreezeStore(ds),
+		promotionStore:            datastore.NewPromotionStore(ds),
 		stageLogStore:             sls,
 		applicationLiveStateStore: alss,
 		commandStore:              cmds,
@@ -1179,19 +1183,47 @@ func (a *WebAPI) ApproveStage(ctx context.Context, req *webservice.ApproveStageR
 		return nil, err
 	}
 
-	deployment, err := getDeployment(ctx, a.deploymentStore, req.DeploymentId, a.logger)
+	deployment, err := a.getUncompletedStageDeployment(ctx, req.DeploymentId, req.StageId, claims.Role.ProjectId, "approve")
 	if err != nil {
 		return nil, err
 	}
-	if err := a.validateDeploymentBelongsToProject(ctx, req.DeploymentId, claims.Role.ProjectId); err != nil {
+
+	commandID := uuid.New().String()
+	cmd := model.Command{
+		Id:            commandID,
+		PipedId:       deployment.PipedId,
+		ApplicationId: deployment.ApplicationId,
+		ProjectId:     deployment.ProjectId,
+		DeploymentId:  req.DeploymentId,
+		StageId:       req.StageId,
+		Type:          model.Command_APPROVE_STAGE,
+		Commander:     claims.Subject,
+		Metadata:      makeCommanderMetadata(claims.Teams),
+		ApproveStage: &model.Command_ApproveStage{
+			DeploymentId: req.DeploymentId,
+			StageId:      req.StageId,
+			Comment:      req.Comment,
+		},
+	}
+	if err := addCommand(ctx, a.commandStore, &cmd, a.logger); err != nil {
 		return nil, err
 	}
-	stage, ok := deployment.StageStatusMap()[req.StageId]
-	if !ok {
-		return nil, status.Error(codes.FailedPrecondition, "The stage was not found in the deployment")
+
+	return &webservice.ApproveStageResponse{
+		CommandId: commandID,
+	}, nil
+}
+
+func (a *WebAPI) RejectStage(ctx context.Context, req *webservice.RejectStageRequest) (*webservice.RejectStageResponse, error) {
+	claims, err := rpcauth.ExtractClaims(ctx)
+	if err != nil {
+		a.logger.Error("failed to authenticate the current user", zap.Error(err))
+		return nil, err
 	}
-	if model.IsCompletedStage(stage) {
-		return nil, status.Errorf(codes.FailedPrecondition, "Could not approve the stage because it was already completed")
+
+	deployment, err := a.getUncompletedStageDeployment(ctx, req.DeploymentId, req.StageId, claims.Role.ProjectId, "reject")
+	if err != nil {
+		return nil, err
 	}
 
 	commandID := uuid.New().String()
@@ -1202,22 +1234,153 @@ func (a *WebAPI) ApproveStage(ctx context.Context, req *webservice.ApproveStageR
 		ProjectId:     deployment.ProjectId,
 		DeploymentId:  req.DeploymentId,
 		StageId:       req.StageId,
-		Type:          model.Command_APPROVE_STAGE,
+		Type:          model.Command_REJECT_STAGE,
 		Commander:     claims.Subject,
-		ApproveStage: &model.Command_ApproveStage{
+		Metadata:      makeCommanderMetadata(claims.Teams),
+		RejectStage: &model.Command_RejectStage{
 			DeploymentId: req.DeploymentId,
 			StageId:      req.StageId,
+			Comment:      req.Comment,
 		},
 	}
 	if err := addCommand(ctx, a.commandStore, &cmd, a.logger); err != nil {
 		return nil, err
 	}
 
-	return &webservice.ApproveStageResponse{
+	return &webservice.RejectStageResponse{
 		CommandId: commandID,
 	}, nil
 }
 
+// RetryStage resumes a failed deployment from the specified failed stage.
+func (a *WebAPI) RetryStage(ctx context.Context, req *webservice.RetryStageRequest) (*webservice.RetryStageResponse, error) {
+	claims, err := rpcauth.ExtractClaims(ctx)
+	if err != nil {
+		a.logger.Error("failed to authenticate the current user", zap.Error(err))
+		return nil, err
+	}
+
+	deployment, err := getDeployment(ctx, a.deploymentStore, req.DeploymentId, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Role.ProjectId != deployment.ProjectId {
+		return nil, status.Error(codes.InvalidArgument, "Requested deployment does not belong to your project")
+	}
+
+	commandID, err := retryStage(ctx, a.deploymentStore, a.applicationStore, a.commandStore, deployment, req.StageId, claims.Subject, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &webservice.RetryStageResponse{
+		CommandId: commandID,
+	}, nil
+}
+
+// SkipStage ends the specified running ANALYSIS or WAIT stage
+// to let the deployment move on to the next stages.
+func (a *WebAPI) SkipStage(ctx context.Context, req *webservice.SkipStageRequest) (*webservice.SkipStageResponse, error) {
+	claims, err := rpcauth.ExtractClaims(ctx)
+	if err != nil {
+		a.logger.Error("failed to authenticate the current user", zap.Error(err))
+		return nil, err
+	}
+
+	deployment, err := getDeployment(ctx, a.deploymentStore, req.DeploymentId, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Role.ProjectId != deployment.ProjectId {
+		return nil, status.Error(codes.InvalidArgument, "Requested deployment does not belong to your project")
+	}
+
+	commandID, err := skipStage(ctx, a.commandStore, deployment, req.StageId, claims.Subject, makeCommanderMetadata(claims.Teams), a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &webservice.SkipStageResponse{
+		CommandId: commandID,
+	}, nil
+}
+
+// OverrideDeploymentWindow lets a deployment held by its deployment windows
+// or deployment freezes run right now.
+func (a *WebAPI) OverrideDeploymentWindow(ctx context.Context, req *webservice.OverrideDeploymentWindowRequest) (*webservice.OverrideDeploymentWindowResponse, error) {
+	claims, err := rpcauth.ExtractClaims(ctx)
+	if err != nil {
+		a.logger.Error("failed to authenticate the current user", zap.Error(err))
+		return nil, err
+	}
+
+	deployment, err := getDeployment(ctx, a.deploymentStore, req.DeploymentId, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Role.ProjectId != deployment.ProjectId {
+		return nil, status.Error(codes.InvalidArgument, "Requested deployment does not belong to your project")
+	}
+
+	if model.IsCompletedDeployment(deployment.Status) {
+		return nil, status.Errorf(codes.FailedPrecondition, "could not override the deployment window because the deployment was already completed")
+	}
+
+	cmd := model.Command{
+		Id:            uuid.New().String(),
+		PipedId:       deployment.PipedId,
+		ApplicationId: deployment.ApplicationId,
+		ProjectId:     deployment.ProjectId,
+		DeploymentId:  req.DeploymentId,
+		Type:          model.Command_OVERRIDE_DEPLOYMENT_WINDOW,
+		Commander:     claims.Subject,
+		OverrideDeploymentWindow: &model.Command_OverrideDeploymentWindow{
+			DeploymentId: req.DeploymentId,
+		},
+	}
+	if err := addCommand(ctx, a.commandStore, &cmd, a.logger); err != nil {
+		return nil, err
+	}
+
+	return &webservice.OverrideDeploymentWindowResponse{
+		CommandId: cmd.Id,
+	}, nil
+}
+
+// getUncompletedStageDeployment returns the deployment after ensuring that
+// it belongs to the given project and its specified stage has not completed yet.
+func (a *WebAPI) getUncompletedStageDeployment(ctx context.Context, deploymentID, stageID, projectID, action string) (*model.Deployment, error) {
+	deployment, err := getDeployment(ctx, a.deploymentStore, deploymentID, a.logger)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.validateDeploymentBelongsToProject(ctx, deploymentID, projectID); err != nil {
+		return nil, err
+	}
+	stage, ok := deployment.StageStatusMap()[stageID]
+	if !ok {
+		return nil, status.Error(codes.FailedPrecondition, "The stage was not found in the deployment")
+	}
+	if model.IsCompletedStage(stage) {
+		return nil, status.Errorf(codes.FailedPrecondition, "Could not %s the stage because it was already completed", action)
+	}
+	return deployment, nil
+}
+
+// makeCommanderMetadata returns the command metadata used by piped to check
+// whether the commander is allowed to handle the stage.
+func makeCommanderMetadata(teams []string) map[string]string {
+	if len(teams) == 0 {
+		return nil
+	}
+	return map[string]string{
+		model.CommanderTeamsMetadataKey: strings.Join(teams, ","),
+	}
+}
+
 func (a *WebAPI) GetApplicationLiveState(ctx context.Context, req *webservice.GetApplicationLiveStateRequest) (*webservice.GetApplicationLiveStateResponse, error) {
 	claims, err := rpcauth.ExtractClaims(ctx)
 	if err != nil {
@@ -1449,6 +1612,10 @@ func (a *WebAPI) ListDeploymentConfigTemplates(ctx context.Context, req *webserv
 		templates = cloudrunDeploymentConfigTemplates
 	case model.ApplicationKind_ECS:
 		templates = ecsDeploymentConfigTemplates
+	case model.ApplicationKind_CLOUDFUNCTIONS:
+		templates = cloudFunctionsDeploymentConfigTemplates
+	case model.ApplicationKind_NOMAD:
+		templates = nomadDeploymentConfigTemplates
 	default:
 		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("Unknown application kind %v", app.Kind))
 	}
@@ -1593,6 +1760,80 @@ func (a *WebAPI) ListAPIKeys(ctx context.Context, req *webservice.ListAPIKeysReq
 	}, nil
 }
 
+func (a *WebAPI) AddDeploymentFreeze(ctx context.Context, req *webservice.AddDeploymentFreezeRequest) (*webservice.AddDeploymentFreezeResponse, error) {
+	claims, err := rpcauth.ExtractClaims(ctx)
+	if err != nil {
+		a.logger.Error("failed to authenticate the current user", zap.Error(err))
+		return nil, err
+	}
+
+	f := model.DeploymentFreeze{
+		Id:            uuid.New().String(),
+		ProjectId:     claims.Role.ProjectId,
+		ApplicationId: req.ApplicationId,
+		Reason:        req.Reason,
+		StartedAt:     req.StartedAt,
+		EndedAt:       req.EndedAt,
+		Creator:       claims.Subject,
+	}
+	if err := addDeploymentFreeze(ctx, a.deploymentFreezeStore, a.applicationStore, &f, a.logger); err != nil {
+		return nil, err
+	}
+
+	return &webservice.AddDeploymentFreezeResponse{
+		Id: f.Id,
+	}, nil
+}
+
+func (a *WebAPI) EndDeploymentFreeze(ctx context.Context, req *webservice.EndDeploymentFreezeRequest) (*webservice.EndDeploymentFreezeResponse, error) {
+	claims, err := rpcauth.ExtractClaims(ctx)
+	if err != nil {
+		a.logger.Error("failed to authenticate the current user", zap.Error(err))
+		return nil, err
+	}
+
+	if err := endDeploymentFreeze(ctx, a.deploymentFreezeStore, req.Id, claims.Role.ProjectId, a.logger); err != nil {
+		return nil, err
+	}
+
+	return &webservice.EndDeploymentFreezeResponse{}, nil
+}
+
+func (a *WebAPI) ListDeploymentFreezes(ctx context.Context, req *webservice.ListDeploymentFreezesRequest) (*webservice.ListDeploymentFreezesResponse, error) {
+	claims, err := rpcauth.ExtractClaims(ctx)
+	if err != nil {
+		a.logger.Error("failed to authenticate the current user", zap.Error(err))
+		return nil, err
+	}
+
+	freezes, err := listDeploymentFreezes(ctx, a.deploymentFreezeStore, claims.Role.ProjectId, req.Options.GetIncludeEnded(), time.Now(), a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &webservice.ListDeploymentFreezesResponse{
+		Freezes: freezes,
+	}, nil
+}
+
+// ListPromotions returns the promotion history of a deployment or an application.
+func (a *WebAPI) ListPromotions(ctx context.Context, req *webservice.ListPromotionsRequest) (*webservice.ListPromotionsResponse, error) {
+	claims, err := rpcauth.ExtractClaims(ctx)
+	if err != nil {
+		a.logger.Error("failed to authenticate the current user", zap.Error(err))
+		return nil, err
+	}
+
+	promotions, err := listPromotions(ctx, a.promotionStore, claims.Role.ProjectId, req.DeploymentId, req.ApplicationId, req.Limit, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &webservice.ListPromotionsResponse{
+		Promotions: promotions,
+	}, nil
+}
+
 // GetInsightData returns the accumulated insight data.
 func (a *WebAPI) GetInsightData(ctx context.Context, req *webservice.GetInsightDataRequest) (*webservice.GetInsightDataResponse, error) {
 	claims, err := rpcauth.ExtractClaims(ctx)
@@ -89,6 +89,7 @@ func (h *authHandler) handleCallback(w http.ResponseWriter, r *http.Request) {
 		defaultTokenTTL,
 		*user.Role,
 	)
+	claims.Teams = user.Teams
 	signedToken, err := h.signer.Sign(claims)
 	if err != nil {
 		h.handleError(w, r, "Internal error", err)
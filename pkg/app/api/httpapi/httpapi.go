@@ -15,6 +15,7 @@
 package httpapi
 
 import (
+	"context"
 	"net/http"
 	"path/filepath"
 
@@ -27,8 +28,10 @@ import (
 	"github.com/pipe-cd/pipe/pkg/model"
 )
 
-// NewHandler gives back an HTTP handler for serving PipeCD SPA.
+// NewHandler gives back an HTTP handler for serving PipeCD SPA
+// and receiving webhook events from Git providers.
 func NewHandler(
+	ctx context.Context,
 	signer jwt.Signer,
 	staticDir string,
 	decrypter decrypter,
@@ -38,6 +41,9 @@ func NewHandler(
 	sharedSSOConfigs map[string]*model.ProjectSSOConfig,
 	projectGetter projectGetter,
 	secureCookie bool,
+	gitWebhookSecret string,
+	pipedLister pipedLister,
+	commandAdder commandAdder,
 	logger *zap.Logger,
 ) http.Handler {
 	mux := http.NewServeMux()
@@ -76,5 +82,11 @@ func NewHandler(
 	register(callbackPath, http.HandlerFunc(a.handleCallback))
 	register(logoutPath, http.HandlerFunc(a.handleLogout))
 
+	// The webhook endpoint is enabled only when its secret was configured.
+	if gitWebhookSecret != "" {
+		wh := newWebhookHandler(ctx, gitWebhookSecret, pipedLister, commandAdder, logger)
+		register(gitWebhookPath, http.HandlerFunc(wh.handleGitWebhook))
+	}
+
 	return mux
 }
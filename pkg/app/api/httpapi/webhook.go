@@ -0,0 +1,433 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpapi
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/pipe-cd/pipe/pkg/cache"
+	"github.com/pipe-cd/pipe/pkg/cache/memorycache"
+	"github.com/pipe-cd/pipe/pkg/datastore"
+	"github.com/pipe-cd/pipe/pkg/git"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+const (
+	// gitWebhookPath is the path configured in the webhook settings of Git providers.
+	gitWebhookPath = "/webhook/git"
+
+	// webhookCommander is used as the commander of the commands created by webhook events.
+	webhookCommander = "git-webhook"
+
+	githubEventHeader        = "X-GitHub-Event"
+	githubSignatureHeader    = "X-Hub-Signature-256"
+	gitlabEventHeader        = "X-Gitlab-Event"
+	gitlabTokenHeader        = "X-Gitlab-Token"
+	bitbucketEventHeader     = "X-Event-Key"
+	bitbucketSignatureHeader = "X-Hub-Signature"
+
+	githubPushEvent    = "push"
+	gitlabPushEvent    = "Push Hook"
+	gitlabTagPushEvent = "Tag Push Hook"
+	bitbucketPushEvent = "repo:push"
+
+	branchRefPrefix = "refs/heads/"
+	tagRefPrefix    = "refs/tags/"
+	zeroCommitHash  = "0000000000000000000000000000000000000000"
+
+	maxWebhookPayloadSize = 25 << 20
+
+	// The registered repositories of pipeds are cached for a while
+	// to not query the datastore on every push event.
+	pipedCacheTTL              = 5 * time.Minute
+	pipedCacheEvictionInterval = time.Minute
+)
+
+type pipedLister interface {
+	ListPipeds(ctx context.Context, opts datastore.ListOptions) ([]*model.Piped, error)
+}
+
+type commandAdder interface {
+	AddCommand(ctx context.Context, command *model.Command) error
+}
+
+// pushEvent represents a push of a branch or a tag to a Git repository.
+type pushEvent struct {
+	// The normalized URLs of the pushed repository.
+	remotes []string
+	// Only one of branch and tag is set.
+	branch string
+	tag    string
+	commit string
+}
+
+// matchRepository reports whether the given repository watched by a piped is affected by this event.
+// A pushed tag is matched with all branches of the repository
+// since the tagged commit can be checked by the piped watching any of them.
+func (e pushEvent) matchRepository(remote, branch string) bool {
+	if e.tag == "" && e.branch != branch {
+		return false
+	}
+	for _, r := range e.remotes {
+		if r == remote {
+			return true
+		}
+	}
+	return false
+}
+
+// webhookHandler handles all incoming push events sent from Git providers.
+type webhookHandler struct {
+	secret       string
+	pipedLister  pipedLister
+	commandAdder commandAdder
+	pipedCache   cache.Cache
+	logger       *zap.Logger
+}
+
+func newWebhookHandler(ctx context.Context, secret string, pipedLister pipedLister, commandAdder commandAdder, logger *zap.Logger) *webhookHandler {
+	return &webhookHandler{
+		secret:       secret,
+		pipedLister:  pipedLister,
+		commandAdder: commandAdder,
+		pipedCache:   memorycache.NewTTLCache(ctx, pipedCacheTTL, pipedCacheEvictionInterval),
+		logger:       logger.Named("webhook-handler"),
+	}
+}
+
+// handleGitWebhook verifies the incoming push event and then notifies
+// all pipeds watching the pushed repository branch (or any branch for tag pushes) by adding a REPOSITORY_UPDATED command.
+// The optional "project" query parameter can be used to limit the notified pipeds to a specific project.
+func (h *webhookHandler) handleGitWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	payload, err := ioutil.ReadAll(io.LimitReader(r.Body, maxWebhookPayloadSize))
+	if err != nil {
+		h.logger.Error("failed to read webhook payload", zap.Error(err))
+		http.Error(w, "Failed to read payload", http.StatusBadRequest)
+		return
+	}
+
+	events, err := h.parseRequest(r.Header, payload)
+	if err != nil {
+		h.logger.Info("received an invalid webhook request", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(events) == 0 {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	projectID := r.URL.Query().Get(projectFormKey)
+	notified, err := h.notifyPipeds(r.Context(), projectID, events)
+	if err != nil {
+		http.Error(w, "Failed to notify pipeds", http.StatusInternalServerError)
+		return
+	}
+
+	fmt.Fprintf(w, "notified %d piped(s)", notified)
+}
+
+// parseRequest verifies the request based on the headers of the Git provider
+// and returns the list of branch and tag push events contained in the payload.
+// An empty list is returned for the events other than push.
+func (h *webhookHandler) parseRequest(header http.Header, payload []byte) ([]pushEvent, error) {
+	switch {
+	case header.Get(githubEventHeader) != "":
+		if !verifyHMACSignature(header.Get(githubSignatureHeader), payload, h.secret) {
+			return nil, fmt.Errorf("invalid signature")
+		}
+		if header.Get(githubEventHeader) != githubPushEvent {
+			return nil, nil
+		}
+		return parseGitHubPushEvent(payload)
+
+	case header.Get(gitlabEventHeader) != "":
+		token := header.Get(gitlabTokenHeader)
+		if subtle.ConstantTimeCompare([]byte(token), []byte(h.secret)) != 1 {
+			return nil, fmt.Errorf("invalid token")
+		}
+		if e := header.Get(gitlabEventHeader); e != gitlabPushEvent && e != gitlabTagPushEvent {
+			return nil, nil
+		}
+		return parseGitLabPushEvent(payload)
+
+	case header.Get(bitbucketEventHeader) != "":
+		if !verifyHMACSignature(header.Get(bitbucketSignatureHeader), payload, h.secret) {
+			return nil, fmt.Errorf("invalid signature")
+		}
+		if header.Get(bitbucketEventHeader) != bitbucketPushEvent {
+			return nil, nil
+		}
+		return parseBitbucketPushEvent(payload)
+
+	default:
+		return nil, fmt.Errorf("unsupported webhook request")
+	}
+}
+
+func (h *webhookHandler) notifyPipeds(ctx context.Context, projectID string, events []pushEvent) (int, error) {
+	pipeds, err := h.listPipeds(ctx, projectID)
+	if err != nil {
+		h.logger.Error("failed to list pipeds", zap.Error(err))
+		return 0, err
+	}
+
+	var (
+		notified int
+		failures []string
+	)
+	for _, p := range pipeds {
+		for _, r := range p.Repositories {
+			remote, err := git.NormalizeRemoteURL(r.Remote)
+			if err != nil {
+				continue
+			}
+			for _, e := range events {
+				if !e.matchRepository(remote, r.Branch) {
+					continue
+				}
+				cmd := model.Command{
+					Id:        uuid.New().String(),
+					PipedId:   p.Id,
+					ProjectId: p.ProjectId,
+					Type:      model.Command_REPOSITORY_UPDATED,
+					Commander: webhookCommander,
+					RepositoryUpdated: &model.Command_RepositoryUpdated{
+						RepositoryId: r.Id,
+						Branch:       r.Branch,
+						CommitHash:   e.commit,
+					},
+				}
+				if err := h.commandAdder.AddCommand(ctx, &cmd); err != nil {
+					h.logger.Error("failed to add command to notify the repository update",
+						zap.String("piped-id", p.Id),
+						zap.String("repo-id", r.Id),
+						zap.Error(err),
+					)
+					// Continue to notify the other pipeds.
+					failures = append(failures, fmt.Sprintf("piped %s repository %s: %v", p.Id, r.Id, err))
+					break
+				}
+				notified++
+				// Notify each repository only once even if multiple events matched.
+				break
+			}
+		}
+	}
+
+	h.logger.Info(fmt.Sprintf("notified %d piped(s) about the repository update", notified))
+	if len(failures) > 0 {
+		return notified, fmt.Errorf("failed to notify %d repository update(s): %s", len(failures), strings.Join(failures, "; "))
+	}
+	return notified, nil
+}
+
+// listPipeds returns the enabled pipeds of the given project, or of all projects if it is empty.
+// The result is cached for a while to reduce load on the datastore.
+func (h *webhookHandler) listPipeds(ctx context.Context, projectID string) ([]*model.Piped, error) {
+	if v, err := h.pipedCache.Get(projectID); err == nil {
+		return v.([]*model.Piped), nil
+	}
+
+	filters := []datastore.ListFilter{
+		{
+			Field:    "Disabled",
+			Operator: datastore.OperatorEqual,
+			Value:    false,
+		},
+	}
+	if projectID != "" {
+		filters = append([]datastore.ListFilter{
+			{
+				Field:    "ProjectId",
+				Operator: datastore.OperatorEqual,
+				Value:    projectID,
+			},
+		}, filters...)
+	}
+
+	pipeds, err := h.pipedLister.ListPipeds(ctx, datastore.ListOptions{
+		Filters: filters,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.pipedCache.Put(projectID, pipeds); err != nil {
+		h.logger.Warn("failed to put pipeds into cache", zap.Error(err))
+	}
+	return pipeds, nil
+}
+
+// verifyHMACSignature checks whether the given signature in form of "sha256=<hex-digest>"
+// is the HMAC-SHA256 of the payload using the secret as the key.
+func verifyHMACSignature(signature string, payload []byte, secret string) bool {
+	const prefix = "sha256="
+	if !strings.HasPrefix(signature, prefix) {
+		return false
+	}
+	sig, err := hex.DecodeString(strings.TrimPrefix(signature, prefix))
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hmac.Equal(sig, mac.Sum(nil))
+}
+
+func parseGitHubPushEvent(payload []byte) ([]pushEvent, error) {
+	var p struct {
+		Ref        string `json:"ref"`
+		After      string `json:"after"`
+		Repository struct {
+			HTMLURL  string `json:"html_url"`
+			CloneURL string `json:"clone_url"`
+			SSHURL   string `json:"ssh_url"`
+		} `json:"repository"`
+	}
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+	return makePushEvents(p.Ref, p.After, p.Repository.HTMLURL, p.Repository.CloneURL, p.Repository.SSHURL), nil
+}
+
+func parseGitLabPushEvent(payload []byte) ([]pushEvent, error) {
+	var p struct {
+		Ref     string `json:"ref"`
+		After   string `json:"after"`
+		Project struct {
+			WebURL     string `json:"web_url"`
+			GitHTTPURL string `json:"git_http_url"`
+			GitSSHURL  string `json:"git_ssh_url"`
+		} `json:"project"`
+	}
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+	return makePushEvents(p.Ref, p.After, p.Project.WebURL, p.Project.GitHTTPURL, p.Project.GitSSHURL), nil
+}
+
+func parseBitbucketPushEvent(payload []byte) ([]pushEvent, error) {
+	var p struct {
+		Push struct {
+			Changes []struct {
+				New *struct {
+					Type   string `json:"type"`
+					Name   string `json:"name"`
+					Target struct {
+						Hash string `json:"hash"`
+					} `json:"target"`
+				} `json:"new"`
+			} `json:"changes"`
+		} `json:"push"`
+		Repository struct {
+			Links struct {
+				HTML struct {
+					Href string `json:"href"`
+				} `json:"html"`
+			} `json:"links"`
+		} `json:"repository"`
+	}
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+
+	events := make([]pushEvent, 0, len(p.Push.Changes))
+	for _, c := range p.Push.Changes {
+		// The new state is null when the branch or tag was deleted.
+		if c.New == nil {
+			continue
+		}
+		var ref string
+		switch c.New.Type {
+		case "branch":
+			ref = branchRefPrefix + c.New.Name
+		case "tag":
+			ref = tagRefPrefix + c.New.Name
+		default:
+			continue
+		}
+		events = append(events, makePushEvents(ref, c.New.Target.Hash, p.Repository.Links.HTML.Href)...)
+	}
+	return events, nil
+}
+
+// makePushEvents returns a push event for the given ref
+// or nothing if the ref is neither a branch nor a tag or it was deleted.
+func makePushEvents(ref, commit string, repoURLs ...string) []pushEvent {
+	if commit == zeroCommitHash {
+		return nil
+	}
+	var branch, tag string
+	switch {
+	case strings.HasPrefix(ref, branchRefPrefix):
+		branch = strings.TrimPrefix(ref, branchRefPrefix)
+	case strings.HasPrefix(ref, tagRefPrefix):
+		tag = strings.TrimPrefix(ref, tagRefPrefix)
+	default:
+		return nil
+	}
+
+	var (
+		remotes = make([]string, 0, len(repoURLs))
+		seen    = make(map[string]struct{}, len(repoURLs))
+	)
+	for _, u := range repoURLs {
+		if u == "" {
+			continue
+		}
+		remote, err := git.NormalizeRemoteURL(u)
+		if err != nil {
+			continue
+		}
+		if _, ok := seen[remote]; ok {
+			continue
+		}
+		seen[remote] = struct{}{}
+		remotes = append(remotes, remote)
+	}
+	if len(remotes) == 0 {
+		return nil
+	}
+
+	return []pushEvent{
+		{
+			remotes: remotes,
+			branch:  branch,
+			tag:     tag,
+			commit:  commit,
+		},
+	}
+}
@@ -0,0 +1,430 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpapi
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/pipe-cd/pipe/pkg/datastore"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+const testWebhookSecret = "test-secret"
+
+type fakePipedLister struct {
+	pipeds []*model.Piped
+	calls  int
+}
+
+func (l *fakePipedLister) ListPipeds(_ context.Context, _ datastore.ListOptions) ([]*model.Piped, error) {
+	l.calls++
+	return l.pipeds, nil
+}
+
+type fakeCommandAdder struct {
+	commands      []*model.Command
+	failedPipedID string
+}
+
+func (a *fakeCommandAdder) AddCommand(_ context.Context, cmd *model.Command) error {
+	if cmd.PipedId == a.failedPipedID {
+		return errors.New("unavailable")
+	}
+	a.commands = append(a.commands, cmd)
+	return nil
+}
+
+func signPayload(payload string) string {
+	mac := hmac.New(sha256.New, []byte(testWebhookSecret))
+	mac.Write([]byte(payload))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyHMACSignature(t *testing.T) {
+	payload := []byte(`{"ref":"refs/heads/master"}`)
+
+	testcases := []struct {
+		name      string
+		signature string
+		expected  bool
+	}{
+		{
+			name:      "valid signature",
+			signature: signPayload(string(payload)),
+			expected:  true,
+		},
+		{
+			name:      "missing signature",
+			signature: "",
+			expected:  false,
+		},
+		{
+			name:      "wrong algorithm",
+			signature: strings.Replace(signPayload(string(payload)), "sha256=", "sha1=", 1),
+			expected:  false,
+		},
+		{
+			name:      "signed by another secret",
+			signature: "sha256=" + strings.Repeat("0", 64),
+			expected:  false,
+		},
+		{
+			name:      "malformed signature",
+			signature: "sha256=xyz",
+			expected:  false,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := verifyHMACSignature(tc.signature, payload, testWebhookSecret)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestParseRequest(t *testing.T) {
+	const (
+		githubPayload = `{
+  "ref": "refs/heads/master",
+  "after": "abc123",
+  "repository": {
+    "html_url": "https://github.com/org/repo",
+    "clone_url": "https://github.com/org/repo.git",
+    "ssh_url": "git@github.com:org/repo.git"
+  }
+}`
+		githubTagPayload = `{
+  "ref": "refs/tags/v0.1.0",
+  "after": "abc123",
+  "repository": {
+    "html_url": "https://github.com/org/repo"
+  }
+}`
+		githubDeletePayload = `{
+  "ref": "refs/heads/feature",
+  "after": "0000000000000000000000000000000000000000",
+  "repository": {
+    "html_url": "https://github.com/org/repo"
+  }
+}`
+		gitlabPayload = `{
+  "ref": "refs/heads/main",
+  "after": "def456",
+  "project": {
+    "web_url": "https://gitlab.com/org/repo",
+    "git_http_url": "https://gitlab.com/org/repo.git",
+    "git_ssh_url": "git@gitlab.com:org/repo.git"
+  }
+}`
+		gitlabTagPayload = `{
+  "ref": "refs/tags/v0.1.0",
+  "after": "def456",
+  "project": {
+    "web_url": "https://gitlab.com/org/repo"
+  }
+}`
+		bitbucketPayload = `{
+  "push": {
+    "changes": [
+      {"new": {"type": "branch", "name": "master", "target": {"hash": "ghi789"}}},
+      {"new": {"type": "tag", "name": "v0.1.0", "target": {"hash": "ghi789"}}},
+      {"new": null}
+    ]
+  },
+  "repository": {
+    "links": {"html": {"href": "https://bitbucket.org/org/repo"}}
+  }
+}`
+	)
+
+	testcases := []struct {
+		name        string
+		header      map[string]string
+		payload     string
+		expected    []pushEvent
+		expectedErr bool
+	}{
+		{
+			name: "github push",
+			header: map[string]string{
+				githubEventHeader:     githubPushEvent,
+				githubSignatureHeader: signPayload(githubPayload),
+			},
+			payload: githubPayload,
+			expected: []pushEvent{
+				{
+					remotes: []string{"github.com/org/repo"},
+					branch:  "master",
+					commit:  "abc123",
+				},
+			},
+		},
+		{
+			name: "github push with invalid signature",
+			header: map[string]string{
+				githubEventHeader:     githubPushEvent,
+				githubSignatureHeader: signPayload("another"),
+			},
+			payload:     githubPayload,
+			expectedErr: true,
+		},
+		{
+			name: "github ping",
+			header: map[string]string{
+				githubEventHeader:     "ping",
+				githubSignatureHeader: signPayload("{}"),
+			},
+			payload: "{}",
+		},
+		{
+			name: "github tag push",
+			header: map[string]string{
+				githubEventHeader:     githubPushEvent,
+				githubSignatureHeader: signPayload(githubTagPayload),
+			},
+			payload: githubTagPayload,
+			expected: []pushEvent{
+				{
+					remotes: []string{"github.com/org/repo"},
+					tag:     "v0.1.0",
+					commit:  "abc123",
+				},
+			},
+		},
+		{
+			name: "github branch deletion",
+			header: map[string]string{
+				githubEventHeader:     githubPushEvent,
+				githubSignatureHeader: signPayload(githubDeletePayload),
+			},
+			payload: githubDeletePayload,
+		},
+		{
+			name: "gitlab push",
+			header: map[string]string{
+				gitlabEventHeader: gitlabPushEvent,
+				gitlabTokenHeader: testWebhookSecret,
+			},
+			payload: gitlabPayload,
+			expected: []pushEvent{
+				{
+					remotes: []string{"gitlab.com/org/repo"},
+					branch:  "main",
+					commit:  "def456",
+				},
+			},
+		},
+		{
+			name: "gitlab tag push",
+			header: map[string]string{
+				gitlabEventHeader: gitlabTagPushEvent,
+				gitlabTokenHeader: testWebhookSecret,
+			},
+			payload: gitlabTagPayload,
+			expected: []pushEvent{
+				{
+					remotes: []string{"gitlab.com/org/repo"},
+					tag:     "v0.1.0",
+					commit:  "def456",
+				},
+			},
+		},
+		{
+			name: "gitlab push with invalid token",
+			header: map[string]string{
+				gitlabEventHeader: gitlabPushEvent,
+				gitlabTokenHeader: "wrong",
+			},
+			payload:     gitlabPayload,
+			expectedErr: true,
+		},
+		{
+			name: "bitbucket push",
+			header: map[string]string{
+				bitbucketEventHeader:     bitbucketPushEvent,
+				bitbucketSignatureHeader: signPayload(bitbucketPayload),
+			},
+			payload: bitbucketPayload,
+			expected: []pushEvent{
+				{
+					remotes: []string{"bitbucket.org/org/repo"},
+					branch:  "master",
+					commit:  "ghi789",
+				},
+				{
+					remotes: []string{"bitbucket.org/org/repo"},
+					tag:     "v0.1.0",
+					commit:  "ghi789",
+				},
+			},
+		},
+		{
+			name: "bitbucket push without signature",
+			header: map[string]string{
+				bitbucketEventHeader: bitbucketPushEvent,
+			},
+			payload:     bitbucketPayload,
+			expectedErr: true,
+		},
+		{
+			name:        "unsupported provider",
+			payload:     githubPayload,
+			expectedErr: true,
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	h := newWebhookHandler(ctx, testWebhookSecret, nil, nil, zap.NewNop())
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			header := http.Header{}
+			for k, v := range tc.header {
+				header.Set(k, v)
+			}
+			got, err := h.parseRequest(header, []byte(tc.payload))
+			assert.Equal(t, tc.expectedErr, err != nil)
+			assert.Equal(t, len(tc.expected), len(got))
+			if len(tc.expected) > 0 {
+				assert.Equal(t, tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestHandleGitWebhook(t *testing.T) {
+	const (
+		payload = `{
+  "ref": "refs/heads/master",
+  "after": "abc123",
+  "repository": {
+    "html_url": "https://github.com/org/repo"
+  }
+}`
+		tagPayload = `{
+  "ref": "refs/tags/v0.1.0",
+  "after": "def456",
+  "repository": {
+    "html_url": "https://github.com/org/repo"
+  }
+}`
+	)
+
+	pipedLister := &fakePipedLister{
+		pipeds: []*model.Piped{
+			{
+				Id:        "piped-1",
+				ProjectId: "project",
+				Repositories: []*model.ApplicationGitRepository{
+					{Id: "repo-1", Remote: "git@github.com:org/repo.git", Branch: "master"},
+					{Id: "repo-2", Remote: "git@github.com:org/repo.git", Branch: "dev"},
+					{Id: "repo-3", Remote: "git@github.com:org/another.git", Branch: "master"},
+				},
+			},
+			{
+				Id:        "piped-2",
+				ProjectId: "project",
+				Repositories: []*model.ApplicationGitRepository{
+					{Id: "repo", Remote: "https://github.com/org/repo.git", Branch: "master"},
+				},
+			},
+		},
+	}
+	commandAdder := &fakeCommandAdder{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	h := newWebhookHandler(ctx, testWebhookSecret, pipedLister, commandAdder, zap.NewNop())
+
+	req := httptest.NewRequest(http.MethodPost, gitWebhookPath, strings.NewReader(payload))
+	req.Header.Set(githubEventHeader, githubPushEvent)
+	req.Header.Set(githubSignatureHeader, signPayload(payload))
+	rec := httptest.NewRecorder()
+
+	h.handleGitWebhook(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	require.Equal(t, 2, len(commandAdder.commands))
+	expected := []*model.Command_RepositoryUpdated{
+		{RepositoryId: "repo-1", Branch: "master", CommitHash: "abc123"},
+		{RepositoryId: "repo", Branch: "master", CommitHash: "abc123"},
+	}
+	for i, cmd := range commandAdder.commands {
+		assert.Equal(t, model.Command_REPOSITORY_UPDATED, cmd.Type)
+		assert.Equal(t, "project", cmd.ProjectId)
+		assert.Equal(t, expected[i], cmd.RepositoryUpdated)
+	}
+	assert.Equal(t, "piped-1", commandAdder.commands[0].PipedId)
+	assert.Equal(t, "piped-2", commandAdder.commands[1].PipedId)
+
+	// Requests with an invalid signature must not notify anything.
+	req = httptest.NewRequest(http.MethodPost, gitWebhookPath, strings.NewReader(payload))
+	req.Header.Set(githubEventHeader, githubPushEvent)
+	req.Header.Set(githubSignatureHeader, signPayload("another"))
+	rec = httptest.NewRecorder()
+
+	h.handleGitWebhook(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, 2, len(commandAdder.commands))
+
+	// A pushed tag must be notified to all branches of the repository.
+	req = httptest.NewRequest(http.MethodPost, gitWebhookPath, strings.NewReader(tagPayload))
+	req.Header.Set(githubEventHeader, githubPushEvent)
+	req.Header.Set(githubSignatureHeader, signPayload(tagPayload))
+	rec = httptest.NewRecorder()
+
+	h.handleGitWebhook(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	require.Equal(t, 5, len(commandAdder.commands))
+	expected = []*model.Command_RepositoryUpdated{
+		{RepositoryId: "repo-1", Branch: "master", CommitHash: "def456"},
+		{RepositoryId: "repo-2", Branch: "dev", CommitHash: "def456"},
+		{RepositoryId: "repo", Branch: "master", CommitHash: "def456"},
+	}
+	for i, cmd := range commandAdder.commands[2:] {
+		assert.Equal(t, expected[i], cmd.RepositoryUpdated)
+	}
+
+	// The list of pipeds must be loaded from the datastore only once.
+	assert.Equal(t, 1, pipedLister.calls)
+
+	// A failure of notifying a piped must not prevent notifying the others.
+	commandAdder.failedPipedID = "piped-1"
+	req = httptest.NewRequest(http.MethodPost, gitWebhookPath, strings.NewReader(payload))
+	req.Header.Set(githubEventHeader, githubPushEvent)
+	req.Header.Set(githubSignatureHeader, signPayload(payload))
+	rec = httptest.NewRecorder()
+
+	h.handleGitWebhook(rec, req)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	require.Equal(t, 6, len(commandAdder.commands))
+	assert.Equal(t, "piped-2", commandAdder.commands[5].PipedId)
+	assert.Equal(t, &model.Command_RepositoryUpdated{RepositoryId: "repo", Branch: "master", CommitHash: "abc123"}, commandAdder.commands[5].RepositoryUpdated)
+}
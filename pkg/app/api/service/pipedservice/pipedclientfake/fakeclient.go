@@ -486,4 +486,14 @@ func (c *fakeClient) ListEvents(ctx context.Context, req *pipedservice.ListEvent
 	return &pipedservice.ListEventsResponse{}, nil
 }
 
+func (c *fakeClient) ListDeploymentFreezes(ctx context.Context, req *pipedservice.ListDeploymentFreezesRequest, opts ...grpc.CallOption) (*pipedservice.ListDeploymentFreezesResponse, error) {
+	c.logger.Info("fake client received ListDeploymentFreezes rpc", zap.Any("request", req))
+	return &pipedservice.ListDeploymentFreezesResponse{}, nil
+}
+
+func (c *fakeClient) ReportPromotion(ctx context.Context, req *pipedservice.ReportPromotionRequest, opts ...grpc.CallOption) (*pipedservice.ReportPromotionResponse, error) {
+	c.logger.Info("fake client received ReportPromotion rpc", zap.Any("request", req))
+	return &pipedservice.ReportPromotionResponse{}, nil
+}
+
 var _ pipedservice.PipedServiceClient = (*fakeClient)(nil)
@@ -76,6 +76,12 @@ func (a *authorizer) Authorize(method string, r model.Role) bool {
 		return isAdmin(r)
 	case "/pipe.api.service.webservice.WebService/ListAPIKeys":
 		return isAdmin(r)
+	case "/pipe.api.service.webservice.WebService/AddDeploymentFreeze":
+		return isAdmin(r)
+	case "/pipe.api.service.webservice.WebService/EndDeploymentFreeze":
+		return isAdmin(r)
+	case "/pipe.api.service.webservice.WebService/OverrideDeploymentWindow":
+		return isAdmin(r)
 
 	case "/pipe.api.service.webservice.WebService/AddApplication":
 		return isAdmin(r) || isEditor(r)
@@ -95,6 +101,12 @@ func (a *authorizer) Authorize(method string, r model.Role) bool {
 		return isAdmin(r) || isEditor(r)
 	case "/pipe.api.service.webservice.WebService/ApproveStage":
 		return isAdmin(r) || isEditor(r)
+	case "/pipe.api.service.webservice.WebService/RejectStage":
+		return isAdmin(r) || isEditor(r)
+	case "/pipe.api.service.webservice.WebService/RetryStage":
+		return isAdmin(r) || isEditor(r)
+	case "/pipe.api.service.webservice.WebService/SkipStage":
+		return isAdmin(r) || isEditor(r)
 	case "/pipe.api.service.webservice.WebService/GenerateApplicationSealedSecret":
 		return isAdmin(r) || isEditor(r)
 
@@ -128,6 +140,10 @@ func (a *authorizer) Authorize(method string, r model.Role) bool {
 		return isAdmin(r) || isEditor(r) || isViewer(r)
 	case "/pipe.api.service.webservice.WebService/GetInsightApplicationCount":
 		return isAdmin(r) || isEditor(r) || isViewer(r)
+	case "/pipe.api.service.webservice.WebService/ListDeploymentFreezes":
+		return isAdmin(r) || isEditor(r) || isViewer(r)
+	case "/pipe.api.service.webservice.WebService/ListPromotions":
+		return isAdmin(r) || isEditor(r) || isViewer(r)
 	}
 
 	return false
@@ -291,6 +291,22 @@ func TestParseIndexes(t *testing.T) {
 				},
 			},
 		},
+		{
+			CollectionGroup: "DeploymentFreeze",
+			QueryScope:      "COLLECTION",
+			Fields: []field{
+				{
+					FieldPath:   "ProjectId",
+					Order:       "ASCENDING",
+					ArrayConfig: "",
+				},
+				{
+					FieldPath:   "EndedAt",
+					Order:       "ASCENDING",
+					ArrayConfig: "",
+				},
+			},
+		},
 		{
 			CollectionGroup: "Event",
 			QueryScope:      "COLLECTION",
@@ -359,6 +375,48 @@ func TestParseIndexes(t *testing.T) {
 				},
 			},
 		},
+		{
+			CollectionGroup: "Promotion",
+			QueryScope:      "COLLECTION",
+			Fields: []field{
+				{
+					FieldPath:   "ProjectId",
+					Order:       "ASCENDING",
+					ArrayConfig: "",
+				},
+				{
+					FieldPath:   "ApplicationId",
+					Order:       "ASCENDING",
+					ArrayConfig: "",
+				},
+				{
+					FieldPath:   "CreatedAt",
+					Order:       "DESCENDING",
+					ArrayConfig: "",
+				},
+			},
+		},
+		{
+			CollectionGroup: "Promotion",
+			QueryScope:      "COLLECTION",
+			Fields: []field{
+				{
+					FieldPath:   "ProjectId",
+					Order:       "ASCENDING",
+					ArrayConfig: "",
+				},
+				{
+					FieldPath:   "DeploymentId",
+					Order:       "ASCENDING",
+					ArrayConfig: "",
+				},
+				{
+					FieldPath:   "CreatedAt",
+					Order:       "DESCENDING",
+					ArrayConfig: "",
+				},
+			},
+		},
 	}
 
 	got, err := parseIndexes()
@@ -33,7 +33,11 @@ func NewCommand() *cobra.Command {
 		Short: "Manage deployment resources.",
 	}
 
-	cmd.AddCommand(newWaitStatusCommand(c))
+	cmd.AddCommand(
+		newWaitStatusCommand(c),
+		newRetryCommand(c),
+		newSkipCommand(c),
+	)
 
 	c.clientOptions.RegisterPersistentFlags(cmd)
 
@@ -0,0 +1,101 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployment
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pipe-cd/pipe/pkg/app/api/service/apiservice"
+	"github.com/pipe-cd/pipe/pkg/cli"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+type retry struct {
+	root *command
+
+	deploymentID string
+	stageID      string
+}
+
+func newRetryCommand(root *command) *cobra.Command {
+	c := &retry{
+		root: root,
+	}
+	cmd := &cobra.Command{
+		Use:   "retry",
+		Short: "Retry the failed stage of a failed deployment and resume the deployment from that stage.",
+		RunE:  cli.WithContext(c.run),
+	}
+
+	cmd.Flags().StringVar(&c.deploymentID, "deployment-id", c.deploymentID, "The deployment ID.")
+	cmd.Flags().StringVar(&c.stageID, "stage-id", c.stageID, "The ID of the failed stage. Default is the failed stage of the deployment.")
+
+	cmd.MarkFlagRequired("deployment-id")
+
+	return cmd
+}
+
+func (c *retry) run(ctx context.Context, t cli.Telemetry) error {
+	cli, err := c.root.clientOptions.NewClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize client: %w", err)
+	}
+	defer cli.Close()
+
+	stageID := c.stageID
+	if stageID == "" {
+		resp, err := cli.GetDeployment(ctx, &apiservice.GetDeploymentRequest{
+			DeploymentId: c.deploymentID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get deployment: %w", err)
+		}
+		stage, ok := findFailedStage(resp.Deployment)
+		if !ok {
+			return fmt.Errorf("no failed stage was found in deployment %s", c.deploymentID)
+		}
+		stageID = stage.Id
+	}
+
+	req := &apiservice.RetryStageRequest{
+		DeploymentId: c.deploymentID,
+		StageId:      stageID,
+	}
+
+	resp, err := cli.RetryStage(ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to retry stage: %w", err)
+	}
+
+	t.Logger.Info(fmt.Sprintf("Successfully requested to retry stage %s, command id = %s", stageID, resp.CommandId))
+	return nil
+}
+
+// findFailedStage returns the failed pipeline stage of the given deployment.
+func findFailedStage(d *model.Deployment) (*model.PipelineStage, bool) {
+	for _, s := range d.Stages {
+		if !s.Visible || s.Status != model.StageStatus_STAGE_FAILURE {
+			continue
+		}
+		if s.Name == model.StageRollback.String() || s.Name == model.StageScriptRunRollback.String() {
+			continue
+		}
+		return s, true
+	}
+	return nil, false
+}
@@ -0,0 +1,135 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployment
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pipe-cd/pipe/pkg/app/api/service/apiservice"
+	"github.com/pipe-cd/pipe/pkg/cli"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+type skip struct {
+	root *command
+
+	deploymentID string
+	stageID      string
+	untilStageID string
+}
+
+func newSkipCommand(root *command) *cobra.Command {
+	c := &skip{
+		root: root,
+	}
+	cmd := &cobra.Command{
+		Use:   "skip",
+		Short: "Skip the running ANALYSIS or WAIT stage of a deployment, or fast-forward the deployment to a given stage.",
+		RunE:  cli.WithContext(c.run),
+	}
+
+	cmd.Flags().StringVar(&c.deploymentID, "deployment-id", c.deploymentID, "The deployment ID.")
+	cmd.Flags().StringVar(&c.stageID, "stage-id", c.stageID, "The ID of the ANALYSIS or WAIT stage to skip.")
+	cmd.Flags().StringVar(&c.untilStageID, "until-stage-id", c.untilStageID, "The ID of the stage to fast-forward to. All uncompleted ANALYSIS and WAIT stages that stage depends on will be skipped.")
+
+	cmd.MarkFlagRequired("deployment-id")
+
+	return cmd
+}
+
+func (c *skip) run(ctx context.Context, t cli.Telemetry) error {
+	if (c.stageID == "") == (c.untilStageID == "") {
+		return fmt.Errorf("exactly one of --stage-id or --until-stage-id must be specified")
+	}
+
+	cli, err := c.root.clientOptions.NewClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize client: %w", err)
+	}
+	defer cli.Close()
+
+	stageIDs := []string{c.stageID}
+	if c.untilStageID != "" {
+		resp, err := cli.GetDeployment(ctx, &apiservice.GetDeploymentRequest{
+			DeploymentId: c.deploymentID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get deployment: %w", err)
+		}
+		stages, err := findSkippableStages(resp.Deployment, c.untilStageID)
+		if err != nil {
+			return err
+		}
+		if len(stages) == 0 {
+			t.Logger.Info(fmt.Sprintf("No stage to skip before stage %s", c.untilStageID))
+			return nil
+		}
+		stageIDs = make([]string, 0, len(stages))
+		for _, s := range stages {
+			stageIDs = append(stageIDs, s.Id)
+		}
+	}
+
+	for _, id := range stageIDs {
+		req := &apiservice.SkipStageRequest{
+			DeploymentId: c.deploymentID,
+			StageId:      id,
+		}
+		resp, err := cli.SkipStage(ctx, req)
+		if err != nil {
+			return fmt.Errorf("failed to skip stage %s: %w", id, err)
+		}
+		t.Logger.Info(fmt.Sprintf("Successfully requested to skip stage %s, command id = %s", id, resp.CommandId))
+	}
+	return nil
+}
+
+// findSkippableStages returns all uncompleted ANALYSIS and WAIT stages
+// the given target stage directly or indirectly requires, in the pipeline order.
+func findSkippableStages(d *model.Deployment, targetID string) ([]*model.PipelineStage, error) {
+	target, ok := d.FindStage(targetID)
+	if !ok {
+		return nil, fmt.Errorf("stage %s was not found in deployment %s", targetID, d.Id)
+	}
+
+	required := make(map[string]struct{})
+	queue := append([]string(nil), target.Requires...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if _, ok := required[id]; ok {
+			continue
+		}
+		required[id] = struct{}{}
+		if s, ok := d.FindStage(id); ok {
+			queue = append(queue, s.Requires...)
+		}
+	}
+
+	stages := make([]*model.PipelineStage, 0, len(required))
+	for _, s := range d.Stages {
+		if _, ok := required[s.Id]; !ok {
+			continue
+		}
+		if model.IsCompletedStage(s.Status) || !model.Stage(s.Name).IsSkippable() {
+			continue
+		}
+		stages = append(stages, s)
+	}
+	return stages, nil
+}
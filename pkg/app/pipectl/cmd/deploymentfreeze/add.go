@@ -0,0 +1,107 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploymentfreeze
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pipe-cd/pipe/pkg/app/api/service/apiservice"
+	"github.com/pipe-cd/pipe/pkg/cli"
+)
+
+type add struct {
+	root *command
+
+	appID    string
+	reason   string
+	start    string
+	end      string
+	duration time.Duration
+}
+
+func newAddCommand(root *command) *cobra.Command {
+	c := &add{
+		root: root,
+	}
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Add a deployment freeze to block all deployments of the project or of a given application.",
+		RunE:  cli.WithContext(c.run),
+	}
+
+	cmd.Flags().StringVar(&c.appID, "app-id", c.appID, "The application ID. Leave it empty to freeze all applications of the project.")
+	cmd.Flags().StringVar(&c.reason, "reason", c.reason, "The reason of the freeze.")
+	cmd.Flags().StringVar(&c.start, "start", c.start, "The start time of the freeze in RFC3339 format. Default is now.")
+	cmd.Flags().StringVar(&c.end, "end", c.end, "The end time of the freeze in RFC3339 format.")
+	cmd.Flags().DurationVar(&c.duration, "duration", c.duration, "How long the freeze lasts from the start time. Used when --end is not specified.")
+
+	cmd.MarkFlagRequired("reason")
+
+	return cmd
+}
+
+func (c *add) run(ctx context.Context, t cli.Telemetry) error {
+	startedAt := time.Now()
+	if c.start != "" {
+		v, err := time.Parse(time.RFC3339, c.start)
+		if err != nil {
+			return fmt.Errorf("invalid start time: %w", err)
+		}
+		startedAt = v
+	}
+
+	var endedAt time.Time
+	switch {
+	case c.end != "":
+		v, err := time.Parse(time.RFC3339, c.end)
+		if err != nil {
+			return fmt.Errorf("invalid end time: %w", err)
+		}
+		endedAt = v
+	case c.duration > 0:
+		endedAt = startedAt.Add(c.duration)
+	default:
+		return fmt.Errorf("either --end or --duration must be specified")
+	}
+
+	if !endedAt.After(startedAt) {
+		return fmt.Errorf("end time must be after the start time")
+	}
+
+	cli, err := c.root.clientOptions.NewClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize client: %w", err)
+	}
+	defer cli.Close()
+
+	req := &apiservice.AddDeploymentFreezeRequest{
+		ApplicationId: c.appID,
+		Reason:        c.reason,
+		StartedAt:     startedAt.Unix(),
+		EndedAt:       endedAt.Unix(),
+	}
+
+	resp, err := cli.AddDeploymentFreeze(ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to add deployment freeze: %w", err)
+	}
+
+	t.Logger.Info(fmt.Sprintf("Successfully added deployment freeze id = %s", resp.Id))
+	return nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploymentfreeze
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pipe-cd/pipe/pkg/app/api/service/apiservice"
+	"github.com/pipe-cd/pipe/pkg/cli"
+)
+
+type end struct {
+	root *command
+
+	id string
+}
+
+func newEndCommand(root *command) *cobra.Command {
+	c := &end{
+		root: root,
+	}
+	cmd := &cobra.Command{
+		Use:   "end",
+		Short: "End a deployment freeze immediately.",
+		RunE:  cli.WithContext(c.run),
+	}
+
+	cmd.Flags().StringVar(&c.id, "id", c.id, "The ID of deployment freeze.")
+
+	cmd.MarkFlagRequired("id")
+
+	return cmd
+}
+
+func (c *end) run(ctx context.Context, t cli.Telemetry) error {
+	cli, err := c.root.clientOptions.NewClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize client: %w", err)
+	}
+	defer cli.Close()
+
+	req := &apiservice.EndDeploymentFreezeRequest{
+		Id: c.id,
+	}
+
+	if _, err := cli.EndDeploymentFreeze(ctx, req); err != nil {
+		return fmt.Errorf("failed to end deployment freeze: %w", err)
+	}
+
+	t.Logger.Info("Successfully ended deployment freeze")
+	return nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploymentfreeze
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pipe-cd/pipe/pkg/app/api/service/apiservice"
+	"github.com/pipe-cd/pipe/pkg/cli"
+)
+
+type list struct {
+	root *command
+
+	includeEnded bool
+	stdout       io.Writer
+}
+
+func newListCommand(root *command) *cobra.Command {
+	c := &list{
+		root:   root,
+		stdout: os.Stdout,
+	}
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Show the list of deployment freezes.",
+		RunE:  cli.WithContext(c.run),
+	}
+
+	cmd.Flags().BoolVar(&c.includeEnded, "include-ended", c.includeEnded, "True to also show the freezes which have already ended.")
+
+	return cmd
+}
+
+func (c *list) run(ctx context.Context, _ cli.Telemetry) error {
+	cli, err := c.root.clientOptions.NewClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize client: %w", err)
+	}
+	defer cli.Close()
+
+	req := &apiservice.ListDeploymentFreezesRequest{
+		IncludeEnded: c.includeEnded,
+	}
+
+	resp, err := cli.ListDeploymentFreezes(ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to list deployment freezes: %w", err)
+	}
+
+	bytes, err := json.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("failed to marshal deployment freezes: %w", err)
+	}
+
+	fmt.Fprintln(c.stdout, string(bytes))
+	return nil
+}
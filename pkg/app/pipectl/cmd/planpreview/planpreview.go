@@ -253,7 +253,7 @@ type ApplicationInfo struct {
 	EnvID                string
 	EnvName              string
 	EnvURL               string
-	ApplicationKind      string // KUBERNETES, TERRAFORM, CLOUDRUN, LAMBDA, ECS
+	ApplicationKind      string // KUBERNETES, TERRAFORM, CROSSPLANE, CLOUDRUN, LAMBDA, ECS, CLOUDFUNCTIONS, NOMAD
 	ApplicationDirectory string
 }
 
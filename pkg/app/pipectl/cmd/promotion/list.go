@@ -0,0 +1,86 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promotion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pipe-cd/pipe/pkg/app/api/service/apiservice"
+	"github.com/pipe-cd/pipe/pkg/cli"
+)
+
+type list struct {
+	root *command
+
+	deploymentID string
+	appID        string
+	limit        int32
+	stdout       io.Writer
+}
+
+func newListCommand(root *command) *cobra.Command {
+	c := &list{
+		root:   root,
+		stdout: os.Stdout,
+	}
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Show the promotion history of a deployment or an application.",
+		RunE:  cli.WithContext(c.run),
+	}
+
+	cmd.Flags().StringVar(&c.deploymentID, "deployment-id", c.deploymentID, "The ID of the deployment whose promotions should be shown.")
+	cmd.Flags().StringVar(&c.appID, "app-id", c.appID, "The ID of the application whose promotions should be shown.")
+	cmd.Flags().Int32Var(&c.limit, "limit", c.limit, "The maximum number of promotions to show. Default is 50.")
+
+	return cmd
+}
+
+func (c *list) run(ctx context.Context, _ cli.Telemetry) error {
+	if (c.deploymentID == "") == (c.appID == "") {
+		return fmt.Errorf("exactly one of --deployment-id or --app-id must be specified")
+	}
+
+	cli, err := c.root.clientOptions.NewClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize client: %w", err)
+	}
+	defer cli.Close()
+
+	req := &apiservice.ListPromotionsRequest{
+		DeploymentId:  c.deploymentID,
+		ApplicationId: c.appID,
+		Limit:         c.limit,
+	}
+
+	resp, err := cli.ListPromotions(ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to list promotions: %w", err)
+	}
+
+	bytes, err := json.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("failed to marshal promotions: %w", err)
+	}
+
+	fmt.Fprintln(c.stdout, string(bytes))
+	return nil
+}
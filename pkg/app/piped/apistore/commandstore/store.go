@@ -122,11 +122,11 @@ func (s *store) sync(ctx context.Context) error {
 	)
 	for _, cmd := range resp.Commands {
 		switch cmd.Type {
-		case model.Command_SYNC_APPLICATION, model.Command_UPDATE_APPLICATION_CONFIG:
+		case model.Command_SYNC_APPLICATION, model.Command_UPDATE_APPLICATION_CONFIG, model.Command_REPOSITORY_UPDATED:
 			applicationCommands = append(applicationCommands, s.makeReportableCommand(cmd))
-		case model.Command_CANCEL_DEPLOYMENT:
+		case model.Command_CANCEL_DEPLOYMENT, model.Command_OVERRIDE_DEPLOYMENT_WINDOW, model.Command_RETRY_STAGE:
 			deploymentCommands = append(deploymentCommands, s.makeReportableCommand(cmd))
-		case model.Command_APPROVE_STAGE:
+		case model.Command_APPROVE_STAGE, model.Command_REJECT_STAGE, model.Command_SKIP_STAGE:
 			stageCommands = append(stageCommands, s.makeReportableCommand(cmd))
 		case model.Command_BUILD_PLAN_PREVIEW:
 			planPreviewCommands = append(planPreviewCommands, s.makeReportableCommand(cmd))
@@ -0,0 +1,372 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudfunctions
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+	htransport "google.golang.org/api/transport/http"
+
+	"github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/cloudrun"
+)
+
+const (
+	apiEndpoint        = "https://cloudfunctions.googleapis.com/v2/"
+	cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+	operationCheckInterval = 5 * time.Second
+	trafficCheckInterval   = 5 * time.Second
+	trafficCheckTimeout    = 10 * time.Minute
+)
+
+type client struct {
+	projectID  string
+	region     string
+	httpClient *http.Client
+	runClient  cloudrun.Client
+	logger     *zap.Logger
+}
+
+func newClient(ctx context.Context, projectID, region, credentialsFile string, logger *zap.Logger) (*client, error) {
+	c := &client{
+		projectID: projectID,
+		region:    region,
+		logger:    logger.Named("cloudfunctions"),
+	}
+
+	options := []option.ClientOption{
+		option.WithScopes(cloudPlatformScope),
+	}
+	if len(credentialsFile) > 0 {
+		data, err := ioutil.ReadFile(credentialsFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read credentials file (%w)", err)
+		}
+		options = append(options, option.WithCredentialsJSON(data))
+	}
+
+	httpClient, _, err := htransport.NewClient(ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+	c.httpClient = httpClient
+
+	// The traffic of a 2nd gen function is managed by the Cloud Run service running it.
+	runClient, err := cloudrun.NewClient(ctx, projectID, region, credentialsFile, logger)
+	if err != nil {
+		return nil, err
+	}
+	c.runClient = runClient
+
+	return c, nil
+}
+
+func (c *client) GetFunction(ctx context.Context, name string) (*Function, error) {
+	var fn Function
+	if err := c.do(ctx, http.MethodGet, c.functionName(name), nil, nil, &fn); err != nil {
+		return nil, err
+	}
+	return &fn, nil
+}
+
+func (c *client) ListFunctions(ctx context.Context) ([]*Function, error) {
+	var (
+		out   []*Function
+		query = url.Values{}
+	)
+	for {
+		var resp struct {
+			Functions     []*Function `json:"functions"`
+			NextPageToken string      `json:"nextPageToken"`
+		}
+		if err := c.do(ctx, http.MethodGet, c.collection(), query, nil, &resp); err != nil {
+			return nil, err
+		}
+		out = append(out, resp.Functions...)
+		if resp.NextPageToken == "" {
+			return out, nil
+		}
+		query.Set("pageToken", resp.NextPageToken)
+	}
+}
+
+func (c *client) CreateFunction(ctx context.Context, fm FunctionManifest) (*Function, error) {
+	var (
+		op    operation
+		query = url.Values{"functionId": {fm.Spec.Name}}
+		fn    = makeFunction(c.functionName(fm.Spec.Name), fm, true)
+	)
+	if err := c.do(ctx, http.MethodPost, c.collection(), query, fn, &op); err != nil {
+		return nil, fmt.Errorf("failed to create function %s: %w", fm.Spec.Name, err)
+	}
+	if err := c.waitOperation(ctx, &op); err != nil {
+		return nil, err
+	}
+	return c.GetFunction(ctx, fm.Spec.Name)
+}
+
+func (c *client) UpdateFunction(ctx context.Context, fm FunctionManifest, allTraffic bool) (*Function, error) {
+	var (
+		op   operation
+		name = c.functionName(fm.Spec.Name)
+	)
+	// All fields are replaced since no update mask is specified.
+	if err := c.do(ctx, http.MethodPatch, name, nil, makeFunction(name, fm, allTraffic), &op); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to update function %s: %w", fm.Spec.Name, err)
+	}
+	if err := c.waitOperation(ctx, &op); err != nil {
+		return nil, err
+	}
+	return c.GetFunction(ctx, fm.Spec.Name)
+}
+
+func (c *client) GetTraffic(ctx context.Context, name string) ([]RevisionTraffic, error) {
+	svc, err := c.getService(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return makeRevisionTraffics(svc), nil
+}
+
+func (c *client) UpdateTraffic(ctx context.Context, name string, traffics []RevisionTraffic) error {
+	svc, err := c.getService(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	// Use the live service as the manifest to change nothing but its traffic.
+	data, err := json.Marshal(svc)
+	if err != nil {
+		return err
+	}
+	sm, err := cloudrun.ParseServiceManifest(data)
+	if err != nil {
+		return err
+	}
+	runTraffics := make([]cloudrun.RevisionTraffic, 0, len(traffics))
+	for _, t := range traffics {
+		runTraffics = append(runTraffics, cloudrun.RevisionTraffic{
+			RevisionName: t.RevisionName,
+			Percent:      t.Percent,
+		})
+	}
+	if err := sm.UpdateTraffic(runTraffics); err != nil {
+		return err
+	}
+
+	svc, err = c.runClient.Update(ctx, sm)
+	if err != nil {
+		return fmt.Errorf("failed to update the traffic of function %s: %w", name, err)
+	}
+	var generation int64
+	if svc.Metadata != nil {
+		generation = svc.Metadata.Generation
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, trafficCheckTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(trafficCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		applied, err := svc.CheckTrafficApplied(generation, runTraffics)
+		if err != nil {
+			return err
+		}
+		if applied {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("the traffic of function %s was not applied before the deadline (%w)", name, ctx.Err())
+		case <-ticker.C:
+		}
+
+		svc, err = c.runClient.Get(ctx, sm.Name)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// getService returns the Cloud Run service running the given function.
+func (c *client) getService(ctx context.Context, name string) (*cloudrun.Service, error) {
+	fn, err := c.GetFunction(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if fn.ServiceConfig == nil || fn.ServiceConfig.Service == "" {
+		return nil, fmt.Errorf("function %s has not been running on any service yet", name)
+	}
+
+	svc, err := c.runClient.Get(ctx, path.Base(fn.ServiceConfig.Service))
+	if errors.Is(err, cloudrun.ErrServiceNotFound) {
+		return nil, ErrNotFound
+	}
+	return svc, err
+}
+
+func makeRevisionTraffics(svc *cloudrun.Service) []RevisionTraffic {
+	if svc.Status == nil {
+		return nil
+	}
+	var (
+		out   = make([]RevisionTraffic, 0, len(svc.Status.Traffic))
+		index = make(map[string]int, len(svc.Status.Traffic))
+	)
+	for _, t := range svc.Status.Traffic {
+		if t == nil || t.Percent == 0 {
+			continue
+		}
+		if i, ok := index[t.RevisionName]; ok {
+			out[i].Percent += int(t.Percent)
+			continue
+		}
+		index[t.RevisionName] = len(out)
+		out = append(out, RevisionTraffic{
+			RevisionName: t.RevisionName,
+			Percent:      int(t.Percent),
+		})
+	}
+	return out
+}
+
+func makeFunction(name string, fm FunctionManifest, allTraffic bool) *Function {
+	spec := fm.Spec
+	return &Function{
+		Name:        name,
+		Description: spec.Description,
+		BuildConfig: &BuildConfig{
+			Runtime:    spec.Runtime,
+			EntryPoint: spec.EntryPoint,
+			Source: &Source{
+				StorageSource: &StorageSource{
+					Bucket:     spec.Source.Bucket,
+					Object:     spec.Source.Object,
+					Generation: spec.Source.Generation,
+				},
+			},
+			EnvironmentVariables: spec.BuildEnvironments,
+		},
+		ServiceConfig: &ServiceConfig{
+			TimeoutSeconds:             spec.Timeout,
+			AvailableMemory:            spec.Memory,
+			EnvironmentVariables:       spec.Environments,
+			MinInstanceCount:           spec.MinInstances,
+			MaxInstanceCount:           spec.MaxInstances,
+			IngressSettings:            spec.IngressSettings,
+			ServiceAccountEmail:        spec.ServiceAccount,
+			AllTrafficOnLatestRevision: allTraffic,
+		},
+		Labels: spec.Labels,
+	}
+}
+
+// operation represents a long-running operation of the Cloud Functions v2 API.
+type operation struct {
+	Name  string `json:"name"`
+	Done  bool   `json:"done"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+func (c *client) waitOperation(ctx context.Context, op *operation) error {
+	ticker := time.NewTicker(operationCheckInterval)
+	defer ticker.Stop()
+
+	for !op.Done {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("operation %s was not completed (%w)", op.Name, ctx.Err())
+		case <-ticker.C:
+		}
+		if err := c.do(ctx, http.MethodGet, op.Name, nil, nil, op); err != nil {
+			return fmt.Errorf("unable to get operation %s: %w", op.Name, err)
+		}
+	}
+	if op.Error != nil {
+		return fmt.Errorf("operation %s was failed: code=%d, message=%s", op.Name, op.Error.Code, op.Error.Message)
+	}
+	return nil
+}
+
+// do sends a request to the given resource and decodes its response into out.
+// ErrNotFound is returned when the resource does not exist.
+func (c *client) do(ctx context.Context, method, resource string, query url.Values, in, out interface{}) error {
+	u := apiEndpoint + resource
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := googleapi.CheckResponse(resp); err != nil {
+		if e, ok := err.(*googleapi.Error); ok && e.Code == http.StatusNotFound {
+			return ErrNotFound
+		}
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (c *client) collection() string {
+	return fmt.Sprintf("projects/%s/locations/%s/functions", c.projectID, c.region)
+}
+
+func (c *client) functionName(name string) string {
+	return fmt.Sprintf("projects/%s/locations/%s/functions/%s", c.projectID, c.region, name)
+}
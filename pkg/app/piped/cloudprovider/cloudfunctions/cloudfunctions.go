@@ -0,0 +1,198 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudfunctions
+
+import (
+	"context"
+	"errors"
+	"path"
+	"path/filepath"
+	"sync"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/pipe-cd/pipe/pkg/config"
+)
+
+const (
+	DefaultFunctionManifestFilename = "function.yaml"
+
+	// The keys of the labels given to the functions deployed by piped.
+	// The label keys of GCP resources can only contain lowercase letters, numbers, underscores and dashes.
+	LabelManagedBy   = "pipecd-dev-managed-by"
+	LabelPiped       = "pipecd-dev-piped"
+	LabelApplication = "pipecd-dev-application"
+	LabelCommitHash  = "pipecd-dev-commit-hash"
+	ManagedByPiped   = "piped"
+
+	FunctionStateActive = "ACTIVE"
+)
+
+var (
+	ErrNotFound = errors.New("not found")
+)
+
+// Function represents a function resource of the Cloud Functions v2 API.
+// Only the fields used by piped are defined.
+// https://cloud.google.com/functions/docs/reference/rest/v2/projects.locations.functions
+type Function struct {
+	// The resource name in the format of projects/*/locations/*/functions/*.
+	Name          string            `json:"name,omitempty"`
+	Description   string            `json:"description,omitempty"`
+	BuildConfig   *BuildConfig      `json:"buildConfig,omitempty"`
+	ServiceConfig *ServiceConfig    `json:"serviceConfig,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	// Output only fields.
+	State         string          `json:"state,omitempty"`
+	StateMessages []*StateMessage `json:"stateMessages,omitempty"`
+	UpdateTime    string          `json:"updateTime,omitempty"`
+	Environment   string          `json:"environment,omitempty"`
+}
+
+type BuildConfig struct {
+	Runtime              string            `json:"runtime,omitempty"`
+	EntryPoint           string            `json:"entryPoint,omitempty"`
+	Source               *Source           `json:"source,omitempty"`
+	EnvironmentVariables map[string]string `json:"environmentVariables,omitempty"`
+}
+
+type Source struct {
+	StorageSource *StorageSource `json:"storageSource,omitempty"`
+}
+
+type StorageSource struct {
+	Bucket     string `json:"bucket,omitempty"`
+	Object     string `json:"object,omitempty"`
+	Generation int64  `json:"generation,omitempty,string"`
+}
+
+type ServiceConfig struct {
+	TimeoutSeconds       int32             `json:"timeoutSeconds,omitempty"`
+	AvailableMemory      string            `json:"availableMemory,omitempty"`
+	EnvironmentVariables map[string]string `json:"environmentVariables,omitempty"`
+	MinInstanceCount     int32             `json:"minInstanceCount,omitempty"`
+	MaxInstanceCount     int32             `json:"maxInstanceCount,omitempty"`
+	IngressSettings      string            `json:"ingressSettings,omitempty"`
+	ServiceAccountEmail  string            `json:"serviceAccountEmail,omitempty"`
+	// Whether all traffic is routed to the latest revision.
+	// It is always sent since false is meaningful for deploying a new revision without traffic.
+	AllTrafficOnLatestRevision bool `json:"allTrafficOnLatestRevision"`
+	// Output only fields.
+	// The resource name of the underlying Cloud Run service.
+	Service string `json:"service,omitempty"`
+	// The name of the latest revision of the underlying Cloud Run service.
+	Revision string `json:"revision,omitempty"`
+	URI      string `json:"uri,omitempty"`
+}
+
+type StateMessage struct {
+	Severity string `json:"severity,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+// ShortName returns the last part of the resource name.
+func (f *Function) ShortName() string {
+	return path.Base(f.Name)
+}
+
+// LatestRevision returns the latest revision of the service running the function.
+func (f *Function) LatestRevision() string {
+	if f.ServiceConfig == nil {
+		return ""
+	}
+	return f.ServiceConfig.Revision
+}
+
+// RevisionTraffic represents the percent of traffic routed to a revision.
+type RevisionTraffic struct {
+	RevisionName string `json:"revisionName"`
+	Percent      int    `json:"percent"`
+}
+
+// Client is a wrapper of the Cloud Functions API and the Cloud Run API.
+// Because a 2nd gen function is running on a Cloud Run service,
+// its traffic is managed via the Cloud Run API.
+type Client interface {
+	// GetFunction returns ErrNotFound when the function does not exist.
+	GetFunction(ctx context.Context, name string) (*Function, error)
+	// ListFunctions returns all functions in the project and region of this client.
+	ListFunctions(ctx context.Context) ([]*Function, error)
+	// CreateFunction creates a new function and waits until it was deployed.
+	CreateFunction(ctx context.Context, fm FunctionManifest) (*Function, error)
+	// UpdateFunction deploys a new revision of the function and waits until it was deployed.
+	// When allTraffic is false, the traffic keeps being routed as before
+	// so the new revision receives no traffic until UpdateTraffic is called.
+	// ErrNotFound is returned when the function does not exist.
+	UpdateFunction(ctx context.Context, fm FunctionManifest, allTraffic bool) (*Function, error)
+	// GetTraffic returns how the traffic of the function is split among its revisions.
+	GetTraffic(ctx context.Context, name string) ([]RevisionTraffic, error)
+	// UpdateTraffic splits the traffic of the function among the given revisions
+	// and waits until it was applied.
+	UpdateTraffic(ctx context.Context, name string, traffics []RevisionTraffic) error
+}
+
+// Registry holds a pool of clients.
+type Registry interface {
+	Client(ctx context.Context, name string, cfg *config.CloudProviderCloudFunctionsConfig, logger *zap.Logger) (Client, error)
+}
+
+// LoadFunctionManifest returns FunctionManifest object from a given function manifest file.
+func LoadFunctionManifest(appDir, functionManifestFilename string) (FunctionManifest, error) {
+	if functionManifestFilename == "" {
+		functionManifestFilename = DefaultFunctionManifestFilename
+	}
+	return loadFunctionManifest(filepath.Join(appDir, functionManifestFilename))
+}
+
+var defaultRegistry = &registry{
+	clients:  make(map[string]Client),
+	newGroup: &singleflight.Group{},
+}
+
+// DefaultRegistry returns the default pool of clients.
+func DefaultRegistry() Registry {
+	return defaultRegistry
+}
+
+type registry struct {
+	clients  map[string]Client
+	mu       sync.RWMutex
+	newGroup *singleflight.Group
+}
+
+func (r *registry) Client(ctx context.Context, name string, cfg *config.CloudProviderCloudFunctionsConfig, logger *zap.Logger) (Client, error) {
+	r.mu.RLock()
+	client, ok := r.clients[name]
+	r.mu.RUnlock()
+	if ok {
+		return client, nil
+	}
+
+	c, err, _ := r.newGroup.Do(name, func() (interface{}, error) {
+		return newClient(ctx, cfg.Project, cfg.Region, cfg.CredentialsFile, logger)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	client = c.(Client)
+	r.mu.Lock()
+	r.clients[name] = client
+	r.mu.Unlock()
+
+	return client, nil
+}
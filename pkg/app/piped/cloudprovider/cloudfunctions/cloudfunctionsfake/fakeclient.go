@@ -0,0 +1,192 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cloudfunctionsfake provides an in-memory implementation of cloudfunctions.Client
+// that can be used to test the components using Cloud Functions without accessing GCP.
+package cloudfunctionsfake
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/cloudfunctions"
+)
+
+type function struct {
+	fn        *provider.Function
+	revisions map[string]struct{}
+	traffics  []provider.RevisionTraffic
+}
+
+type fakeClient struct {
+	projectID string
+	region    string
+	functions map[string]*function
+	mu        sync.RWMutex
+}
+
+// NewClient returns a new fakeClient holding no function.
+func NewClient(projectID, region string) *fakeClient {
+	return &fakeClient{
+		projectID: projectID,
+		region:    region,
+		functions: make(map[string]*function),
+	}
+}
+
+func (c *fakeClient) GetFunction(_ context.Context, name string) (*provider.Function, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	f, ok := c.functions[name]
+	if !ok {
+		return nil, provider.ErrNotFound
+	}
+	return copyFunction(f.fn), nil
+}
+
+func (c *fakeClient) ListFunctions(_ context.Context) ([]*provider.Function, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	out := make([]*provider.Function, 0, len(c.functions))
+	for _, f := range c.functions {
+		out = append(out, copyFunction(f.fn))
+	}
+	return out, nil
+}
+
+func (c *fakeClient) CreateFunction(_ context.Context, fm provider.FunctionManifest) (*provider.Function, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name := fm.Spec.Name
+	if _, ok := c.functions[name]; ok {
+		return nil, fmt.Errorf("function %s already exists", name)
+	}
+	f := &function{
+		revisions: make(map[string]struct{}),
+	}
+	c.functions[name] = f
+	c.deploy(f, fm, true)
+	return copyFunction(f.fn), nil
+}
+
+func (c *fakeClient) UpdateFunction(_ context.Context, fm provider.FunctionManifest, allTraffic bool) (*provider.Function, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	f, ok := c.functions[fm.Spec.Name]
+	if !ok {
+		return nil, provider.ErrNotFound
+	}
+	c.deploy(f, fm, allTraffic)
+	return copyFunction(f.fn), nil
+}
+
+func (c *fakeClient) GetTraffic(_ context.Context, name string) ([]provider.RevisionTraffic, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	f, ok := c.functions[name]
+	if !ok {
+		return nil, provider.ErrNotFound
+	}
+	return append([]provider.RevisionTraffic(nil), f.traffics...), nil
+}
+
+func (c *fakeClient) UpdateTraffic(_ context.Context, name string, traffics []provider.RevisionTraffic) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	f, ok := c.functions[name]
+	if !ok {
+		return provider.ErrNotFound
+	}
+	var total int
+	for _, t := range traffics {
+		if _, ok := f.revisions[t.RevisionName]; !ok {
+			return fmt.Errorf("revision %s was not found", t.RevisionName)
+		}
+		total += t.Percent
+	}
+	if total != 100 {
+		return fmt.Errorf("total traffic percent must be 100 but got %d", total)
+	}
+	f.traffics = append([]provider.RevisionTraffic(nil), traffics...)
+	f.fn.ServiceConfig.AllTrafficOnLatestRevision = len(traffics) == 1 && traffics[0].RevisionName == f.fn.ServiceConfig.Revision
+	return nil
+}
+
+// deploy creates a new revision of the given function as Cloud Functions does.
+func (c *fakeClient) deploy(f *function, fm provider.FunctionManifest, allTraffic bool) {
+	var (
+		spec     = fm.Spec
+		revision = fmt.Sprintf("%s-%05d", spec.Name, len(f.revisions)+1)
+		labels   = make(map[string]string, len(spec.Labels))
+	)
+	for k, v := range spec.Labels {
+		labels[k] = v
+	}
+	f.revisions[revision] = struct{}{}
+	f.fn = &provider.Function{
+		Name:        fmt.Sprintf("projects/%s/locations/%s/functions/%s", c.projectID, c.region, spec.Name),
+		Description: spec.Description,
+		BuildConfig: &provider.BuildConfig{
+			Runtime:    spec.Runtime,
+			EntryPoint: spec.EntryPoint,
+			Source: &provider.Source{
+				StorageSource: &provider.StorageSource{
+					Bucket:     spec.Source.Bucket,
+					Object:     spec.Source.Object,
+					Generation: spec.Source.Generation,
+				},
+			},
+			EnvironmentVariables: spec.BuildEnvironments,
+		},
+		ServiceConfig: &provider.ServiceConfig{
+			TimeoutSeconds:             spec.Timeout,
+			AvailableMemory:            spec.Memory,
+			EnvironmentVariables:       spec.Environments,
+			MinInstanceCount:           spec.MinInstances,
+			MaxInstanceCount:           spec.MaxInstances,
+			IngressSettings:            spec.IngressSettings,
+			ServiceAccountEmail:        spec.ServiceAccount,
+			AllTrafficOnLatestRevision: allTraffic,
+			Service:                    fmt.Sprintf("projects/%s/locations/%s/services/%s", c.projectID, c.region, spec.Name),
+			Revision:                   revision,
+			URI:                        fmt.Sprintf("https://%s-fake.a.run.app", spec.Name),
+		},
+		Labels: labels,
+		State:  provider.FunctionStateActive,
+	}
+	if allTraffic {
+		f.traffics = []provider.RevisionTraffic{
+			{
+				RevisionName: revision,
+				Percent:      100,
+			},
+		}
+	}
+}
+
+func copyFunction(fn *provider.Function) *provider.Function {
+	out := *fn
+	if fn.ServiceConfig != nil {
+		sc := *fn.ServiceConfig
+		out.ServiceConfig = &sc
+	}
+	return &out
+}
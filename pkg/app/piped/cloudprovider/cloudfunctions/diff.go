@@ -0,0 +1,121 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudfunctions
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Diff compares the function manifest defined in Git with the live function,
+// and returns the list of human-readable differences between them.
+// The optional fields which are not specified in the manifest are not compared
+// since Cloud Functions fills them with its default values.
+func Diff(expected FunctionManifest, live *Function) []string {
+	var (
+		e       = expected.Spec
+		build   = live.BuildConfig
+		service = live.ServiceConfig
+		out     []string
+	)
+	add := func(field string, expected, actual interface{}) {
+		out = append(out, fmt.Sprintf("%s: expected %v but got %v", field, expected, actual))
+	}
+	if build == nil {
+		build = &BuildConfig{}
+	}
+	if service == nil {
+		service = &ServiceConfig{}
+	}
+
+	if e.Description != live.Description {
+		add("description", e.Description, live.Description)
+	}
+	if e.Runtime != build.Runtime {
+		add("runtime", e.Runtime, build.Runtime)
+	}
+	if e.EntryPoint != build.EntryPoint {
+		add("entryPoint", e.EntryPoint, build.EntryPoint)
+	}
+
+	var source StorageSource
+	if build.Source != nil && build.Source.StorageSource != nil {
+		source = *build.Source.StorageSource
+	}
+	if e.Source.Bucket != source.Bucket {
+		add("source.bucket", e.Source.Bucket, source.Bucket)
+	}
+	if e.Source.Object != source.Object {
+		add("source.object", e.Source.Object, source.Object)
+	}
+	if e.Source.Generation != 0 && e.Source.Generation != source.Generation {
+		add("source.generation", e.Source.Generation, source.Generation)
+	}
+
+	if e.Memory != "" && e.Memory != service.AvailableMemory {
+		add("memory", e.Memory, service.AvailableMemory)
+	}
+	if e.Timeout != 0 && e.Timeout != service.TimeoutSeconds {
+		add("timeout", e.Timeout, service.TimeoutSeconds)
+	}
+	if e.MinInstances != service.MinInstanceCount {
+		add("minInstances", e.MinInstances, service.MinInstanceCount)
+	}
+	if e.MaxInstances != 0 && e.MaxInstances != service.MaxInstanceCount {
+		add("maxInstances", e.MaxInstances, service.MaxInstanceCount)
+	}
+	if e.ServiceAccount != "" && e.ServiceAccount != service.ServiceAccountEmail {
+		add("serviceAccount", e.ServiceAccount, service.ServiceAccountEmail)
+	}
+	if e.IngressSettings != "" && e.IngressSettings != service.IngressSettings {
+		add("ingressSettings", e.IngressSettings, service.IngressSettings)
+	}
+
+	for _, k := range diffMapKeys(e.Environments, service.EnvironmentVariables) {
+		// Do not show the values since they may contain secrets.
+		out = append(out, fmt.Sprintf("environments.%s: value was changed", k))
+	}
+	for _, k := range diffMapKeys(e.BuildEnvironments, build.EnvironmentVariables) {
+		out = append(out, fmt.Sprintf("buildEnvironments.%s: value was changed", k))
+	}
+
+	liveLabels := make(map[string]string, len(live.Labels))
+	for k, v := range live.Labels {
+		if !isBuiltinLabel(k) {
+			liveLabels[k] = v
+		}
+	}
+	for _, k := range diffMapKeys(e.Labels, liveLabels) {
+		out = append(out, fmt.Sprintf("labels.%s: expected %q but got %q", k, e.Labels[k], liveLabels[k]))
+	}
+	return out
+}
+
+// diffMapKeys returns the sorted keys whose values are different between the given maps.
+func diffMapKeys(a, b map[string]string) []string {
+	keys := make([]string, 0)
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || bv != v {
+			keys = append(keys, k)
+		}
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
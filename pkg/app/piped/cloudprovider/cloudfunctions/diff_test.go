@@ -0,0 +1,73 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudfunctions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	expected := FunctionManifest{
+		Spec: FunctionManifestSpec{
+			Name:       "helloworld",
+			Runtime:    "go116",
+			EntryPoint: "HelloWorld",
+			Source: FunctionSource{
+				Bucket: "pipecd-functions",
+				Object: "helloworld/v0.2.0.zip",
+			},
+			Memory:       "256M",
+			Environments: map[string]string{"FOO": "bar"},
+			Labels:       map[string]string{"team": "payment"},
+		},
+	}
+	live := &Function{
+		Name: "projects/project/locations/asia-northeast1/functions/helloworld",
+		BuildConfig: &BuildConfig{
+			Runtime:    "go116",
+			EntryPoint: "HelloWorld",
+			Source: &Source{
+				StorageSource: &StorageSource{
+					Bucket:     "pipecd-functions",
+					Object:     "helloworld/v0.1.0.zip",
+					Generation: 1634567890123456,
+				},
+			},
+		},
+		ServiceConfig: &ServiceConfig{
+			AvailableMemory:      "256M",
+			TimeoutSeconds:       60,
+			EnvironmentVariables: map[string]string{"FOO": "baz"},
+			MaxInstanceCount:     100,
+		},
+		Labels: map[string]string{
+			"team":                   "payment",
+			"pipecd-dev-managed-by":  "piped",
+			"pipecd-dev-application": "app-id",
+		},
+	}
+
+	got := Diff(expected, live)
+	assert.Equal(t, []string{
+		"source.object: expected helloworld/v0.2.0.zip but got helloworld/v0.1.0.zip",
+		"environments.FOO: value was changed",
+	}, got)
+
+	expected.Spec.Source.Object = "helloworld/v0.1.0.zip"
+	expected.Spec.Environments["FOO"] = "baz"
+	assert.Empty(t, Diff(expected, live))
+}
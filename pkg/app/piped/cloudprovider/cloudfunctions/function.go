@@ -0,0 +1,185 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudfunctions
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"regexp"
+	"strconv"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	versionV1Beta1       = "pipecd.dev/v1beta1"
+	functionManifestKind = "CloudFunction"
+	// The upper limit of timeout for HTTP functions as noted via
+	// https://cloud.google.com/functions/docs/configuring/timeout
+	timeoutUpperLimit = 3600
+)
+
+var (
+	functionNameRegex = regexp.MustCompile(`^[a-z]([-a-z0-9]{0,61}[a-z0-9])?$`)
+
+	supportedIngressSettings = map[string]struct{}{
+		"ALLOW_ALL":               {},
+		"ALLOW_INTERNAL_ONLY":     {},
+		"ALLOW_INTERNAL_AND_GCLB": {},
+	}
+)
+
+type FunctionManifest struct {
+	Kind       string               `json:"kind"`
+	APIVersion string               `json:"apiVersion,omitempty"`
+	Spec       FunctionManifestSpec `json:"spec"`
+}
+
+func (fm *FunctionManifest) validate() error {
+	if fm.APIVersion != versionV1Beta1 {
+		return fmt.Errorf("unsupported version: %s", fm.APIVersion)
+	}
+	if fm.Kind != functionManifestKind {
+		return fmt.Errorf("invalid manifest kind given: %s", fm.Kind)
+	}
+	if err := fm.Spec.validate(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// FunctionManifestSpec contains configuration for CloudFunction.
+// The function code is built from the zip archive stored in Cloud Storage.
+type FunctionManifestSpec struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// The runtime to run the function, e.g. "go116" or "nodejs16".
+	Runtime string `json:"runtime"`
+	// The name of the function to be executed.
+	EntryPoint string         `json:"entryPoint"`
+	Source     FunctionSource `json:"source"`
+	// The amount of memory available for the function, e.g. "256M".
+	// Empty means the default value of Cloud Functions.
+	Memory string `json:"memory,omitempty"`
+	// The function execution timeout in seconds.
+	// Zero means the default value of Cloud Functions.
+	Timeout      int32 `json:"timeout,omitempty"`
+	MinInstances int32 `json:"minInstances,omitempty"`
+	// Zero means the default value of Cloud Functions.
+	MaxInstances int32 `json:"maxInstances,omitempty"`
+	// The email of the service account to run the function.
+	ServiceAccount string `json:"serviceAccount,omitempty"`
+	// One of ALLOW_ALL, ALLOW_INTERNAL_ONLY or ALLOW_INTERNAL_AND_GCLB.
+	IngressSettings   string            `json:"ingressSettings,omitempty"`
+	Environments      map[string]string `json:"environments,omitempty"`
+	BuildEnvironments map[string]string `json:"buildEnvironments,omitempty"`
+	Labels            map[string]string `json:"labels,omitempty"`
+}
+
+// FunctionSource is the location of the zip archive in Cloud Storage.
+type FunctionSource struct {
+	Bucket string `json:"bucket"`
+	Object string `json:"object"`
+	// The generation of the object.
+	// Zero means the latest one.
+	Generation int64 `json:"generation,omitempty"`
+}
+
+func (fmp FunctionManifestSpec) validate() error {
+	if len(fmp.Name) == 0 {
+		return fmt.Errorf("function name is missing")
+	}
+	if !functionNameRegex.MatchString(fmp.Name) {
+		return fmt.Errorf("function name %q must consist of lower case letters, digits and '-', start with a letter and be at most 63 characters", fmp.Name)
+	}
+	if len(fmp.Runtime) == 0 {
+		return fmt.Errorf("runtime is missing")
+	}
+	if len(fmp.EntryPoint) == 0 {
+		return fmt.Errorf("entryPoint is missing")
+	}
+	if fmp.Source.Bucket == "" || fmp.Source.Object == "" {
+		return fmt.Errorf("both source.bucket and source.object are required")
+	}
+	if fmp.Timeout < 0 || fmp.Timeout > timeoutUpperLimit {
+		return fmt.Errorf("timeout must be between 0 and %d", timeoutUpperLimit)
+	}
+	if fmp.MinInstances < 0 || fmp.MaxInstances < 0 {
+		return fmt.Errorf("minInstances and maxInstances must not be negative")
+	}
+	if fmp.MaxInstances > 0 && fmp.MinInstances > fmp.MaxInstances {
+		return fmt.Errorf("minInstances must not be greater than maxInstances")
+	}
+	if s := fmp.IngressSettings; s != "" {
+		if _, ok := supportedIngressSettings[s]; !ok {
+			return fmt.Errorf("unsupported ingressSettings %q", s)
+		}
+	}
+	return nil
+}
+
+func loadFunctionManifest(path string) (FunctionManifest, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return FunctionManifest{}, err
+	}
+	return parseFunctionManifest(data)
+}
+
+func parseFunctionManifest(data []byte) (FunctionManifest, error) {
+	var obj FunctionManifest
+	if err := yaml.Unmarshal(data, &obj); err != nil {
+		return FunctionManifest{}, err
+	}
+	if err := obj.validate(); err != nil {
+		return FunctionManifest{}, err
+	}
+	return obj, nil
+}
+
+// FindArtifactVersion returns the version of the function code defined in the given manifest.
+// That is the generation of the source object, or its base name when the generation is not specified.
+func FindArtifactVersion(fm FunctionManifest) (string, error) {
+	switch {
+	case fm.Spec.Source.Generation != 0:
+		return strconv.FormatInt(fm.Spec.Source.Generation, 10), nil
+	case fm.Spec.Source.Object != "":
+		return path.Base(fm.Spec.Source.Object), nil
+	default:
+		return "", fmt.Errorf("source object is missing")
+	}
+}
+
+// MakeLabels returns a copy of the given labels with the ones used by PipeCD to identify the function.
+func MakeLabels(labels map[string]string, pipedID, appID, commitHash string) map[string]string {
+	out := make(map[string]string, len(labels)+4)
+	for k, v := range labels {
+		out[k] = v
+	}
+	out[LabelManagedBy] = ManagedByPiped
+	out[LabelPiped] = pipedID
+	out[LabelApplication] = appID
+	out[LabelCommitHash] = commitHash
+	return out
+}
+
+func isBuiltinLabel(key string) bool {
+	switch key {
+	case LabelManagedBy, LabelPiped, LabelApplication, LabelCommitHash:
+		return true
+	}
+	return false
+}
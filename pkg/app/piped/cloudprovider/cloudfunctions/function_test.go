@@ -0,0 +1,210 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudfunctions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFunctionManifest(t *testing.T) {
+	testcases := []struct {
+		name        string
+		data        string
+		expected    FunctionManifest
+		expectedErr bool
+	}{
+		{
+			name: "correct config",
+			data: `
+apiVersion: pipecd.dev/v1beta1
+kind: CloudFunction
+spec:
+  name: helloworld
+  runtime: go116
+  entryPoint: HelloWorld
+  source:
+    bucket: pipecd-functions
+    object: helloworld/v0.1.0.zip
+    generation: 1634567890123456
+  memory: 256M
+  timeout: 60
+  maxInstances: 10
+  ingressSettings: ALLOW_ALL
+  environments:
+    FOO: bar
+`,
+			expected: FunctionManifest{
+				Kind:       "CloudFunction",
+				APIVersion: "pipecd.dev/v1beta1",
+				Spec: FunctionManifestSpec{
+					Name:       "helloworld",
+					Runtime:    "go116",
+					EntryPoint: "HelloWorld",
+					Source: FunctionSource{
+						Bucket:     "pipecd-functions",
+						Object:     "helloworld/v0.1.0.zip",
+						Generation: 1634567890123456,
+					},
+					Memory:          "256M",
+					Timeout:         60,
+					MaxInstances:    10,
+					IngressSettings: "ALLOW_ALL",
+					Environments:    map[string]string{"FOO": "bar"},
+				},
+			},
+		},
+		{
+			name: "invalid kind",
+			data: `
+apiVersion: pipecd.dev/v1beta1
+kind: LambdaFunction
+spec:
+  name: helloworld
+  runtime: go116
+  entryPoint: HelloWorld
+  source:
+    bucket: pipecd-functions
+    object: helloworld/v0.1.0.zip
+`,
+			expectedErr: true,
+		},
+		{
+			name: "invalid function name",
+			data: `
+apiVersion: pipecd.dev/v1beta1
+kind: CloudFunction
+spec:
+  name: Hello_World
+  runtime: go116
+  entryPoint: HelloWorld
+  source:
+    bucket: pipecd-functions
+    object: helloworld/v0.1.0.zip
+`,
+			expectedErr: true,
+		},
+		{
+			name: "missing source object",
+			data: `
+apiVersion: pipecd.dev/v1beta1
+kind: CloudFunction
+spec:
+  name: helloworld
+  runtime: go116
+  entryPoint: HelloWorld
+  source:
+    bucket: pipecd-functions
+`,
+			expectedErr: true,
+		},
+		{
+			name: "min instances greater than max instances",
+			data: `
+apiVersion: pipecd.dev/v1beta1
+kind: CloudFunction
+spec:
+  name: helloworld
+  runtime: go116
+  entryPoint: HelloWorld
+  source:
+    bucket: pipecd-functions
+    object: helloworld/v0.1.0.zip
+  minInstances: 3
+  maxInstances: 2
+`,
+			expectedErr: true,
+		},
+		{
+			name: "unsupported ingress settings",
+			data: `
+apiVersion: pipecd.dev/v1beta1
+kind: CloudFunction
+spec:
+  name: helloworld
+  runtime: go116
+  entryPoint: HelloWorld
+  source:
+    bucket: pipecd-functions
+    object: helloworld/v0.1.0.zip
+  ingressSettings: ALLOW_NONE
+`,
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			fm, err := parseFunctionManifest([]byte(tc.data))
+			assert.Equal(t, tc.expectedErr, err != nil)
+			if err == nil {
+				assert.Equal(t, tc.expected, fm)
+			}
+		})
+	}
+}
+
+func TestFindArtifactVersion(t *testing.T) {
+	testcases := []struct {
+		name     string
+		source   FunctionSource
+		expected string
+	}{
+		{
+			name: "generation is specified",
+			source: FunctionSource{
+				Bucket:     "pipecd-functions",
+				Object:     "helloworld/v0.1.0.zip",
+				Generation: 1634567890123456,
+			},
+			expected: "1634567890123456",
+		},
+		{
+			name: "object name",
+			source: FunctionSource{
+				Bucket: "pipecd-functions",
+				Object: "helloworld/v0.1.0.zip",
+			},
+			expected: "v0.1.0.zip",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			fm := FunctionManifest{
+				Spec: FunctionManifestSpec{
+					Source: tc.source,
+				},
+			}
+			version, err := FindArtifactVersion(fm)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, version)
+		})
+	}
+}
+
+func TestMakeLabels(t *testing.T) {
+	labels := map[string]string{"team": "payment"}
+	got := MakeLabels(labels, "piped-id", "app-id", "commit-hash")
+
+	assert.Equal(t, map[string]string{
+		"team":                   "payment",
+		"pipecd-dev-managed-by":  "piped",
+		"pipecd-dev-piped":       "piped-id",
+		"pipecd-dev-application": "app-id",
+		"pipecd-dev-commit-hash": "commit-hash",
+	}, got)
+	// The given labels must not be modified.
+	assert.Equal(t, map[string]string{"team": "payment"}, labels)
+}
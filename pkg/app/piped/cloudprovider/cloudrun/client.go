@@ -108,22 +108,79 @@ func (c *client) Update(ctx context.Context, sm ServiceManifest) (*Service, erro
 	return (*Service)(service), nil
 }
 
-func (c *client) List(ctx context.Context) error {
+func (c *client) Get(ctx context.Context, serviceName string) (*Service, error) {
 	var (
-		svc    = run.NewNamespacesServicesService(c.client)
-		parent = makeCloudRunParent(c.projectID)
-		call   = svc.List(parent)
+		svc  = run.NewNamespacesServicesService(c.client)
+		name = makeCloudRunServiceName(c.projectID, serviceName)
+		call = svc.Get(name)
 	)
-
 	call.Context(ctx)
 
-	resp, err := call.Do()
+	service, err := call.Do()
 	if err != nil {
-		return err
+		if e, ok := err.(*googleapi.Error); ok && e.Code == http.StatusNotFound {
+			return nil, ErrServiceNotFound
+		}
+		return nil, err
 	}
+	return (*Service)(service), nil
+}
+
+func (c *client) List(ctx context.Context, labelSelector string) ([]*Service, error) {
+	var (
+		svc    = run.NewNamespacesServicesService(c.client)
+		parent = makeCloudRunParent(c.projectID)
+		out    []*Service
+		token  string
+	)
+	for {
+		call := svc.List(parent).LabelSelector(labelSelector)
+		if token != "" {
+			call.Continue(token)
+		}
+		call.Context(ctx)
 
-	fmt.Println(resp)
-	return nil
+		resp, err := call.Do()
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range resp.Items {
+			out = append(out, (*Service)(s))
+		}
+		if resp.Metadata == nil || resp.Metadata.Continue == "" {
+			return out, nil
+		}
+		token = resp.Metadata.Continue
+	}
+}
+
+func (c *client) ListRevisions(ctx context.Context, serviceName string) ([]*Revision, error) {
+	var (
+		svc      = run.NewNamespacesRevisionsService(c.client)
+		parent   = makeCloudRunParent(c.projectID)
+		selector = fmt.Sprintf("%s=%s", labelServiceName, serviceName)
+		out      []*Revision
+		token    string
+	)
+	for {
+		call := svc.List(parent).LabelSelector(selector)
+		if token != "" {
+			call.Continue(token)
+		}
+		call.Context(ctx)
+
+		resp, err := call.Do()
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range resp.Items {
+			out = append(out, (*Revision)(r))
+		}
+		if resp.Metadata == nil || resp.Metadata.Continue == "" {
+			return out, nil
+		}
+		token = resp.Metadata.Continue
+	}
 }
 
 func makeCloudRunParent(projectID string) string {
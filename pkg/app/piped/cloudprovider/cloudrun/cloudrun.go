@@ -29,6 +29,13 @@ import (
 
 const (
 	DefaultServiceManifestFilename = "service.yaml"
+
+	// The keys of the labels given to the services deployed by piped.
+	LabelManagedBy   = "pipecd-dev-managed-by"
+	LabelPiped       = "pipecd-dev-piped"
+	LabelApplication = "pipecd-dev-application"
+	LabelCommitHash  = "pipecd-dev-commit-hash"
+	ManagedByPiped   = "piped"
 )
 
 var (
@@ -37,9 +44,17 @@ var (
 
 type Service run.Service
 
+type Revision run.Revision
+
 type Client interface {
 	Create(ctx context.Context, sm ServiceManifest) (*Service, error)
 	Update(ctx context.Context, sm ServiceManifest) (*Service, error)
+	// Get returns ErrServiceNotFound when the service does not exist.
+	Get(ctx context.Context, serviceName string) (*Service, error)
+	// List returns all services matching the given label selector.
+	List(ctx context.Context, labelSelector string) ([]*Service, error)
+	// ListRevisions returns all revisions of the given service.
+	ListRevisions(ctx context.Context, serviceName string) ([]*Revision, error)
 }
 
 type Registry interface {
@@ -54,6 +69,12 @@ func LoadServiceManifest(appDir, serviceFilename string) (ServiceManifest, error
 	return loadServiceManifest(path)
 }
 
+// NewClient returns a new client for the services in the given project and region.
+// Unlike the clients provided by the registry, the returned one is not shared.
+func NewClient(ctx context.Context, projectID, region, credentialsFile string, logger *zap.Logger) (Client, error) {
+	return newClient(ctx, projectID, region, credentialsFile, logger)
+}
+
 var defaultRegistry = &registry{
 	clients:  make(map[string]Client),
 	newGroup: &singleflight.Group{},
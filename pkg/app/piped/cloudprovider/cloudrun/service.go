@@ -0,0 +1,117 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudrun
+
+import (
+	"fmt"
+
+	"google.golang.org/api/run/v1"
+)
+
+const (
+	conditionTypeReady = "Ready"
+	conditionTrue      = "True"
+	conditionFalse     = "False"
+
+	// The label given by Cloud Run to the revisions for the name of their service.
+	labelServiceName = "serving.knative.dev/service"
+)
+
+// CheckTrafficApplied reports whether the given service has been reconciled up to the given generation
+// and is routing traffic as specified.
+// An error is returned when the service became not ready, for example, because its revision was failed to start.
+func (s *Service) CheckTrafficApplied(generation int64, traffics []RevisionTraffic) (bool, error) {
+	if s.Status == nil || s.Status.ObservedGeneration < generation {
+		return false, nil
+	}
+
+	var ready bool
+	for _, c := range s.Status.Conditions {
+		if c == nil || c.Type != conditionTypeReady {
+			continue
+		}
+		switch c.Status {
+		case conditionTrue:
+			ready = true
+		case conditionFalse:
+			return false, fmt.Errorf("service is not ready: %s %s", c.Reason, c.Message)
+		}
+	}
+	if !ready {
+		return false, nil
+	}
+
+	expected := make(map[string]int64, len(traffics))
+	for _, t := range traffics {
+		expected[t.RevisionName] += int64(t.Percent)
+	}
+	actual := make(map[string]int64, len(s.Status.Traffic))
+	for _, t := range s.Status.Traffic {
+		if t != nil {
+			actual[t.RevisionName] += t.Percent
+		}
+	}
+	for revision, percent := range expected {
+		if actual[revision] != percent {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// FindTrafficTagURL returns the URL assigned to the given traffic tag.
+func (s *Service) FindTrafficTagURL(tag string) (string, bool) {
+	if s.Status == nil {
+		return "", false
+	}
+	for _, t := range s.Status.Traffic {
+		if t != nil && t.Tag == tag && t.Url != "" {
+			return t.Url, true
+		}
+	}
+	return "", false
+}
+
+// ReadyCondition returns the Ready condition of the service.
+// Nil is returned when the service has not been reported its status yet.
+func (s *Service) ReadyCondition() *run.GoogleCloudRunV1Condition {
+	if s.Status == nil {
+		return nil
+	}
+	return findReadyCondition(s.Status.Conditions)
+}
+
+// ReadyCondition returns the Ready condition of the revision.
+// Nil is returned when the revision has not been reported its status yet.
+func (r *Revision) ReadyCondition() *run.GoogleCloudRunV1Condition {
+	if r.Status == nil {
+		return nil
+	}
+	return findReadyCondition(r.Status.Conditions)
+}
+
+// IsReadyCondition reports whether the given condition is a satisfied Ready condition.
+func IsReadyCondition(c *run.GoogleCloudRunV1Condition) bool {
+	return c != nil && c.Status == conditionTrue
+}
+
+func findReadyCondition(conditions []*run.GoogleCloudRunV1Condition) *run.GoogleCloudRunV1Condition {
+	for _, c := range conditions {
+		if c != nil && c.Type == conditionTypeReady {
+			return c
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudrun
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	run "google.golang.org/api/run/v1"
+)
+
+func TestCheckTrafficApplied(t *testing.T) {
+	traffics := []RevisionTraffic{
+		{RevisionName: "helloworld-v010-1234567", Percent: 10},
+		{RevisionName: "helloworld-v001-1234567", Percent: 90},
+	}
+	makeService := func(generation int64, ready string, traffics ...*run.TrafficTarget) *Service {
+		return &Service{
+			Status: &run.ServiceStatus{
+				ObservedGeneration: generation,
+				Conditions: []*run.GoogleCloudRunV1Condition{
+					{Type: "Ready", Status: ready, Reason: "RevisionFailed", Message: "container failed to start"},
+				},
+				Traffic: traffics,
+			},
+		}
+	}
+
+	testcases := []struct {
+		name        string
+		service     *Service
+		expected    bool
+		expectedErr bool
+	}{
+		{
+			name:    "no status",
+			service: &Service{},
+		},
+		{
+			name: "older generation",
+			service: makeService(1, "True",
+				&run.TrafficTarget{RevisionName: "helloworld-v001-1234567", Percent: 100},
+			),
+		},
+		{
+			name: "still reconciling",
+			service: makeService(2, "Unknown",
+				&run.TrafficTarget{RevisionName: "helloworld-v010-1234567", Percent: 10},
+				&run.TrafficTarget{RevisionName: "helloworld-v001-1234567", Percent: 90},
+			),
+		},
+		{
+			name:        "not ready",
+			service:     makeService(2, "False"),
+			expectedErr: true,
+		},
+		{
+			name: "traffic is not matched",
+			service: makeService(2, "True",
+				&run.TrafficTarget{RevisionName: "helloworld-v001-1234567", Percent: 100},
+			),
+		},
+		{
+			name: "applied",
+			service: makeService(3, "True",
+				&run.TrafficTarget{RevisionName: "helloworld-v010-1234567", Percent: 10, Tag: "canary"},
+				&run.TrafficTarget{RevisionName: "helloworld-v001-1234567", Percent: 90},
+			),
+			expected: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			applied, err := tc.service.CheckTrafficApplied(2, traffics)
+			assert.Equal(t, tc.expected, applied)
+			assert.Equal(t, tc.expectedErr, err != nil)
+		})
+	}
+}
+
+func TestFindTrafficTagURL(t *testing.T) {
+	svc := &Service{
+		Status: &run.ServiceStatus{
+			Traffic: []*run.TrafficTarget{
+				{RevisionName: "helloworld-v010-1234567", Percent: 10, Tag: "canary", Url: "https://canary---helloworld-abc.a.run.app"},
+				{RevisionName: "helloworld-v001-1234567", Percent: 90},
+			},
+		},
+	}
+
+	url, ok := svc.FindTrafficTagURL("canary")
+	assert.True(t, ok)
+	assert.Equal(t, "https://canary---helloworld-abc.a.run.app", url)
+
+	_, ok = svc.FindTrafficTagURL("unknown")
+	assert.False(t, ok)
+}
@@ -33,9 +33,32 @@ func (m ServiceManifest) SetRevision(name string) error {
 	return unstructured.SetNestedField(m.u.Object, name, "spec", "template", "metadata", "name")
 }
 
+// AddLabels adds the given labels to the service.
+// The existing labels with the same keys are overwritten.
+func (m ServiceManifest) AddLabels(labels map[string]string) {
+	if len(labels) == 0 {
+		return
+	}
+	merged := m.u.GetLabels()
+	if merged == nil {
+		merged = make(map[string]string, len(labels))
+	}
+	for k, v := range labels {
+		merged[k] = v
+	}
+	m.u.SetLabels(merged)
+}
+
+// Labels returns the labels of the service.
+func (m ServiceManifest) Labels() map[string]string {
+	return m.u.GetLabels()
+}
+
 type RevisionTraffic struct {
 	RevisionName string `json:"revisionName"`
 	Percent      int    `json:"percent"`
+	// The tag to give the revision its own URL.
+	Tag string `json:"tag,omitempty"`
 }
 
 func (m ServiceManifest) UpdateTraffic(revisions []RevisionTraffic) error {
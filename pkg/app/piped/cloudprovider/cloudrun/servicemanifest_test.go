@@ -13,3 +13,39 @@
 // limitations under the License.
 
 package cloudrun
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceManifestAddLabels(t *testing.T) {
+	sm, err := ParseServiceManifest([]byte(`
+apiVersion: serving.knative.dev/v1
+kind: Service
+metadata:
+  name: helloworld
+  labels:
+    cloud.googleapis.com/location: asia-northeast1
+    pipecd-dev-commit-hash: old-hash
+spec:
+  template:
+    spec:
+      containers:
+        - image: gcr.io/pipecd/helloworld:v0.1.0
+`))
+	require.NoError(t, err)
+
+	sm.AddLabels(map[string]string{
+		LabelManagedBy:  ManagedByPiped,
+		LabelCommitHash: "new-hash",
+	})
+	expected := map[string]string{
+		"cloud.googleapis.com/location": "asia-northeast1",
+		LabelManagedBy:                  ManagedByPiped,
+		LabelCommitHash:                 "new-hash",
+	}
+	assert.Equal(t, expected, sm.Labels())
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -102,34 +103,106 @@ func (c *client) CreateService(ctx context.Context, service types.Service) (*typ
 	// as part of service definition for that purpose.
 	output.Service.LaunchType = service.LaunchType
 	output.Service.NetworkConfiguration = service.NetworkConfiguration
+	output.Service.CapacityProviderStrategy = service.CapacityProviderStrategy
 
 	return output.Service, nil
 }
 
 func (c *client) UpdateService(ctx context.Context, service types.Service) (*types.Service, error) {
+	// Since we use EXTERNAL deployment controller, only the below properties can be updated by UpdateService.
+	// The others such as network configuration, capacity provider strategy and platform version
+	// are applied by the task sets created in further step.
 	input := &ecs.UpdateServiceInput{
-		Cluster:           service.ClusterArn,
-		Service:           service.ServiceName,
-		DesiredCount:      aws.Int32(service.DesiredCount),
-		PlacementStrategy: service.PlacementStrategy,
-		// TODO: Support update other properties of service.
-		// PlacementConstraints:    service.PlacementConstraints,
+		Cluster:                       service.ClusterArn,
+		Service:                       service.ServiceName,
+		DesiredCount:                  aws.Int32(service.DesiredCount),
+		DeploymentConfiguration:       service.DeploymentConfiguration,
+		HealthCheckGracePeriodSeconds: service.HealthCheckGracePeriodSeconds,
+		PlacementConstraints:          service.PlacementConstraints,
+		PlacementStrategy:             service.PlacementStrategy,
+	}
+	// Specify the empty lists to remove the placement settings those were removed from the service definition.
+	if input.PlacementConstraints == nil {
+		input.PlacementConstraints = []types.PlacementConstraint{}
+	}
+	if input.PlacementStrategy == nil {
+		input.PlacementStrategy = []types.PlacementStrategy{}
 	}
 	output, err := c.ecsClient.UpdateService(ctx, input)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update ECS service %s: %w", *service.ServiceName, err)
 	}
 
+	if err := c.updateServiceTags(ctx, *output.Service.ServiceArn, service.Tags); err != nil {
+		return nil, fmt.Errorf("failed to update tags of ECS service %s: %w", *service.ServiceName, err)
+	}
+
 	// Hack: Since we use EXTERNAL deployment controller, the below configurations are not allowed to be passed
 	// in UpdateService step, but it required in further step (CreateTaskSet step). We reassign those values
 	// as part of service definition for that purpose.
 	output.Service.LaunchType = service.LaunchType
 	output.Service.NetworkConfiguration = service.NetworkConfiguration
+	output.Service.CapacityProviderStrategy = service.CapacityProviderStrategy
+	output.Service.PlatformVersion = service.PlatformVersion
 
 	return output.Service, nil
 }
 
-func (c *client) RegisterTaskDefinition(ctx context.Context, taskDefinition types.TaskDefinition) (*types.TaskDefinition, error) {
+// updateServiceTags makes the tags of the given service be the same with the given ones.
+// The tags managed by AWS and the revision history of task definition recorded by PipeCD are kept as is.
+func (c *client) updateServiceTags(ctx context.Context, serviceArn string, tags []types.Tag) error {
+	output, err := c.ecsClient.ListTagsForResource(ctx, &ecs.ListTagsForResourceInput{
+		ResourceArn: aws.String(serviceArn),
+	})
+	if err != nil {
+		return err
+	}
+
+	desired := make(map[string]string, len(tags))
+	for _, t := range tags {
+		desired[aws.ToString(t.Key)] = aws.ToString(t.Value)
+	}
+	current := make(map[string]string, len(output.Tags))
+	removedKeys := make([]string, 0)
+	for _, t := range output.Tags {
+		key := aws.ToString(t.Key)
+		if strings.HasPrefix(key, "aws:") || key == LabelTaskDefinitionRevisions {
+			continue
+		}
+		current[key] = aws.ToString(t.Value)
+		if _, ok := desired[key]; !ok {
+			removedKeys = append(removedKeys, key)
+		}
+	}
+	updatedTags := make([]types.Tag, 0)
+	for _, t := range tags {
+		if v, ok := current[aws.ToString(t.Key)]; !ok || v != aws.ToString(t.Value) {
+			updatedTags = append(updatedTags, t)
+		}
+	}
+
+	if len(updatedTags) > 0 {
+		_, err := c.ecsClient.TagResource(ctx, &ecs.TagResourceInput{
+			ResourceArn: aws.String(serviceArn),
+			Tags:        updatedTags,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if len(removedKeys) > 0 {
+		_, err := c.ecsClient.UntagResource(ctx, &ecs.UntagResourceInput{
+			ResourceArn: aws.String(serviceArn),
+			TagKeys:     removedKeys,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *client) RegisterTaskDefinition(ctx context.Context, taskDefinition types.TaskDefinition, tags []types.Tag) (*types.TaskDefinition, error) {
 	input := &ecs.RegisterTaskDefinitionInput{
 		Family:                  taskDefinition.Family,
 		ContainerDefinitions:    taskDefinition.ContainerDefinitions,
@@ -140,7 +213,7 @@ func (c *client) RegisterTaskDefinition(ctx context.Context, taskDefinition type
 		// Requires defined at task level in case Fargate is used.
 		Cpu:    taskDefinition.Cpu,
 		Memory: taskDefinition.Memory,
-		// TODO: Support tags for registering task definition.
+		Tags:   tags,
 	}
 	output, err := c.ecsClient.RegisterTaskDefinition(ctx, input)
 	if err != nil {
@@ -149,7 +222,60 @@ func (c *client) RegisterTaskDefinition(ctx context.Context, taskDefinition type
 	return output.TaskDefinition, nil
 }
 
-func (c *client) CreateTaskSet(ctx context.Context, service types.Service, taskDefinition types.TaskDefinition, targetGroup types.LoadBalancer, scale int) (*types.TaskSet, error) {
+func (c *client) ListTaskDefinitionArns(ctx context.Context, family string) ([]string, error) {
+	var (
+		arns  []string
+		input = &ecs.ListTaskDefinitionsInput{
+			FamilyPrefix: aws.String(family),
+			Status:       types.TaskDefinitionStatusActive,
+		}
+	)
+	for {
+		output, err := c.ecsClient.ListTaskDefinitions(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list ECS task definitions of family %s: %w", family, err)
+		}
+		arns = append(arns, output.TaskDefinitionArns...)
+		if output.NextToken == nil {
+			break
+		}
+		input.NextToken = output.NextToken
+	}
+	return arns, nil
+}
+
+func (c *client) DeregisterTaskDefinition(ctx context.Context, taskDefinitionArn string) error {
+	input := &ecs.DeregisterTaskDefinitionInput{
+		TaskDefinition: aws.String(taskDefinitionArn),
+	}
+	if _, err := c.ecsClient.DeregisterTaskDefinition(ctx, input); err != nil {
+		return fmt.Errorf("failed to deregister ECS task definition %s: %w", taskDefinitionArn, err)
+	}
+	return nil
+}
+
+func (c *client) ListTags(ctx context.Context, resourceArn string) ([]types.Tag, error) {
+	output, err := c.ecsClient.ListTagsForResource(ctx, &ecs.ListTagsForResourceInput{
+		ResourceArn: aws.String(resourceArn),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags of ECS resource %s: %w", resourceArn, err)
+	}
+	return output.Tags, nil
+}
+
+func (c *client) AddTags(ctx context.Context, resourceArn string, tags []types.Tag) error {
+	_, err := c.ecsClient.TagResource(ctx, &ecs.TagResourceInput{
+		ResourceArn: aws.String(resourceArn),
+		Tags:        tags,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add tags to ECS resource %s: %w", resourceArn, err)
+	}
+	return nil
+}
+
+func (c *client) CreateTaskSet(ctx context.Context, service types.Service, taskDefinition types.TaskDefinition, targetGroup types.LoadBalancer, scale int, tags []types.Tag) (*types.TaskSet, error) {
 	if taskDefinition.TaskDefinitionArn == nil {
 		return nil, fmt.Errorf("failed to create task set of task family %s: no task definition provided", *taskDefinition.Family)
 	}
@@ -161,8 +287,15 @@ func (c *client) CreateTaskSet(ctx context.Context, service types.Service, taskD
 		// If you specify the awsvpc network mode, the task is allocated an elastic network interface,
 		// and you must specify a NetworkConfiguration when run a task with the task definition.
 		NetworkConfiguration: service.NetworkConfiguration,
-		LaunchType:           service.LaunchType,
 		LoadBalancers:        []types.LoadBalancer{targetGroup},
+		PlatformVersion:      service.PlatformVersion,
+		Tags:                 tags,
+	}
+	// The launch type can not be specified together with the capacity provider strategy.
+	if len(service.CapacityProviderStrategy) > 0 {
+		input.CapacityProviderStrategy = service.CapacityProviderStrategy
+	} else {
+		input.LaunchType = service.LaunchType
 	}
 	output, err := c.ecsClient.CreateTaskSet(ctx, input)
 	if err != nil {
@@ -242,70 +375,107 @@ func (c *client) ServiceExists(ctx context.Context, clusterName string, serviceN
 	return false, nil
 }
 
-func (c *client) GetListener(ctx context.Context, targetGroup types.LoadBalancer) (string, error) {
-	loadBalancerArn, err := c.getLoadBalancerArn(ctx, *targetGroup.TargetGroupArn)
+func (c *client) GetListenerArns(ctx context.Context, targetGroup types.LoadBalancer) ([]string, error) {
+	loadBalancerArns, err := c.getLoadBalancerArns(ctx, *targetGroup.TargetGroupArn)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	input := &elasticloadbalancingv2.DescribeListenersInput{
-		LoadBalancerArn: aws.String(loadBalancerArn),
-	}
-	output, err := c.elbClient.DescribeListeners(ctx, input)
-	if err != nil {
-		return "", err
-	}
-	if len(output.Listeners) == 0 {
-		return "", cloudprovider.ErrNotFound
+	var listenerArns []string
+	for _, loadBalancerArn := range loadBalancerArns {
+		input := &elasticloadbalancingv2.DescribeListenersInput{
+			LoadBalancerArn: aws.String(loadBalancerArn),
+		}
+		for {
+			output, err := c.elbClient.DescribeListeners(ctx, input)
+			if err != nil {
+				return nil, err
+			}
+			for _, l := range output.Listeners {
+				listenerArns = append(listenerArns, *l.ListenerArn)
+			}
+			if output.NextMarker == nil {
+				break
+			}
+			input.Marker = output.NextMarker
+		}
 	}
-	// Note: Suppose the load balancer only have one listener.
-	// TODO: Support multi listeners pattern.
-	if len(output.Listeners) > 1 {
-		return "", fmt.Errorf("invalid listener configuration pointed to %s target group", *targetGroup.TargetGroupArn)
+	if len(listenerArns) == 0 {
+		return nil, cloudprovider.ErrNotFound
 	}
-
-	return *output.Listeners[0].ListenerArn, nil
+	return listenerArns, nil
 }
 
-func (c *client) getLoadBalancerArn(ctx context.Context, targetGroupArn string) (string, error) {
+func (c *client) getLoadBalancerArns(ctx context.Context, targetGroupArn string) ([]string, error) {
 	input := &elasticloadbalancingv2.DescribeTargetGroupsInput{
 		TargetGroupArns: []string{targetGroupArn},
 	}
 	output, err := c.elbClient.DescribeTargetGroups(ctx, input)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	if len(output.TargetGroups) == 0 {
-		return "", cloudprovider.ErrNotFound
+	if len(output.TargetGroups) == 0 || len(output.TargetGroups[0].LoadBalancerArns) == 0 {
+		return nil, cloudprovider.ErrNotFound
 	}
-	// Note: Currently, only support TargetGroup which serves traffic from one Load Balancer.
-	return output.TargetGroups[0].LoadBalancerArns[0], nil
+	return output.TargetGroups[0].LoadBalancerArns, nil
 }
 
-func (c *client) ModifyListener(ctx context.Context, listenerArn string, routingTrafficCfg RoutingTrafficConfig) error {
+func (c *client) ModifyListeners(ctx context.Context, listenerArns []string, routingTrafficCfg RoutingTrafficConfig) error {
 	if len(routingTrafficCfg) != 2 {
 		return fmt.Errorf("invalid listener configuration: requires 2 target groups")
 	}
-	input := &elasticloadbalancingv2.ModifyListenerInput{
-		ListenerArn: aws.String(listenerArn),
-		DefaultActions: []elbtypes.Action{
-			{
-				Type: elbtypes.ActionTypeEnumForward,
-				ForwardConfig: &elbtypes.ForwardActionConfig{
-					TargetGroups: []elbtypes.TargetGroupTuple{
-						{
-							TargetGroupArn: aws.String(routingTrafficCfg[0].TargetGroupArn),
-							Weight:         aws.Int32(int32(routingTrafficCfg[0].Weight)),
-						},
-						{
-							TargetGroupArn: aws.String(routingTrafficCfg[1].TargetGroupArn),
-							Weight:         aws.Int32(int32(routingTrafficCfg[1].Weight)),
-						},
-					},
-				},
-			},
-		},
+
+	var modified int
+	for _, listenerArn := range listenerArns {
+		rules, err := c.describeRules(ctx, listenerArn)
+		if err != nil {
+			return fmt.Errorf("failed to describe rules of listener %s: %w", listenerArn, err)
+		}
+		for _, rule := range rules {
+			actions, ok := routingTrafficCfg.applyToActions(rule.Actions)
+			if !ok {
+				continue
+			}
+			// The actions of the default rule can be modified only via the listener.
+			if rule.IsDefault {
+				_, err = c.elbClient.ModifyListener(ctx, &elasticloadbalancingv2.ModifyListenerInput{
+					ListenerArn:    aws.String(listenerArn),
+					DefaultActions: actions,
+				})
+			} else {
+				_, err = c.elbClient.ModifyRule(ctx, &elasticloadbalancingv2.ModifyRuleInput{
+					RuleArn: rule.RuleArn,
+					Actions: actions,
+				})
+			}
+			if err != nil {
+				return fmt.Errorf("failed to modify rule %s of listener %s: %w", aws.ToString(rule.RuleArn), listenerArn, err)
+			}
+			modified++
+		}
+	}
+	if modified == 0 {
+		return fmt.Errorf("no listener rule forwarding traffic to the target groups was found")
+	}
+	return nil
+}
+
+func (c *client) describeRules(ctx context.Context, listenerArn string) ([]elbtypes.Rule, error) {
+	var (
+		rules []elbtypes.Rule
+		input = &elasticloadbalancingv2.DescribeRulesInput{
+			ListenerArn: aws.String(listenerArn),
+		}
+	)
+	for {
+		output, err := c.elbClient.DescribeRules(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, output.Rules...)
+		if output.NextMarker == nil {
+			return rules, nil
+		}
+		input.Marker = output.NextMarker
 	}
-	_, err := c.elbClient.ModifyListener(ctx, input)
-	return err
 }
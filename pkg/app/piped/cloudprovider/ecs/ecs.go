@@ -26,6 +26,17 @@ import (
 	"github.com/pipe-cd/pipe/pkg/config"
 )
 
+const (
+	LabelManagedBy   = "pipecd.dev/managed-by"  // Always be piped.
+	LabelPiped       = "pipecd.dev/piped"       // The id of piped handling this application.
+	LabelApplication = "pipecd.dev/application" // The application this resource belongs to.
+	LabelCommitHash  = "pipecd.dev/commit-hash" // Hash value of the deployed commit.
+	// The revisions of task definition deployed by the latest successful deployments.
+	// It is given to the service and formatted as the space-separated revision numbers from the newest one.
+	LabelTaskDefinitionRevisions = "pipecd.dev/task-definition-revisions"
+	ManagedByPiped               = "piped"
+)
+
 // Client is wrapper of ECS client.
 type Client interface {
 	ECS
@@ -36,16 +47,25 @@ type ECS interface {
 	ServiceExists(ctx context.Context, clusterName string, servicesName string) (bool, error)
 	CreateService(ctx context.Context, service types.Service) (*types.Service, error)
 	UpdateService(ctx context.Context, service types.Service) (*types.Service, error)
-	RegisterTaskDefinition(ctx context.Context, taskDefinition types.TaskDefinition) (*types.TaskDefinition, error)
+	RegisterTaskDefinition(ctx context.Context, taskDefinition types.TaskDefinition, tags []types.Tag) (*types.TaskDefinition, error)
+	// ListTaskDefinitionArns returns the ARNs of all ACTIVE revisions of the task definition families
+	// whose names start with the given family.
+	ListTaskDefinitionArns(ctx context.Context, family string) ([]string, error)
+	DeregisterTaskDefinition(ctx context.Context, taskDefinitionArn string) error
+	ListTags(ctx context.Context, resourceArn string) ([]types.Tag, error)
+	AddTags(ctx context.Context, resourceArn string, tags []types.Tag) error
 	GetPrimaryTaskSet(ctx context.Context, service types.Service) (*types.TaskSet, error)
-	CreateTaskSet(ctx context.Context, service types.Service, taskDefinition types.TaskDefinition, targetGroup types.LoadBalancer, scale int) (*types.TaskSet, error)
+	CreateTaskSet(ctx context.Context, service types.Service, taskDefinition types.TaskDefinition, targetGroup types.LoadBalancer, scale int, tags []types.Tag) (*types.TaskSet, error)
 	DeleteTaskSet(ctx context.Context, service types.Service, taskSetArn string) error
 	UpdateServicePrimaryTaskSet(ctx context.Context, service types.Service, taskSet types.TaskSet) (*types.TaskSet, error)
 }
 
 type ELB interface {
-	GetListener(ctx context.Context, targetGroup types.LoadBalancer) (string, error)
-	ModifyListener(ctx context.Context, listenerArn string, routingTrafficCfg RoutingTrafficConfig) error
+	// GetListenerArns returns all listeners of the load balancers serving the given target group.
+	GetListenerArns(ctx context.Context, targetGroup types.LoadBalancer) ([]string, error)
+	// ModifyListeners updates all rules of the given listeners those forward traffic
+	// to the target groups in the given config to route traffic with the specified weights.
+	ModifyListeners(ctx context.Context, listenerArns []string, routingTrafficCfg RoutingTrafficConfig) error
 }
 
 // Registry holds a pool of aws client wrappers.
@@ -65,6 +85,12 @@ func LoadTaskDefinition(appDir, taskDefinition string) (types.TaskDefinition, er
 	return loadTaskDefinition(path)
 }
 
+// LoadTaskDefinitionTags returns the tags specified in a given task definition file.
+func LoadTaskDefinitionTags(appDir, taskDefinition string) ([]types.Tag, error) {
+	path := filepath.Join(appDir, taskDefinition)
+	return loadTaskDefinitionTags(path)
+}
+
 // LoadTargetGroups returns primary & canary target groups according to the defined in pipe definition file.
 func LoadTargetGroups(targetGroups config.ECSTargetGroups) (*types.LoadBalancer, *types.LoadBalancer, error) {
 	return loadTargetGroups(targetGroups)
@@ -14,9 +14,71 @@
 
 package ecs
 
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	elbtypes "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+)
+
 type RoutingTrafficConfig []targetGroupWeight
 
 type targetGroupWeight struct {
 	TargetGroupArn string
 	Weight         int
 }
+
+// applyToActions returns a copy of the given actions whose forward actions
+// are changed to route traffic to the target groups with the configured weights.
+// Only the forward actions those are forwarding to any of the configured target groups are changed.
+// The returned bool reports whether any action was changed.
+func (c RoutingTrafficConfig) applyToActions(actions []elbtypes.Action) ([]elbtypes.Action, bool) {
+	targetGroups := make(map[string]struct{}, len(c))
+	for _, tg := range c {
+		targetGroups[tg.TargetGroupArn] = struct{}{}
+	}
+	isTarget := func(arn *string) bool {
+		_, ok := targetGroups[aws.ToString(arn)]
+		return ok
+	}
+
+	var (
+		out     = make([]elbtypes.Action, 0, len(actions))
+		changed bool
+	)
+	for _, a := range actions {
+		if a.Type != elbtypes.ActionTypeEnumForward {
+			out = append(out, a)
+			continue
+		}
+		forwarding := isTarget(a.TargetGroupArn)
+		if a.ForwardConfig != nil {
+			for _, tg := range a.ForwardConfig.TargetGroups {
+				forwarding = forwarding || isTarget(tg.TargetGroupArn)
+			}
+		}
+		if !forwarding {
+			out = append(out, a)
+			continue
+		}
+
+		tuples := make([]elbtypes.TargetGroupTuple, 0, len(c))
+		for _, tg := range c {
+			tuples = append(tuples, elbtypes.TargetGroupTuple{
+				TargetGroupArn: aws.String(tg.TargetGroupArn),
+				Weight:         aws.Int32(int32(tg.Weight)),
+			})
+		}
+		forwardConfig := &elbtypes.ForwardActionConfig{
+			TargetGroups: tuples,
+		}
+		if a.ForwardConfig != nil {
+			forwardConfig.TargetGroupStickinessConfig = a.ForwardConfig.TargetGroupStickinessConfig
+		}
+		out = append(out, elbtypes.Action{
+			Type:          elbtypes.ActionTypeEnumForward,
+			Order:         a.Order,
+			ForwardConfig: forwardConfig,
+		})
+		changed = true
+	}
+	return out, changed
+}
@@ -0,0 +1,94 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ecs
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	elbtypes "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyToActions(t *testing.T) {
+	cfg := RoutingTrafficConfig{
+		{TargetGroupArn: "primary", Weight: 80},
+		{TargetGroupArn: "canary", Weight: 20},
+	}
+	expectedForward := elbtypes.Action{
+		Type:  elbtypes.ActionTypeEnumForward,
+		Order: aws.Int32(2),
+		ForwardConfig: &elbtypes.ForwardActionConfig{
+			TargetGroups: []elbtypes.TargetGroupTuple{
+				{TargetGroupArn: aws.String("primary"), Weight: aws.Int32(80)},
+				{TargetGroupArn: aws.String("canary"), Weight: aws.Int32(20)},
+			},
+		},
+	}
+	authenticate := elbtypes.Action{
+		Type:  elbtypes.ActionTypeEnumAuthenticateOidc,
+		Order: aws.Int32(1),
+	}
+
+	testcases := []struct {
+		name            string
+		actions         []elbtypes.Action
+		expected        []elbtypes.Action
+		expectedChanged bool
+	}{
+		{
+			name: "forward to a single target group",
+			actions: []elbtypes.Action{
+				authenticate,
+				{Type: elbtypes.ActionTypeEnumForward, Order: aws.Int32(2), TargetGroupArn: aws.String("primary")},
+			},
+			expected:        []elbtypes.Action{authenticate, expectedForward},
+			expectedChanged: true,
+		},
+		{
+			name: "forward to weighted target groups",
+			actions: []elbtypes.Action{
+				{
+					Type:  elbtypes.ActionTypeEnumForward,
+					Order: aws.Int32(2),
+					ForwardConfig: &elbtypes.ForwardActionConfig{
+						TargetGroups: []elbtypes.TargetGroupTuple{
+							{TargetGroupArn: aws.String("primary"), Weight: aws.Int32(100)},
+							{TargetGroupArn: aws.String("canary"), Weight: aws.Int32(0)},
+						},
+					},
+				},
+			},
+			expected:        []elbtypes.Action{expectedForward},
+			expectedChanged: true,
+		},
+		{
+			name: "forward to other target group",
+			actions: []elbtypes.Action{
+				{Type: elbtypes.ActionTypeEnumForward, TargetGroupArn: aws.String("other")},
+			},
+			expected: []elbtypes.Action{
+				{Type: elbtypes.ActionTypeEnumForward, TargetGroupArn: aws.String("other")},
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, changed := cfg.applyToActions(tc.actions)
+			assert.Equal(t, tc.expectedChanged, changed)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
@@ -17,10 +17,12 @@ package ecs
 import (
 	"fmt"
 	"io/ioutil"
+	"strconv"
 	"strings"
 
 	"sigs.k8s.io/yaml"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
 )
 
@@ -40,6 +42,137 @@ func parseTaskDefinition(data []byte) (types.TaskDefinition, error) {
 	return obj, nil
 }
 
+func loadTaskDefinitionTags(path string) ([]types.Tag, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseTaskDefinitionTags(data)
+}
+
+// parseTaskDefinitionTags parses the tags field of the task definition
+// since it is not a part of types.TaskDefinition.
+func parseTaskDefinitionTags(data []byte) ([]types.Tag, error) {
+	var obj struct {
+		Tags []types.Tag `json:"tags"`
+	}
+	if err := yaml.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	return obj.Tags, nil
+}
+
+// MakeTags returns the given tags with the tags used by PipeCD to manage the resources.
+// The PipeCD-managed tags take precedence over the given ones with the same keys.
+func MakeTags(tags []types.Tag, pipedID, appID, commitHash string) []types.Tag {
+	managed := map[string]string{
+		LabelManagedBy:   ManagedByPiped,
+		LabelPiped:       pipedID,
+		LabelApplication: appID,
+		LabelCommitHash:  commitHash,
+	}
+	out := make([]types.Tag, 0, len(tags)+len(managed))
+	for _, t := range tags {
+		if _, ok := managed[aws.ToString(t.Key)]; !ok {
+			out = append(out, t)
+		}
+	}
+	for _, k := range []string{LabelManagedBy, LabelPiped, LabelApplication, LabelCommitHash} {
+		out = append(out, types.Tag{
+			Key:   aws.String(k),
+			Value: aws.String(managed[k]),
+		})
+	}
+	return out
+}
+
+// ParseTaskDefinitionArn returns the family and the revision number of the given task definition ARN
+// which is formatted as arn:aws:ecs:<region>:<account-id>:task-definition/<family>:<revision>.
+func ParseTaskDefinitionArn(arn string) (family string, revision int, err error) {
+	parts := strings.SplitN(arn, ":task-definition/", 2)
+	if len(parts) != 2 {
+		return "", 0, fmt.Errorf("malformed task definition arn %s", arn)
+	}
+	i := strings.LastIndex(parts[1], ":")
+	if i < 0 {
+		return "", 0, fmt.Errorf("missing revision in task definition arn %s", arn)
+	}
+	revision, err = strconv.Atoi(parts[1][i+1:])
+	if err != nil {
+		return "", 0, fmt.Errorf("malformed revision in task definition arn %s: %w", arn, err)
+	}
+	return parts[1][:i], revision, nil
+}
+
+// ParseTaskDefinitionRevisions parses the value of LabelTaskDefinitionRevisions tag.
+// The malformed revision numbers are ignored.
+func ParseTaskDefinitionRevisions(value string) []int {
+	fields := strings.Fields(value)
+	revisions := make([]int, 0, len(fields))
+	for _, f := range fields {
+		if r, err := strconv.Atoi(f); err == nil {
+			revisions = append(revisions, r)
+		}
+	}
+	return revisions
+}
+
+// FormatTaskDefinitionRevisions formats the given revision numbers as the value of LabelTaskDefinitionRevisions tag.
+func FormatTaskDefinitionRevisions(revisions []int) string {
+	fields := make([]string, 0, len(revisions))
+	for _, r := range revisions {
+		fields = append(fields, strconv.Itoa(r))
+	}
+	return strings.Join(fields, " ")
+}
+
+// AddTaskDefinitionRevision returns the revisions of the latest limit successful deployments
+// by adding the given revision to the recorded ones those are ordered from the newest one.
+func AddTaskDefinitionRevision(revisions []int, revision, limit int) []int {
+	out := make([]int, 0, limit)
+	out = append(out, revision)
+	for _, r := range revisions {
+		if len(out) >= limit {
+			break
+		}
+		if r != revision {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// FindOutdatedTaskDefinitionArns returns the ARNs of the revisions of the given family
+// those are older than the newest kept revision but are not kept.
+// The revisions of other families and the ones registered after the newest kept revision are excluded.
+func FindOutdatedTaskDefinitionArns(arns []string, family string, kept []int) []string {
+	if len(kept) == 0 {
+		return nil
+	}
+	var (
+		keptSet = make(map[int]struct{}, len(kept))
+		newest  int
+		out     []string
+	)
+	for _, r := range kept {
+		keptSet[r] = struct{}{}
+		if r > newest {
+			newest = r
+		}
+	}
+	for _, arn := range arns {
+		f, r, err := ParseTaskDefinitionArn(arn)
+		if err != nil || f != family || r >= newest {
+			continue
+		}
+		if _, ok := keptSet[r]; ok {
+			continue
+		}
+		out = append(out, arn)
+	}
+	return out
+}
+
 // FindImageTag parses image tag from given ECS task definition.
 func FindImageTag(taskDefinition types.TaskDefinition) (string, error) {
 	if len(taskDefinition.ContainerDefinitions) == 0 {
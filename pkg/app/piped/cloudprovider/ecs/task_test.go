@@ -15,6 +15,7 @@
 package ecs
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -78,3 +79,116 @@ cpu: 256
 		})
 	}
 }
+
+func TestParseTaskDefinitionTags(t *testing.T) {
+	tags, err := parseTaskDefinitionTags([]byte(`
+family: nginx-canary-fam-1
+tags:
+  - key: team
+    value: payment
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Tag{{Key: aws.String("team"), Value: aws.String("payment")}}, tags)
+}
+
+func TestMakeTags(t *testing.T) {
+	tags := []types.Tag{
+		{Key: aws.String("team"), Value: aws.String("payment")},
+		{Key: aws.String(LabelCommitHash), Value: aws.String("overwritten")},
+	}
+	expected := []types.Tag{
+		{Key: aws.String("team"), Value: aws.String("payment")},
+		{Key: aws.String(LabelManagedBy), Value: aws.String(ManagedByPiped)},
+		{Key: aws.String(LabelPiped), Value: aws.String("piped-id")},
+		{Key: aws.String(LabelApplication), Value: aws.String("app-id")},
+		{Key: aws.String(LabelCommitHash), Value: aws.String("commit-hash")},
+	}
+	assert.Equal(t, expected, MakeTags(tags, "piped-id", "app-id", "commit-hash"))
+}
+
+func TestParseTaskDefinitionArn(t *testing.T) {
+	testcases := []struct {
+		name             string
+		arn              string
+		expectedFamily   string
+		expectedRevision int
+		expectedErr      bool
+	}{
+		{
+			name:             "valid arn",
+			arn:              "arn:aws:ecs:ap-northeast-1:123456789012:task-definition/nginx-canary-fam-1:12",
+			expectedFamily:   "nginx-canary-fam-1",
+			expectedRevision: 12,
+		},
+		{
+			name:        "missing revision",
+			arn:         "arn:aws:ecs:ap-northeast-1:123456789012:task-definition/nginx-canary-fam-1",
+			expectedErr: true,
+		},
+		{
+			name:        "not a task definition",
+			arn:         "arn:aws:ecs:ap-northeast-1:123456789012:service/cluster/nginx:12",
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			family, revision, err := ParseTaskDefinitionArn(tc.arn)
+			assert.Equal(t, tc.expectedErr, err != nil)
+			assert.Equal(t, tc.expectedFamily, family)
+			assert.Equal(t, tc.expectedRevision, revision)
+		})
+	}
+}
+
+func TestTaskDefinitionRevisions(t *testing.T) {
+	revisions := ParseTaskDefinitionRevisions("9 x 7")
+	assert.Equal(t, []int{9, 7}, revisions)
+
+	revisions = AddTaskDefinitionRevision(revisions, 12, 2)
+	assert.Equal(t, []int{12, 9}, revisions)
+	assert.Equal(t, "12 9", FormatTaskDefinitionRevisions(revisions))
+
+	assert.Equal(t, []int{12, 9}, AddTaskDefinitionRevision(revisions, 12, 3))
+}
+
+func TestFindOutdatedTaskDefinitionArns(t *testing.T) {
+	arn := func(family string, revision int) string {
+		return fmt.Sprintf("arn:aws:ecs:ap-northeast-1:123456789012:task-definition/%s:%d", family, revision)
+	}
+	arns := []string{
+		arn("fam", 1),
+		arn("fam", 2),
+		arn("fam-other", 3),
+		arn("fam", 4),
+		arn("fam", 5),
+		arn("fam", 6),
+		arn("fam", 7),
+	}
+
+	testcases := []struct {
+		name     string
+		kept     []int
+		expected []string
+	}{
+		{
+			name: "no kept revision",
+		},
+		{
+			name:     "keep the latest successful deployment",
+			kept:     []int{6},
+			expected: []string{arn("fam", 1), arn("fam", 2), arn("fam", 4), arn("fam", 5)},
+		},
+		{
+			name:     "keep the latest two successful deployments",
+			kept:     []int{6, 2},
+			expected: []string{arn("fam", 1), arn("fam", 4), arn("fam", 5)},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := FindOutdatedTaskDefinitionArns(arns, "fam", tc.kept)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
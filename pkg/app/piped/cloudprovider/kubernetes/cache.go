@@ -66,3 +66,9 @@ func (c AppManifestsCache) Put(commit string, manifests []Manifest) {
 func appManifestsCacheKey(appID, commit string) string {
 	return fmt.Sprintf("%s/%s", appID, commit)
 }
+
+// TargetCacheID returns the ID used instead of the application ID
+// to cache the manifests rendered for a target of a multi-cluster application.
+func TargetCacheID(appID, target string) string {
+	return fmt.Sprintf("%s:%s", appID, target)
+}
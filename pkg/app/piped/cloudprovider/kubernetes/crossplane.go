@@ -0,0 +1,123 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+const (
+	crossplaneConditionReady  = "Ready"
+	crossplaneConditionSynced = "Synced"
+)
+
+// crossplaneReferenceFields are the spec fields Crossplane uses for
+// managed resources (forProvider), composite resources and claims (the rest).
+var crossplaneReferenceFields = []string{
+	"forProvider",
+	"compositionRef",
+	"compositionSelector",
+	"compositionRevisionRef",
+	"resourceRef",
+	"resourceRefs",
+	"writeConnectionSecretToRef",
+}
+
+// IsCrossplaneReconciledResource reports whether the given manifest is
+// a resource that will be reconciled by Crossplane and then marked with the Ready and Synced conditions.
+// That includes the managed resources, composite resources and claims,
+// while the configuration resources such as ProviderConfig or Composition are not.
+func IsCrossplaneReconciledResource(m Manifest) bool {
+	if IsKubernetesBuiltInResource(m.Key.APIVersion) {
+		return false
+	}
+	spec, ok := m.u.Object["spec"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	for _, f := range crossplaneReferenceFields {
+		if _, ok := spec[f]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// determineCrossplaneResourceHealth determines the health status of the given resource
+// based on the Ready and Synced conditions set by Crossplane.
+// The last returned value is false when the resource has neither of those conditions.
+func determineCrossplaneResourceHealth(obj *unstructured.Unstructured) (status model.KubernetesResourceState_HealthStatus, desc string, ok bool) {
+	conditions, _, err := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	if err != nil || len(conditions) == 0 {
+		return
+	}
+
+	var ready, synced map[string]interface{}
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		switch cond["type"] {
+		case crossplaneConditionReady:
+			ready = cond
+		case crossplaneConditionSynced:
+			synced = cond
+		}
+	}
+	if ready == nil && synced == nil {
+		return
+	}
+
+	ok = true
+	status = model.KubernetesResourceState_OTHER
+	// A resource failing to be synced with the external system must be reported
+	// even though it was ready before.
+	if synced != nil && synced["status"] != "True" {
+		desc = fmt.Sprintf("%q is not synced: %s", obj.GetName(), describeCrossplaneCondition(synced))
+		return
+	}
+	if ready != nil && ready["status"] != "True" {
+		desc = fmt.Sprintf("%q is not ready: %s", obj.GetName(), describeCrossplaneCondition(ready))
+		return
+	}
+	if ready == nil {
+		desc = fmt.Sprintf("%q is synced but waiting to be ready", obj.GetName())
+		return
+	}
+
+	status = model.KubernetesResourceState_HEALTHY
+	desc = fmt.Sprintf("%q is ready and synced", obj.GetName())
+	return
+}
+
+func describeCrossplaneCondition(cond map[string]interface{}) string {
+	var parts []string
+	if reason, ok := cond["reason"].(string); ok && reason != "" {
+		parts = append(parts, reason)
+	}
+	if message, ok := cond["message"].(string); ok && message != "" {
+		parts = append(parts, message)
+	}
+	if len(parts) == 0 {
+		return fmt.Sprintf("condition status is %v", cond["status"])
+	}
+	return strings.Join(parts, ": ")
+}
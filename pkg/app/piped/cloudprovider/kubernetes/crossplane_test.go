@@ -0,0 +1,181 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+func TestDetermineCrossplaneResourceHealth(t *testing.T) {
+	testcases := []struct {
+		name           string
+		manifest       string
+		expectedStatus model.KubernetesResourceState_HealthStatus
+		expectedDesc   string
+		expectedOK     bool
+	}{
+		{
+			name: "no conditions",
+			manifest: `
+apiVersion: s3.aws.crossplane.io/v1beta1
+kind: Bucket
+metadata:
+  name: bucket
+spec:
+  forProvider:
+    locationConstraint: us-east-1
+`,
+		},
+		{
+			name: "ready and synced",
+			manifest: `
+apiVersion: s3.aws.crossplane.io/v1beta1
+kind: Bucket
+metadata:
+  name: bucket
+status:
+  conditions:
+  - type: Ready
+    status: "True"
+    reason: Available
+  - type: Synced
+    status: "True"
+    reason: ReconcileSuccess
+`,
+			expectedStatus: model.KubernetesResourceState_HEALTHY,
+			expectedDesc:   `"bucket" is ready and synced`,
+			expectedOK:     true,
+		},
+		{
+			name: "not ready",
+			manifest: `
+apiVersion: s3.aws.crossplane.io/v1beta1
+kind: Bucket
+metadata:
+  name: bucket
+status:
+  conditions:
+  - type: Ready
+    status: "False"
+    reason: Creating
+  - type: Synced
+    status: "True"
+    reason: ReconcileSuccess
+`,
+			expectedStatus: model.KubernetesResourceState_OTHER,
+			expectedDesc:   `"bucket" is not ready: Creating`,
+			expectedOK:     true,
+		},
+		{
+			name: "ready but failed to sync",
+			manifest: `
+apiVersion: s3.aws.crossplane.io/v1beta1
+kind: Bucket
+metadata:
+  name: bucket
+status:
+  conditions:
+  - type: Ready
+    status: "True"
+    reason: Available
+  - type: Synced
+    status: "False"
+    reason: ReconcileError
+    message: access denied
+`,
+			expectedStatus: model.KubernetesResourceState_OTHER,
+			expectedDesc:   `"bucket" is not synced: ReconcileError: access denied`,
+			expectedOK:     true,
+		},
+		{
+			name: "synced but not yet ready",
+			manifest: `
+apiVersion: database.example.org/v1alpha1
+kind: PostgreSQLInstance
+metadata:
+  name: db
+status:
+  conditions:
+  - type: Synced
+    status: "True"
+    reason: ReconcileSuccess
+`,
+			expectedStatus: model.KubernetesResourceState_OTHER,
+			expectedDesc:   `"db" is synced but waiting to be ready`,
+			expectedOK:     true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			manifests, err := ParseManifests(tc.manifest)
+			require.NoError(t, err)
+			require.Len(t, manifests, 1)
+
+			status, desc, ok := determineCrossplaneResourceHealth(manifests[0].u)
+			assert.Equal(t, tc.expectedOK, ok)
+			assert.Equal(t, tc.expectedStatus, status)
+			assert.Equal(t, tc.expectedDesc, desc)
+		})
+	}
+}
+
+func TestIsCrossplaneReconciledResource(t *testing.T) {
+	manifests, err := ParseManifests(`
+apiVersion: s3.aws.crossplane.io/v1beta1
+kind: Bucket
+metadata:
+  name: bucket
+spec:
+  forProvider:
+    locationConstraint: us-east-1
+---
+apiVersion: database.example.org/v1alpha1
+kind: PostgreSQLInstance
+metadata:
+  name: db
+  namespace: default
+spec:
+  compositionSelector:
+    matchLabels:
+      provider: aws
+---
+apiVersion: aws.crossplane.io/v1beta1
+kind: ProviderConfig
+metadata:
+  name: default
+spec:
+  credentials:
+    source: InjectedIdentity
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 1
+`)
+	require.NoError(t, err)
+	require.Len(t, manifests, 4)
+
+	assert.True(t, IsCrossplaneReconciledResource(manifests[0]))
+	assert.True(t, IsCrossplaneReconciledResource(manifests[1]))
+	assert.False(t, IsCrossplaneReconciledResource(manifests[2]))
+	assert.False(t, IsCrossplaneReconciledResource(manifests[3]))
+}
@@ -30,6 +30,11 @@ type Kubectl struct {
 	version  string
 	execPath string
 	config   *rest.Config
+
+	// The cluster to connect to.
+	// Empty means the cluster configured in the default kubeconfig.
+	masterURL      string
+	kubeConfigPath string
 }
 
 func NewKubectl(version, path string) *Kubectl {
@@ -39,6 +44,29 @@ func NewKubectl(version, path string) *Kubectl {
 	}
 }
 
+// WithCluster returns a copy of this Kubectl that runs commands against the given cluster.
+func (c *Kubectl) WithCluster(masterURL, kubeConfigPath string) *Kubectl {
+	copied := *c
+	copied.masterURL = masterURL
+	copied.kubeConfigPath = kubeConfigPath
+	return &copied
+}
+
+// makeCommonArgs returns the flags used to connect to the cluster and select the namespace.
+func (c *Kubectl) makeCommonArgs(namespace string) []string {
+	args := make([]string, 0, 6)
+	if c.kubeConfigPath != "" {
+		args = append(args, "--kubeconfig", c.kubeConfigPath)
+	}
+	if c.masterURL != "" {
+		args = append(args, "--server", c.masterURL)
+	}
+	if namespace != "" {
+		args = append(args, "-n", namespace)
+	}
+	return args
+}
+
 func (c *Kubectl) Apply(ctx context.Context, namespace string, manifest Manifest) (err error) {
 	defer func() {
 		kubernetesmetrics.IncKubectlCallsCounter(
@@ -53,10 +81,7 @@ func (c *Kubectl) Apply(ctx context.Context, namespace string, manifest Manifest
 		return err
 	}
 
-	args := make([]string, 0, 5)
-	if namespace != "" {
-		args = append(args, "-n", namespace)
-	}
+	args := c.makeCommonArgs(namespace)
 	args = append(args, "apply", "-f", "-")
 
 	cmd := exec.CommandContext(ctx, c.execPath, args...)
@@ -79,10 +104,7 @@ func (c *Kubectl) Delete(ctx context.Context, namespace string, r ResourceKey) (
 		)
 	}()
 
-	args := make([]string, 0, 5)
-	if namespace != "" {
-		args = append(args, "-n", namespace)
-	}
+	args := c.makeCommonArgs(namespace)
 	args = append(args, "delete", r.Kind, r.Name)
 
 	cmd := exec.CommandContext(ctx, c.execPath, args...)
@@ -96,3 +118,58 @@ func (c *Kubectl) Delete(ctx context.Context, namespace string, r ResourceKey) (
 	}
 	return nil
 }
+
+func (c *Kubectl) Get(ctx context.Context, namespace string, r ResourceKey) (m Manifest, err error) {
+	defer func() {
+		kubernetesmetrics.IncKubectlCallsCounter(
+			c.version,
+			kubernetesmetrics.LabelGetCommand,
+			err == nil,
+		)
+	}()
+
+	args := c.makeCommonArgs(namespace)
+	args = append(args, "get", r.Kind, r.Name, "-o", "yaml")
+
+	cmd := exec.CommandContext(ctx, c.execPath, args...)
+	out, err := cmd.CombinedOutput()
+
+	if strings.Contains(string(out), "(NotFound)") {
+		return Manifest{}, fmt.Errorf("failed to get: %s, (%w), %v", string(out), ErrNotFound, err)
+	}
+	if err != nil {
+		return Manifest{}, fmt.Errorf("failed to get: %s, %v", string(out), err)
+	}
+
+	ms, err := ParseManifests(string(out))
+	if err != nil {
+		return Manifest{}, fmt.Errorf("failed to parse manifests %v: %v", r, err)
+	}
+	if len(ms) == 0 {
+		return Manifest{}, ErrNotFound
+	}
+	return ms[0], nil
+}
+
+func (c *Kubectl) Logs(ctx context.Context, namespace string, r ResourceKey) (logs string, err error) {
+	defer func() {
+		kubernetesmetrics.IncKubectlCallsCounter(
+			c.version,
+			kubernetesmetrics.LabelLogsCommand,
+			err == nil,
+		)
+	}()
+
+	args := c.makeCommonArgs(namespace)
+	args = append(args, "logs", strings.ToLower(r.Kind)+"/"+r.Name, "--all-containers", "--prefix")
+
+	cmd := exec.CommandContext(ctx, c.execPath, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("failed to get logs: %s (%v)", stderr.String(), err)
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,32 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKubectlMakeCommonArgs(t *testing.T) {
+	kubectl := NewKubectl("1.18.2", "kubectl")
+	assert.Empty(t, kubectl.makeCommonArgs(""))
+	assert.Equal(t, []string{"-n", "default"}, kubectl.makeCommonArgs("default"))
+
+	cluster := kubectl.WithCluster("https://cluster-asia:6443", "/etc/kube/asia")
+	assert.Equal(t, []string{"--kubeconfig", "/etc/kube/asia", "--server", "https://cluster-asia:6443", "-n", "default"}, cluster.makeCommonArgs("default"))
+	// The original one must not be changed.
+	assert.Empty(t, kubectl.makeCommonArgs(""))
+}
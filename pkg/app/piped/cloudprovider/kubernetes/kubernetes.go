@@ -42,6 +42,8 @@ const (
 	LabelOriginalAPIVersion   = "pipecd.dev/original-api-version"   // The api version defined in git configuration. e.g. apps/v1
 	LabelIgnoreDriftDirection = "pipecd.dev/ignore-drift-detection" // Whether the drift detection should ignore this resource.
 	AnnotationConfigHash      = "pipecd.dev/config-hash"            // The hash value of all mouting config resources.
+	AnnotationHook            = "pipecd.dev/hook"                   // The sync phase at which this resource should be run as a hook. e.g. PreSync
+	AnnotationSyncWave        = "pipecd.dev/sync-wave"              // The wave number to which this resource belongs. Lower waves are applied first.
 	ManagedByPiped            = "piped"
 	IgnoreDriftDetectionTrue  = "true"
 
@@ -73,6 +75,10 @@ type Applier interface {
 	ApplyManifest(ctx context.Context, manifest Manifest) error
 	// Delete deletes the given resource from Kubernetes cluster.
 	Delete(ctx context.Context, key ResourceKey) error
+	// Get returns the manifest of the given resource running in Kubernetes cluster.
+	Get(ctx context.Context, key ResourceKey) (Manifest, error)
+	// Logs returns the logs of all containers of the given resource.
+	Logs(ctx context.Context, key ResourceKey) (string, error)
 }
 
 type gitClient interface {
@@ -91,6 +97,7 @@ type provider struct {
 	repoDir        string
 	configFileName string
 	input          config.KubernetesDeploymentInput
+	cluster        *config.CloudProviderKubernetesConfig
 	logger         *zap.Logger
 
 	kubectl          *Kubectl
@@ -109,8 +116,18 @@ func initSharedGitClient(logger *zap.Logger) error {
 	return err
 }
 
-func NewProvider(appName, appDir, repoDir, configFileName string, input config.KubernetesDeploymentInput, logger *zap.Logger) Provider {
-	return &provider{
+type Option func(*provider)
+
+// WithCluster sets the cluster where the resources should be applied to.
+// By default, the cluster configured in the default kubeconfig will be used.
+func WithCluster(cfg *config.CloudProviderKubernetesConfig) Option {
+	return func(p *provider) {
+		p.cluster = cfg
+	}
+}
+
+func NewProvider(appName, appDir, repoDir, configFileName string, input config.KubernetesDeploymentInput, logger *zap.Logger, opts ...Option) Provider {
+	p := &provider{
 		appName:        appName,
 		appDir:         appDir,
 		repoDir:        repoDir,
@@ -118,6 +135,10 @@ func NewProvider(appName, appDir, repoDir, configFileName string, input config.K
 		input:          input,
 		logger:         logger.Named("kubernetes-provider"),
 	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
 }
 
 func NewManifestLoader(appName, appDir, repoDir, configFileName string, input config.KubernetesDeploymentInput, logger *zap.Logger) ManifestLoader {
@@ -137,6 +158,9 @@ func (p *provider) init(ctx context.Context) {
 	if p.initErr != nil {
 		return
 	}
+	if p.cluster != nil {
+		p.kubectl = p.kubectl.WithCluster(p.cluster.MasterURL, p.cluster.KubeConfigPath)
+	}
 
 	switch p.templatingMethod {
 	case TemplatingMethodHelm:
@@ -245,6 +269,26 @@ func (p *provider) Delete(ctx context.Context, k ResourceKey) (err error) {
 	return p.kubectl.Delete(ctx, p.getNamespaceToRun(k), k)
 }
 
+// Get returns the manifest of the given resource running in Kubernetes cluster.
+func (p *provider) Get(ctx context.Context, k ResourceKey) (Manifest, error) {
+	p.initOnce.Do(func() { p.init(ctx) })
+	if p.initErr != nil {
+		return Manifest{}, p.initErr
+	}
+
+	return p.kubectl.Get(ctx, p.getNamespaceToRun(k), k)
+}
+
+// Logs returns the logs of all containers of the given resource.
+func (p *provider) Logs(ctx context.Context, k ResourceKey) (string, error) {
+	p.initOnce.Do(func() { p.init(ctx) })
+	if p.initErr != nil {
+		return "", p.initErr
+	}
+
+	return p.kubectl.Logs(ctx, p.getNamespaceToRun(k), k)
+}
+
 // getNamespaceToRun returns namespace used on kubectl apply/delete commands.
 // priority: config.KubernetesDeploymentInput > kubernetes.ResourceKey
 func (p *provider) getNamespaceToRun(k ResourceKey) string {
@@ -36,6 +36,8 @@ type ToolCommand string
 const (
 	LabelApplyCommand  ToolCommand = "apply"
 	LabelDeleteCommand ToolCommand = "delete"
+	LabelGetCommand    ToolCommand = "get"
+	LabelLogsCommand   ToolCommand = "logs"
 )
 
 type CommandOutput string
@@ -58,24 +58,26 @@ var builtInApiVersions = map[string]struct{}{
 }
 
 const (
-	KindDeployment            = "Deployment"
-	KindStatefulSet           = "StatefulSet"
-	KindDaemonSet             = "DaemonSet"
-	KindReplicaSet            = "ReplicaSet"
-	KindPod                   = "Pod"
-	KindJob                   = "Job"
-	KindCronJob               = "CronJob"
-	KindConfigMap             = "ConfigMap"
-	KindSecret                = "Secret"
-	KindPersistentVolume      = "PersistentVolume"
-	KindPersistentVolumeClaim = "PersistentVolumeClaim"
-	KindService               = "Service"
-	KindIngress               = "Ingress"
-	KindServiceAccount        = "ServiceAccount"
-	KindRole                  = "Role"
-	KindRoleBinding           = "RoleBinding"
-	KindClusterRole           = "ClusterRole"
-	KindClusterRoleBinding    = "ClusterRoleBinding"
+	KindDeployment               = "Deployment"
+	KindStatefulSet              = "StatefulSet"
+	KindDaemonSet                = "DaemonSet"
+	KindReplicaSet               = "ReplicaSet"
+	KindPod                      = "Pod"
+	KindJob                      = "Job"
+	KindCronJob                  = "CronJob"
+	KindConfigMap                = "ConfigMap"
+	KindSecret                   = "Secret"
+	KindPersistentVolume         = "PersistentVolume"
+	KindPersistentVolumeClaim    = "PersistentVolumeClaim"
+	KindService                  = "Service"
+	KindIngress                  = "Ingress"
+	KindServiceAccount           = "ServiceAccount"
+	KindRole                     = "Role"
+	KindRoleBinding              = "RoleBinding"
+	KindClusterRole              = "ClusterRole"
+	KindClusterRoleBinding       = "ClusterRoleBinding"
+	KindNamespace                = "Namespace"
+	KindCustomResourceDefinition = "CustomResourceDefinition"
 
 	DefaultNamespace = "default"
 )
@@ -176,6 +178,26 @@ func (k ResourceKey) IsSecret() bool {
 	return true
 }
 
+func (k ResourceKey) IsNamespace() bool {
+	if k.Kind != KindNamespace {
+		return false
+	}
+	if !IsKubernetesBuiltInResource(k.APIVersion) {
+		return false
+	}
+	return true
+}
+
+func (k ResourceKey) IsCustomResourceDefinition() bool {
+	if k.Kind != KindCustomResourceDefinition {
+		return false
+	}
+	if !IsKubernetesBuiltInResource(k.APIVersion) {
+		return false
+	}
+	return true
+}
+
 // IsLess reports whether the key should sort before the given key.
 func (k ResourceKey) IsLess(a ResourceKey) bool {
 	if k.APIVersion < a.APIVersion {
@@ -63,8 +63,16 @@ func MakeKubernetesResourceState(uid string, key ResourceKey, obj *unstructured.
 	return state
 }
 
+// DetermineManifestHealth returns the health status of the given running resource.
+func DetermineManifestHealth(m Manifest) (status model.KubernetesResourceState_HealthStatus, desc string) {
+	return determineResourceHealth(m.Key, m.u)
+}
+
 func determineResourceHealth(key ResourceKey, obj *unstructured.Unstructured) (status model.KubernetesResourceState_HealthStatus, desc string) {
 	if !IsKubernetesBuiltInResource(key.APIVersion) {
+		if status, desc, ok := determineCrossplaneResourceHealth(obj); ok {
+			return status, desc
+		}
 		desc = fmt.Sprintf("Unreadable resource kind %s/%s", key.APIVersion, key.Kind)
 		return
 	}
@@ -106,6 +114,10 @@ func determineResourceHealth(key ResourceKey, obj *unstructured.Unstructured) (s
 		return determineClusterRoleHealth(obj)
 	case KindClusterRoleBinding:
 		return determineClusterRoleBindingHealth(obj)
+	case KindNamespace:
+		return determineNamespaceHealth(obj)
+	case KindCustomResourceDefinition:
+		return determineCustomResourceDefinitionHealth(obj)
 	default:
 		desc = "Unimplemented or unknown resource"
 		return
@@ -512,3 +524,52 @@ func determineServiceAccountHealth(obj *unstructured.Unstructured) (status model
 	status = model.KubernetesResourceState_HEALTHY
 	return
 }
+
+func determineNamespaceHealth(obj *unstructured.Unstructured) (status model.KubernetesResourceState_HealthStatus, desc string) {
+	ns := &corev1.Namespace{}
+	err := scheme.Scheme.Convert(obj, ns, nil)
+	if err != nil {
+		status = model.KubernetesResourceState_OTHER
+		desc = fmt.Sprintf("Unexpected error while calculating: unable to convert %T to %T: %v", obj, ns, err)
+		return
+	}
+
+	switch ns.Status.Phase {
+	case corev1.NamespaceActive:
+		status = model.KubernetesResourceState_HEALTHY
+	case corev1.NamespaceTerminating:
+		status = model.KubernetesResourceState_OTHER
+		desc = "Namespace is being terminated"
+	default:
+		status = model.KubernetesResourceState_OTHER
+		desc = "The current phase of Namespace is unexpected"
+	}
+	return
+}
+
+func determineCustomResourceDefinitionHealth(obj *unstructured.Unstructured) (status model.KubernetesResourceState_HealthStatus, desc string) {
+	status = model.KubernetesResourceState_OTHER
+	conditions, _, err := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	if err != nil {
+		desc = fmt.Sprintf("Unexpected error while calculating: unable to read conditions: %v", err)
+		return
+	}
+
+	// CustomResourceDefinition is ready to serve its custom resources
+	// only after its Established condition has become true.
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok || cond["type"] != "Established" {
+			continue
+		}
+		if cond["status"] == "True" {
+			status = model.KubernetesResourceState_HEALTHY
+			return
+		}
+		desc = fmt.Sprintf("CustomResourceDefinition is not established yet: %v", cond["message"])
+		return
+	}
+
+	desc = "Waiting for CustomResourceDefinition to be established"
+	return
+}
@@ -15,9 +15,12 @@
 package lambda
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"reflect"
 	"time"
 
@@ -26,6 +29,8 @@ import (
 	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
 	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
 	"go.uber.org/zap"
 
 	"github.com/pipe-cd/pipe/pkg/backoff"
@@ -107,20 +112,110 @@ func (c *client) IsFunctionExist(ctx context.Context, name string) (bool, error)
 	return true, nil
 }
 
+func (c *client) GetFunction(ctx context.Context, name string) (FunctionManifest, error) {
+	input := &lambda.GetFunctionInput{
+		FunctionName: aws.String(name),
+	}
+	var body []byte
+	output, err := c.client.GetFunction(ctx, input, withResponseBody(&body))
+	if err != nil {
+		var nfe *types.ResourceNotFoundException
+		if errors.As(err, &nfe) {
+			return FunctionManifest{}, ErrNotFound
+		}
+		return FunctionManifest{}, fmt.Errorf("failed to get Lambda function %s: %w", name, err)
+	}
+	// Read the fields not available in the version of AWS SDK we are using from the response body.
+	var additional struct {
+		Configuration struct {
+			Architectures    []string
+			EphemeralStorage *struct {
+				Size int32
+			}
+		}
+	}
+	if err := json.Unmarshal(body, &additional); len(body) > 0 && err != nil {
+		return FunctionManifest{}, fmt.Errorf("failed to parse the response of Lambda function %s: %w", name, err)
+	}
+
+	cfg := output.Configuration
+	fm := FunctionManifest{
+		Kind:       functionManifestKind,
+		APIVersion: versionV1Beta1,
+		Spec: FunctionManifestSpec{
+			Name:    aws.ToString(cfg.FunctionName),
+			Role:    aws.ToString(cfg.Role),
+			Runtime: string(cfg.Runtime),
+			Handler: aws.ToString(cfg.Handler),
+			Memory:  aws.ToInt32(cfg.MemorySize),
+			Timeout: aws.ToInt32(cfg.Timeout),
+			Tags:    output.Tags,
+		},
+		codeSHA256: aws.ToString(cfg.CodeSha256),
+	}
+	if output.Code != nil {
+		fm.Spec.ImageURI = aws.ToString(output.Code.ImageUri)
+	}
+	for _, l := range cfg.Layers {
+		fm.Spec.Layers = append(fm.Spec.Layers, aws.ToString(l.Arn))
+	}
+	if v := cfg.VpcConfig; v != nil && (len(v.SubnetIds) > 0 || len(v.SecurityGroupIds) > 0) {
+		fm.Spec.VPCConfig = &VPCConfig{
+			SubnetIDs:        v.SubnetIds,
+			SecurityGroupIDs: v.SecurityGroupIds,
+		}
+	}
+	fm.Spec.Architectures = additional.Configuration.Architectures
+	if s := additional.Configuration.EphemeralStorage; s != nil {
+		fm.Spec.EphemeralStorage = &EphemeralStorage{
+			Size: s.Size,
+		}
+	}
+	if cfg.Environment != nil {
+		fm.Spec.Environments = cfg.Environment.Variables
+	}
+	return fm, nil
+}
+
 func (c *client) CreateFunction(ctx context.Context, fm FunctionManifest) error {
 	input := &lambda.CreateFunctionInput{
-		Code: &types.FunctionCode{
-			ImageUri: aws.String(fm.Spec.ImageURI),
-		},
-		PackageType:  types.PackageType("Image"),
 		Role:         aws.String(fm.Spec.Role),
 		FunctionName: aws.String(fm.Spec.Name),
+		MemorySize:   aws.Int32(fm.Spec.Memory),
+		Timeout:      aws.Int32(fm.Spec.Timeout),
 		Tags:         fm.Spec.Tags,
 		Environment: &types.Environment{
 			Variables: fm.Spec.Environments,
 		},
+		VpcConfig: makeVPCConfig(fm.Spec.VPCConfig),
+	}
+	if fm.Spec.isZipPackage() {
+		input.PackageType = types.PackageTypeZip
+		input.Code = &types.FunctionCode{
+			S3Bucket:        optionalString(fm.Spec.S3Bucket),
+			S3Key:           optionalString(fm.Spec.S3Key),
+			S3ObjectVersion: optionalString(fm.Spec.S3ObjectVersion),
+			ZipFile:         fm.zipFile,
+		}
+		input.Runtime = types.Runtime(fm.Spec.Runtime)
+		input.Handler = aws.String(fm.Spec.Handler)
+		input.Layers = fm.Spec.Layers
+	} else {
+		input.PackageType = types.PackageTypeImage
+		input.Code = &types.FunctionCode{
+			ImageUri: aws.String(fm.Spec.ImageURI),
+		}
 	}
-	_, err := c.client.CreateFunction(ctx, input)
+
+	fields := make(map[string]interface{})
+	if len(fm.Spec.Architectures) > 0 {
+		fields["Architectures"] = fm.Spec.Architectures
+	}
+	if fm.Spec.EphemeralStorage != nil {
+		fields["EphemeralStorage"] = map[string]int32{"Size": fm.Spec.EphemeralStorage.Size}
+	}
+
+	_, err := c.client.CreateFunction(ctx, input, withAdditionalFields(fields))
 	if err != nil {
 		return fmt.Errorf("failed to create Lambda function %s: %w", fm.Spec.Name, err)
 	}
@@ -131,14 +226,29 @@ func (c *client) UpdateFunction(ctx context.Context, fm FunctionManifest) error
 	// Update function code.
 	codeInput := &lambda.UpdateFunctionCodeInput{
 		FunctionName: aws.String(fm.Spec.Name),
-		ImageUri:     aws.String(fm.Spec.ImageURI),
 	}
-	_, err := c.client.UpdateFunctionCode(ctx, codeInput)
+	if fm.Spec.isZipPackage() {
+		codeInput.S3Bucket = optionalString(fm.Spec.S3Bucket)
+		codeInput.S3Key = optionalString(fm.Spec.S3Key)
+		codeInput.S3ObjectVersion = optionalString(fm.Spec.S3ObjectVersion)
+		codeInput.ZipFile = fm.zipFile
+	} else {
+		codeInput.ImageUri = aws.String(fm.Spec.ImageURI)
+	}
+	// Always specify the architectures and ephemeral storage with the default values applied
+	// so that the removed ones are reverted to the defaults.
+	codeFields := map[string]interface{}{
+		"Architectures": fm.Spec.architectures(),
+	}
+	_, err := c.client.UpdateFunctionCode(ctx, codeInput, withAdditionalFields(codeFields))
 	if err != nil {
 		return fmt.Errorf("failed to update function code for Lambda function %s: %w", fm.Spec.Name, err)
 	}
 
 	// Update function configuration.
+	configFields := map[string]interface{}{
+		"EphemeralStorage": map[string]int32{"Size": fm.Spec.ephemeralStorageSize()},
+	}
 	retry := backoff.NewRetry(RequestRetryTime, backoff.NewConstant(RetryIntervalDuration))
 	updateFunctionConfigurationSucceed := false
 	for retry.WaitNext(ctx) {
@@ -149,8 +259,25 @@ func (c *client) UpdateFunction(ctx context.Context, fm FunctionManifest) error
 			Environment: &types.Environment{
 				Variables: fm.Spec.Environments,
 			},
+			VpcConfig: makeVPCConfig(fm.Spec.VPCConfig),
+		}
+		// Always specify the VPC config so that the function is disconnected from the VPC when it was removed.
+		if configInput.VpcConfig == nil {
+			configInput.VpcConfig = &types.VpcConfig{
+				SubnetIds:        []string{},
+				SecurityGroupIds: []string{},
+			}
+		}
+		if fm.Spec.isZipPackage() {
+			configInput.Runtime = types.Runtime(fm.Spec.Runtime)
+			configInput.Handler = aws.String(fm.Spec.Handler)
+			// Always specify the layers so that the removed ones are detached from the function.
+			configInput.Layers = fm.Spec.Layers
+			if configInput.Layers == nil {
+				configInput.Layers = []string{}
+			}
 		}
-		_, err = c.client.UpdateFunctionConfiguration(ctx, configInput)
+		_, err = c.client.UpdateFunctionConfiguration(ctx, configInput, withAdditionalFields(configFields))
 		if err != nil {
 			c.logger.Error("Failed to update function configuration")
 		} else {
@@ -193,52 +320,122 @@ func (c *client) GetTrafficConfig(ctx context.Context, fm FunctionManifest) (rou
 		return
 	}
 
-	routingTrafficCfg = make(map[TrafficConfigKeyName]VersionTraffic)
-	/* The current return value from GetAlias as below
-	{
-		"AliasArn": "arn:aws:lambda:ap-northeast-1:769161735124:function:SimpleCanaryFunction:Service",
-		"Name": "Service",
-		"FunctionVersion": "1",
-		"Description": "",
-		"RoutingConfig": {
-			"AdditionalVersionWeights": {
-				"3": 0.9
-			}
-		},
-		"RevisionId": "fe08805f-9851-44fc-9a79-6e086aefc290"
+	routingTrafficCfg = makeRoutingTrafficConfig(cfg.FunctionVersion, cfg.RoutingConfig)
+	return
+}
+
+func (c *client) ListFunctions(ctx context.Context) ([]FunctionState, error) {
+	var (
+		out       []FunctionState
+		paginator = lambda.NewListFunctionsPaginator(c.client, &lambda.ListFunctionsInput{})
+	)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list Lambda functions: %w", err)
+		}
+		for i := range page.Functions {
+			out = append(out, makeFunctionState(&page.Functions[i]))
+		}
 	}
-	Note:
-	- In case RoutingConfig is nil, this mean 100% of traffic is handled by version represented by FunctionVersion value (PRIMARY version).
-	- In case RoutingConfig is not nil, RoutingConfig.AdditionalVersionWeights is expected to have ONLY ONE key/value pair
-	which presents the SECONDARY version handling traffic (represented by the value of the pair).
-		in short
-			_ version: 1 - FunctionVersion (the PRIMARY) handles (1 - 0.9) percentage of current traffic.
-			_ version: 3 - AdditionalVersionWeights key (the SECONDARY) handles 0.9 percentage of current traffic.
-	*/
-	// In case RoutingConfig is nil, 100 percent of current traffic is handled by FunctionVersion version.
-	if cfg.RoutingConfig == nil {
-		routingTrafficCfg[TrafficPrimaryVersionKeyName] = VersionTraffic{
-			Version: aws.ToString(cfg.FunctionVersion),
-			Percent: 100,
+	return out, nil
+}
+
+func (c *client) ListTags(ctx context.Context, functionARN string) (map[string]string, error) {
+	input := &lambda.ListTagsInput{
+		Resource: aws.String(functionARN),
+	}
+	output, err := c.client.ListTags(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags of Lambda function %s: %w", functionARN, err)
+	}
+	return output.Tags, nil
+}
+
+func (c *client) GetFunctionState(ctx context.Context, name, version string) (FunctionState, error) {
+	input := &lambda.GetFunctionConfigurationInput{
+		FunctionName: aws.String(name),
+		Qualifier:    optionalString(version),
+	}
+	output, err := c.client.GetFunctionConfiguration(ctx, input)
+	if err != nil {
+		var nfe *types.ResourceNotFoundException
+		if errors.As(err, &nfe) {
+			return FunctionState{}, ErrNotFound
 		}
-		return
+		return FunctionState{}, fmt.Errorf("failed to get configuration of Lambda function %s:%s: %w", name, version, err)
+	}
+	return makeFunctionState(&types.FunctionConfiguration{
+		FunctionName:           output.FunctionName,
+		FunctionArn:            output.FunctionArn,
+		Version:                output.Version,
+		Description:            output.Description,
+		Runtime:                output.Runtime,
+		MemorySize:             output.MemorySize,
+		Timeout:                output.Timeout,
+		CodeSha256:             output.CodeSha256,
+		State:                  output.State,
+		StateReason:            output.StateReason,
+		LastUpdateStatus:       output.LastUpdateStatus,
+		LastUpdateStatusReason: output.LastUpdateStatusReason,
+		LastModified:           output.LastModified,
+	}), nil
+}
+
+func (c *client) GetAlias(ctx context.Context, name string) (Alias, error) {
+	input := &lambda.GetAliasInput{
+		FunctionName: aws.String(name),
+		Name:         aws.String(defaultAliasName),
 	}
-	// In case RoutingConfig is provided, FunctionVersion value represents the primary version while
-	// RoutingConfig.AdditionalVersionWeights key represents the secondary version.
-	var secondaryVersionTraffic float64
-	for version, weight := range cfg.RoutingConfig.AdditionalVersionWeights {
-		secondaryVersionTraffic = percentageToPercent(weight)
-		routingTrafficCfg[TrafficSecondaryVersionKeyName] = VersionTraffic{
-			Version: version,
-			Percent: secondaryVersionTraffic,
+	output, err := c.client.GetAlias(ctx, input)
+	if err != nil {
+		var nfe *types.ResourceNotFoundException
+		if errors.As(err, &nfe) {
+			return Alias{}, ErrNotFound
 		}
+		return Alias{}, fmt.Errorf("failed to get alias of Lambda function %s: %w", name, err)
 	}
-	routingTrafficCfg[TrafficPrimaryVersionKeyName] = VersionTraffic{
-		Version: aws.ToString(cfg.FunctionVersion),
-		Percent: 100 - secondaryVersionTraffic,
+	return Alias{
+		Name:    aws.ToString(output.Name),
+		ARN:     aws.ToString(output.AliasArn),
+		Traffic: makeRoutingTrafficConfig(output.FunctionVersion, output.RoutingConfig),
+	}, nil
+}
+
+func (c *client) GetReservedConcurrency(ctx context.Context, name string) (int32, bool, error) {
+	input := &lambda.GetFunctionConcurrencyInput{
+		FunctionName: aws.String(name),
 	}
+	output, err := c.client.GetFunctionConcurrency(ctx, input)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get concurrency of Lambda function %s: %w", name, err)
+	}
+	if output.ReservedConcurrentExecutions == nil {
+		return 0, false, nil
+	}
+	return *output.ReservedConcurrentExecutions, true, nil
+}
 
-	return
+func (c *client) GetProvisionedConcurrency(ctx context.Context, name, qualifier string) (ProvisionedConcurrency, error) {
+	input := &lambda.GetProvisionedConcurrencyConfigInput{
+		FunctionName: aws.String(name),
+		Qualifier:    aws.String(qualifier),
+	}
+	output, err := c.client.GetProvisionedConcurrencyConfig(ctx, input)
+	if err != nil {
+		var nfe *types.ProvisionedConcurrencyConfigNotFoundException
+		if errors.As(err, &nfe) {
+			return ProvisionedConcurrency{}, ErrNotFound
+		}
+		return ProvisionedConcurrency{}, fmt.Errorf("failed to get provisioned concurrency of Lambda function %s:%s: %w", name, qualifier, err)
+	}
+	return ProvisionedConcurrency{
+		Requested:    aws.ToInt32(output.RequestedProvisionedConcurrentExecutions),
+		Available:    aws.ToInt32(output.AvailableProvisionedConcurrentExecutions),
+		Allocated:    aws.ToInt32(output.AllocatedProvisionedConcurrentExecutions),
+		Status:       string(output.Status),
+		StatusReason: aws.ToString(output.StatusReason),
+	}, nil
 }
 
 func (c *client) CreateTrafficConfig(ctx context.Context, fm FunctionManifest, version string) error {
@@ -281,6 +478,56 @@ func (c *client) UpdateTrafficConfig(ctx context.Context, fm FunctionManifest, r
 	return nil
 }
 
+// makeRoutingTrafficConfig builds the routing traffic config from the version and the routing config of an alias.
+func makeRoutingTrafficConfig(functionVersion *string, routingConfig *types.AliasRoutingConfiguration) RoutingTrafficConfig {
+	routingTrafficCfg := make(map[TrafficConfigKeyName]VersionTraffic)
+	/* The current return value from GetAlias as below
+	{
+		"AliasArn": "arn:aws:lambda:ap-northeast-1:769161735124:function:SimpleCanaryFunction:Service",
+		"Name": "Service",
+		"FunctionVersion": "1",
+		"Description": "",
+		"RoutingConfig": {
+			"AdditionalVersionWeights": {
+				"3": 0.9
+			}
+		},
+		"RevisionId": "fe08805f-9851-44fc-9a79-6e086aefc290"
+	}
+	Note:
+	- In case RoutingConfig is nil, this mean 100% of traffic is handled by version represented by FunctionVersion value (PRIMARY version).
+	- In case RoutingConfig is not nil, RoutingConfig.AdditionalVersionWeights is expected to have ONLY ONE key/value pair
+	which presents the SECONDARY version handling traffic (represented by the value of the pair).
+		in short
+			_ version: 1 - FunctionVersion (the PRIMARY) handles (1 - 0.9) percentage of current traffic.
+			_ version: 3 - AdditionalVersionWeights key (the SECONDARY) handles 0.9 percentage of current traffic.
+	*/
+	// In case RoutingConfig is nil, 100 percent of current traffic is handled by FunctionVersion version.
+	if routingConfig == nil {
+		routingTrafficCfg[TrafficPrimaryVersionKeyName] = VersionTraffic{
+			Version: aws.ToString(functionVersion),
+			Percent: 100,
+		}
+		return routingTrafficCfg
+	}
+	// In case RoutingConfig is provided, FunctionVersion value represents the primary version while
+	// RoutingConfig.AdditionalVersionWeights key represents the secondary version.
+	var secondaryVersionTraffic float64
+	for version, weight := range routingConfig.AdditionalVersionWeights {
+		secondaryVersionTraffic = percentageToPercent(weight)
+		routingTrafficCfg[TrafficSecondaryVersionKeyName] = VersionTraffic{
+			Version: version,
+			Percent: secondaryVersionTraffic,
+		}
+	}
+	routingTrafficCfg[TrafficPrimaryVersionKeyName] = VersionTraffic{
+		Version: aws.ToString(functionVersion),
+		Percent: 100 - secondaryVersionTraffic,
+	}
+
+	return routingTrafficCfg
+}
+
 func (c *client) updateTagsConfig(ctx context.Context, fm FunctionManifest) error {
 	getFuncInput := &lambda.GetFunctionInput{
 		FunctionName: aws.String(fm.Spec.Name),
@@ -376,6 +623,92 @@ func makeFlowControlTagsMaps(remoteTags, definedTags map[string]string) (newDefi
 	return
 }
 
+func makeVPCConfig(cfg *VPCConfig) *types.VpcConfig {
+	if cfg == nil {
+		return nil
+	}
+	return &types.VpcConfig{
+		SubnetIds:        cfg.SubnetIDs,
+		SecurityGroupIds: cfg.SecurityGroupIDs,
+	}
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return aws.String(s)
+}
+
+// withAdditionalFields adds the given fields into the JSON body of the request.
+// This is used to specify the fields which are supported by Lambda API
+// but not available in the version of AWS SDK we are using, such as Architectures and EphemeralStorage.
+func withAdditionalFields(fields map[string]interface{}) func(*lambda.Options) {
+	return func(o *lambda.Options) {
+		if len(fields) == 0 {
+			return
+		}
+		o.APIOptions = append(o.APIOptions, func(stack *middleware.Stack) error {
+			return stack.Serialize.Add(middleware.SerializeMiddlewareFunc("PipeCDAdditionalFields", func(
+				ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler,
+			) (middleware.SerializeOutput, middleware.Metadata, error) {
+				req, ok := in.Request.(*smithyhttp.Request)
+				if !ok {
+					return middleware.SerializeOutput{}, middleware.Metadata{}, fmt.Errorf("unexpected request type %T", in.Request)
+				}
+				body := make(map[string]interface{})
+				if stream := req.GetStream(); stream != nil {
+					if err := json.NewDecoder(stream).Decode(&body); err != nil && err != io.EOF {
+						return middleware.SerializeOutput{}, middleware.Metadata{}, fmt.Errorf("failed to decode request body: %w", err)
+					}
+				}
+				for k, v := range fields {
+					body[k] = v
+				}
+				data, err := json.Marshal(body)
+				if err != nil {
+					return middleware.SerializeOutput{}, middleware.Metadata{}, err
+				}
+				if in.Request, err = req.SetStream(bytes.NewReader(data)); err != nil {
+					return middleware.SerializeOutput{}, middleware.Metadata{}, err
+				}
+				return next.HandleSerialize(ctx, in)
+			}), middleware.After)
+		})
+	}
+}
+
+// withResponseBody copies the JSON body of the response into the given byte slice.
+// This is used to read the fields which are returned by Lambda API
+// but not available in the version of AWS SDK we are using, such as Architectures and EphemeralStorage.
+func withResponseBody(body *[]byte) func(*lambda.Options) {
+	return func(o *lambda.Options) {
+		o.APIOptions = append(o.APIOptions, func(stack *middleware.Stack) error {
+			return stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc("PipeCDResponseBody", func(
+				ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+			) (middleware.DeserializeOutput, middleware.Metadata, error) {
+				out, metadata, err := next.HandleDeserialize(ctx, in)
+				if err != nil {
+					return out, metadata, err
+				}
+				resp, ok := out.RawResponse.(*smithyhttp.Response)
+				if !ok {
+					return out, metadata, fmt.Errorf("unexpected response type %T", out.RawResponse)
+				}
+				data, err := io.ReadAll(resp.Body)
+				resp.Body.Close()
+				if err != nil {
+					return out, metadata, fmt.Errorf("failed to read response body: %w", err)
+				}
+				*body = data
+				// Restore the body to be deserialized by AWS SDK.
+				resp.Body = io.NopCloser(bytes.NewReader(data))
+				return out, metadata, nil
+			}), middleware.After)
+		})
+	}
+}
+
 func precentToPercentage(in float64) float64 {
 	return in / 100.0
 }
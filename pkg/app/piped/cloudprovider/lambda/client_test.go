@@ -15,9 +15,19 @@
 package lambda
 
 import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 )
 
 func TestMakeFlowControlTagsMap(t *testing.T) {
@@ -129,3 +139,72 @@ func TestMakeFlowControlTagsMap(t *testing.T) {
 		})
 	}
 }
+
+func TestMakeRoutingTrafficConfig(t *testing.T) {
+	got := makeRoutingTrafficConfig(aws.String("1"), nil)
+	assert.Equal(t, RoutingTrafficConfig{
+		TrafficPrimaryVersionKeyName: {Version: "1", Percent: 100},
+	}, got)
+
+	got = makeRoutingTrafficConfig(aws.String("1"), &types.AliasRoutingConfiguration{
+		AdditionalVersionWeights: map[string]float64{"3": 0.25},
+	})
+	assert.Equal(t, RoutingTrafficConfig{
+		TrafficPrimaryVersionKeyName:   {Version: "1", Percent: 75},
+		TrafficSecondaryVersionKeyName: {Version: "3", Percent: 25},
+	}, got)
+}
+
+func TestUnsupportedFields(t *testing.T) {
+	bodies := make(map[string]map[string]interface{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies[r.Method+" "+r.URL.Path] = body
+		if r.Method == http.MethodGet {
+			io.WriteString(w, `{
+  "Configuration": {
+    "FunctionName": "SimpleFunction",
+    "FunctionArn": "arn:aws:lambda:us-west-2:123456789012:function:SimpleFunction",
+    "Architectures": ["arm64"],
+    "EphemeralStorage": {"Size": 1024},
+    "VpcConfig": {"SubnetIds": [], "SecurityGroupIds": []}
+  }
+}`)
+			return
+		}
+		io.WriteString(w, `{}`)
+	}))
+	defer srv.Close()
+
+	c := &client{
+		client: lambda.New(lambda.Options{
+			Region:           "us-west-2",
+			EndpointResolver: lambda.EndpointResolverFromURL(srv.URL),
+			Credentials:      aws.AnonymousCredentials{},
+		}),
+		logger: zap.NewNop(),
+	}
+	ctx := context.Background()
+
+	fm, err := c.GetFunction(ctx, "SimpleFunction")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"arm64"}, fm.Spec.Architectures)
+	assert.Equal(t, &EphemeralStorage{Size: 1024}, fm.Spec.EphemeralStorage)
+	assert.Nil(t, fm.Spec.VPCConfig)
+
+	// The removed settings are reverted to the defaults.
+	fm.Spec.Architectures = nil
+	fm.Spec.EphemeralStorage = nil
+	fm.Spec.ImageURI = "ecr.region.amazonaws.com/lambda-simple-function:v0.0.1"
+	err = c.UpdateFunction(ctx, fm)
+	require.NoError(t, err)
+
+	code := bodies["PUT /2015-03-31/functions/SimpleFunction/code"]
+	assert.Equal(t, []interface{}{"x86_64"}, code["Architectures"])
+	assert.Equal(t, "ecr.region.amazonaws.com/lambda-simple-function:v0.0.1", code["ImageUri"])
+
+	cfg := bodies["PUT /2015-03-31/functions/SimpleFunction/configuration"]
+	assert.Equal(t, map[string]interface{}{"Size": float64(512)}, cfg["EphemeralStorage"])
+	assert.Equal(t, map[string]interface{}{"SubnetIds": []interface{}{}, "SecurityGroupIds": []interface{}{}}, cfg["VpcConfig"])
+}
@@ -0,0 +1,135 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambda
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Diff compares the function manifest defined in Git with the one built from the live state,
+// and returns the list of human-readable differences between them.
+// The code of the zip package stored in S3 is not compared because it can not be determined from the live state.
+func Diff(expected, live FunctionManifest) []string {
+	var (
+		e   = expected.Spec
+		l   = live.Spec
+		out []string
+	)
+	add := func(field string, expected, actual interface{}) {
+		out = append(out, fmt.Sprintf("%s: expected %v but got %v", field, expected, actual))
+	}
+
+	if e.Role != l.Role {
+		add("role", e.Role, l.Role)
+	}
+	if e.Memory != l.Memory {
+		add("memory", e.Memory, l.Memory)
+	}
+	if e.Timeout != l.Timeout {
+		add("timeout", e.Timeout, l.Timeout)
+	}
+	if e.ImageURI != l.ImageURI {
+		add("image", e.ImageURI, l.ImageURI)
+	}
+	if e.isZipPackage() {
+		if e.Runtime != l.Runtime {
+			add("runtime", e.Runtime, l.Runtime)
+		}
+		if e.Handler != l.Handler {
+			add("handler", e.Handler, l.Handler)
+		}
+		if !equalStrings(e.Layers, l.Layers) {
+			add("layers", e.Layers, l.Layers)
+		}
+	}
+	if expected.zipFile != nil && live.codeSHA256 != "" {
+		if sha := computeCodeSHA256(expected.zipFile); sha != live.codeSHA256 {
+			add("source code sha256", sha, live.codeSHA256)
+		}
+	}
+	// The live architectures and ephemeral storage are empty when they were not returned by Lambda API.
+	if len(l.Architectures) > 0 && !equalStrings(e.architectures(), l.Architectures) {
+		add("architectures", e.architectures(), l.Architectures)
+	}
+	if l.EphemeralStorage != nil && e.ephemeralStorageSize() != l.EphemeralStorage.Size {
+		add("ephemeralStorage.size", e.ephemeralStorageSize(), l.EphemeralStorage.Size)
+	}
+	var (
+		expectedSubnets, expectedSecurityGroups []string
+		liveSubnets, liveSecurityGroups         []string
+	)
+	if e.VPCConfig != nil {
+		expectedSubnets, expectedSecurityGroups = e.VPCConfig.SubnetIDs, e.VPCConfig.SecurityGroupIDs
+	}
+	if l.VPCConfig != nil {
+		liveSubnets, liveSecurityGroups = l.VPCConfig.SubnetIDs, l.VPCConfig.SecurityGroupIDs
+	}
+	if !equalStringSets(expectedSubnets, liveSubnets) {
+		add("vpcConfig.subnetIds", expectedSubnets, liveSubnets)
+	}
+	if !equalStringSets(expectedSecurityGroups, liveSecurityGroups) {
+		add("vpcConfig.securityGroupIds", expectedSecurityGroups, liveSecurityGroups)
+	}
+	for _, k := range diffMapKeys(e.Environments, l.Environments) {
+		// Do not show the values since they may contain secrets.
+		out = append(out, fmt.Sprintf("environments.%s: value was changed", k))
+	}
+	for _, k := range diffMapKeys(e.Tags, l.Tags) {
+		// The tags given by piped are not defined in Git.
+		if isBuiltinTag(k) {
+			continue
+		}
+		out = append(out, fmt.Sprintf("tags.%s: expected %q but got %q", k, e.Tags[k], l.Tags[k]))
+	}
+	return out
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func equalStringSets(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]string(nil), a...)
+	b = append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	return strings.Join(a, ",") == strings.Join(b, ",")
+}
+
+// diffMapKeys returns the sorted keys whose values are different between the given maps.
+func diffMapKeys(a, b map[string]string) []string {
+	keys := make([]string, 0)
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || bv != v {
+			keys = append(keys, k)
+		}
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,136 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambda
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	spec := FunctionManifestSpec{
+		Name:         "SimpleFunction",
+		Role:         "arn:aws:iam::xxxxx:role/lambda-role",
+		Memory:       128,
+		Timeout:      5,
+		S3Bucket:     "functions",
+		S3Key:        "simple.zip",
+		Runtime:      "python3.8",
+		Handler:      "app.handler",
+		Layers:       []string{"layer-1"},
+		VPCConfig:    &VPCConfig{SubnetIDs: []string{"subnet-1", "subnet-2"}},
+		Environments: map[string]string{"FOO": "foo"},
+		Tags:         map[string]string{"team": "a"},
+	}
+	testcases := []struct {
+		name     string
+		live     func(s *FunctionManifestSpec)
+		expected []string
+	}{
+		{
+			name: "no diff",
+			live: func(s *FunctionManifestSpec) {
+				s.VPCConfig = &VPCConfig{SubnetIDs: []string{"subnet-2", "subnet-1"}}
+			},
+		},
+		{
+			name: "tags given by piped are ignored",
+			live: func(s *FunctionManifestSpec) {
+				s.Tags = MakeTags(s.Tags, "piped-id", "app-id", "commit-hash")
+			},
+		},
+		{
+			name: "default architectures and ephemeral storage",
+			live: func(s *FunctionManifestSpec) {
+				s.VPCConfig = &VPCConfig{SubnetIDs: []string{"subnet-2", "subnet-1"}}
+				s.Architectures = []string{"x86_64"}
+				s.EphemeralStorage = &EphemeralStorage{Size: 512}
+			},
+		},
+		{
+			name: "changed architectures, ephemeral storage and vpc config",
+			live: func(s *FunctionManifestSpec) {
+				s.VPCConfig = &VPCConfig{
+					SubnetIDs:        []string{"subnet-1", "subnet-2"},
+					SecurityGroupIDs: []string{"sg-1"},
+				}
+				s.Architectures = []string{"arm64"}
+				s.EphemeralStorage = &EphemeralStorage{Size: 1024}
+			},
+			expected: []string{
+				"architectures: expected [x86_64] but got [arm64]",
+				"ephemeralStorage.size: expected 512 but got 1024",
+				"vpcConfig.securityGroupIds: expected [] but got [sg-1]",
+			},
+		},
+		{
+			name: "changed fields",
+			live: func(s *FunctionManifestSpec) {
+				s.Memory = 256
+				s.Handler = "main.handler"
+				s.Layers = nil
+				s.VPCConfig = nil
+				s.Environments = map[string]string{"FOO": "bar"}
+				s.Tags = map[string]string{"team": "a", "owner": "b"}
+			},
+			expected: []string{
+				"memory: expected 128 but got 256",
+				"handler: expected app.handler but got main.handler",
+				"layers: expected [layer-1] but got []",
+				"vpcConfig.subnetIds: expected [subnet-1 subnet-2] but got []",
+				"environments.FOO: value was changed",
+				`tags.owner: expected "" but got "b"`,
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			live := spec
+			live.S3Bucket, live.S3Key = "", ""
+			tc.live(&live)
+			got := Diff(FunctionManifest{Spec: spec}, FunctionManifest{Spec: live})
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestDiffSourceCode(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lambda-source")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "app.py"), []byte("def handler(event, context): pass"), 0644))
+
+	data, err := zipDirectory(dir)
+	require.NoError(t, err)
+
+	// The same package must be created from the same files.
+	again, err := zipDirectory(dir)
+	require.NoError(t, err)
+	assert.Equal(t, data, again)
+
+	spec := FunctionManifestSpec{Source: "src", Runtime: "python3.8", Handler: "app.handler"}
+	expected := FunctionManifest{Spec: spec, zipFile: data}
+
+	got := Diff(expected, FunctionManifest{Spec: spec, codeSHA256: computeCodeSHA256(data)})
+	assert.Empty(t, got)
+
+	got = Diff(expected, FunctionManifest{Spec: spec, codeSHA256: "changed"})
+	assert.Equal(t, 1, len(got))
+}
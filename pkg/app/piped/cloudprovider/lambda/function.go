@@ -17,6 +17,7 @@ package lambda
 import (
 	"fmt"
 	"io/ioutil"
+	"path"
 	"strings"
 
 	"sigs.k8s.io/yaml"
@@ -30,12 +31,30 @@ const (
 	memoryLowerLimit  = 1
 	timeoutLowerLimit = 1
 	timeoutUpperLimit = 900
+	// Ephemeral storage limits in MB as noted via
+	// https://docs.aws.amazon.com/lambda/latest/dg/configuration-function-common.html#configuration-ephemeral-storage
+	ephemeralStorageLowerLimit = 512
+	ephemeralStorageUpperLimit = 10240
 )
 
+// The architecture used when no architecture was specified.
+const defaultArchitecture = "x86_64"
+
+var supportedArchitectures = map[string]struct{}{
+	"x86_64": {},
+	"arm64":  {},
+}
+
 type FunctionManifest struct {
 	Kind       string               `json:"kind"`
 	APIVersion string               `json:"apiVersion,omitempty"`
 	Spec       FunctionManifestSpec `json:"spec"`
+
+	// The zip package built from the source directory.
+	zipFile []byte
+	// The base64-encoded SHA256 hash of the function code.
+	// This is only set for the manifest built from the live state.
+	codeSHA256 string
 }
 
 func (fm *FunctionManifest) validate() error {
@@ -52,22 +71,68 @@ func (fm *FunctionManifest) validate() error {
 }
 
 // FunctionManifestSpec contains configuration for LambdaFunction.
+// The function code must be given by exactly one of the following ways:
+// a container image, a zip package stored in S3,
+// or a directory in Git that will be zipped by piped.
 type FunctionManifestSpec struct {
-	Name         string            `json:"name"`
-	Role         string            `json:"role"`
-	ImageURI     string            `json:"image"`
-	Memory       int32             `json:"memory"`
-	Timeout      int32             `json:"timeout"`
-	Tags         map[string]string `json:"tags,omitempty"`
-	Environments map[string]string `json:"environments,omitempty"`
+	Name     string `json:"name"`
+	Role     string `json:"role"`
+	ImageURI string `json:"image,omitempty"`
+	// The S3 location of the zip package.
+	S3Bucket        string `json:"s3Bucket,omitempty"`
+	S3Key           string `json:"s3Key,omitempty"`
+	S3ObjectVersion string `json:"s3ObjectVersion,omitempty"`
+	// The path to the directory containing the function code.
+	// It is relative to the application directory.
+	Source string `json:"source,omitempty"`
+	// The runtime and handler are required for zip packages.
+	Runtime          string            `json:"runtime,omitempty"`
+	Handler          string            `json:"handler,omitempty"`
+	Layers           []string          `json:"layers,omitempty"`
+	Architectures    []string          `json:"architectures,omitempty"`
+	VPCConfig        *VPCConfig        `json:"vpcConfig,omitempty"`
+	EphemeralStorage *EphemeralStorage `json:"ephemeralStorage,omitempty"`
+	Memory           int32             `json:"memory"`
+	Timeout          int32             `json:"timeout"`
+	Tags             map[string]string `json:"tags,omitempty"`
+	Environments     map[string]string `json:"environments,omitempty"`
+}
+
+// VPCConfig contains the VPC settings to connect the function to.
+type VPCConfig struct {
+	SubnetIDs        []string `json:"subnetIds,omitempty"`
+	SecurityGroupIDs []string `json:"securityGroupIds,omitempty"`
+}
+
+// EphemeralStorage contains the size of the /tmp directory in MB.
+type EphemeralStorage struct {
+	Size int32 `json:"size"`
+}
+
+// architectures returns the instruction set architectures of the function
+// with the default value applied.
+func (fmp FunctionManifestSpec) architectures() []string {
+	if len(fmp.Architectures) == 0 {
+		return []string{defaultArchitecture}
+	}
+	return fmp.Architectures
+}
+
+// ephemeralStorageSize returns the size of the /tmp directory in MB
+// with the default value applied.
+func (fmp FunctionManifestSpec) ephemeralStorageSize() int32 {
+	if fmp.EphemeralStorage == nil {
+		return ephemeralStorageLowerLimit
+	}
+	return fmp.EphemeralStorage.Size
 }
 
 func (fmp FunctionManifestSpec) validate() error {
 	if len(fmp.Name) == 0 {
 		return fmt.Errorf("lambda function is missing")
 	}
-	if len(fmp.ImageURI) == 0 {
-		return fmt.Errorf("image uri is missing")
+	if err := fmp.validateCode(); err != nil {
+		return err
 	}
 	if len(fmp.Role) == 0 {
 		return fmt.Errorf("role is missing")
@@ -78,9 +143,58 @@ func (fmp FunctionManifestSpec) validate() error {
 	if fmp.Timeout < timeoutLowerLimit || fmp.Timeout > timeoutUpperLimit {
 		return fmt.Errorf("timeout is missing or out of range")
 	}
+	if len(fmp.Architectures) > 1 {
+		return fmt.Errorf("only one architecture can be specified")
+	}
+	for _, a := range fmp.Architectures {
+		if _, ok := supportedArchitectures[a]; !ok {
+			return fmt.Errorf("unsupported architecture %q", a)
+		}
+	}
+	if s := fmp.EphemeralStorage; s != nil && (s.Size < ephemeralStorageLowerLimit || s.Size > ephemeralStorageUpperLimit) {
+		return fmt.Errorf("ephemeral storage size must be between %d and %d", ephemeralStorageLowerLimit, ephemeralStorageUpperLimit)
+	}
+	return nil
+}
+
+func (fmp FunctionManifestSpec) validateCode() error {
+	var (
+		hasImage  = fmp.ImageURI != ""
+		hasS3     = fmp.S3Bucket != "" || fmp.S3Key != "" || fmp.S3ObjectVersion != ""
+		hasSource = fmp.Source != ""
+		count     int
+	)
+	for _, has := range []bool{hasImage, hasS3, hasSource} {
+		if has {
+			count++
+		}
+	}
+	if count != 1 {
+		return fmt.Errorf("exactly one of image, s3Bucket/s3Key or source must be specified")
+	}
+
+	if hasImage {
+		if fmp.Runtime != "" || fmp.Handler != "" || len(fmp.Layers) > 0 {
+			return fmt.Errorf("runtime, handler and layers can not be used for container image function")
+		}
+		return nil
+	}
+	if hasS3 && (fmp.S3Bucket == "" || fmp.S3Key == "") {
+		return fmt.Errorf("both s3Bucket and s3Key are required for zip package stored in S3")
+	}
+	if fmp.Runtime == "" {
+		return fmt.Errorf("runtime is required for zip package")
+	}
+	if fmp.Handler == "" {
+		return fmt.Errorf("handler is required for zip package")
+	}
 	return nil
 }
 
+func (fmp FunctionManifestSpec) isZipPackage() bool {
+	return fmp.ImageURI == ""
+}
+
 func loadFunctionManifest(path string) (FunctionManifest, error) {
 	data, err := ioutil.ReadFile(path)
 	if err != nil {
@@ -114,6 +228,21 @@ func DecideRevisionName(fm FunctionManifest, commit string) (string, error) {
 	return fmt.Sprintf("%s-%s-%s", fm.Spec.Name, tag, commit), nil
 }
 
+// FindArtifactVersion returns the version of the function code defined in the given manifest.
+// That is the image tag for container image, or the object version (or key) for zip package stored in S3.
+func FindArtifactVersion(fm FunctionManifest) (string, error) {
+	switch {
+	case fm.Spec.ImageURI != "":
+		return FindImageTag(fm)
+	case fm.Spec.S3ObjectVersion != "":
+		return fm.Spec.S3ObjectVersion, nil
+	case fm.Spec.S3Key != "":
+		return path.Base(fm.Spec.S3Key), nil
+	default:
+		return "", fmt.Errorf("unable to determine the version of function code built from source directory")
+	}
+}
+
 // FindImageTag parses image tag from given LambdaFunction manifest.
 func FindImageTag(fm FunctionManifest) (string, error) {
 	name, tag := parseContainerImage(fm.Spec.ImageURI)
@@ -20,7 +20,7 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func TestparseFunctionManifest(t *testing.T) {
+func TestParseFunctionManifest(t *testing.T) {
 	testcases := []struct {
 		name     string
 		data     string
@@ -90,6 +90,100 @@ func TestparseFunctionManifest(t *testing.T) {
 	  "timeout": 1000,
 	  "image": "ecr.region.amazonaws.com/lambda-simple-function:v0.0.1"
   }
+}`,
+			wantSpec: FunctionManifest{},
+			wantErr:  true,
+		},
+		{
+			name: "zip package stored in S3 with layers",
+			data: `{
+  "apiVersion": "pipecd.dev/v1beta1",
+  "kind": "LambdaFunction",
+  "spec": {
+	  "name": "SimpleFunction",
+	  "role": "arn:aws:iam::xxxxx:role/lambda-role",
+	  "memory": 128,
+	  "timeout": 5,
+	  "s3Bucket": "functions",
+	  "s3Key": "simple/v0.0.1.zip",
+	  "runtime": "python3.8",
+	  "handler": "app.handler",
+	  "layers": ["arn:aws:lambda:region:xxxxx:layer:common:3"],
+	  "architectures": ["arm64"],
+	  "vpcConfig": {"subnetIds": ["subnet-1"], "securityGroupIds": ["sg-1"]},
+	  "ephemeralStorage": {"size": 1024}
+  }
+}`,
+			wantSpec: FunctionManifest{
+				Kind:       "LambdaFunction",
+				APIVersion: "pipecd.dev/v1beta1",
+				Spec: FunctionManifestSpec{
+					Name:          "SimpleFunction",
+					Role:          "arn:aws:iam::xxxxx:role/lambda-role",
+					Memory:        128,
+					Timeout:       5,
+					S3Bucket:      "functions",
+					S3Key:         "simple/v0.0.1.zip",
+					Runtime:       "python3.8",
+					Handler:       "app.handler",
+					Layers:        []string{"arn:aws:lambda:region:xxxxx:layer:common:3"},
+					Architectures: []string{"arm64"},
+					VPCConfig: &VPCConfig{
+						SubnetIDs:        []string{"subnet-1"},
+						SecurityGroupIDs: []string{"sg-1"},
+					},
+					EphemeralStorage: &EphemeralStorage{Size: 1024},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "both image and source are specified",
+			data: `{
+  "apiVersion": "pipecd.dev/v1beta1",
+  "kind": "LambdaFunction",
+  "spec": {
+	  "name": "SimpleFunction",
+	  "role": "arn:aws:iam::xxxxx:role/lambda-role",
+	  "memory": 128,
+	  "timeout": 5,
+	  "image": "ecr.region.amazonaws.com/lambda-simple-function:v0.0.1",
+	  "source": "src"
+  }
+}`,
+			wantSpec: FunctionManifest{},
+			wantErr:  true,
+		},
+		{
+			name: "missing handler for zip package",
+			data: `{
+  "apiVersion": "pipecd.dev/v1beta1",
+  "kind": "LambdaFunction",
+  "spec": {
+	  "name": "SimpleFunction",
+	  "role": "arn:aws:iam::xxxxx:role/lambda-role",
+	  "memory": 128,
+	  "timeout": 5,
+	  "source": "src",
+	  "runtime": "go1.x"
+  }
+}`,
+			wantSpec: FunctionManifest{},
+			wantErr:  true,
+		},
+		{
+			name: "unsupported architecture",
+			data: `{
+  "apiVersion": "pipecd.dev/v1beta1",
+  "kind": "LambdaFunction",
+  "spec": {
+	  "name": "SimpleFunction",
+	  "role": "arn:aws:iam::xxxxx:role/lambda-role",
+	  "memory": 128,
+	  "timeout": 5,
+	  "image": "ecr.region.amazonaws.com/lambda-simple-function:v0.0.1",
+	  "architectures": ["i386"]
+  }
 }`,
 			wantSpec: FunctionManifest{},
 			wantErr:  true,
@@ -103,3 +197,40 @@ func TestparseFunctionManifest(t *testing.T) {
 		})
 	}
 }
+
+func TestFindArtifactVersion(t *testing.T) {
+	testcases := []struct {
+		name     string
+		spec     FunctionManifestSpec
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "container image",
+			spec:     FunctionManifestSpec{ImageURI: "ecr.region.amazonaws.com/lambda-simple-function:v0.0.1"},
+			expected: "v0.0.1",
+		},
+		{
+			name:     "zip package with object version",
+			spec:     FunctionManifestSpec{S3Bucket: "functions", S3Key: "simple.zip", S3ObjectVersion: "xyz"},
+			expected: "xyz",
+		},
+		{
+			name:     "zip package without object version",
+			spec:     FunctionManifestSpec{S3Bucket: "functions", S3Key: "simple/v0.0.1.zip"},
+			expected: "v0.0.1.zip",
+		},
+		{
+			name:    "source directory",
+			spec:    FunctionManifestSpec{Source: "src"},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			version, err := FindArtifactVersion(FunctionManifest{Spec: tc.spec})
+			assert.Equal(t, tc.wantErr, err != nil)
+			assert.Equal(t, tc.expected, version)
+		})
+	}
+}
@@ -28,12 +28,31 @@ import (
 // Client is wrapper of AWS client.
 type Client interface {
 	IsFunctionExist(ctx context.Context, name string) (bool, error)
+	// GetFunction returns the manifest built from the live state of the function.
+	// ErrNotFound is returned when the function does not exist.
+	GetFunction(ctx context.Context, name string) (FunctionManifest, error)
 	CreateFunction(ctx context.Context, fm FunctionManifest) error
 	UpdateFunction(ctx context.Context, fm FunctionManifest) error
 	PublishFunction(ctx context.Context, fm FunctionManifest) (version string, err error)
 	GetTrafficConfig(ctx context.Context, fm FunctionManifest) (routingTrafficCfg RoutingTrafficConfig, err error)
 	CreateTrafficConfig(ctx context.Context, fm FunctionManifest, version string) error
 	UpdateTrafficConfig(ctx context.Context, fm FunctionManifest, routingTraffic RoutingTrafficConfig) error
+	// ListFunctions returns the states of the unpublished version of all functions.
+	ListFunctions(ctx context.Context) ([]FunctionState, error)
+	// ListTags returns the tags of the given function.
+	ListTags(ctx context.Context, functionARN string) (map[string]string, error)
+	// GetFunctionState returns the state of the given version of the function.
+	// ErrNotFound is returned when the function or the version does not exist.
+	GetFunctionState(ctx context.Context, name, version string) (FunctionState, error)
+	// GetAlias returns the alias used by piped to route the traffic to the function.
+	// ErrNotFound is returned when the alias does not exist.
+	GetAlias(ctx context.Context, name string) (Alias, error)
+	// GetReservedConcurrency returns the number of the concurrent executions reserved for the function.
+	// False is returned when no concurrency has been reserved.
+	GetReservedConcurrency(ctx context.Context, name string) (int32, bool, error)
+	// GetProvisionedConcurrency returns the provisioned concurrency configured for the given qualifier.
+	// ErrNotFound is returned when no provisioned concurrency has been configured.
+	GetProvisionedConcurrency(ctx context.Context, name, qualifier string) (ProvisionedConcurrency, error)
 }
 
 // Registry holds a pool of aws client wrappers.
@@ -42,9 +61,21 @@ type Registry interface {
 }
 
 // LoadFunctionManifest returns FunctionManifest object from a given Function config manifest file.
+// When the source directory is specified, its files are also zipped into the package to be deployed.
 func LoadFunctionManifest(appDir, functionManifestFilename string) (FunctionManifest, error) {
 	path := filepath.Join(appDir, functionManifestFilename)
-	return loadFunctionManifest(path)
+	fm, err := loadFunctionManifest(path)
+	if err != nil {
+		return FunctionManifest{}, err
+	}
+	if fm.Spec.Source != "" {
+		data, err := zipDirectory(filepath.Join(appDir, fm.Spec.Source))
+		if err != nil {
+			return FunctionManifest{}, err
+		}
+		fm.zipFile = data
+	}
+	return fm, nil
 }
 
 type registry struct {
@@ -0,0 +1,116 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambda
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+const (
+	// The keys of the tags given to the functions deployed by piped.
+	TagManagedBy   = "pipecd-dev-managed-by"
+	TagPiped       = "pipecd-dev-piped"
+	TagApplication = "pipecd-dev-application"
+	TagCommitHash  = "pipecd-dev-commit-hash"
+	ManagedByPiped = "piped"
+
+	// The values of the state and the last update status of a function.
+	// https://docs.aws.amazon.com/lambda/latest/dg/functions-states.html
+	FunctionStateActive           = string(types.StateActive)
+	FunctionLastUpdateSuccessful  = string(types.LastUpdateStatusSuccessful)
+	ProvisionedConcurrencyReady   = string(types.ProvisionedConcurrencyStatusEnumReady)
+	functionLastModifiedTimestamp = "2006-01-02T15:04:05.000-0700"
+)
+
+// FunctionState represents the live state of a function or one of its published versions.
+type FunctionState struct {
+	Name        string
+	ARN         string
+	Version     string
+	Description string
+	Runtime     string
+	Memory      int32
+	Timeout     int32
+	CodeSHA256  string
+	// The state of the function, e.g. "Pending", "Active", "Inactive" or "Failed".
+	State       string
+	StateReason string
+	// The status of the last update performed on the function, e.g. "Successful", "Failed" or "InProgress".
+	LastUpdateStatus       string
+	LastUpdateStatusReason string
+	LastModified           time.Time
+}
+
+// Alias represents the alias used by piped to route the traffic to the versions of a function.
+type Alias struct {
+	Name    string
+	ARN     string
+	Traffic RoutingTrafficConfig
+}
+
+// ProvisionedConcurrency represents the provisioned concurrency configured for an alias or a version.
+type ProvisionedConcurrency struct {
+	Requested int32
+	Available int32
+	Allocated int32
+	// The status of the allocation, e.g. "IN_PROGRESS", "READY" or "FAILED".
+	Status       string
+	StatusReason string
+}
+
+// MakeTags returns a copy of the given tags with the ones used by PipeCD to identify the function.
+func MakeTags(tags map[string]string, pipedID, appID, commitHash string) map[string]string {
+	out := make(map[string]string, len(tags)+4)
+	for k, v := range tags {
+		out[k] = v
+	}
+	out[TagManagedBy] = ManagedByPiped
+	out[TagPiped] = pipedID
+	out[TagApplication] = appID
+	out[TagCommitHash] = commitHash
+	return out
+}
+
+func isBuiltinTag(key string) bool {
+	switch key {
+	case TagManagedBy, TagPiped, TagApplication, TagCommitHash:
+		return true
+	}
+	return false
+}
+
+func makeFunctionState(cfg *types.FunctionConfiguration) FunctionState {
+	state := FunctionState{
+		Name:                   aws.ToString(cfg.FunctionName),
+		ARN:                    aws.ToString(cfg.FunctionArn),
+		Version:                aws.ToString(cfg.Version),
+		Description:            aws.ToString(cfg.Description),
+		Runtime:                string(cfg.Runtime),
+		Memory:                 aws.ToInt32(cfg.MemorySize),
+		Timeout:                aws.ToInt32(cfg.Timeout),
+		CodeSHA256:             aws.ToString(cfg.CodeSha256),
+		State:                  string(cfg.State),
+		StateReason:            aws.ToString(cfg.StateReason),
+		LastUpdateStatus:       string(cfg.LastUpdateStatus),
+		LastUpdateStatusReason: aws.ToString(cfg.LastUpdateStatusReason),
+	}
+	if t, err := time.Parse(functionLastModifiedTimestamp, aws.ToString(cfg.LastModified)); err == nil {
+		state.LastModified = t
+	}
+	return state
+}
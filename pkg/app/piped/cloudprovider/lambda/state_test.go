@@ -0,0 +1,66 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambda
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMakeFunctionState(t *testing.T) {
+	got := makeFunctionState(&types.FunctionConfiguration{
+		FunctionName:     aws.String("SimpleFunction"),
+		FunctionArn:      aws.String("arn:aws:lambda:ap-northeast-1:123456789012:function:SimpleFunction:3"),
+		Version:          aws.String("3"),
+		Runtime:          types.RuntimePython38,
+		MemorySize:       aws.Int32(128),
+		Timeout:          aws.Int32(5),
+		State:            types.StateActive,
+		LastUpdateStatus: types.LastUpdateStatusSuccessful,
+		LastModified:     aws.String("2021-07-01T04:43:34.000+0000"),
+	})
+	expected := FunctionState{
+		Name:             "SimpleFunction",
+		ARN:              "arn:aws:lambda:ap-northeast-1:123456789012:function:SimpleFunction:3",
+		Version:          "3",
+		Runtime:          "python3.8",
+		Memory:           128,
+		Timeout:          5,
+		State:            FunctionStateActive,
+		LastUpdateStatus: FunctionLastUpdateSuccessful,
+		LastModified:     time.Date(2021, 7, 1, 4, 43, 34, 0, time.UTC),
+	}
+	assert.True(t, expected.LastModified.Equal(got.LastModified))
+	got.LastModified = expected.LastModified
+	assert.Equal(t, expected, got)
+}
+
+func TestMakeTags(t *testing.T) {
+	tags := map[string]string{"team": "a"}
+	got := MakeTags(tags, "piped-id", "app-id", "commit-hash")
+	expected := map[string]string{
+		"team":         "a",
+		TagManagedBy:   ManagedByPiped,
+		TagPiped:       "piped-id",
+		TagApplication: "app-id",
+		TagCommitHash:  "commit-hash",
+	}
+	assert.Equal(t, expected, got)
+	assert.Len(t, tags, 1)
+}
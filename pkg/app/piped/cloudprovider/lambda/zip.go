@@ -0,0 +1,95 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambda
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// zipModifiedTime is used as the modified time of all files in the zip package
+// to make the package, and also its hash, depend only on the file contents.
+var zipModifiedTime = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// zipDirectory creates a zip package containing all files inside the given directory.
+// The same package is always created from the same files
+// so that its hash can be compared with the one of the deployed function code.
+func zipDirectory(dir string) ([]byte, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	var (
+		buf = &bytes.Buffer{}
+		w   = zip.NewWriter(buf)
+	)
+	// filepath.Walk walks the files in lexical order.
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		header := &zip.FileHeader{
+			Name:     filepath.ToSlash(rel),
+			Method:   zip.Deflate,
+			Modified: zipModifiedTime,
+		}
+		// Keep the permission bits since the executable files must be kept executable.
+		header.SetMode(info.Mode().Perm())
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		fw, err := w.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(fw, f)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to zip directory %s: %w", dir, err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// computeCodeSHA256 returns the hash of the given zip package in the same format as Lambda reports.
+func computeCodeSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
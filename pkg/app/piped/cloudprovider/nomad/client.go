@@ -0,0 +1,240 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nomad
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/pipe-cd/pipe/pkg/config"
+)
+
+const (
+	defaultAddress = "http://127.0.0.1:4646"
+	requestTimeout = 30 * time.Second
+)
+
+type client struct {
+	address    string
+	token      string
+	namespace  string
+	region     string
+	httpClient *http.Client
+	logger     *zap.Logger
+}
+
+// NewClient returns a client for the Nomad cluster specified by the given configuration.
+func NewClient(cfg *config.CloudProviderNomadConfig, logger *zap.Logger) (Client, error) {
+	c := &client{
+		address:   strings.TrimRight(cfg.Address, "/"),
+		namespace: cfg.Namespace,
+		region:    cfg.Region,
+		httpClient: &http.Client{
+			Timeout: requestTimeout,
+		},
+		logger: logger.Named("nomad"),
+	}
+	if c.address == "" {
+		c.address = defaultAddress
+	}
+	if cfg.TokenFile != "" {
+		data, err := ioutil.ReadFile(cfg.TokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read token file %s (%w)", cfg.TokenFile, err)
+		}
+		c.token = strings.TrimSpace(string(data))
+	}
+	return c, nil
+}
+
+func (c *client) ParseJob(ctx context.Context, data []byte) (Job, error) {
+	if looksLikeJSON(data) {
+		return parseJobJSON(data)
+	}
+
+	req := struct {
+		JobHCL       string
+		Canonicalize bool
+	}{
+		JobHCL:       string(data),
+		Canonicalize: true,
+	}
+	var job Job
+	if err := c.do(ctx, http.MethodPost, "/v1/jobs/parse", req, &job); err != nil {
+		return nil, fmt.Errorf("unable to parse job (%w)", err)
+	}
+	if job.ID() == "" {
+		return nil, fmt.Errorf("missing job ID")
+	}
+	return job, nil
+}
+
+func (c *client) GetJob(ctx context.Context, id string) (Job, error) {
+	var job Job
+	if err := c.do(ctx, http.MethodGet, "/v1/job/"+url.PathEscape(id), nil, &job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (c *client) ListJobs(ctx context.Context) ([]*JobStub, error) {
+	var jobs []*JobStub
+	query := url.Values{}
+	query.Set("meta", "true")
+	if err := c.doWithQuery(ctx, http.MethodGet, "/v1/jobs", query, nil, &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func (c *client) RegisterJob(ctx context.Context, job Job) (Job, error) {
+	req := struct {
+		Job Job
+	}{
+		Job: job,
+	}
+	var resp struct {
+		EvalID   string
+		Warnings string
+	}
+	if err := c.do(ctx, http.MethodPost, "/v1/jobs", req, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Warnings != "" {
+		c.logger.Warn(fmt.Sprintf("job %s was registered with warnings: %s", job.ID(), resp.Warnings))
+	}
+	return c.GetJob(ctx, job.ID())
+}
+
+func (c *client) PlanJob(ctx context.Context, job Job) (*JobDiff, error) {
+	req := struct {
+		Job  Job
+		Diff bool
+	}{
+		Job:  job,
+		Diff: true,
+	}
+	var resp struct {
+		Diff *JobDiff
+	}
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/v1/job/%s/plan", url.PathEscape(job.ID())), req, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Diff == nil {
+		return &JobDiff{Type: DiffTypeNone, ID: job.ID()}, nil
+	}
+	return resp.Diff, nil
+}
+
+func (c *client) GetLatestDeployment(ctx context.Context, jobID string) (*Deployment, error) {
+	var d *Deployment
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/v1/job/%s/deployment", url.PathEscape(jobID)), nil, &d); err != nil {
+		return nil, err
+	}
+	// Nomad responds null when the job has no deployment.
+	if d == nil {
+		return nil, ErrNotFound
+	}
+	return d, nil
+}
+
+func (c *client) PromoteDeployment(ctx context.Context, id string) error {
+	req := struct {
+		DeploymentID string
+		All          bool
+	}{
+		DeploymentID: id,
+		All:          true,
+	}
+	return c.do(ctx, http.MethodPost, "/v1/deployment/promote/"+url.PathEscape(id), req, nil)
+}
+
+func (c *client) FailDeployment(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodPost, "/v1/deployment/fail/"+url.PathEscape(id), nil, nil)
+}
+
+// do sends a request to the Nomad HTTP API and decodes its response into out.
+// ErrNotFound is returned when the requested resource does not exist.
+func (c *client) do(ctx context.Context, method, path string, in, out interface{}) error {
+	return c.doWithQuery(ctx, method, path, url.Values{}, in, out)
+}
+
+// doWithQuery is the same as do but sends the given query parameters together.
+func (c *client) doWithQuery(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	if c.namespace != "" {
+		query.Set("namespace", c.namespace)
+	}
+	if c.region != "" {
+		query.Set("region", c.region)
+	}
+	u := c.address + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("X-Nomad-Token", c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response code %d from %s %s: %s", resp.StatusCode, method, path, strings.TrimSpace(string(data)))
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(out)
+}
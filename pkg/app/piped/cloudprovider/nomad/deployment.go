@@ -0,0 +1,87 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nomad
+
+const (
+	DeploymentStatusRunning    = "running"
+	DeploymentStatusPending    = "pending"
+	DeploymentStatusPaused     = "paused"
+	DeploymentStatusBlocked    = "blocked"
+	DeploymentStatusSuccessful = "successful"
+	DeploymentStatusFailed     = "failed"
+	DeploymentStatusCancelled  = "cancelled"
+)
+
+// Deployment represents a Nomad deployment which rolls out a version of a job.
+type Deployment struct {
+	ID                string
+	JobID             string
+	JobVersion        uint64
+	Status            string
+	StatusDescription string
+	TaskGroups        map[string]*DeploymentState
+}
+
+// DeploymentState represents the state of a task group in a deployment.
+type DeploymentState struct {
+	AutoPromote     bool
+	Promoted        bool
+	DesiredCanaries int
+	DesiredTotal    int
+	PlacedCanaries  []string
+	PlacedAllocs    int
+	HealthyAllocs   int
+	UnhealthyAllocs int
+}
+
+// IsActive reports whether the deployment is still rolling out the job.
+func (d *Deployment) IsActive() bool {
+	switch d.Status {
+	case DeploymentStatusRunning, DeploymentStatusPending, DeploymentStatusPaused, DeploymentStatusBlocked:
+		return true
+	}
+	return false
+}
+
+// IsFailed reports whether the deployment was finished without rolling out the job.
+func (d *Deployment) IsFailed() bool {
+	return d.Status == DeploymentStatusFailed || d.Status == DeploymentStatusCancelled
+}
+
+// RequiresPromotion reports whether the deployment is waiting for its canaries to be promoted.
+func (d *Deployment) RequiresPromotion() bool {
+	if !d.IsActive() {
+		return false
+	}
+	for _, s := range d.TaskGroups {
+		if s.DesiredCanaries > 0 && !s.Promoted {
+			return true
+		}
+	}
+	return false
+}
+
+// CanariesHealthy reports whether all canaries of the deployment have been placed and became healthy.
+func (d *Deployment) CanariesHealthy() bool {
+	for _, s := range d.TaskGroups {
+		if s.DesiredCanaries == 0 {
+			continue
+		}
+		if len(s.PlacedCanaries) < s.DesiredCanaries || s.HealthyAllocs < s.DesiredCanaries {
+			return false
+		}
+	}
+	return true
+}
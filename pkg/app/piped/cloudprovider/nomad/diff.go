@@ -0,0 +1,134 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nomad
+
+import (
+	"fmt"
+)
+
+const (
+	DiffTypeNone    = "None"
+	DiffTypeAdded   = "Added"
+	DiffTypeDeleted = "Deleted"
+	DiffTypeEdited  = "Edited"
+)
+
+// JobDiff represents the differences returned by the job plan API.
+type JobDiff struct {
+	Type       string
+	ID         string
+	Fields     []*FieldDiff
+	Objects    []*ObjectDiff
+	TaskGroups []*TaskGroupDiff
+}
+
+type TaskGroupDiff struct {
+	Type    string
+	Name    string
+	Fields  []*FieldDiff
+	Objects []*ObjectDiff
+	Tasks   []*TaskDiff
+}
+
+type TaskDiff struct {
+	Type    string
+	Name    string
+	Fields  []*FieldDiff
+	Objects []*ObjectDiff
+}
+
+type ObjectDiff struct {
+	Type    string
+	Name    string
+	Fields  []*FieldDiff
+	Objects []*ObjectDiff
+}
+
+type FieldDiff struct {
+	Type string
+	Name string
+	Old  string
+	New  string
+}
+
+// Summaries returns a line for each changed field of the job.
+// The added or deleted objects are summarized into one line instead of listing all their fields.
+func (d *JobDiff) Summaries() []string {
+	if d == nil || d.Type == DiffTypeNone || d.Type == "" {
+		return nil
+	}
+	var out []string
+	out = appendFieldSummaries(out, "", d.Fields)
+	out = appendObjectSummaries(out, "", d.Objects)
+	for _, g := range d.TaskGroups {
+		path := fmt.Sprintf("TaskGroups[%s]", g.Name)
+		switch g.Type {
+		case DiffTypeAdded, DiffTypeDeleted:
+			out = append(out, summarizeObject(path, g.Type))
+		case DiffTypeEdited:
+			out = appendFieldSummaries(out, path, g.Fields)
+			out = appendObjectSummaries(out, path, g.Objects)
+			for _, t := range g.Tasks {
+				taskPath := fmt.Sprintf("%s.Tasks[%s]", path, t.Name)
+				switch t.Type {
+				case DiffTypeAdded, DiffTypeDeleted:
+					out = append(out, summarizeObject(taskPath, t.Type))
+				case DiffTypeEdited:
+					out = appendFieldSummaries(out, taskPath, t.Fields)
+					out = appendObjectSummaries(out, taskPath, t.Objects)
+				}
+			}
+		}
+	}
+	return out
+}
+
+func appendFieldSummaries(out []string, path string, fields []*FieldDiff) []string {
+	for _, f := range fields {
+		if f.Type == DiffTypeNone {
+			continue
+		}
+		out = append(out, fmt.Sprintf("%s: %q => %q", joinPath(path, f.Name), f.Old, f.New))
+	}
+	return out
+}
+
+func appendObjectSummaries(out []string, path string, objects []*ObjectDiff) []string {
+	for _, o := range objects {
+		objectPath := joinPath(path, o.Name)
+		switch o.Type {
+		case DiffTypeAdded, DiffTypeDeleted:
+			out = append(out, summarizeObject(objectPath, o.Type))
+		case DiffTypeEdited:
+			out = appendFieldSummaries(out, objectPath, o.Fields)
+			out = appendObjectSummaries(out, objectPath, o.Objects)
+		}
+	}
+	return out
+}
+
+func summarizeObject(path, diffType string) string {
+	if diffType == DiffTypeAdded {
+		return fmt.Sprintf("%s was added", path)
+	}
+	return fmt.Sprintf("%s was deleted", path)
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,93 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nomad
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobDiffSummaries(t *testing.T) {
+	testcases := []struct {
+		name     string
+		diff     *JobDiff
+		expected []string
+	}{
+		{
+			name:     "nil",
+			expected: nil,
+		},
+		{
+			name:     "no difference",
+			diff:     &JobDiff{Type: DiffTypeNone},
+			expected: nil,
+		},
+		{
+			name: "edited",
+			diff: &JobDiff{
+				Type: DiffTypeEdited,
+				Fields: []*FieldDiff{
+					{Type: DiffTypeEdited, Name: "Priority", Old: "50", New: "70"},
+					{Type: DiffTypeNone, Name: "Type", Old: "service", New: "service"},
+				},
+				Objects: []*ObjectDiff{
+					{Type: DiffTypeAdded, Name: "Periodic"},
+				},
+				TaskGroups: []*TaskGroupDiff{
+					{
+						Type: DiffTypeEdited,
+						Name: "web",
+						Fields: []*FieldDiff{
+							{Type: DiffTypeEdited, Name: "Count", Old: "3", New: "2"},
+						},
+						Tasks: []*TaskDiff{
+							{
+								Type: DiffTypeEdited,
+								Name: "web",
+								Objects: []*ObjectDiff{
+									{
+										Type: DiffTypeEdited,
+										Name: "Config",
+										Fields: []*FieldDiff{
+											{Type: DiffTypeEdited, Name: "image", Old: "helloworld:v0.1.0", New: "helloworld:v0.2.0"},
+										},
+									},
+								},
+							},
+							{Type: DiffTypeDeleted, Name: "sidecar"},
+						},
+					},
+					{Type: DiffTypeAdded, Name: "worker"},
+					{Type: DiffTypeNone, Name: "cache"},
+				},
+			},
+			expected: []string{
+				`Priority: "50" => "70"`,
+				`Periodic was added`,
+				`TaskGroups[web].Count: "3" => "2"`,
+				`TaskGroups[web].Tasks[web].Config.image: "helloworld:v0.1.0" => "helloworld:v0.2.0"`,
+				`TaskGroups[web].Tasks[sidecar] was deleted`,
+				`TaskGroups[worker] was added`,
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.diff.Summaries()
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
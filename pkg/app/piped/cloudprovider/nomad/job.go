@@ -0,0 +1,180 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nomad
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+const (
+	JobTypeService  = "service"
+	JobTypeBatch    = "batch"
+	JobTypeSystem   = "system"
+	JobTypeSysBatch = "sysbatch"
+)
+
+// Job represents a Nomad job in the JSON form used by the Nomad HTTP API.
+// Only the fields used by PipeCD are accessed, so the others are kept as they are.
+type Job map[string]interface{}
+
+// JobStub represents the summary of a job returned by the job list API.
+type JobStub struct {
+	ID         string
+	Type       string
+	Status     string
+	Version    uint64
+	Meta       map[string]string
+	JobSummary *JobSummary
+}
+
+// JobSummary represents the allocation counts of the task groups in a job.
+type JobSummary struct {
+	Summary map[string]TaskGroupSummary
+}
+
+// TaskGroupSummary represents the allocation counts of a task group by their statuses.
+type TaskGroupSummary struct {
+	Queued   int
+	Complete int
+	Failed   int
+	Running  int
+	Starting int
+	Lost     int
+}
+
+// ID returns the ID of the job. The name is used when the ID was not specified.
+func (j Job) ID() string {
+	if id, ok := j["ID"].(string); ok && id != "" {
+		return id
+	}
+	name, _ := j["Name"].(string)
+	return name
+}
+
+// Type returns the type of the job. The default is service.
+func (j Job) Type() string {
+	if t, ok := j["Type"].(string); ok && t != "" {
+		return t
+	}
+	return JobTypeService
+}
+
+// Version returns the version number assigned by Nomad when the job was registered.
+func (j Job) Version() uint64 {
+	n, _ := toInt(j["Version"])
+	return uint64(n)
+}
+
+// Meta returns a copy of the meta of the job.
+func (j Job) Meta() map[string]string {
+	meta, _ := j["Meta"].(map[string]interface{})
+	out := make(map[string]string, len(meta))
+	for k, v := range meta {
+		if s, ok := v.(string); ok {
+			out[k] = s
+		}
+	}
+	return out
+}
+
+// SetMeta replaces the meta of the job.
+func (j Job) SetMeta(meta map[string]string) {
+	m := make(map[string]interface{}, len(meta))
+	for k, v := range meta {
+		m[k] = v
+	}
+	j["Meta"] = m
+}
+
+// HasCanary reports whether the update strategy of the job or any of its task groups places canaries.
+func (j Job) HasCanary() bool {
+	if hasCanary(j["Update"]) {
+		return true
+	}
+	groups, _ := j["TaskGroups"].([]interface{})
+	for _, g := range groups {
+		if group, ok := g.(map[string]interface{}); ok && hasCanary(group["Update"]) {
+			return true
+		}
+	}
+	return false
+}
+
+// RequiresDeployment reports whether Nomad creates a deployment when the job was updated.
+func (j Job) RequiresDeployment() bool {
+	return j.Type() == JobTypeService
+}
+
+func hasCanary(update interface{}) bool {
+	u, ok := update.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	n, _ := toInt(u["Canary"])
+	return n > 0
+}
+
+func toInt(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		i, err := n.Int64()
+		return i, err == nil
+	case float64:
+		return int64(n), true
+	case int:
+		return int64(n), true
+	case string:
+		i, err := strconv.ParseInt(n, 10, 64)
+		return i, err == nil
+	}
+	return 0, false
+}
+
+// looksLikeJSON reports whether the given job file content is written in JSON.
+func looksLikeJSON(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && data[0] == '{'
+}
+
+// parseJobJSON parses the given JSON content into a job.
+// Both the job object itself and the one wrapped by the "Job" field are accepted.
+func parseJobJSON(data []byte) (Job, error) {
+	job, err := decodeJob(data)
+	if err != nil {
+		return nil, err
+	}
+	if inner, ok := job["Job"].(map[string]interface{}); ok {
+		job = Job(inner)
+	}
+	if job.ID() == "" {
+		return nil, fmt.Errorf("missing job ID")
+	}
+	return job, nil
+}
+
+// decodeJob decodes the given data while keeping the numbers as they are
+// since some of them like the indexes may not fit into float64.
+func decodeJob(data []byte) (Job, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var job Job
+	if err := dec.Decode(&job); err != nil {
+		return nil, fmt.Errorf("malformed job (%w)", err)
+	}
+	return job, nil
+}
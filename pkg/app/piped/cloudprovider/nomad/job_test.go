@@ -0,0 +1,160 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nomad
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJobJSON(t *testing.T) {
+	testcases := []struct {
+		name           string
+		data           string
+		expectedID     string
+		expectedType   string
+		expectedCanary bool
+		expectedErr    bool
+	}{
+		{
+			name: "job object",
+			data: `{
+  "ID": "web",
+  "TaskGroups": [{"Name": "web", "Count": 3}]
+}`,
+			expectedID:   "web",
+			expectedType: JobTypeService,
+		},
+		{
+			name: "wrapped by Job field",
+			data: `{
+  "Job": {
+    "Name": "web",
+    "Type": "batch"
+  }
+}`,
+			expectedID:   "web",
+			expectedType: JobTypeBatch,
+		},
+		{
+			name: "canary in job update strategy",
+			data: `{
+  "ID": "web",
+  "Update": {"Canary": 1}
+}`,
+			expectedID:     "web",
+			expectedType:   JobTypeService,
+			expectedCanary: true,
+		},
+		{
+			name: "canary in task group update strategy",
+			data: `{
+  "ID": "web",
+  "TaskGroups": [{"Name": "web", "Update": {"Canary": 2}}]
+}`,
+			expectedID:     "web",
+			expectedType:   JobTypeService,
+			expectedCanary: true,
+		},
+		{
+			name:        "missing ID",
+			data:        `{"Type": "service"}`,
+			expectedErr: true,
+		},
+		{
+			name:        "malformed",
+			data:        `{"ID": `,
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.True(t, looksLikeJSON([]byte(tc.data)))
+			job, err := parseJobJSON([]byte(tc.data))
+			assert.Equal(t, tc.expectedErr, err != nil)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.expectedID, job.ID())
+			assert.Equal(t, tc.expectedType, job.Type())
+			assert.Equal(t, tc.expectedCanary, job.HasCanary())
+		})
+	}
+}
+
+func TestJobMeta(t *testing.T) {
+	job, err := parseJobJSON([]byte(`{"ID": "web", "Version": 3, "Meta": {"team": "pipecd"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), job.Version())
+
+	meta := MakeMeta(job.Meta(), "piped-id", "app-id", "commit-hash")
+	job.SetMeta(meta)
+	assert.Equal(t, map[string]string{
+		"team":          "pipecd",
+		MetaManagedBy:   ManagedByPiped,
+		MetaPiped:       "piped-id",
+		MetaApplication: "app-id",
+		MetaCommitHash:  "commit-hash",
+	}, job.Meta())
+}
+
+func TestFindArtifactVersion(t *testing.T) {
+	testcases := []struct {
+		name        string
+		data        string
+		expected    string
+		expectedErr bool
+	}{
+		{
+			name: "hcl",
+			data: `
+job "web" {
+  group "web" {
+    task "web" {
+      driver = "docker"
+      config {
+        image = "gcr.io/pipecd/helloworld:v0.1.0"
+      }
+    }
+  }
+}`,
+			expected: "v0.1.0",
+		},
+		{
+			name:     "json",
+			data:     `{"ID": "web", "TaskGroups": [{"Tasks": [{"Config": {"image": "localhost:5000/helloworld:v0.2.0"}}]}]}`,
+			expected: "v0.2.0",
+		},
+		{
+			name:     "no tag",
+			data:     `config { image = "localhost:5000/helloworld" }`,
+			expected: "latest",
+		},
+		{
+			name:        "no image",
+			data:        `job "web" {}`,
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			version, err := FindArtifactVersion([]byte(tc.data))
+			assert.Equal(t, tc.expectedErr, err != nil)
+			assert.Equal(t, tc.expected, version)
+		})
+	}
+}
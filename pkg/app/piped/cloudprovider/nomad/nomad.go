@@ -0,0 +1,153 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nomad
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/pipe-cd/pipe/pkg/config"
+)
+
+const (
+	DefaultJobFilename = "job.nomad"
+
+	MetaManagedBy   = "pipecd-dev-managed-by"  // Always be piped.
+	MetaPiped       = "pipecd-dev-piped"       // The id of piped handling this job.
+	MetaApplication = "pipecd-dev-application" // The application this job belongs to.
+	MetaCommitHash  = "pipecd-dev-commit-hash" // Hash value of the deployed commit.
+
+	ManagedByPiped = "piped"
+)
+
+var (
+	ErrNotFound = errors.New("not found")
+
+	imageRegex = regexp.MustCompile(`"?image"?\s*[=:]\s*"([^"]+)"`)
+)
+
+// Client is a wrapper of the Nomad HTTP API.
+type Client interface {
+	// ParseJob converts the given job file content into the JSON form used by the API.
+	// The content can be written in either HCL or JSON.
+	ParseJob(ctx context.Context, data []byte) (Job, error)
+	// GetJob returns ErrNotFound when the job does not exist.
+	GetJob(ctx context.Context, id string) (Job, error)
+	// ListJobs returns the summaries of all jobs including their meta.
+	ListJobs(ctx context.Context) ([]*JobStub, error)
+	// RegisterJob creates or updates the given job and returns the registered one.
+	RegisterJob(ctx context.Context, job Job) (Job, error)
+	// PlanJob returns the differences between the given job and the registered one.
+	PlanJob(ctx context.Context, job Job) (*JobDiff, error)
+	// GetLatestDeployment returns ErrNotFound when the job has no deployment.
+	GetLatestDeployment(ctx context.Context, jobID string) (*Deployment, error)
+	// PromoteDeployment promotes the canaries of all task groups in the given deployment.
+	PromoteDeployment(ctx context.Context, id string) error
+	// FailDeployment marks the given deployment as failed to stop placing its allocations.
+	FailDeployment(ctx context.Context, id string) error
+}
+
+// Registry holds a pool of clients.
+type Registry interface {
+	Client(name string, cfg *config.CloudProviderNomadConfig, logger *zap.Logger) (Client, error)
+}
+
+// LoadJobFile returns the content of the given job file placing in the application directory.
+func LoadJobFile(appDir, jobFilename string) ([]byte, error) {
+	if jobFilename == "" {
+		jobFilename = DefaultJobFilename
+	}
+	data, err := ioutil.ReadFile(filepath.Join(appDir, jobFilename))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read job file %s (%w)", jobFilename, err)
+	}
+	return data, nil
+}
+
+// FindArtifactVersion returns the tag of the first image used by the tasks in the given job file.
+func FindArtifactVersion(data []byte) (string, error) {
+	matches := imageRegex.FindSubmatch(data)
+	if matches == nil {
+		return "", fmt.Errorf("no image was found in the job file")
+	}
+	image := string(matches[1])
+	// Ignore the port of the registry host.
+	name := image[strings.LastIndex(image, "/")+1:]
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		return name[i+1:], nil
+	}
+	return "latest", nil
+}
+
+// MakeMeta returns a copy of the given meta with the ones used by PipeCD to identify the job.
+func MakeMeta(meta map[string]string, pipedID, appID, commitHash string) map[string]string {
+	out := make(map[string]string, len(meta)+4)
+	for k, v := range meta {
+		out[k] = v
+	}
+	out[MetaManagedBy] = ManagedByPiped
+	out[MetaPiped] = pipedID
+	out[MetaApplication] = appID
+	out[MetaCommitHash] = commitHash
+	return out
+}
+
+type registry struct {
+	clients  map[string]Client
+	mu       sync.RWMutex
+	newGroup *singleflight.Group
+}
+
+func (r *registry) Client(name string, cfg *config.CloudProviderNomadConfig, logger *zap.Logger) (Client, error) {
+	r.mu.RLock()
+	client, ok := r.clients[name]
+	r.mu.RUnlock()
+	if ok {
+		return client, nil
+	}
+
+	c, err, _ := r.newGroup.Do(name, func() (interface{}, error) {
+		return NewClient(cfg, logger)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	client = c.(Client)
+	r.mu.Lock()
+	r.clients[name] = client
+	r.mu.Unlock()
+
+	return client, nil
+}
+
+var defaultRegistry = &registry{
+	clients:  make(map[string]Client),
+	newGroup: &singleflight.Group{},
+}
+
+// DefaultRegistry returns the default pool of clients.
+func DefaultRegistry() Registry {
+	return defaultRegistry
+}
@@ -0,0 +1,359 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package nomadfake provides an in-memory implementation of the Nomad HTTP API
+// that can be used to test the components using Nomad without running a cluster.
+// Only the JSON job format is supported and all allocations become healthy right after being placed.
+package nomadfake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/nomad"
+)
+
+type job struct {
+	versions    []provider.Job
+	deployments []*provider.Deployment
+}
+
+// Server is a fake Nomad HTTP API server.
+type Server struct {
+	*httptest.Server
+
+	jobs   map[string]*job
+	nextID int
+	mu     sync.Mutex
+}
+
+// NewServer starts and returns a new Server holding no job.
+// It should be closed at the end of the test.
+func NewServer() *Server {
+	s := &Server{
+		jobs: make(map[string]*job),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Deployments returns the copies of all deployments of the given job in the order they were created.
+func (s *Server) Deployments(jobID string) []provider.Deployment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j, ok := s.jobs[jobID]
+	if !ok {
+		return nil
+	}
+	out := make([]provider.Deployment, 0, len(j.deployments))
+	for _, d := range j.deployments {
+		out = append(out, *d)
+	}
+	return out
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.Method == http.MethodGet && len(parts) == 2 && parts[1] == "jobs":
+		s.listJobs(w)
+	case r.Method == http.MethodPost && len(parts) == 2 && parts[1] == "jobs":
+		s.registerJob(w, r)
+	case r.Method == http.MethodGet && len(parts) == 3 && parts[1] == "job":
+		s.getJob(w, parts[2])
+	case r.Method == http.MethodPost && len(parts) == 4 && parts[1] == "job" && parts[3] == "plan":
+		s.planJob(w, r, parts[2])
+	case r.Method == http.MethodGet && len(parts) == 4 && parts[1] == "job" && parts[3] == "deployment":
+		s.getLatestDeployment(w, parts[2])
+	case r.Method == http.MethodPost && len(parts) == 4 && parts[1] == "deployment" && parts[2] == "promote":
+		s.updateDeployment(w, parts[3], true)
+	case r.Method == http.MethodPost && len(parts) == 4 && parts[1] == "deployment" && parts[2] == "fail":
+		s.updateDeployment(w, parts[3], false)
+	default:
+		http.Error(w, fmt.Sprintf("%s %s is not supported", r.Method, r.URL.Path), http.StatusBadRequest)
+	}
+}
+
+func (s *Server) registerJob(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Job provider.Job
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	id := req.Job.ID()
+	if id == "" {
+		http.Error(w, "missing job ID", http.StatusBadRequest)
+		return
+	}
+
+	j, ok := s.jobs[id]
+	if !ok {
+		j = &job{}
+		s.jobs[id] = j
+	}
+	// As Nomad does, the version is not changed when the spec is the same.
+	if n := len(j.versions); n > 0 && reflect.DeepEqual(normalize(j.versions[n-1]), normalize(req.Job)) {
+		writeJSON(w, map[string]string{"EvalID": s.newID("eval")})
+		return
+	}
+
+	registered := copyJob(req.Job)
+	registered["ID"] = id
+	registered["Version"] = len(j.versions)
+	j.versions = append(j.versions, registered)
+
+	if registered.RequiresDeployment() {
+		// The running deployment is cancelled by the newer one.
+		for _, d := range j.deployments {
+			if d.IsActive() {
+				d.Status = provider.DeploymentStatusCancelled
+			}
+		}
+		j.deployments = append(j.deployments, s.newDeployment(registered))
+	}
+	writeJSON(w, map[string]string{"EvalID": s.newID("eval")})
+}
+
+// listJobs returns the summaries of all jobs sorted by their IDs.
+// All allocations of the latest version are considered as running.
+func (s *Server) listJobs(w http.ResponseWriter) {
+	ids := make([]string, 0, len(s.jobs))
+	for id := range s.jobs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	stubs := make([]*provider.JobStub, 0, len(ids))
+	for _, id := range ids {
+		latest := s.jobs[id].versions[len(s.jobs[id].versions)-1]
+		summary := &provider.JobSummary{
+			Summary: make(map[string]provider.TaskGroupSummary),
+		}
+		groups, _ := latest["TaskGroups"].([]interface{})
+		for _, g := range groups {
+			group, _ := g.(map[string]interface{})
+			name, _ := group["Name"].(string)
+			summary.Summary[name] = provider.TaskGroupSummary{
+				Running: intOf(group["Count"], 1),
+			}
+		}
+		stubs = append(stubs, &provider.JobStub{
+			ID:         id,
+			Type:       latest.Type(),
+			Status:     "running",
+			Version:    latest.Version(),
+			Meta:       latest.Meta(),
+			JobSummary: summary,
+		})
+	}
+	writeJSON(w, stubs)
+}
+
+func (s *Server) getJob(w http.ResponseWriter, id string) {
+	j, ok := s.jobs[id]
+	if !ok {
+		http.Error(w, "job not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, j.versions[len(j.versions)-1])
+}
+
+// planJob returns an edited field for each top-level field having a different value.
+func (s *Server) planJob(w http.ResponseWriter, r *http.Request, id string) {
+	var req struct {
+		Job provider.Job
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	diff := &provider.JobDiff{ID: id, Type: provider.DiffTypeAdded}
+	if j, ok := s.jobs[id]; ok {
+		diff.Type = provider.DiffTypeNone
+		var (
+			live     = normalize(j.versions[len(j.versions)-1])
+			expected = normalize(req.Job)
+			keys     = make(map[string]struct{})
+		)
+		for k := range live {
+			keys[k] = struct{}{}
+		}
+		for k := range expected {
+			keys[k] = struct{}{}
+		}
+		names := make([]string, 0, len(keys))
+		for k := range keys {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		for _, k := range names {
+			if reflect.DeepEqual(live[k], expected[k]) {
+				continue
+			}
+			diff.Type = provider.DiffTypeEdited
+			diff.Fields = append(diff.Fields, &provider.FieldDiff{
+				Type: provider.DiffTypeEdited,
+				Name: k,
+				Old:  toString(live[k]),
+				New:  toString(expected[k]),
+			})
+		}
+	}
+	writeJSON(w, map[string]interface{}{"Diff": diff})
+}
+
+func (s *Server) getLatestDeployment(w http.ResponseWriter, jobID string) {
+	j, ok := s.jobs[jobID]
+	if !ok || len(j.deployments) == 0 {
+		writeJSON(w, nil)
+		return
+	}
+	writeJSON(w, j.deployments[len(j.deployments)-1])
+}
+
+func (s *Server) updateDeployment(w http.ResponseWriter, id string, promote bool) {
+	for _, j := range s.jobs {
+		for _, d := range j.deployments {
+			if d.ID != id {
+				continue
+			}
+			if !d.IsActive() {
+				http.Error(w, fmt.Sprintf("deployment %s is %s", id, d.Status), http.StatusBadRequest)
+				return
+			}
+			if !promote {
+				d.Status = provider.DeploymentStatusFailed
+				d.StatusDescription = "Deployment marked as failed"
+				writeJSON(w, map[string]string{"EvalID": s.newID("eval")})
+				return
+			}
+			for _, state := range d.TaskGroups {
+				state.Promoted = true
+				state.HealthyAllocs = state.DesiredTotal
+				state.PlacedAllocs = state.DesiredTotal
+			}
+			d.Status = provider.DeploymentStatusSuccessful
+			d.StatusDescription = "Deployment completed successfully"
+			writeJSON(w, map[string]string{"EvalID": s.newID("eval")})
+			return
+		}
+	}
+	http.Error(w, "deployment not found", http.StatusNotFound)
+}
+
+// newDeployment returns a deployment placing all allocations of the given job.
+// The deployment stays running until being promoted when the job places canaries.
+func (s *Server) newDeployment(j provider.Job) *provider.Deployment {
+	d := &provider.Deployment{
+		ID:                s.newID("deployment"),
+		JobID:             j.ID(),
+		JobVersion:        j.Version(),
+		Status:            provider.DeploymentStatusSuccessful,
+		StatusDescription: "Deployment completed successfully",
+		TaskGroups:        make(map[string]*provider.DeploymentState),
+	}
+	jobCanary := canaryOf(j["Update"])
+	groups, _ := j["TaskGroups"].([]interface{})
+	for _, g := range groups {
+		group, _ := g.(map[string]interface{})
+		name, _ := group["Name"].(string)
+		count := intOf(group["Count"], 1)
+		canary := canaryOf(group["Update"])
+		if canary == 0 {
+			canary = jobCanary
+		}
+		state := &provider.DeploymentState{
+			DesiredCanaries: canary,
+			DesiredTotal:    count,
+			PlacedAllocs:    count,
+			HealthyAllocs:   count,
+		}
+		if canary > 0 {
+			for i := 0; i < canary; i++ {
+				state.PlacedCanaries = append(state.PlacedCanaries, s.newID("alloc"))
+			}
+			state.PlacedAllocs = canary
+			state.HealthyAllocs = canary
+			d.Status = provider.DeploymentStatusRunning
+			d.StatusDescription = "Deployment is running but requires manual promotion"
+		}
+		d.TaskGroups[name] = state
+	}
+	return d
+}
+
+func (s *Server) newID(prefix string) string {
+	s.nextID++
+	return fmt.Sprintf("%s-%d", prefix, s.nextID)
+}
+
+// normalize returns the given job without the fields set by Nomad.
+func normalize(j provider.Job) map[string]interface{} {
+	out := make(map[string]interface{}, len(j))
+	for k, v := range copyJob(j) {
+		if k == "Version" {
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
+
+func copyJob(j provider.Job) provider.Job {
+	data, _ := json.Marshal(j)
+	var out provider.Job
+	json.Unmarshal(data, &out)
+	return out
+}
+
+func canaryOf(update interface{}) int {
+	u, _ := update.(map[string]interface{})
+	return intOf(u["Canary"], 0)
+}
+
+func intOf(v interface{}, def int) int {
+	if n, ok := v.(float64); ok {
+		return int(n)
+	}
+	return def
+}
+
+func toString(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
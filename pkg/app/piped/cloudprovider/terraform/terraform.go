@@ -17,18 +17,23 @@ package terraform
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os/exec"
+	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type options struct {
-	noColor  bool
-	vars     []string
-	varFiles []string
+	noColor     bool
+	vars        []string
+	varFiles    []string
+	lockTimeout time.Duration
 }
 
 type Option func(*options)
@@ -51,6 +56,14 @@ func WithVarFiles(files []string) Option {
 	}
 }
 
+// WithLockTimeout makes the apply commands wait for the given duration
+// to acquire the state lock instead of failing immediately.
+func WithLockTimeout(d time.Duration) Option {
+	return func(opts *options) {
+		opts.lockTimeout = d
+	}
+}
+
 type Terraform struct {
 	execPath string
 	dir      string
@@ -137,11 +150,47 @@ func GetExitCode(err error) int {
 }
 
 func (t *Terraform) Plan(ctx context.Context, w io.Writer) (PlanResult, error) {
+	return t.plan(ctx, w)
+}
+
+// SavePlan does the same as Plan but also saves the generated plan
+// into the given file to be applied later by ApplyPlan.
+func (t *Terraform) SavePlan(ctx context.Context, w io.Writer, planFile string) (PlanResult, error) {
+	return t.plan(ctx, w, fmt.Sprintf("-out=%s", planFile))
+}
+
+// SaveRefreshOnlyPlan runs a refresh-only plan to detect the changes made outside of Terraform
+// and saves it into the given file. Use ShowPlan to read the drifted resources.
+// This requires terraform v0.15.4 or later.
+func (t *Terraform) SaveRefreshOnlyPlan(ctx context.Context, w io.Writer, planFile string) error {
+	_, err := t.runPlan(ctx, w, "-refresh-only", fmt.Sprintf("-out=%s", planFile))
+	switch GetExitCode(err) {
+	case 0, 2:
+		return nil
+	default:
+		return err
+	}
+}
+
+func (t *Terraform) plan(ctx context.Context, w io.Writer, extraArgs ...string) (PlanResult, error) {
+	out, err := t.runPlan(ctx, w, extraArgs...)
+	switch GetExitCode(err) {
+	case 0:
+		return PlanResult{}, nil
+	case 2:
+		return parsePlanResult(out, !t.options.noColor)
+	default:
+		return PlanResult{}, err
+	}
+}
+
+func (t *Terraform) runPlan(ctx context.Context, w io.Writer, extraArgs ...string) (string, error) {
 	args := []string{
 		"plan",
 		"-lock=false",
 		"-detailed-exitcode",
 	}
+	args = append(args, extraArgs...)
 	args = append(args, t.makeCommonCommandArgs()...)
 
 	var buf bytes.Buffer
@@ -154,14 +203,129 @@ func (t *Terraform) Plan(ctx context.Context, w io.Writer) (PlanResult, error) {
 
 	io.WriteString(w, fmt.Sprintf("terraform %s", strings.Join(args, " ")))
 	err := cmd.Run()
-	switch GetExitCode(err) {
-	case 0:
-		return PlanResult{}, nil
-	case 2:
-		return parsePlanResult(buf.String(), !t.options.noColor)
-	default:
-		return PlanResult{}, err
+	return buf.String(), err
+}
+
+// ResourceChange represents a change to a single resource.
+type ResourceChange struct {
+	// The full address of the resource, e.g. "aws_instance.web[0]".
+	Address string
+	// The resource type, e.g. "aws_instance".
+	Type string
+	// The resource name, e.g. "web".
+	Name string
+	// The actions will be taken on the resource.
+	// Possible values are "no-op", "create", "read", "update" and "delete".
+	// A replacement is represented by both "delete" and "create".
+	Actions []string
+	// The sorted list of top-level attributes whose values were changed.
+	ChangedAttributes []string
+}
+
+// HasAction checks whether the given action will be taken on the resource.
+func (c ResourceChange) HasAction(action string) bool {
+	for _, a := range c.Actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// Plan represents the content of a saved plan file.
+type Plan struct {
+	// The changes will be made to the resources by applying the plan.
+	ResourceChanges []ResourceChange
+	// The changes made to the resources outside of Terraform
+	// since the last time the state was updated.
+	ResourceDrifts []ResourceChange
+	// The whole plan in the JSON format output by "terraform show -json".
+	JSON []byte
+}
+
+// ShowPlan reads the given plan file and returns the resource changes inside it.
+func (t *Terraform) ShowPlan(ctx context.Context, planFile string) (*Plan, error) {
+	args := []string{
+		"show",
+		"-json",
+		planFile,
+	}
+	cmd := exec.CommandContext(ctx, t.execPath, args...)
+	cmd.Dir = t.dir
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to show plan: %s (%w)", stderr.String(), err)
+	}
+	return parsePlanJSON(out)
+}
+
+type planJSON struct {
+	ResourceChanges []resourceChangeJSON `json:"resource_changes"`
+	ResourceDrift   []resourceChangeJSON `json:"resource_drift"`
+}
+
+type resourceChangeJSON struct {
+	Address string `json:"address"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Change  struct {
+		Actions []string               `json:"actions"`
+		Before  map[string]interface{} `json:"before"`
+		After   map[string]interface{} `json:"after"`
+	} `json:"change"`
+}
+
+func (rc resourceChangeJSON) toResourceChange() ResourceChange {
+	return ResourceChange{
+		Address:           rc.Address,
+		Type:              rc.Type,
+		Name:              rc.Name,
+		Actions:           rc.Change.Actions,
+		ChangedAttributes: changedAttributes(rc.Change.Before, rc.Change.After),
+	}
+}
+
+func changedAttributes(before, after map[string]interface{}) []string {
+	keys := make(map[string]struct{}, len(before)+len(after))
+	for k := range before {
+		keys[k] = struct{}{}
+	}
+	for k := range after {
+		keys[k] = struct{}{}
+	}
+
+	var changed []string
+	for k := range keys {
+		if !reflect.DeepEqual(before[k], after[k]) {
+			changed = append(changed, k)
+		}
 	}
+	sort.Strings(changed)
+	return changed
+}
+
+func parsePlanJSON(data []byte) (*Plan, error) {
+	var p planJSON
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("unable to parse plan json (%w)", err)
+	}
+
+	plan := &Plan{
+		ResourceChanges: make([]ResourceChange, 0, len(p.ResourceChanges)),
+		ResourceDrifts:  make([]ResourceChange, 0, len(p.ResourceDrift)),
+		JSON:            data,
+	}
+	for _, rc := range p.ResourceChanges {
+		plan.ResourceChanges = append(plan.ResourceChanges, rc.toResourceChange())
+	}
+	for _, rc := range p.ResourceDrift {
+		plan.ResourceDrifts = append(plan.ResourceDrifts, rc.toResourceChange())
+	}
+	return plan, nil
 }
 
 func (t *Terraform) makeCommonCommandArgs() (args []string) {
@@ -177,6 +341,33 @@ func (t *Terraform) makeCommonCommandArgs() (args []string) {
 	return
 }
 
+func (t *Terraform) makeLockTimeoutArgs() []string {
+	if t.options.lockTimeout <= 0 {
+		return nil
+	}
+	return []string{fmt.Sprintf("-lock-timeout=%s", t.options.lockTimeout)}
+}
+
+var (
+	stateLockErrorRegex = regexp.MustCompile(`Error acquiring the state lock`)
+	stateLockIDRegex    = regexp.MustCompile(`(?m)^\s*ID:\s+(\S+)\s*$`)
+)
+
+// FindStateLockError checks whether the given command output reports that
+// the state could not be locked, and returns the ID of the lock holding it.
+// The ID is empty when it was not reported by the backend.
+func FindStateLockError(out string) (lockID string, found bool) {
+	out = stripAnsiCodes(out)
+	loc := stateLockErrorRegex.FindStringIndex(out)
+	if loc == nil {
+		return "", false
+	}
+	if s := stateLockIDRegex.FindStringSubmatch(out[loc[1]:]); len(s) == 2 {
+		return s[1], true
+	}
+	return "", true
+}
+
 var (
 	planHasChangeRegex = regexp.MustCompile(`(?m)^Plan: (\d+) to add, (\d+) to change, (\d+) to destroy.$`)
 	planNoChangesRegex = regexp.MustCompile(`(?m)^No changes. Infrastructure is up-to-date.$`)
@@ -236,6 +427,7 @@ func (t *Terraform) Apply(ctx context.Context, w io.Writer) error {
 		"-auto-approve",
 		"-input=false",
 	}
+	args = append(args, t.makeLockTimeoutArgs()...)
 	args = append(args, t.makeCommonCommandArgs()...)
 
 	cmd := exec.CommandContext(ctx, t.execPath, args...)
@@ -246,3 +438,25 @@ func (t *Terraform) Apply(ctx context.Context, w io.Writer) error {
 	io.WriteString(w, fmt.Sprintf("terraform %s", strings.Join(args, " ")))
 	return cmd.Run()
 }
+
+// ApplyPlan applies exactly the changes stored in the given plan file.
+func (t *Terraform) ApplyPlan(ctx context.Context, w io.Writer, planFile string) error {
+	args := []string{
+		"apply",
+		"-auto-approve",
+		"-input=false",
+	}
+	args = append(args, t.makeLockTimeoutArgs()...)
+	if t.options.noColor {
+		args = append(args, "-no-color")
+	}
+	args = append(args, planFile)
+
+	cmd := exec.CommandContext(ctx, t.execPath, args...)
+	cmd.Dir = t.dir
+	cmd.Stdout = w
+	cmd.Stderr = w
+
+	io.WriteString(w, fmt.Sprintf("terraform %s", strings.Join(args, " ")))
+	return cmd.Run()
+}
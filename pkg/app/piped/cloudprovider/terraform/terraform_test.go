@@ -13,3 +13,116 @@
 // limitations under the License.
 
 package terraform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePlanJSON(t *testing.T) {
+	data := []byte(`{
+  "format_version": "0.2",
+  "resource_drift": [
+    {
+      "address": "aws_s3_bucket.logs",
+      "type": "aws_s3_bucket",
+      "name": "logs",
+      "change": {
+        "actions": ["update"],
+        "before": {"bucket": "logs", "acl": "private", "tags": {"team": "a"}},
+        "after": {"bucket": "logs", "acl": "public-read", "tags": {"team": "b"}}
+      }
+    }
+  ],
+  "resource_changes": [
+    {
+      "address": "aws_instance.web",
+      "type": "aws_instance",
+      "name": "web",
+      "change": {"actions": ["create"], "before": null, "after": {"ami": "ami-123"}}
+    },
+    {
+      "address": "aws_db_instance.main",
+      "type": "aws_db_instance",
+      "name": "main",
+      "change": {"actions": ["delete", "create"], "before": {"engine": "mysql"}, "after": {"engine": "mysql"}}
+    }
+  ]
+}`)
+	plan, err := parsePlanJSON(data)
+	require.NoError(t, err)
+	expected := &Plan{
+		ResourceChanges: []ResourceChange{
+			{
+				Address:           "aws_instance.web",
+				Type:              "aws_instance",
+				Name:              "web",
+				Actions:           []string{"create"},
+				ChangedAttributes: []string{"ami"},
+			},
+			{
+				Address: "aws_db_instance.main",
+				Type:    "aws_db_instance",
+				Name:    "main",
+				Actions: []string{"delete", "create"},
+			},
+		},
+		ResourceDrifts: []ResourceChange{
+			{
+				Address:           "aws_s3_bucket.logs",
+				Type:              "aws_s3_bucket",
+				Name:              "logs",
+				Actions:           []string{"update"},
+				ChangedAttributes: []string{"acl", "tags"},
+			},
+		},
+		JSON: data,
+	}
+	assert.Equal(t, expected, plan)
+	assert.True(t, plan.ResourceChanges[1].HasAction("delete"))
+	assert.False(t, plan.ResourceChanges[0].HasAction("delete"))
+
+	_, err = parsePlanJSON([]byte("invalid"))
+	assert.Error(t, err)
+}
+
+func TestFindStateLockError(t *testing.T) {
+	testcases := []struct {
+		name       string
+		out        string
+		expectedID string
+		expected   bool
+	}{
+		{
+			name: "no lock error",
+			out:  "Apply complete! Resources: 1 added, 0 changed, 0 destroyed.",
+		},
+		{
+			name: "lock error with id",
+			out: "\x1b[31m\x1b[1mError: \x1b[0mError acquiring the state lock\n" + `
+Error message: ConditionalCheckFailedException: The conditional request failed
+Lock Info:
+  ID:        7f4b7d3c-2b3e-9a61-4b8e-0e9a2c1b5f21
+  Path:      tfstate/terraform.tfstate
+  Operation: OperationTypeApply
+  Who:       runner@ci
+`,
+			expectedID: "7f4b7d3c-2b3e-9a61-4b8e-0e9a2c1b5f21",
+			expected:   true,
+		},
+		{
+			name:     "lock error without id",
+			out:      "Error: Error acquiring the state lock\n\nError message: timeout",
+			expected: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			id, found := FindStateLockError(tc.out)
+			assert.Equal(t, tc.expected, found)
+			assert.Equal(t, tc.expectedID, id)
+		})
+	}
+}
@@ -289,10 +289,16 @@ func (p *piped) run(ctx context.Context, t cli.Telemetry) (runErr error) {
 	// Create memory caches.
 	appManifestsCache := memorycache.NewTTLCache(ctx, time.Hour, time.Minute)
 
+	decrypter, err := p.initializeSecretDecrypter(cfg)
+	if err != nil {
+		t.Logger.Error("failed to initialize secret decrypter", zap.Error(err))
+		return err
+	}
+
 	var liveStateGetter livestatestore.Getter
 	// Start running application live state store.
 	{
-		s := livestatestore.NewStore(cfg, applicationLister, p.gracePeriod, t.Logger)
+		s := livestatestore.NewStore(cfg, applicationLister, gitClient, decrypter, p.gracePeriod, t.Logger)
 		group.Go(func() error {
 			return s.Run(ctx)
 		})
@@ -307,12 +313,6 @@ func (p *piped) run(ctx context.Context, t cli.Telemetry) (runErr error) {
 		})
 	}
 
-	decrypter, err := p.initializeSecretDecrypter(cfg)
-	if err != nil {
-		t.Logger.Error("failed to initialize secret decrypter", zap.Error(err))
-		return err
-	}
-
 	// Start running application application drift detector.
 	{
 		d := driftdetector.NewDetector(
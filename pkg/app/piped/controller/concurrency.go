@@ -0,0 +1,176 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/pipe-cd/pipe/pkg/app/api/service/pipedservice"
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+// applyConcurrencyPolicies cancels the deployments superseded by the newest pending deployment
+// of each application based on the concurrency mode configured at that newest deployment.
+// - supersede: the older pending deployments are cancelled
+// - cancel-running: the running deployment is also cancelled (with rollback)
+// It returns the pending deployments those are still waiting to be planned.
+func (c *controller) applyConcurrencyPolicies(ctx context.Context, pendings []*model.Deployment) []*model.Deployment {
+	var (
+		targets   = make([]*model.Deployment, 0, len(pendings))
+		remaining = make([]*model.Deployment, 0, len(pendings))
+	)
+	for _, d := range pendings {
+		// Ignore already processed one.
+		if _, ok := c.donePlanners[d.Id]; ok {
+			continue
+		}
+		targets = append(targets, d)
+	}
+	latests := findLatestDeployments(targets)
+
+	for _, d := range targets {
+		latest := latests[d.ApplicationId]
+		if d.Id == latest.Id || concurrencyMode(latest) == config.DeploymentConcurrencyModeQueue {
+			remaining = append(remaining, d)
+			continue
+		}
+
+		// The superseded deployment is being planned,
+		// so it will be cancelled by its planner once the planning has finished.
+		if p, ok := c.planners[d.ApplicationId]; ok && p.ID() == d.Id {
+			p.Cancel(makeSupersedingCommand(d, latest))
+			remaining = append(remaining, d)
+			continue
+		}
+
+		if err := c.cancelSupersededDeployment(ctx, d, latest); err != nil {
+			remaining = append(remaining, d)
+			continue
+		}
+		c.donePlanners[d.Id] = time.Now()
+	}
+
+	for appID, latest := range latests {
+		if concurrencyMode(latest) != config.DeploymentConcurrencyModeCancelRunning {
+			continue
+		}
+		s, ok := c.schedulers[appID]
+		if !ok || s.IsDone() || !s.deployment.TriggerBefore(latest) {
+			continue
+		}
+		c.logger.Info("cancel the running deployment in favor of the newer deployment",
+			zap.String("app-id", appID),
+			zap.String("deployment-id", s.ID()),
+			zap.String("newer-deployment-id", latest.Id),
+		)
+		s.Cancel(makeSupersedingCommand(s.deployment, latest))
+	}
+
+	return remaining
+}
+
+// cancelSupersededDeployment marks the given pending deployment as cancelled
+// in favor of the newer one without planning it.
+// This is not retried since the same deployment will be checked again at the next sync.
+func (c *controller) cancelSupersededDeployment(ctx context.Context, d, newer *model.Deployment) error {
+	var (
+		reason = fmt.Sprintf("Superseded by the newer deployment %s triggered by commit %s", newer.Id, newer.CommitHash())
+		req    = &pipedservice.ReportDeploymentCompletedRequest{
+			DeploymentId: d.Id,
+			Status:       model.DeploymentStatus_DEPLOYMENT_CANCELLED,
+			StatusReason: reason,
+			CompletedAt:  time.Now().Unix(),
+		}
+		logger = c.logger.With(
+			zap.String("app-id", d.ApplicationId),
+			zap.String("deployment-id", d.Id),
+			zap.String("newer-deployment-id", newer.Id),
+		)
+	)
+
+	if _, err := c.apiClient.ReportDeploymentCompleted(ctx, req); err != nil {
+		logger.Error("failed to mark the superseded deployment to be cancelled", zap.Error(err))
+		return err
+	}
+	logger.Info("cancelled the pending deployment in favor of the newer deployment")
+
+	env, err := c.environmentLister.Get(ctx, d.EnvId)
+	if err != nil {
+		logger.Error("failed to get the environment of the cancelled deployment", zap.Error(err))
+		return nil
+	}
+	c.notifier.Notify(model.NotificationEvent{
+		Type: model.NotificationEventType_EVENT_DEPLOYMENT_CANCELLED,
+		Metadata: &model.NotificationEventDeploymentCancelled{
+			Deployment: d,
+			EnvName:    env.Name,
+			Commander:  supersedingCommander(newer),
+		},
+	})
+	return nil
+}
+
+// makeSupersedingCommand returns a command to cancel the given deployment in favor of the newer one.
+// This command is created by piped itself instead of being sent from the control-plane,
+// so reporting its result does nothing.
+func makeSupersedingCommand(d, newer *model.Deployment) model.ReportableCommand {
+	return model.ReportableCommand{
+		Command: &model.Command{
+			PipedId:       d.PipedId,
+			ApplicationId: d.ApplicationId,
+			DeploymentId:  d.Id,
+			ProjectId:     d.ProjectId,
+			Commander:     supersedingCommander(newer),
+			Type:          model.Command_CANCEL_DEPLOYMENT,
+			CancelDeployment: &model.Command_CancelDeployment{
+				DeploymentId: d.Id,
+			},
+		},
+		Report: func(_ context.Context, _ model.CommandStatus, _ map[string]string, _ []byte) error {
+			return nil
+		},
+	}
+}
+
+// supersedingCommander returns the name used as the commander of cancellations caused by the given deployment.
+// e.g. "Cancelled by the newer deployment xxx while executing stage yyy"
+func supersedingCommander(newer *model.Deployment) string {
+	return fmt.Sprintf("the newer deployment %s", newer.Id)
+}
+
+// concurrencyMode returns the concurrency mode recorded into the given deployment while triggering.
+func concurrencyMode(d *model.Deployment) config.DeploymentConcurrencyMode {
+	if m := d.Metadata[model.DeploymentConcurrencyModeMetadataKey]; m != "" {
+		return config.DeploymentConcurrencyMode(m)
+	}
+	return config.DeploymentConcurrencyModeQueue
+}
+
+// findLatestDeployments returns the most recently triggered deployment of each application.
+func findLatestDeployments(ds []*model.Deployment) map[string]*model.Deployment {
+	latests := make(map[string]*model.Deployment, len(ds))
+	for _, d := range ds {
+		if pre, ok := latests[d.ApplicationId]; ok && d.TriggerBefore(pre) {
+			continue
+		}
+		latests[d.ApplicationId] = d
+	}
+	return latests
+}
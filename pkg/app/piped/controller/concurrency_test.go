@@ -0,0 +1,178 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	"github.com/pipe-cd/pipe/pkg/app/api/service/pipedservice"
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+type fakeAPIClient struct {
+	apiClient
+	completed []*pipedservice.ReportDeploymentCompletedRequest
+}
+
+func (c *fakeAPIClient) ReportDeploymentCompleted(_ context.Context, req *pipedservice.ReportDeploymentCompletedRequest, _ ...grpc.CallOption) (*pipedservice.ReportDeploymentCompletedResponse, error) {
+	c.completed = append(c.completed, req)
+	return &pipedservice.ReportDeploymentCompletedResponse{}, nil
+}
+
+type fakeEnvironmentLister struct{}
+
+func (fakeEnvironmentLister) Get(_ context.Context, id string) (*model.Environment, error) {
+	return &model.Environment{Id: id, Name: id}, nil
+}
+
+type fakeNotifier struct {
+	events []model.NotificationEvent
+}
+
+func (n *fakeNotifier) Notify(event model.NotificationEvent) {
+	n.events = append(n.events, event)
+}
+
+func newTestDeployment(id, appID string, commitCreatedAt int64, mode config.DeploymentConcurrencyMode) *model.Deployment {
+	d := &model.Deployment{
+		Id:            id,
+		ApplicationId: appID,
+		EnvId:         "env",
+		Trigger: &model.DeploymentTrigger{
+			Commit: &model.Commit{
+				Hash:      "hash-" + id,
+				CreatedAt: commitCreatedAt,
+			},
+			Timestamp: commitCreatedAt,
+		},
+		Status: model.DeploymentStatus_DEPLOYMENT_PENDING,
+	}
+	if mode != "" {
+		d.Metadata = map[string]string{
+			model.DeploymentConcurrencyModeMetadataKey: string(mode),
+		}
+	}
+	return d
+}
+
+func TestConcurrencyMode(t *testing.T) {
+	d := newTestDeployment("d", "app", 1, "")
+	assert.Equal(t, config.DeploymentConcurrencyModeQueue, concurrencyMode(d))
+
+	d = newTestDeployment("d", "app", 1, config.DeploymentConcurrencyModeSupersede)
+	assert.Equal(t, config.DeploymentConcurrencyModeSupersede, concurrencyMode(d))
+}
+
+func TestFindLatestDeployments(t *testing.T) {
+	var (
+		d1 = newTestDeployment("d1", "app-1", 1, "")
+		d2 = newTestDeployment("d2", "app-1", 3, "")
+		d3 = newTestDeployment("d3", "app-1", 2, "")
+		d4 = newTestDeployment("d4", "app-2", 1, "")
+	)
+	got := findLatestDeployments([]*model.Deployment{d1, d2, d3, d4})
+	assert.Equal(t, map[string]*model.Deployment{
+		"app-1": d2,
+		"app-2": d4,
+	}, got)
+}
+
+func TestApplyConcurrencyPolicies(t *testing.T) {
+	testcases := []struct {
+		name              string
+		mode              config.DeploymentConcurrencyMode
+		expectedRemaining []string
+		expectedCancelled []string
+		expectedRunning   bool
+	}{
+		{
+			name:              "queue",
+			mode:              config.DeploymentConcurrencyModeQueue,
+			expectedRemaining: []string{"d1", "d2", "d3"},
+			expectedRunning:   true,
+		},
+		{
+			name:              "supersede",
+			mode:              config.DeploymentConcurrencyModeSupersede,
+			expectedRemaining: []string{"d3"},
+			expectedCancelled: []string{"d1", "d2"},
+			expectedRunning:   true,
+		},
+		{
+			name:              "cancel-running",
+			mode:              config.DeploymentConcurrencyModeCancelRunning,
+			expectedRemaining: []string{"d3"},
+			expectedCancelled: []string{"d1", "d2"},
+			expectedRunning:   false,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				apiClient = &fakeAPIClient{}
+				notifier  = &fakeNotifier{}
+				running   = &scheduler{
+					deployment:  newTestDeployment("d0", "app", 0, ""),
+					cancelledCh: make(chan *model.ReportableCommand, 1),
+				}
+				c = &controller{
+					apiClient:         apiClient,
+					environmentLister: fakeEnvironmentLister{},
+					notifier:          notifier,
+					planners:          make(map[string]*planner),
+					donePlanners:      make(map[string]time.Time),
+					schedulers:        map[string]*scheduler{"app": running},
+					logger:            zap.NewNop(),
+				}
+				pendings = []*model.Deployment{
+					newTestDeployment("d1", "app", 1, ""),
+					newTestDeployment("d2", "app", 2, ""),
+					newTestDeployment("d3", "app", 3, tc.mode),
+				}
+			)
+
+			remaining := c.applyConcurrencyPolicies(context.Background(), pendings)
+			remainingIDs := make([]string, 0, len(remaining))
+			for _, d := range remaining {
+				remainingIDs = append(remainingIDs, d.Id)
+			}
+			assert.Equal(t, tc.expectedRemaining, remainingIDs)
+
+			require.Equal(t, len(tc.expectedCancelled), len(apiClient.completed))
+			require.Equal(t, len(tc.expectedCancelled), len(notifier.events))
+			for i, id := range tc.expectedCancelled {
+				assert.Equal(t, id, apiClient.completed[i].DeploymentId)
+				assert.Equal(t, model.DeploymentStatus_DEPLOYMENT_CANCELLED, apiClient.completed[i].Status)
+				assert.Equal(t, "Superseded by the newer deployment d3 triggered by commit hash-d3", apiClient.completed[i].StatusReason)
+				assert.Contains(t, c.donePlanners, id)
+			}
+
+			assert.Equal(t, !tc.expectedRunning, running.cancelled)
+			if !tc.expectedRunning {
+				cmd := <-running.cancelledCh
+				assert.Equal(t, "the newer deployment d3", cmd.Commander)
+				assert.Equal(t, "d0", cmd.DeploymentId)
+			}
+		})
+	}
+}
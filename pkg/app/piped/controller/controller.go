@@ -50,6 +50,8 @@ type apiClient interface {
 	ReportDeploymentCompleted(ctx context.Context, req *pipedservice.ReportDeploymentCompletedRequest, opts ...grpc.CallOption) (*pipedservice.ReportDeploymentCompletedResponse, error)
 	SaveDeploymentMetadata(ctx context.Context, req *pipedservice.SaveDeploymentMetadataRequest, opts ...grpc.CallOption) (*pipedservice.SaveDeploymentMetadataResponse, error)
 	ReportApplicationMostRecentDeployment(ctx context.Context, req *pipedservice.ReportApplicationMostRecentDeploymentRequest, opts ...grpc.CallOption) (*pipedservice.ReportApplicationMostRecentDeploymentResponse, error)
+	ListDeploymentFreezes(ctx context.Context, req *pipedservice.ListDeploymentFreezesRequest, opts ...grpc.CallOption) (*pipedservice.ListDeploymentFreezesResponse, error)
+	ReportPromotion(ctx context.Context, req *pipedservice.ReportPromotionRequest, opts ...grpc.CallOption) (*pipedservice.ReportPromotionResponse, error)
 
 	ReportStageStatusChanged(ctx context.Context, req *pipedservice.ReportStageStatusChangedRequest, opts ...grpc.CallOption) (*pipedservice.ReportStageStatusChangedResponse, error)
 	SaveStageMetadata(ctx context.Context, req *pipedservice.SaveStageMetadataRequest, opts ...grpc.CallOption) (*pipedservice.SaveStageMetadataResponse, error)
@@ -74,6 +76,7 @@ type commandLister interface {
 
 type applicationLister interface {
 	Get(id string) (*model.Application, bool)
+	List() []*model.Application
 }
 
 type environmentLister interface {
@@ -229,7 +232,7 @@ L:
 			// after piped is restarted all running deployments need to be loaded firstly.
 			c.syncSchedulers(ctx)
 			c.syncPlanners(ctx)
-			c.checkCommands()
+			c.checkCommands(ctx)
 		}
 	}
 
@@ -246,9 +249,31 @@ L:
 
 // checkCommands lists all unhandled commands for running deployments
 // and forwards them to their planners and schedulers.
-func (c *controller) checkCommands() {
+func (c *controller) checkCommands(ctx context.Context) {
 	commands := c.commandLister.ListDeploymentCommands()
 	for _, cmd := range commands {
+		if cmd.GetRetryStage() != nil {
+			c.retryDeployment(ctx, cmd)
+			continue
+		}
+		if cmd.GetOverrideDeploymentWindow() != nil {
+			if scheduler, ok := c.schedulers[cmd.ApplicationId]; ok && scheduler.ID() == cmd.DeploymentId {
+				scheduler.OverrideDeploymentWindow(ctx, cmd)
+				c.logger.Info("a command OverrideDeploymentWindow was forwarded to its scheduler",
+					zap.String("app-id", cmd.ApplicationId),
+					zap.String("deployment-id", cmd.DeploymentId),
+				)
+				continue
+			}
+			// Only the running scheduler can hold the deployment,
+			// e.g. the deployment being planned or already completed is not held.
+			reportNotHeldOverrideCommand(ctx, &cmd, c.logger)
+			c.logger.Info("a command OverrideDeploymentWindow was sent to a deployment that is not held",
+				zap.String("app-id", cmd.ApplicationId),
+				zap.String("deployment-id", cmd.DeploymentId),
+			)
+			continue
+		}
 		if cmd.GetCancelDeployment() == nil {
 			continue
 		}
@@ -281,6 +306,45 @@ func (c *controller) checkCommands() {
 	}
 }
 
+// retryDeployment makes the failed deployment specified by the given RETRY_STAGE command
+// be able to be scheduled again. The control-plane has already brought that deployment back
+// to RUNNING from the failed stage, so we just need to forget its done scheduler.
+func (c *controller) retryDeployment(ctx context.Context, cmd model.ReportableCommand) {
+	logger := c.logger.With(
+		zap.String("app-id", cmd.ApplicationId),
+		zap.String("deployment-id", cmd.DeploymentId),
+		zap.String("stage-id", cmd.StageId),
+	)
+
+	if s, ok := c.schedulers[cmd.ApplicationId]; ok {
+		// The scheduler of the failed deployment has not been removed yet,
+		// wait until the next check to not mark it as done again.
+		if s.ID() == cmd.DeploymentId && s.IsDone() {
+			return
+		}
+		if s.ID() != cmd.DeploymentId {
+			logger.Warn("unable to retry the deployment because the application has another running deployment")
+			if err := cmd.Report(ctx, model.CommandStatus_COMMAND_FAILED, nil, nil); err != nil {
+				logger.Error("failed to report command status", zap.Error(err))
+			}
+			return
+		}
+	}
+
+	delete(c.doneSchedulers, cmd.DeploymentId)
+
+	// Application will be marked as DEPLOYING again while the retried deployment is running.
+	if err := reportApplicationDeployingStatus(ctx, c.apiClient, cmd.ApplicationId, true); err != nil {
+		logger.Error("failed to mark application as deploying", zap.Error(err))
+	}
+
+	if err := cmd.Report(ctx, model.CommandStatus_COMMAND_SUCCEEDED, nil, nil); err != nil {
+		logger.Error("failed to report command status", zap.Error(err))
+		return
+	}
+	logger.Info("a command RetryStage was handled, the deployment will be scheduled again")
+}
+
 // syncPlanners adds new planner for newly PENDING deployments.
 func (c *controller) syncPlanners(ctx context.Context) error {
 	// Remove stale planners from the recently completed list.
@@ -321,6 +385,12 @@ func (c *controller) syncPlanners(ctx context.Context) error {
 		return nil
 	}
 
+	// Cancel the deployments superseded by the newer ones of the same application.
+	pendings = c.applyConcurrencyPolicies(ctx, pendings)
+	if len(pendings) == 0 {
+		return nil
+	}
+
 	c.logger.Info(fmt.Sprintf("there are %d pending deployments for planning", len(pendings)),
 		zap.Int("count", len(c.planners)),
 	)
@@ -565,6 +635,7 @@ func (c *controller) startNewScheduler(ctx context.Context, d *model.Deployment)
 		c.gitClient,
 		c.commandLister,
 		c.applicationLister,
+		c.deploymentLister,
 		c.liveResourceLister,
 		c.logPersister,
 		c.notifier,
@@ -631,6 +702,10 @@ func (l appLiveResourceLister) ListKubernetesResources() ([]provider.Manifest, b
 	return l.lister.ListKubernetesAppLiveResources(l.cloudProvider, l.appID)
 }
 
+func (l appLiveResourceLister) ListKubernetesResourcesInCloudProvider(cloudProvider string) ([]provider.Manifest, bool) {
+	return l.lister.ListKubernetesAppLiveResources(cloudProvider, l.appID)
+}
+
 func reportApplicationDeployingStatus(ctx context.Context, c apiClient, appID string, deploying bool) error {
 	var (
 		err   error
@@ -0,0 +1,298 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/pipe-cd/pipe/pkg/app/api/service/pipedservice"
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+const dependencyCheckInterval = 15 * time.Second
+
+// waitForDependencies holds the deployment until the deployments of the applications
+// it depends on for the same or a later commit have been completed successfully.
+// The returned boolean is false when the deployment should not be continued,
+// in that case the deployment should be completed with the returned status and reason,
+// and the returned command is the one cancelled the deployment if any.
+// The returned reason is empty when the scheduler was terminated.
+func (s *scheduler) waitForDependencies(ctx context.Context, repoDir string, timeout <-chan time.Time) (*model.ReportableCommand, model.DeploymentStatus, string, bool) {
+	if len(s.genericDeploymentConfig.DependsOn) == 0 {
+		return nil, model.DeploymentStatus_DEPLOYMENT_SUCCESS, "", true
+	}
+
+	if err := s.validateDependencies(repoDir); err != nil {
+		return nil, model.DeploymentStatus_DEPLOYMENT_FAILURE, fmt.Sprintf("Invalid dependencies: %v", err), false
+	}
+
+	ticker := time.NewTicker(dependencyCheckInterval)
+	defer ticker.Stop()
+
+	var (
+		lastReason string
+		rechecking bool
+	)
+	for {
+		holdReason, st, reason := s.checkDependencies(ctx)
+		// The completion of a deployment is reported before it is recorded as the most recently
+		// successful one of its application, so a dependency deployment that has just succeeded
+		// might look like it was not completed successfully. Check again after the next interval
+		// to confirm that before cancelling.
+		if st == model.DeploymentStatus_DEPLOYMENT_CANCELLED && reason != "" && !rechecking {
+			holdReason, reason = fmt.Sprintf("Checking the dependencies again before cancelling: %s", reason), ""
+			rechecking = true
+		} else {
+			rechecking = false
+		}
+		if reason != "" {
+			s.logger.Info("the dependencies of the deployment could not be satisfied", zap.String("reason", reason))
+			return nil, st, reason, false
+		}
+		if holdReason == "" {
+			if lastReason != "" {
+				s.logger.Info("the dependencies of the deployment have been deployed")
+				s.setWaitingFor(ctx, nil)
+				if err := s.reportDeploymentStatusChanged(ctx, model.DeploymentStatus_DEPLOYMENT_RUNNING, "The dependencies have been deployed"); err != nil {
+					s.logger.Error("failed to report deployment status", zap.Error(err))
+				}
+			}
+			return nil, model.DeploymentStatus_DEPLOYMENT_SUCCESS, "", true
+		}
+
+		if lastReason == "" {
+			s.setWaitingFor(ctx, s.genericDeploymentConfig.DependsOn)
+		}
+		if holdReason != lastReason {
+			s.logger.Info("the deployment is waiting for its dependencies", zap.String("reason", holdReason))
+			if err := s.reportDeploymentStatusChanged(ctx, model.DeploymentStatus_DEPLOYMENT_RUNNING, fmt.Sprintf("Deployment is on hold: %s", holdReason)); err != nil {
+				s.logger.Error("failed to report deployment status", zap.Error(err))
+			}
+			lastReason = holdReason
+		}
+
+		select {
+		case <-ticker.C:
+
+		case <-timeout:
+			return nil, model.DeploymentStatus_DEPLOYMENT_FAILURE, fmt.Sprintf("Timed out while waiting for the dependencies (%s)", lastReason), false
+
+		case cmd := <-s.cancelledCh:
+			if cmd != nil {
+				return cmd, model.DeploymentStatus_DEPLOYMENT_CANCELLED, fmt.Sprintf("Cancelled by %s while waiting for the dependencies", cmd.Commander), false
+			}
+
+		case <-ctx.Done():
+			return nil, model.DeploymentStatus_DEPLOYMENT_CANCELLED, "", false
+		}
+	}
+}
+
+// validateDependencies checks that all dependencies are managed by this piped and
+// the application does not depend on itself directly or through the dependencies
+// configured at the target commit of the other applications in the same environment.
+func (s *scheduler) validateDependencies(repoDir string) error {
+	app, ok := s.applicationLister.Get(s.deployment.ApplicationId)
+	if !ok {
+		return fmt.Errorf("application %s was not found", s.deployment.ApplicationId)
+	}
+
+	// The deployments of the applications managed by the other pipeds
+	// are not visible from this piped so they can not be waited for.
+	for _, name := range s.genericDeploymentConfig.DependsOn {
+		if name == app.Name {
+			continue
+		}
+		if _, ok := s.findApplication(name); !ok {
+			return fmt.Errorf("dependency application %s was not found in the same environment managed by this piped", name)
+		}
+	}
+
+	dependsOn := map[string][]string{
+		app.Name: s.genericDeploymentConfig.DependsOn,
+	}
+	for _, a := range s.applicationLister.List() {
+		if a.Id == app.Id || a.EnvId != app.EnvId || a.GitPath.Repo.Id != app.GitPath.Repo.Id {
+			continue
+		}
+		cfg, err := config.LoadFromYAML(filepath.Join(repoDir, a.GitPath.GetDeploymentConfigFilePath()))
+		if err != nil {
+			continue
+		}
+		if spec, ok := cfg.GetGenericDeployment(); ok {
+			dependsOn[a.Name] = spec.DependsOn
+		}
+	}
+
+	return config.ValidateDependsOn(app.Name, dependsOn)
+}
+
+// setWaitingFor saves the IDs of the applications the deployment is waiting for
+// to let the deployments of those applications know they must not wait for this one.
+func (s *scheduler) setWaitingFor(ctx context.Context, names []string) {
+	ids := make([]string, 0, len(names))
+	for _, name := range names {
+		if app, ok := s.findApplication(name); ok {
+			ids = append(ids, app.Id)
+		}
+	}
+	if err := s.metadataStore.Set(ctx, model.DeploymentWaitingForMetadataKey, strings.Join(ids, ",")); err != nil {
+		s.logger.Error("failed to save the applications the deployment is waiting for", zap.Error(err))
+	}
+}
+
+func (s *scheduler) checkDependencies(ctx context.Context) (holdReason string, completedStatus model.DeploymentStatus, completedReason string) {
+	var inProgress []*model.Deployment
+	inProgress = append(inProgress, s.deploymentLister.ListPendings()...)
+	inProgress = append(inProgress, s.deploymentLister.ListPlanneds()...)
+	inProgress = append(inProgress, s.deploymentLister.ListRunnings()...)
+
+	for _, name := range s.genericDeploymentConfig.DependsOn {
+		app, ok := s.findApplication(name)
+		if !ok {
+			return "", model.DeploymentStatus_DEPLOYMENT_FAILURE, fmt.Sprintf("Dependency application %s was not found in the same environment", name)
+		}
+
+		succeeded, err := s.getApplicationMostRecentDeployment(ctx, app.Id, model.DeploymentStatus_DEPLOYMENT_SUCCESS)
+		if err != nil {
+			s.logger.Error("failed to get the most recently successful deployment", zap.String("dependency", app.Id), zap.Error(err))
+			return fmt.Sprintf("Unable to check the deployments of dependency application %s", name), completedStatus, ""
+		}
+		triggered, err := s.getApplicationMostRecentDeployment(ctx, app.Id, model.DeploymentStatus_DEPLOYMENT_PENDING)
+		if err != nil {
+			s.logger.Error("failed to get the most recently triggered deployment", zap.String("dependency", app.Id), zap.Error(err))
+			return fmt.Sprintf("Unable to check the deployments of dependency application %s", name), completedStatus, ""
+		}
+
+		holdReason, completedStatus, completedReason = checkDependency(s.deployment, app, inProgress, succeeded, triggered)
+		if holdReason != "" || completedReason != "" {
+			return
+		}
+	}
+	return "", completedStatus, ""
+}
+
+// checkDependency decides whether the given deployment can be started
+// by looking at the deployments of one of the applications it depends on.
+// The deployment should be held while the returned hold reason is not empty,
+// and should be completed with the returned status when the returned reason is not empty.
+func checkDependency(d *model.Deployment, dependency *model.Application, inProgress []*model.Deployment, succeeded, triggered *model.ApplicationDeploymentReference) (holdReason string, completedStatus model.DeploymentStatus, completedReason string) {
+	// The dependency has already been deployed.
+	if succeeded != nil && isSameOrLaterCommit(succeeded.Trigger.Commit, d.Trigger.Commit) {
+		return "", completedStatus, ""
+	}
+
+	for _, ipd := range inProgress {
+		if ipd.ApplicationId != dependency.Id {
+			continue
+		}
+		// Do not wait for the deployment that is waiting for this application
+		// directly or through the other deployments since it would never be completed.
+		if isWaitingFor(ipd.ApplicationId, d.ApplicationId, inProgress) {
+			return "", model.DeploymentStatus_DEPLOYMENT_FAILURE, fmt.Sprintf("Circular dependency: the deployment %s of dependency application %s is waiting for this application", ipd.Id, dependency.Name)
+		}
+		return fmt.Sprintf("Waiting for the deployment %s of dependency application %s", ipd.Id, dependency.Name), completedStatus, ""
+	}
+
+	// The dependency was triggered for the same or a later commit
+	// but its deployment was not completed successfully.
+	if triggered != nil && isSameOrLaterCommit(triggered.Trigger.Commit, d.Trigger.Commit) {
+		return "", model.DeploymentStatus_DEPLOYMENT_CANCELLED, fmt.Sprintf("Cancelled because the deployment %s of dependency application %s was not completed successfully", triggered.DeploymentId, dependency.Name)
+	}
+
+	// Nothing to wait because the dependency was not changed by the commit.
+	return "", completedStatus, ""
+}
+
+// isWaitingFor reports whether any in-progress deployment of the given application
+// is waiting for the target application directly or through the other in-progress deployments.
+func isWaitingFor(appID, targetAppID string, inProgress []*model.Deployment) bool {
+	waitingFor := make(map[string][]string, len(inProgress))
+	for _, d := range inProgress {
+		if v := d.Metadata[model.DeploymentWaitingForMetadataKey]; v != "" {
+			waitingFor[d.ApplicationId] = append(waitingFor[d.ApplicationId], strings.Split(v, ",")...)
+		}
+	}
+
+	var (
+		visited = make(map[string]struct{})
+		visit   func(id string) bool
+	)
+	visit = func(id string) bool {
+		if _, ok := visited[id]; ok {
+			return false
+		}
+		visited[id] = struct{}{}
+		for _, w := range waitingFor[id] {
+			if w == targetAppID || visit(w) {
+				return true
+			}
+		}
+		return false
+	}
+	return visit(appID)
+}
+
+// isSameOrLaterCommit reports whether the given commit is the same as or was created after the base one.
+func isSameOrLaterCommit(c, base *model.Commit) bool {
+	if c == nil || base == nil {
+		return false
+	}
+	return c.Hash == base.Hash || c.CreatedAt > base.CreatedAt
+}
+
+func (s *scheduler) findApplication(name string) (*model.Application, bool) {
+	for _, app := range s.applicationLister.List() {
+		if app.Name == name && app.EnvId == s.deployment.EnvId {
+			return app, true
+		}
+	}
+	return nil, false
+}
+
+func (s *scheduler) getApplicationMostRecentDeployment(ctx context.Context, applicationID string, st model.DeploymentStatus) (*model.ApplicationDeploymentReference, error) {
+	var (
+		err   error
+		resp  *pipedservice.GetApplicationMostRecentDeploymentResponse
+		retry = pipedservice.NewRetry(3)
+		req   = &pipedservice.GetApplicationMostRecentDeploymentRequest{
+			ApplicationId: applicationID,
+			Status:        st,
+		}
+	)
+
+	for retry.WaitNext(ctx) {
+		resp, err = s.apiClient.GetApplicationMostRecentDeployment(ctx, req)
+		if err == nil {
+			return resp.Deployment, nil
+		}
+		if status.Code(err) == codes.NotFound {
+			return nil, nil
+		}
+		if !pipedservice.Retriable(err) {
+			return nil, err
+		}
+	}
+	return nil, err
+}
@@ -0,0 +1,324 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/pipe-cd/pipe/pkg/app/api/service/pipedservice"
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+func TestCheckDependency(t *testing.T) {
+	var (
+		dependency = &model.Application{Id: "backend-id", Name: "backend"}
+		deployment = &model.Deployment{
+			Id:            "frontend-deployment",
+			ApplicationId: "frontend-id",
+			Trigger: &model.DeploymentTrigger{
+				Commit: &model.Commit{Hash: "commit-2", CreatedAt: 200},
+			},
+		}
+		makeRef = func(id, hash string, createdAt int64) *model.ApplicationDeploymentReference {
+			return &model.ApplicationDeploymentReference{
+				DeploymentId: id,
+				Trigger: &model.DeploymentTrigger{
+					Commit: &model.Commit{Hash: hash, CreatedAt: createdAt},
+				},
+			}
+		}
+	)
+
+	testcases := []struct {
+		name               string
+		inProgress         []*model.Deployment
+		succeeded          *model.ApplicationDeploymentReference
+		triggered          *model.ApplicationDeploymentReference
+		expectedHoldReason string
+		expectedStatus     model.DeploymentStatus
+		expectedReason     string
+	}{
+		{
+			name: "dependency has never been deployed",
+		},
+		{
+			name:      "dependency was not changed by the commit",
+			succeeded: makeRef("backend-1", "commit-1", 100),
+			triggered: makeRef("backend-1", "commit-1", 100),
+		},
+		{
+			name:      "dependency was deployed at the same commit",
+			succeeded: makeRef("backend-2", "commit-2", 200),
+			triggered: makeRef("backend-2", "commit-2", 200),
+		},
+		{
+			name:      "dependency was deployed at a later commit",
+			succeeded: makeRef("backend-3", "commit-3", 300),
+			triggered: makeRef("backend-3", "commit-3", 300),
+			inProgress: []*model.Deployment{
+				{Id: "backend-4", ApplicationId: "backend-id"},
+			},
+		},
+		{
+			name:      "dependency is being deployed",
+			succeeded: makeRef("backend-1", "commit-1", 100),
+			triggered: makeRef("backend-2", "commit-2", 200),
+			inProgress: []*model.Deployment{
+				{Id: "other-1", ApplicationId: "other-id"},
+				{Id: "backend-2", ApplicationId: "backend-id"},
+			},
+			expectedHoldReason: "Waiting for the deployment backend-2 of dependency application backend",
+		},
+		{
+			name:           "dependency deployment was not successful",
+			succeeded:      makeRef("backend-1", "commit-1", 100),
+			triggered:      makeRef("backend-2", "commit-2", 200),
+			expectedStatus: model.DeploymentStatus_DEPLOYMENT_CANCELLED,
+			expectedReason: "Cancelled because the deployment backend-2 of dependency application backend was not completed successfully",
+		},
+		{
+			name:      "dependency is waiting for this application",
+			succeeded: makeRef("backend-1", "commit-1", 100),
+			triggered: makeRef("backend-2", "commit-2", 200),
+			inProgress: []*model.Deployment{
+				{
+					Id:            "backend-2",
+					ApplicationId: "backend-id",
+					Metadata: map[string]string{
+						model.DeploymentWaitingForMetadataKey: "frontend-id",
+					},
+				},
+			},
+			expectedStatus: model.DeploymentStatus_DEPLOYMENT_FAILURE,
+			expectedReason: "Circular dependency: the deployment backend-2 of dependency application backend is waiting for this application",
+		},
+		{
+			name:      "dependency is waiting for this application through another one",
+			succeeded: makeRef("backend-1", "commit-1", 100),
+			triggered: makeRef("backend-2", "commit-2", 200),
+			inProgress: []*model.Deployment{
+				{
+					Id:            "backend-2",
+					ApplicationId: "backend-id",
+					Metadata: map[string]string{
+						model.DeploymentWaitingForMetadataKey: "migration-id",
+					},
+				},
+				{
+					Id:            "migration-2",
+					ApplicationId: "migration-id",
+					Metadata: map[string]string{
+						model.DeploymentWaitingForMetadataKey: "database-id,frontend-id",
+					},
+				},
+			},
+			expectedStatus: model.DeploymentStatus_DEPLOYMENT_FAILURE,
+			expectedReason: "Circular dependency: the deployment backend-2 of dependency application backend is waiting for this application",
+		},
+		{
+			name:      "dependency is waiting for another application",
+			succeeded: makeRef("backend-1", "commit-1", 100),
+			triggered: makeRef("backend-2", "commit-2", 200),
+			inProgress: []*model.Deployment{
+				{
+					Id:            "backend-2",
+					ApplicationId: "backend-id",
+					Metadata: map[string]string{
+						model.DeploymentWaitingForMetadataKey: "migration-id",
+					},
+				},
+			},
+			expectedHoldReason: "Waiting for the deployment backend-2 of dependency application backend",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			holdReason, status, reason := checkDependency(deployment, dependency, tc.inProgress, tc.succeeded, tc.triggered)
+			assert.Equal(t, tc.expectedHoldReason, holdReason)
+			assert.Equal(t, tc.expectedStatus, status)
+			assert.Equal(t, tc.expectedReason, reason)
+		})
+	}
+}
+
+type fakeDependencyAPIClient struct {
+	fakeAPIClient
+	triggered *model.ApplicationDeploymentReference
+}
+
+func (c *fakeDependencyAPIClient) GetApplicationMostRecentDeployment(_ context.Context, req *pipedservice.GetApplicationMostRecentDeploymentRequest, _ ...grpc.CallOption) (*pipedservice.GetApplicationMostRecentDeploymentResponse, error) {
+	if req.Status == model.DeploymentStatus_DEPLOYMENT_PENDING && c.triggered != nil {
+		return &pipedservice.GetApplicationMostRecentDeploymentResponse{Deployment: c.triggered}, nil
+	}
+	return nil, status.Error(codes.NotFound, "deployment is not found")
+}
+
+func (c *fakeDependencyAPIClient) SaveDeploymentMetadata(_ context.Context, _ *pipedservice.SaveDeploymentMetadataRequest, _ ...grpc.CallOption) (*pipedservice.SaveDeploymentMetadataResponse, error) {
+	return &pipedservice.SaveDeploymentMetadataResponse{}, nil
+}
+
+func (c *fakeDependencyAPIClient) ReportDeploymentStatusChanged(_ context.Context, _ *pipedservice.ReportDeploymentStatusChangedRequest, _ ...grpc.CallOption) (*pipedservice.ReportDeploymentStatusChangedResponse, error) {
+	return &pipedservice.ReportDeploymentStatusChangedResponse{}, nil
+}
+
+type fakeApplicationLister []*model.Application
+
+func (l fakeApplicationLister) Get(id string) (*model.Application, bool) {
+	for _, app := range l {
+		if app.Id == id {
+			return app, true
+		}
+	}
+	return nil, false
+}
+
+func (l fakeApplicationLister) List() []*model.Application {
+	return l
+}
+
+type fakeDeploymentLister struct {
+	pendings []*model.Deployment
+}
+
+func (l fakeDeploymentLister) ListPendings() []*model.Deployment {
+	return l.pendings
+}
+
+func (l fakeDeploymentLister) ListPlanneds() []*model.Deployment {
+	return nil
+}
+
+func (l fakeDeploymentLister) ListRunnings() []*model.Deployment {
+	return nil
+}
+
+func TestWaitForDependencies(t *testing.T) {
+	var (
+		repo      = &model.ApplicationGitRepository{Id: "repo"}
+		frontend  = &model.Application{Id: "frontend-id", Name: "frontend", EnvId: "env", GitPath: &model.ApplicationGitPath{Repo: repo, Path: "frontend"}}
+		backend   = &model.Application{Id: "backend-id", Name: "backend", EnvId: "env", GitPath: &model.ApplicationGitPath{Repo: repo, Path: "backend"}}
+		pendingBE = &model.Deployment{Id: "backend-deployment", ApplicationId: backend.Id}
+	)
+
+	testcases := []struct {
+		name           string
+		dependsOn      []string
+		backendConfig  string
+		pendings       []*model.Deployment
+		triggered      *model.ApplicationDeploymentReference
+		expectedStatus model.DeploymentStatus
+		expectedReason string
+	}{
+		{
+			name:           "self reference",
+			dependsOn:      []string{"frontend"},
+			expectedStatus: model.DeploymentStatus_DEPLOYMENT_FAILURE,
+			expectedReason: "Invalid dependencies: application frontend must not depend on itself",
+		},
+		{
+			name:           "dependency managed by another piped",
+			dependsOn:      []string{"database"},
+			expectedStatus: model.DeploymentStatus_DEPLOYMENT_FAILURE,
+			expectedReason: "Invalid dependencies: dependency application database was not found in the same environment managed by this piped",
+		},
+		{
+			name:      "circular dependency",
+			dependsOn: []string{"backend"},
+			backendConfig: `spec:
+  dependsOn: [frontend]`,
+			expectedStatus: model.DeploymentStatus_DEPLOYMENT_FAILURE,
+			expectedReason: "Invalid dependencies: circular dependency frontend -> backend -> frontend",
+		},
+		{
+			name:           "timed out",
+			dependsOn:      []string{"backend"},
+			backendConfig:  "spec: {}",
+			pendings:       []*model.Deployment{pendingBE},
+			expectedStatus: model.DeploymentStatus_DEPLOYMENT_FAILURE,
+			expectedReason: "Timed out while waiting for the dependencies (Waiting for the deployment backend-deployment of dependency application backend)",
+		},
+		{
+			name:          "not cancelled before checking again",
+			dependsOn:     []string{"backend"},
+			backendConfig: "spec: {}",
+			triggered: &model.ApplicationDeploymentReference{
+				DeploymentId: "backend-deployment",
+				Trigger: &model.DeploymentTrigger{
+					Commit: &model.Commit{Hash: "commit", CreatedAt: 100},
+				},
+			},
+			expectedStatus: model.DeploymentStatus_DEPLOYMENT_FAILURE,
+			expectedReason: "Timed out while waiting for the dependencies (Checking the dependencies again before cancelling: Cancelled because the deployment backend-deployment of dependency application backend was not completed successfully)",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			repoDir, err := ioutil.TempDir("", "dependency")
+			require.NoError(t, err)
+			defer os.RemoveAll(repoDir)
+
+			if tc.backendConfig != "" {
+				require.NoError(t, os.MkdirAll(filepath.Join(repoDir, "backend"), 0755))
+				cfg := "apiVersion: pipecd.dev/v1beta1\nkind: KubernetesApp\n" + tc.backendConfig
+				require.NoError(t, ioutil.WriteFile(filepath.Join(repoDir, "backend", model.DefaultDeploymentConfigFileName), []byte(cfg), 0644))
+			}
+
+			var (
+				apiClient = &fakeDependencyAPIClient{triggered: tc.triggered}
+				d         = &model.Deployment{
+					Id:            "frontend-deployment",
+					ApplicationId: frontend.Id,
+					EnvId:         "env",
+					Trigger: &model.DeploymentTrigger{
+						Commit: &model.Commit{Hash: "commit", CreatedAt: 100},
+					},
+				}
+				s = &scheduler{
+					deployment:        d,
+					apiClient:         apiClient,
+					applicationLister: fakeApplicationLister{frontend, backend},
+					deploymentLister:  fakeDeploymentLister{pendings: tc.pendings},
+					metadataStore:     NewMetadataStore(apiClient, d),
+					genericDeploymentConfig: config.GenericDeploymentSpec{
+						DependsOn: tc.dependsOn,
+					},
+					cancelledCh: make(chan *model.ReportableCommand, 1),
+					logger:      zap.NewNop(),
+				}
+				timeout = make(chan time.Time, 1)
+			)
+			timeout <- time.Now()
+
+			cmd, status, reason, ok := s.waitForDependencies(context.Background(), repoDir, timeout)
+			assert.False(t, ok)
+			assert.Nil(t, cmd)
+			assert.Equal(t, tc.expectedStatus, status)
+			assert.Equal(t, tc.expectedReason, reason)
+		})
+	}
+}
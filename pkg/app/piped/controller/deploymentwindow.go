@@ -0,0 +1,142 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/pipe-cd/pipe/pkg/app/api/service/pipedservice"
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+const deploymentWindowCheckInterval = time.Minute
+
+// waitForDeploymentWindow holds the deployment until it is allowed by the deployment windows
+// of the application and the deployment freezes of the project, or an admin overrides them.
+// The returned boolean is false when the deployment should not be continued,
+// the returned command is the one cancelled the deployment in that case.
+func (s *scheduler) waitForDeploymentWindow(ctx context.Context) (*model.ReportableCommand, bool) {
+	ticker := time.NewTicker(deploymentWindowCheckInterval)
+	defer ticker.Stop()
+
+	var lastReason string
+	for {
+		reason, held := s.findHoldReason(ctx)
+		if !held {
+			if lastReason != "" {
+				s.logger.Info("the deployment window has opened")
+				if err := s.reportDeploymentStatusChanged(ctx, model.DeploymentStatus_DEPLOYMENT_RUNNING, "The deployment window has opened"); err != nil {
+					s.logger.Error("failed to report deployment status", zap.Error(err))
+				}
+			}
+			return nil, true
+		}
+
+		if reason != lastReason {
+			s.logger.Info("the deployment is on hold", zap.String("reason", reason))
+			if err := s.reportDeploymentStatusChanged(ctx, model.DeploymentStatus_DEPLOYMENT_RUNNING, fmt.Sprintf("Deployment is on hold: %s", reason)); err != nil {
+				s.logger.Error("failed to report deployment status", zap.Error(err))
+			}
+			lastReason = reason
+		}
+
+		select {
+		case <-ticker.C:
+
+		case cmd := <-s.windowOverriddenCh:
+			s.logger.Info("the deployment window was overridden", zap.String("commander", cmd.Commander))
+			if err := s.reportDeploymentStatusChanged(ctx, model.DeploymentStatus_DEPLOYMENT_RUNNING, fmt.Sprintf("The deployment window was overridden by %s", cmd.Commander)); err != nil {
+				s.logger.Error("failed to report deployment status", zap.Error(err))
+			}
+			if err := cmd.Report(ctx, model.CommandStatus_COMMAND_SUCCEEDED, nil, nil); err != nil {
+				s.logger.Error("failed to report command status", zap.Error(err))
+			}
+			return nil, true
+
+		case cmd := <-s.cancelledCh:
+			if cmd != nil {
+				return cmd, false
+			}
+
+		case <-ctx.Done():
+			return nil, false
+		}
+	}
+}
+
+func (s *scheduler) findHoldReason(ctx context.Context) (string, bool) {
+	resp, err := s.apiClient.ListDeploymentFreezes(ctx, &pipedservice.ListDeploymentFreezesRequest{})
+	if err != nil {
+		s.logger.Error("failed to list deployment freezes", zap.Error(err))
+		return "Unable to check the deployment freezes", true
+	}
+	return findDeploymentHoldReason(s.genericDeploymentConfig.DeploymentWindows, resp.Freezes, s.deployment.ApplicationId, s.nowFunc())
+}
+
+// closeWindowOverride is called when the deployment is no longer held by the deployment windows.
+// It reports the unused OverrideDeploymentWindow command and makes the ones
+// forwarded after this be reported right away.
+func (s *scheduler) closeWindowOverride(ctx context.Context) {
+	s.windowOverrideMu.Lock()
+	defer s.windowOverrideMu.Unlock()
+
+	if s.windowOverrideClosed {
+		return
+	}
+	s.windowOverrideClosed = true
+
+	select {
+	case cmd := <-s.windowOverriddenCh:
+		reportNotHeldOverrideCommand(ctx, cmd, s.logger)
+	default:
+	}
+}
+
+// reportNotHeldOverrideCommand marks the OverrideDeploymentWindow command
+// sent to a not held deployment as failed.
+func reportNotHeldOverrideCommand(ctx context.Context, cmd *model.ReportableCommand, logger *zap.Logger) {
+	if err := cmd.Report(ctx, model.CommandStatus_COMMAND_FAILED, nil, []byte("deployment is not held")); err != nil {
+		logger.Error("failed to report command status", zap.Error(err))
+	}
+}
+
+// findDeploymentHoldReason returns the reason why the deployment of the given application
+// is not allowed to run at the given time.
+func findDeploymentHoldReason(windows *config.DeploymentWindows, freezes []*model.DeploymentFreeze, appID string, now time.Time) (string, bool) {
+	for _, f := range freezes {
+		if !f.AppliesTo(appID) || !f.IsActive(now) {
+			continue
+		}
+		return fmt.Sprintf("%s (frozen until %s)", f.Reason, time.Unix(f.EndedAt, 0).UTC().Format(time.RFC3339)), true
+	}
+	if windows != nil {
+		return windows.HoldReason(now)
+	}
+	return "", false
+}
+
+func hasStartedStage(d *model.Deployment) bool {
+	for _, s := range d.Stages {
+		if s.Status != model.StageStatus_STAGE_NOT_STARTED_YET {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+func TestFindDeploymentHoldReason(t *testing.T) {
+	now := time.Date(2021, 6, 4, 18, 0, 0, 0, time.UTC)
+	windows := &config.DeploymentWindows{
+		Deny: []config.DeploymentWindow{
+			{
+				Cron:     "0 17 * * FRI",
+				Duration: config.Duration(64 * time.Hour),
+				Reason:   "No deployment on weekends",
+			},
+		},
+	}
+	freezes := []*model.DeploymentFreeze{
+		{
+			ApplicationId: "app-2",
+			Reason:        "Migrating database",
+			StartedAt:     now.Add(-time.Hour).Unix(),
+			EndedAt:       now.Add(time.Hour).Unix(),
+		},
+		{
+			Reason:    "Year-end holidays",
+			StartedAt: now.Add(time.Hour).Unix(),
+			EndedAt:   now.Add(2 * time.Hour).Unix(),
+		},
+	}
+
+	testcases := []struct {
+		name           string
+		windows        *config.DeploymentWindows
+		appID          string
+		expectedHeld   bool
+		expectedReason string
+	}{
+		{
+			name:  "nothing held",
+			appID: "app-1",
+		},
+		{
+			name:           "held by an active freeze of the application",
+			appID:          "app-2",
+			expectedHeld:   true,
+			expectedReason: "Migrating database (frozen until 2021-06-04T19:00:00Z)",
+		},
+		{
+			name:           "held by the deployment windows",
+			windows:        windows,
+			appID:          "app-1",
+			expectedHeld:   true,
+			expectedReason: "No deployment on weekends",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			reason, held := findDeploymentHoldReason(tc.windows, freezes, tc.appID, now)
+			assert.Equal(t, tc.expectedHeld, held)
+			assert.Equal(t, tc.expectedReason, reason)
+		})
+	}
+}
+
+func TestHasStartedStage(t *testing.T) {
+	d := &model.Deployment{
+		Stages: []*model.PipelineStage{
+			{Status: model.StageStatus_STAGE_NOT_STARTED_YET},
+			{Status: model.StageStatus_STAGE_NOT_STARTED_YET},
+		},
+	}
+	assert.False(t, hasStartedStage(d))
+
+	d.Stages[0].Status = model.StageStatus_STAGE_SUCCESS
+	assert.True(t, hasStartedStage(d))
+}
+
+func TestOverrideDeploymentWindow(t *testing.T) {
+	reported := make(map[string]model.CommandStatus)
+	makeCommand := func(id string) model.ReportableCommand {
+		return model.ReportableCommand{
+			Command: &model.Command{Id: id},
+			Report: func(_ context.Context, status model.CommandStatus, _ map[string]string, output []byte) error {
+				reported[id] = status
+				assert.Equal(t, "deployment is not held", string(output))
+				return nil
+			},
+		}
+	}
+
+	ctx := context.Background()
+	s := &scheduler{
+		windowOverriddenCh: make(chan *model.ReportableCommand, 1),
+		logger:             zap.NewNop(),
+	}
+
+	// The command is handed over to the held deployment.
+	s.OverrideDeploymentWindow(ctx, makeCommand("held"))
+	assert.Len(t, s.windowOverriddenCh, 1)
+	assert.Empty(t, reported)
+
+	// The unused command is reported when the deployment is no longer held.
+	s.closeWindowOverride(ctx)
+	assert.Len(t, s.windowOverriddenCh, 0)
+	assert.Equal(t, model.CommandStatus_COMMAND_FAILED, reported["held"])
+
+	// The command sent after that is reported right away.
+	s.OverrideDeploymentWindow(ctx, makeCommand("not-held"))
+	assert.Len(t, s.windowOverriddenCh, 0)
+	assert.Equal(t, model.CommandStatus_COMMAND_FAILED, reported["not-held"])
+}
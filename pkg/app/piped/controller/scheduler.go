@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"go.uber.org/atomic"
@@ -30,6 +31,7 @@ import (
 	"github.com/pipe-cd/pipe/pkg/app/piped/executor/registry"
 	"github.com/pipe-cd/pipe/pkg/app/piped/logpersister"
 	pln "github.com/pipe-cd/pipe/pkg/app/piped/planner"
+	"github.com/pipe-cd/pipe/pkg/app/piped/promoter"
 	"github.com/pipe-cd/pipe/pkg/cache"
 	"github.com/pipe-cd/pipe/pkg/config"
 	"github.com/pipe-cd/pipe/pkg/model"
@@ -46,6 +48,7 @@ type scheduler struct {
 	gitClient          gitClient
 	commandLister      commandLister
 	applicationLister  applicationLister
+	deploymentLister   deploymentLister
 	liveResourceLister liveResourceLister
 	logPersister       logpersister.Persister
 	metadataStore      *metadataStore
@@ -53,6 +56,7 @@ type scheduler struct {
 	secretDecrypter    secretDecrypter
 	pipedConfig        *config.PipedSpec
 	appManifestsCache  cache.Cache
+	promoter           promoter.Promoter
 	logger             *zap.Logger
 
 	targetDSP  deploysource.Provider
@@ -61,9 +65,9 @@ type scheduler struct {
 	// Current status of each stages.
 	// We stores their current statuses into this field
 	// because the deployment model is readonly to avoid data race.
-	// We may need a mutex for this field in the future
-	// when the stages can be executed concurrently.
+	// The mutex is required since the stages can be executed concurrently.
 	stageStatuses           map[string]model.StageStatus
+	stageStatusesMu         sync.RWMutex
 	genericDeploymentConfig config.GenericDeploymentSpec
 
 	done                 atomic.Bool
@@ -71,6 +75,10 @@ type scheduler struct {
 	doneDeploymentStatus model.DeploymentStatus
 	cancelled            bool
 	cancelledCh          chan *model.ReportableCommand
+	windowOverrideMu     sync.Mutex
+	windowOverridden     bool
+	windowOverrideClosed bool
+	windowOverriddenCh   chan *model.ReportableCommand
 
 	nowFunc func() time.Time
 }
@@ -83,6 +91,7 @@ func newScheduler(
 	gitClient gitClient,
 	commandLister commandLister,
 	applicationLister applicationLister,
+	deploymentLister deploymentLister,
 	liveResourceLister liveResourceLister,
 	lp logpersister.Persister,
 	notifier notifier,
@@ -110,6 +119,7 @@ func newScheduler(
 		gitClient:            gitClient,
 		commandLister:        commandLister,
 		applicationLister:    applicationLister,
+		deploymentLister:     deploymentLister,
 		liveResourceLister:   liveResourceLister,
 		logPersister:         lp,
 		metadataStore:        NewMetadataStore(apiClient, d),
@@ -117,8 +127,10 @@ func newScheduler(
 		secretDecrypter:      sd,
 		pipedConfig:          pipedConfig,
 		appManifestsCache:    appManifestsCache,
+		promoter:             promoter.NewPromoter(pipedConfig, apiClient, gitClient, workingDir, logger),
 		doneDeploymentStatus: d.Status,
 		cancelledCh:          make(chan *model.ReportableCommand, 1),
+		windowOverriddenCh:   make(chan *model.ReportableCommand, 1),
 		logger:               logger,
 		nowFunc:              time.Now,
 	}
@@ -176,6 +188,24 @@ func (s *scheduler) Cancel(cmd model.ReportableCommand) {
 	close(s.cancelledCh)
 }
 
+// OverrideDeploymentWindow lets the deployment run
+// even while it is not allowed by the deployment windows or freezes.
+// The command is reported as failed right away when the deployment is no longer held.
+func (s *scheduler) OverrideDeploymentWindow(ctx context.Context, cmd model.ReportableCommand) {
+	s.windowOverrideMu.Lock()
+	defer s.windowOverrideMu.Unlock()
+
+	if s.windowOverrideClosed {
+		reportNotHeldOverrideCommand(ctx, &cmd, s.logger)
+		return
+	}
+	if s.windowOverridden {
+		return
+	}
+	s.windowOverridden = true
+	s.windowOverriddenCh <- &cmd
+}
+
 // Run starts running the scheduler.
 // It determines what stage should be executed next by which executor.
 // The returning error does not mean that the pipeline was failed,
@@ -189,6 +219,7 @@ func (s *scheduler) Run(ctx context.Context) error {
 		s.doneDeploymentStatus = deploymentStatus
 		s.done.Store(true)
 	}()
+	defer s.closeWindowOverride(ctx)
 
 	// If this deployment is already completed. Do nothing.
 	if model.IsCompletedDeployment(s.deployment.Status) {
@@ -256,99 +287,206 @@ func (s *scheduler) Run(ctx context.Context) error {
 	}
 	s.genericDeploymentConfig = ds.GenericDeploymentConfig
 
+	// Hold the deployment before starting its first stage
+	// while it is not allowed by the deployment windows or freezes.
+	if !hasStartedStage(s.deployment) {
+		cmd, ok := s.waitForDeploymentWindow(ctx)
+		if !ok && cmd == nil {
+			s.logger.Info("stop scheduler because of temination signal while waiting for a deployment window")
+			return nil
+		}
+		if !ok {
+			deploymentStatus = model.DeploymentStatus_DEPLOYMENT_CANCELLED
+			statusReason = fmt.Sprintf("Cancelled by %s while waiting for a deployment window", cmd.Commander)
+			s.reportDeploymentCompleted(ctx, deploymentStatus, statusReason, cmd.Commander)
+			if err := cmd.Report(ctx, model.CommandStatus_COMMAND_SUCCEEDED, nil, nil); err != nil {
+				s.logger.Error("failed to report command status", zap.Error(err))
+			}
+			return nil
+		}
+	}
+
+	// The deployment is no longer held by the deployment windows.
+	s.closeWindowOverride(ctx)
+
 	timer := time.NewTimer(s.genericDeploymentConfig.Timeout.Duration())
 	defer timer.Stop()
 
-	// Iterate all the stages and execute the uncompleted ones.
-	for i, ps := range s.deployment.Stages {
-		lastStage = s.deployment.Stages[i]
+	// Hold the deployment before starting its first stage
+	// while the deployments of the applications it depends on are not completed.
+	// The waiting time is counted in the deployment timeout.
+	if !hasStartedStage(s.deployment) {
+		cmd, status, reason, ok := s.waitForDependencies(ctx, ds.RepoDir, timer.C)
+		if !ok && reason == "" {
+			s.logger.Info("stop scheduler because of temination signal while waiting for the dependencies")
+			return nil
+		}
+		if !ok {
+			deploymentStatus = status
+			statusReason = reason
+			var commander string
+			if cmd != nil {
+				commander = cmd.Commander
+			}
+			s.reportDeploymentCompleted(ctx, deploymentStatus, statusReason, commander)
+			if cmd != nil {
+				if err := cmd.Report(ctx, model.CommandStatus_COMMAND_SUCCEEDED, nil, nil); err != nil {
+					s.logger.Error("failed to report command status", zap.Error(err))
+				}
+			}
+			return nil
+		}
+	}
+
+	// Execute the uncompleted stages by following their dependencies.
+	// The stages whose required stages have been completed successfully are executed concurrently.
+	// Once a stage was failed or cancelled, no more stage is started and the running ones are cancelled.
+	var (
+		stages      = s.pipelineStages()
+		stageIDs    = make(map[string]struct{}, len(stages))
+		completed   = make(map[string]bool, len(stages))
+		started     = make(map[string]bool, len(stages))
+		handlers    = make(map[string]executor.StopSignalHandler, len(stages))
+		resultCh    = make(chan stageResult, len(stages))
+		cancelledCh = s.cancelledCh
+		numRunning  int
+		stopped     bool
+	)
 
-		if ps.Status == model.StageStatus_STAGE_SUCCESS {
-			continue
+	// stop stops starting new stages and sends the given signal to all running stages.
+	stop := func(signal func(executor.StopSignalHandler)) {
+		stopped = true
+		for id, h := range handlers {
+			signal(h)
+			delete(handlers, id)
 		}
-		if !ps.Visible || ps.Name == model.StageRollback.String() {
-			continue
+	}
+	// complete marks the deployment as completed by the given stage
+	// if it has not been completed by any other stage yet.
+	complete := func(status model.DeploymentStatus, reason string, ps *model.PipelineStage) {
+		if deploymentStatus != model.DeploymentStatus_DEPLOYMENT_SUCCESS {
+			return
 		}
+		deploymentStatus = status
+		statusReason = reason
+		lastStage = ps
+	}
 
+	for _, ps := range stages {
+		stageIDs[ps.Id] = struct{}{}
+		switch ps.Status {
+		case model.StageStatus_STAGE_SUCCESS, model.StageStatus_STAGE_SKIPPED:
+			completed[ps.Id] = true
 		// This stage is already completed by a previous scheduler.
-		if ps.Status == model.StageStatus_STAGE_CANCELLED {
-			deploymentStatus = model.DeploymentStatus_DEPLOYMENT_CANCELLED
-			statusReason = fmt.Sprintf("Deployment was cancelled while executing stage %s", ps.Id)
-			break
-		}
-		if ps.Status == model.StageStatus_STAGE_FAILURE {
-			deploymentStatus = model.DeploymentStatus_DEPLOYMENT_FAILURE
-			statusReason = fmt.Sprintf("Failed while executing stage %s", ps.Id)
-			break
+		case model.StageStatus_STAGE_CANCELLED:
+			complete(model.DeploymentStatus_DEPLOYMENT_CANCELLED, fmt.Sprintf("Deployment was cancelled while executing stage %s", ps.Id), ps)
+			stopped = true
+		case model.StageStatus_STAGE_FAILURE:
+			complete(model.DeploymentStatus_DEPLOYMENT_FAILURE, fmt.Sprintf("Failed while executing stage %s", ps.Id), ps)
+			stopped = true
 		}
+	}
 
-		var (
-			result       model.StageStatus
-			sig, handler = executor.NewStopSignal()
-			doneCh       = make(chan struct{})
-		)
+	for {
+		for _, ps := range stages {
+			if stopped {
+				break
+			}
+			if completed[ps.Id] || started[ps.Id] || !requiresCompleted(ps, stageIDs, completed) {
+				continue
+			}
 
-		go func() {
-			result = s.executeStage(sig, *ps, func(in executor.Input) (executor.Executor, bool) {
-				return s.executorRegistry.Executor(model.Stage(ps.Name), in)
-			})
-			close(doneCh)
-		}()
+			var (
+				stage        = ps
+				sig, handler = executor.NewStopSignal()
+			)
+			started[stage.Id] = true
+			handlers[stage.Id] = handler
+			numRunning++
+			lastStage = stage
+
+			go func() {
+				status := s.executeStage(sig, *stage, func(in executor.Input) (executor.Executor, bool) {
+					return s.executorRegistry.Executor(model.Stage(stage.Name), in)
+				})
+				resultCh <- stageResult{
+					stage:  stage,
+					status: status,
+					signal: sig.Signal(),
+				}
+			}()
+		}
+
+		if numRunning == 0 {
+			break
+		}
 
 		select {
 		case <-ctx.Done():
-			handler.Terminate()
-			<-doneCh
+			stop(executor.StopSignalHandler.Terminate)
+			for ; numRunning > 0; numRunning-- {
+				<-resultCh
+			}
+			s.logger.Info("stop scheduler because of temination signal")
+			return nil
 
 		case <-timer.C:
-			handler.Timeout()
-			<-doneCh
+			stop(executor.StopSignalHandler.Timeout)
 
-		case cmd := <-s.cancelledCh:
+		case cmd := <-cancelledCh:
+			// The channel is closed after the command was sent.
+			cancelledCh = nil
 			if cmd != nil {
 				cancelCommand = cmd
 				cancelCommander = cmd.Commander
-				handler.Cancel()
-				<-doneCh
+				stop(executor.StopSignalHandler.Cancel)
 			}
 
-		case <-doneCh:
-			break
-		}
+		case r := <-resultCh:
+			numRunning--
+			delete(handlers, r.stage.Id)
+
+			switch r.status {
+			// If all operations of the stage were completed successfully
+			// or the stage was skipped by a user its dependent stages can be started.
+			case model.StageStatus_STAGE_SUCCESS, model.StageStatus_STAGE_SKIPPED:
+				completed[r.stage.Id] = true
+				continue
+
+			// The deployment was cancelled by a web user.
+			case model.StageStatus_STAGE_CANCELLED:
+				complete(model.DeploymentStatus_DEPLOYMENT_CANCELLED, fmt.Sprintf("Cancelled by %s while executing stage %s", cancelCommander, r.stage.Id), r.stage)
+
+			case model.StageStatus_STAGE_FAILURE:
+				// The stage was failed because of timing out.
+				if r.signal == executor.StopSignalTimeout {
+					complete(model.DeploymentStatus_DEPLOYMENT_FAILURE, fmt.Sprintf("Timed out while executing stage %s", r.stage.Id), r.stage)
+				} else {
+					complete(model.DeploymentStatus_DEPLOYMENT_FAILURE, fmt.Sprintf("Failed while executing stage %s", r.stage.Id), r.stage)
+				}
+
+			// The deployment was cancelled while running other stages and this stage was stopped before run.
+			default:
+				if cancelCommand != nil {
+					complete(model.DeploymentStatus_DEPLOYMENT_CANCELLED, fmt.Sprintf("Cancelled by %s while executing the previous stage of %s", cancelCommander, r.stage.Id), r.stage)
+				}
+			}
 
-		// If all operations of the stage were completed successfully
-		// handle the next stage.
-		if result == model.StageStatus_STAGE_SUCCESS {
-			continue
+			// Stop the stages running in parallel since the deployment can no longer succeed.
+			stop(executor.StopSignalHandler.Cancel)
 		}
+	}
 
-		// The deployment was cancelled by a web user.
-		if result == model.StageStatus_STAGE_CANCELLED {
+	// Some stages were not executed because the deployment was stopped
+	// although all the running stages had been completed successfully.
+	if deploymentStatus == model.DeploymentStatus_DEPLOYMENT_SUCCESS && len(completed) < len(stages) {
+		if cancelCommand != nil {
 			deploymentStatus = model.DeploymentStatus_DEPLOYMENT_CANCELLED
-			statusReason = fmt.Sprintf("Cancelled by %s while executing stage %s", cancelCommander, ps.Id)
-			break
-		}
-
-		if result == model.StageStatus_STAGE_FAILURE {
+			statusReason = fmt.Sprintf("Cancelled by %s while executing stage %s", cancelCommander, lastStage.Id)
+		} else {
 			deploymentStatus = model.DeploymentStatus_DEPLOYMENT_FAILURE
-			// The stage was failed because of timing out.
-			if sig.Signal() == executor.StopSignalTimeout {
-				statusReason = fmt.Sprintf("Timed out while executing stage %s", ps.Id)
-			} else {
-				statusReason = fmt.Sprintf("Failed while executing stage %s", ps.Id)
-			}
-			break
+			statusReason = fmt.Sprintf("Timed out while executing stage %s", lastStage.Id)
 		}
-
-		// The deployment was cancelled at the previous stage and this stage was terminated before run.
-		if result == model.StageStatus_STAGE_NOT_STARTED_YET && cancelCommand != nil {
-			deploymentStatus = model.DeploymentStatus_DEPLOYMENT_CANCELLED
-			statusReason = fmt.Sprintf("Cancelled by %s while executing the previous stage of %s", cancelCommander, ps.Id)
-			break
-		}
-
-		s.logger.Info("stop scheduler because of temination signal", zap.String("stage-id", ps.Id))
-		return nil
 	}
 
 	// When the deployment has completed but not successful,
@@ -362,27 +500,27 @@ func (s *scheduler) Run(ctx context.Context) error {
 			}
 
 			// Start running rollback stage.
-			var (
-				sig, handler = executor.NewStopSignal()
-				doneCh       = make(chan struct{})
-			)
-			go func() {
-				rbs := *stage
-				rbs.Requires = []string{lastStage.Id}
-				s.executeStage(sig, rbs, func(in executor.Input) (executor.Executor, bool) {
-					return s.executorRegistry.RollbackExecutor(s.deployment.Kind, in)
-				})
-				close(doneCh)
-			}()
-
-			select {
-			case <-ctx.Done():
-				handler.Terminate()
-				<-doneCh
+			status, terminated := s.executeRollbackStage(ctx, *stage, lastStage.Id, func(in executor.Input) (executor.Executor, bool) {
+				return s.executorRegistry.RollbackExecutor(s.deployment.Kind, in)
+			})
+			if terminated {
 				return nil
+			}
+			// Make it clear in the notification that the application may have been left in a broken state.
+			if status == model.StageStatus_STAGE_FAILURE {
+				statusReason = fmt.Sprintf("%s, then the rollback stage %s also failed. The application may need to be recovered manually", statusReason, stage.Id)
+			}
 
-			case <-doneCh:
-				break
+			// Then run the rollback scripts of the SCRIPT_RUN stages those have been started.
+			preStageID := stage.Id
+			for _, rbs := range s.findScriptRunRollbackStages() {
+				_, terminated := s.executeRollbackStage(ctx, *rbs, preStageID, func(in executor.Input) (executor.Executor, bool) {
+					return s.executorRegistry.Executor(model.StageScriptRunRollback, in)
+				})
+				if terminated {
+					return nil
+				}
+				preStageID = rbs.Id
 			}
 		}
 	}
@@ -392,6 +530,10 @@ func (s *scheduler) Run(ctx context.Context) error {
 		if err == nil && deploymentStatus == model.DeploymentStatus_DEPLOYMENT_SUCCESS {
 			s.reportMostRecentlySuccessfulDeployment(ctx)
 		}
+		if deploymentStatus == model.DeploymentStatus_DEPLOYMENT_SUCCESS {
+			s.cleanUp(ctx)
+			s.promote(ctx)
+		}
 	}
 
 	if cancelCommand != nil {
@@ -403,12 +545,103 @@ func (s *scheduler) Run(ctx context.Context) error {
 	return nil
 }
 
+// cleanUp runs the cleaner registered for the application kind of this deployment
+// to clean up the resources those are no longer needed after the deployment succeeded.
+// Since this is just a cleanup, its failure does not affect the deployment status.
+func (s *scheduler) cleanUp(ctx context.Context) {
+	app, ok := s.applicationLister.Get(s.deployment.ApplicationId)
+	if !ok {
+		s.logger.Warn("skip cleaning up since the application was not found")
+		return
+	}
+	cleaner, ok := s.executorRegistry.Cleaner(s.deployment.Kind, executor.Input{
+		Deployment:      s.deployment,
+		EnvName:         s.envName,
+		Application:     app,
+		PipedConfig:     s.pipedConfig,
+		TargetDSP:       s.targetDSP,
+		RunningDSP:      s.runningDSP,
+		MetadataStore:   s.metadataStore,
+		Notifier:        s.notifier,
+		SecretDecrypter: s.secretDecrypter,
+		Logger:          s.logger,
+	})
+	if !ok {
+		return
+	}
+	if err := cleaner.Clean(ctx); err != nil {
+		s.logger.Error("failed to clean up after the deployment succeeded", zap.Error(err))
+	}
+}
+
+// promote does all promotions of the deployed release after the deployment succeeded
+// when its pipeline does not contain any PROMOTE stage to control when they are done.
+// A failed promotion does not affect the deployment since the release has already been deployed successfully.
+func (s *scheduler) promote(ctx context.Context) {
+	promotions := s.genericDeploymentConfig.Promotions
+	if len(promotions) == 0 || s.genericDeploymentConfig.HasStage(model.StagePromote) {
+		return
+	}
+	ds, err := s.targetDSP.GetReadOnly(ctx, ioutil.Discard)
+	if err != nil {
+		s.logger.Error("failed to prepare the deploy source to promote the deployed release", zap.Error(err))
+		return
+	}
+	// The results are reported to the control-plane by the promoter.
+	s.promoter.Promote(ctx, s.deployment, ds.AppDir, promotions)
+}
+
+// executeRollbackStage executes the given rollback stage after the specified stage.
+// It returns the final status of the stage and reports whether the execution was terminated by the given context.
+func (s *scheduler) executeRollbackStage(ctx context.Context, stage model.PipelineStage, requiredStageID string, executorFactory func(executor.Input) (executor.Executor, bool)) (model.StageStatus, bool) {
+	var (
+		sig, handler = executor.NewStopSignal()
+		doneCh       = make(chan struct{})
+		status       model.StageStatus
+	)
+	go func() {
+		stage.Requires = []string{requiredStageID}
+		status = s.executeStage(sig, stage, executorFactory)
+		close(doneCh)
+	}()
+
+	select {
+	case <-ctx.Done():
+		handler.Terminate()
+		<-doneCh
+		return status, true
+
+	case <-doneCh:
+		return status, false
+	}
+}
+
+// findScriptRunRollbackStages returns the SCRIPT_RUN_ROLLBACK stages
+// whose SCRIPT_RUN stage has already been started.
+func (s *scheduler) findScriptRunRollbackStages() []*model.PipelineStage {
+	started := make(map[int32]bool)
+	for _, ps := range s.deployment.Stages {
+		if ps.Name != model.StageScriptRun.String() || !ps.Visible {
+			continue
+		}
+		started[ps.Index] = s.stageStatus(ps.Id) != model.StageStatus_STAGE_NOT_STARTED_YET
+	}
+
+	out := make([]*model.PipelineStage, 0)
+	for _, ps := range s.deployment.Stages {
+		if ps.Name == model.StageScriptRunRollback.String() && started[ps.Index] {
+			out = append(out, ps)
+		}
+	}
+	return out
+}
+
 // executeStage finds the executor for the given stage and execute.
 func (s *scheduler) executeStage(sig executor.StopSignal, ps model.PipelineStage, executorFactory func(executor.Input) (executor.Executor, bool)) (finalStatus model.StageStatus) {
 	var (
 		ctx            = sig.Context()
 		originalStatus = ps.Status
-		lp             = s.logPersister.StageLogPersister(s.deployment.Id, ps.Id)
+		lp             = s.logPersister.StageLogPersister(s.deployment.Id, ps.Id, ps.RetriedCount)
 	)
 	defer func() {
 		// When the piped has been terminated (PS kill) while the stage is still running
@@ -474,8 +707,10 @@ func (s *scheduler) executeStage(sig executor.StopSignal, ps model.PipelineStage
 		Stage:                 &ps,
 		StageConfig:           stageConfig,
 		Deployment:            s.deployment,
+		EnvName:               s.envName,
 		Application:           app,
 		PipedConfig:           s.pipedConfig,
+		WorkingDir:            s.workingDir,
 		TargetDSP:             s.targetDSP,
 		RunningDSP:            s.runningDSP,
 		CommandLister:         cmdLister,
@@ -483,6 +718,9 @@ func (s *scheduler) executeStage(sig executor.StopSignal, ps model.PipelineStage
 		MetadataStore:         s.metadataStore,
 		AppManifestsCache:     s.appManifestsCache,
 		AppLiveResourceLister: alrLister,
+		Notifier:              s.notifier,
+		SecretDecrypter:       s.secretDecrypter,
+		Promoter:              s.promoter,
 		Logger:                s.logger,
 	}
 
@@ -500,9 +738,11 @@ func (s *scheduler) executeStage(sig executor.StopSignal, ps model.PipelineStage
 
 	// Commit deployment state status in the following cases:
 	// - Apply state successfully.
+	// - State was skipped while running (skip via Controlpane).
 	// - State was canceled while running (cancel via Controlpane).
 	// - Apply state failed but not because of terminating piped process.
 	if status == model.StageStatus_STAGE_SUCCESS ||
+		status == model.StageStatus_STAGE_SKIPPED ||
 		status == model.StageStatus_STAGE_CANCELLED ||
 		(status == model.StageStatus_STAGE_FAILURE && !sig.Terminated()) {
 
@@ -530,8 +770,15 @@ func (s *scheduler) reportStageStatus(ctx context.Context, stageID string, statu
 		retry = pipedservice.NewRetry(10)
 	)
 
+	// Keep the retried count of the stage to not lose the logs of the previous attempts.
+	if stage, ok := s.deployment.FindStage(stageID); ok {
+		req.RetriedCount = stage.RetriedCount
+	}
+
 	// Update stage status at local.
+	s.stageStatusesMu.Lock()
 	s.stageStatuses[stageID] = status
+	s.stageStatusesMu.Unlock()
 
 	// Update stage status on the remote.
 	for retry.WaitNext(ctx) {
@@ -574,7 +821,7 @@ func (s *scheduler) reportDeploymentCompleted(ctx context.Context, status model.
 			DeploymentId:  s.deployment.Id,
 			Status:        status,
 			StatusReason:  desc,
-			StageStatuses: s.stageStatuses,
+			StageStatuses: s.copyStageStatuses(),
 			CompletedAt:   now.Unix(),
 		}
 		retry = pipedservice.NewRetry(10)
@@ -660,3 +907,53 @@ type stageCommandLister struct {
 func (s stageCommandLister) ListCommands() []model.ReportableCommand {
 	return s.lister.ListStageCommands(s.deploymentID, s.stageID)
 }
+
+func (s *scheduler) stageStatus(id string) model.StageStatus {
+	s.stageStatusesMu.RLock()
+	defer s.stageStatusesMu.RUnlock()
+	return s.stageStatuses[id]
+}
+
+func (s *scheduler) copyStageStatuses() map[string]model.StageStatus {
+	s.stageStatusesMu.RLock()
+	defer s.stageStatusesMu.RUnlock()
+	out := make(map[string]model.StageStatus, len(s.stageStatuses))
+	for k, v := range s.stageStatuses {
+		out[k] = v
+	}
+	return out
+}
+
+type stageResult struct {
+	stage  *model.PipelineStage
+	status model.StageStatus
+	signal executor.StopSignalType
+}
+
+// pipelineStages returns the visible stages of the pipeline
+// which should be executed in the declared order of their dependencies.
+func (s *scheduler) pipelineStages() []*model.PipelineStage {
+	out := make([]*model.PipelineStage, 0, len(s.deployment.Stages))
+	for _, ps := range s.deployment.Stages {
+		if !ps.Visible || ps.Name == model.StageRollback.String() {
+			continue
+		}
+		out = append(out, ps)
+	}
+	return out
+}
+
+// requiresCompleted reports whether all the pipeline stages required by the given stage
+// have been completed successfully.
+func requiresCompleted(ps *model.PipelineStage, stageIDs map[string]struct{}, completed map[string]bool) bool {
+	for _, id := range ps.Requires {
+		// Ignore the one which is not a stage of the pipeline.
+		if _, ok := stageIDs[id]; !ok {
+			continue
+		}
+		if !completed[id] {
+			return false
+		}
+	}
+	return true
+}
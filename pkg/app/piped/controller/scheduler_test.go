@@ -0,0 +1,98 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+func TestRequiresCompleted(t *testing.T) {
+	stageIDs := map[string]struct{}{
+		"canary":     {},
+		"analysis":   {},
+		"smoke-test": {},
+		"primary":    {},
+	}
+	testcases := []struct {
+		name      string
+		stage     *model.PipelineStage
+		completed map[string]bool
+		expected  bool
+	}{
+		{
+			name:     "no requires",
+			stage:    &model.PipelineStage{Id: "canary"},
+			expected: true,
+		},
+		{
+			name: "one of requires is not completed",
+			stage: &model.PipelineStage{
+				Id:       "primary",
+				Requires: []string{"analysis", "smoke-test"},
+			},
+			completed: map[string]bool{
+				"canary":   true,
+				"analysis": true,
+			},
+			expected: false,
+		},
+		{
+			name: "all requires are completed",
+			stage: &model.PipelineStage{
+				Id:       "primary",
+				Requires: []string{"analysis", "smoke-test"},
+			},
+			completed: map[string]bool{
+				"canary":     true,
+				"analysis":   true,
+				"smoke-test": true,
+			},
+			expected: true,
+		},
+		{
+			name: "required one is not a pipeline stage",
+			stage: &model.PipelineStage{
+				Id:       "canary",
+				Requires: []string{"unknown"},
+			},
+			expected: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := requiresCompleted(tc.stage, stageIDs, tc.completed)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestPipelineStages(t *testing.T) {
+	s := &scheduler{
+		deployment: &model.Deployment{
+			Stages: []*model.PipelineStage{
+				{Id: "canary", Visible: true},
+				{Id: "primary", Visible: true},
+				{Id: "rollback", Name: model.StageRollback.String()},
+				{Id: "smoke-test-rollback", Name: model.StageScriptRunRollback.String()},
+			},
+		},
+	}
+	got := s.pipelineStages()
+	assert.Equal(t, []*model.PipelineStage{s.deployment.Stages[0], s.deployment.Stages[1]}, got)
+}
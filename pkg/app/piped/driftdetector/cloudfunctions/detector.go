@@ -0,0 +1,248 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudfunctions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/cloudfunctions"
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/git"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+type applicationLister interface {
+	ListByCloudProvider(name string) []*model.Application
+}
+
+type gitClient interface {
+	Clone(ctx context.Context, repoID, remote, branch, destination string) (git.Repo, error)
+}
+
+type reporter interface {
+	ReportApplicationSyncState(ctx context.Context, appID string, state model.ApplicationSyncState) error
+}
+
+type detector struct {
+	provider  config.PipedCloudProvider
+	appLister applicationLister
+	gitClient gitClient
+	reporter  reporter
+	interval  time.Duration
+	config    *config.PipedSpec
+	logger    *zap.Logger
+
+	gitRepos map[string]git.Repo
+}
+
+func NewDetector(
+	cp config.PipedCloudProvider,
+	appLister applicationLister,
+	gitClient gitClient,
+	reporter reporter,
+	cfg *config.PipedSpec,
+	logger *zap.Logger,
+) *detector {
+
+	logger = logger.Named("cloudfunctions-detector").With(
+		zap.String("cloud-provider", cp.Name),
+	)
+	return &detector{
+		provider:  cp,
+		appLister: appLister,
+		gitClient: gitClient,
+		reporter:  reporter,
+		interval:  time.Minute,
+		config:    cfg,
+		gitRepos:  make(map[string]git.Repo),
+		logger:    logger,
+	}
+}
+
+func (d *detector) Run(ctx context.Context) error {
+	d.logger.Info("start running drift detector for cloudfunctions applications")
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+L:
+	for {
+		select {
+		case <-ticker.C:
+			d.check(ctx)
+
+		case <-ctx.Done():
+			break L
+		}
+	}
+
+	d.logger.Info("drift detector for cloudfunctions applications has been stopped")
+	return nil
+}
+
+func (d *detector) check(ctx context.Context) {
+	client, err := provider.DefaultRegistry().Client(ctx, d.provider.Name, d.provider.CloudFunctionsConfig, d.logger)
+	if err != nil {
+		d.logger.Error("failed to create cloudfunctions client", zap.Error(err))
+		return
+	}
+
+	appsByRepo := d.listGroupedApplication()
+	for repoID, apps := range appsByRepo {
+		gitRepo, ok := d.gitRepos[repoID]
+		if !ok {
+			// Clone repository for the first time.
+			repoCfg, ok := d.config.GetRepository(repoID)
+			if !ok {
+				d.logger.Error(fmt.Sprintf("repository %s was not found in piped configuration", repoID))
+				continue
+			}
+			gr, err := d.gitClient.Clone(ctx, repoID, repoCfg.Remote, repoCfg.Branch, "")
+			if err != nil {
+				d.logger.Error("failed to clone repository",
+					zap.String("repo-id", repoID),
+					zap.Error(err),
+				)
+				continue
+			}
+			gitRepo = gr
+			d.gitRepos[repoID] = gitRepo
+		}
+
+		// Fetch the latest commit to compare the states.
+		branch := gitRepo.GetClonedBranch()
+		if err := gitRepo.Pull(ctx, branch); err != nil {
+			d.logger.Error("failed to update repository branch",
+				zap.String("repo-id", repoID),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		// Get the head commit of the repository.
+		headCommit, err := gitRepo.GetLatestCommit(ctx)
+		if err != nil {
+			d.logger.Error("failed to get head commit hash",
+				zap.String("repo-id", repoID),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		// Start checking all applications in this repository.
+		for _, app := range apps {
+			if err := d.checkApplication(ctx, client, app, gitRepo, headCommit); err != nil {
+				d.logger.Error(fmt.Sprintf("failed to check application: %s", app.Id), zap.Error(err))
+			}
+		}
+	}
+}
+
+func (d *detector) checkApplication(ctx context.Context, client provider.Client, app *model.Application, repo git.Repo, headCommit git.Commit) error {
+	var (
+		repoDir = repo.GetPath()
+		appDir  = filepath.Join(repoDir, app.GitPath.Path)
+	)
+	cfg, err := d.loadDeploymentConfiguration(repoDir, app)
+	if err != nil {
+		return fmt.Errorf("failed to load deployment configuration: %w", err)
+	}
+
+	headManifest, err := provider.LoadFunctionManifest(appDir, cfg.CloudFunctionsDeploymentSpec.Input.FunctionManifestFile)
+	if err != nil {
+		return fmt.Errorf("failed to load function manifest: %w", err)
+	}
+
+	liveManifest, err := client.GetFunction(ctx, headManifest.Spec.Name)
+	if errors.Is(err, provider.ErrNotFound) {
+		state := makeSyncState([]string{fmt.Sprintf("function %s does not exist", headManifest.Spec.Name)}, headCommit.Hash)
+		return d.reporter.ReportApplicationSyncState(ctx, app.Id, state)
+	}
+	if err != nil {
+		return err
+	}
+
+	state := makeSyncState(provider.Diff(headManifest, liveManifest), headCommit.Hash)
+	return d.reporter.ReportApplicationSyncState(ctx, app.Id, state)
+}
+
+// listGroupedApplication retrieves all applications those should be handled by this director
+// and then groups them by repoID.
+func (d *detector) listGroupedApplication() map[string][]*model.Application {
+	var (
+		apps = d.appLister.ListByCloudProvider(d.provider.Name)
+		m    = make(map[string][]*model.Application)
+	)
+	for _, app := range apps {
+		if app.Kind != model.ApplicationKind_CLOUDFUNCTIONS {
+			continue
+		}
+		repoID := app.GitPath.Repo.Id
+		m[repoID] = append(m[repoID], app)
+	}
+	return m
+}
+
+func (d *detector) loadDeploymentConfiguration(repoPath string, app *model.Application) (*config.Config, error) {
+	path := filepath.Join(repoPath, app.GitPath.GetDeploymentConfigFilePath())
+	cfg, err := config.LoadFromYAML(path)
+	if err != nil {
+		return nil, err
+	}
+	if appKind, ok := config.ToApplicationKind(cfg.Kind); !ok || appKind != app.Kind {
+		return nil, fmt.Errorf("application in deployment configuration file is not match, got: %s, expected: %s", appKind, app.Kind)
+	}
+	return cfg, nil
+}
+
+func (d *detector) ProviderName() string {
+	return d.provider.Name
+}
+
+func makeSyncState(diffs []string, commit string) model.ApplicationSyncState {
+	if len(diffs) == 0 {
+		return model.ApplicationSyncState{
+			Status:      model.ApplicationSyncStatus_SYNCED,
+			ShortReason: "",
+			Reason:      "",
+			Timestamp:   time.Now().Unix(),
+		}
+	}
+
+	shortReason := fmt.Sprintf("There are %d differences between the function defined in Git and the deployed one", len(diffs))
+	if len(commit) >= 7 {
+		commit = commit[:7]
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Diff between the defined state in Git at commit %s and actual state in Cloud Functions:\n\n", commit))
+	for _, diff := range diffs {
+		b.WriteString(fmt.Sprintf("- %s\n", diff))
+	}
+
+	return model.ApplicationSyncState{
+		Status:      model.ApplicationSyncStatus_OUT_OF_SYNC,
+		ShortReason: shortReason,
+		Reason:      b.String(),
+		Timestamp:   time.Now().Unix(),
+	}
+}
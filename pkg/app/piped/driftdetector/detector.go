@@ -28,7 +28,11 @@ import (
 	"google.golang.org/grpc"
 
 	"github.com/pipe-cd/pipe/pkg/app/api/service/pipedservice"
+	"github.com/pipe-cd/pipe/pkg/app/piped/driftdetector/cloudfunctions"
 	"github.com/pipe-cd/pipe/pkg/app/piped/driftdetector/kubernetes"
+	"github.com/pipe-cd/pipe/pkg/app/piped/driftdetector/lambda"
+	"github.com/pipe-cd/pipe/pkg/app/piped/driftdetector/nomad"
+	"github.com/pipe-cd/pipe/pkg/app/piped/driftdetector/terraform"
 	"github.com/pipe-cd/pipe/pkg/app/piped/livestatestore"
 	"github.com/pipe-cd/pipe/pkg/cache"
 	"github.com/pipe-cd/pipe/pkg/config"
@@ -104,6 +108,7 @@ func NewDetector(
 				appLister,
 				gitClient,
 				sg,
+				stateGetter,
 				d,
 				appManifestsCache,
 				cfg,
@@ -111,6 +116,50 @@ func NewDetector(
 				logger,
 			))
 
+		case model.CloudProviderTerraform:
+			sg, ok := stateGetter.TerraformGetter(cp.Name)
+			if !ok {
+				d.logger.Error(fmt.Sprintf("unable to find live state getter for cloud provider: %s", cp.Name))
+				continue
+			}
+			d.detectors = append(d.detectors, terraform.NewDetector(
+				cp,
+				appLister,
+				sg,
+				d,
+				logger,
+			))
+
+		case model.CloudProviderLambda:
+			d.detectors = append(d.detectors, lambda.NewDetector(
+				cp,
+				appLister,
+				gitClient,
+				d,
+				cfg,
+				logger,
+			))
+
+		case model.CloudProviderCloudFunctions:
+			d.detectors = append(d.detectors, cloudfunctions.NewDetector(
+				cp,
+				appLister,
+				gitClient,
+				d,
+				cfg,
+				logger,
+			))
+
+		case model.CloudProviderNomad:
+			d.detectors = append(d.detectors, nomad.NewDetector(
+				cp,
+				appLister,
+				gitClient,
+				d,
+				cfg,
+				logger,
+			))
+
 		default:
 		}
 	}
@@ -47,6 +47,10 @@ type secretDecrypter interface {
 	Decrypt(string) (string, error)
 }
 
+type clusterStateGetter interface {
+	KubernetesGetter(cloudProvider string) (kubernetes.Getter, bool)
+}
+
 type reporter interface {
 	ReportApplicationSyncState(ctx context.Context, appID string, state model.ApplicationSyncState) error
 }
@@ -56,6 +60,7 @@ type detector struct {
 	appLister         applicationLister
 	gitClient         gitClient
 	stateGetter       kubernetes.Getter
+	clusterGetter     clusterStateGetter
 	reporter          reporter
 	appManifestsCache cache.Cache
 	interval          time.Duration
@@ -72,6 +77,7 @@ func NewDetector(
 	appLister applicationLister,
 	gitClient gitClient,
 	stateGetter kubernetes.Getter,
+	clusterGetter clusterStateGetter,
 	reporter reporter,
 	appManifestsCache cache.Cache,
 	cfg *config.PipedSpec,
@@ -87,6 +93,7 @@ func NewDetector(
 		appLister:         appLister,
 		gitClient:         gitClient,
 		stateGetter:       stateGetter,
+		clusterGetter:     clusterGetter,
 		reporter:          reporter,
 		appManifestsCache: appManifestsCache,
 		interval:          time.Minute,
@@ -175,38 +182,77 @@ func (d *detector) check(ctx context.Context) error {
 }
 
 func (d *detector) checkApplication(ctx context.Context, app *model.Application, repo git.Repo, headCommit git.Commit) error {
-	watchingResourceKinds := d.stateGetter.GetWatchingResourceKinds()
-	headManifests, err := d.loadHeadManifests(ctx, app, repo, headCommit, watchingResourceKinds)
+	cfg, err := d.loadDeploymentConfiguration(repo.GetPath(), app)
+	if err != nil {
+		return fmt.Errorf("failed to load deployment configuration: %w", err)
+	}
+	if spec := cfg.KubernetesDeploymentSpec; spec != nil && len(spec.Targets) > 0 {
+		return d.checkApplicationTargets(ctx, app, cfg, spec.Targets, repo, headCommit)
+	}
+
+	result, err := d.diff(ctx, app, cfg, nil, d.stateGetter, repo, headCommit)
 	if err != nil {
 		return err
 	}
+
+	state := makeSyncState(result, headCommit.Hash)
+	return d.reporter.ReportApplicationSyncState(ctx, app.Id, state)
+}
+
+// checkApplicationTargets compares the manifests of all targets with the live ones running in their clusters
+// and reports the aggregated sync state of the application.
+func (d *detector) checkApplicationTargets(ctx context.Context, app *model.Application, cfg *config.Config, targets []config.KubernetesDeploymentTarget, repo git.Repo, headCommit git.Commit) error {
+	states := make([]targetSyncState, 0, len(targets))
+	for i := range targets {
+		t := &targets[i]
+		sg, ok := d.clusterGetter.KubernetesGetter(t.CloudProvider)
+		if !ok {
+			return fmt.Errorf("live state getter for cloud provider %s of target %s was not found", t.CloudProvider, t.Name)
+		}
+		result, err := d.diff(ctx, app, cfg, t, sg, repo, headCommit)
+		if err != nil {
+			return fmt.Errorf("failed to check target %s: %w", t.Name, err)
+		}
+		states = append(states, targetSyncState{
+			target: t.Name,
+			state:  makeSyncState(result, headCommit.Hash),
+		})
+	}
+
+	state := mergeTargetSyncStates(states)
+	return d.reporter.ReportApplicationSyncState(ctx, app.Id, state)
+}
+
+// diff compares the manifests defined in Git at the head commit with the live ones.
+// The given target is nil when the application has no target.
+func (d *detector) diff(ctx context.Context, app *model.Application, cfg *config.Config, target *config.KubernetesDeploymentTarget, stateGetter kubernetes.Getter, repo git.Repo, headCommit git.Commit) (*provider.DiffListResult, error) {
+	appID := app.Id
+	if target != nil {
+		appID = provider.TargetCacheID(app.Id, target.Name)
+	}
+
+	watchingResourceKinds := stateGetter.GetWatchingResourceKinds()
+	headManifests, err := d.loadHeadManifests(ctx, app, cfg, target, repo, headCommit, watchingResourceKinds)
+	if err != nil {
+		return nil, err
+	}
 	headManifests = filterIgnoringManifests(headManifests)
-	d.logger.Info(fmt.Sprintf("application %s has %d manifests at commit %s", app.Id, len(headManifests), headCommit.Hash))
+	d.logger.Info(fmt.Sprintf("application %s has %d manifests at commit %s", appID, len(headManifests), headCommit.Hash))
 
-	liveManifests := d.stateGetter.GetAppLiveManifests(app.Id)
+	liveManifests := stateGetter.GetAppLiveManifests(app.Id)
 	liveManifests = filterIgnoringManifests(liveManifests)
-	d.logger.Info(fmt.Sprintf("application %s has %d live manifests", app.Id, len(liveManifests)))
+	d.logger.Info(fmt.Sprintf("application %s has %d live manifests", appID, len(liveManifests)))
 
-	result, err := provider.DiffList(
+	return provider.DiffList(
 		headManifests,
 		liveManifests,
 		diff.WithEquateEmpty(),
 		diff.WithIgnoreAddingMapKeys(),
 		diff.WithCompareNumberAndNumericString(),
 	)
-	if err != nil {
-		return err
-	}
-
-	state := makeSyncState(result, headCommit.Hash)
-	if state.Status == model.ApplicationSyncStatus_SYNCED {
-		return d.reporter.ReportApplicationSyncState(ctx, app.Id, state)
-	}
-
-	return d.reporter.ReportApplicationSyncState(ctx, app.Id, state)
 }
 
-func (d *detector) loadHeadManifests(ctx context.Context, app *model.Application, repo git.Repo, headCommit git.Commit, watchingResourceKinds []provider.APIVersionKind) ([]provider.Manifest, error) {
+func (d *detector) loadHeadManifests(ctx context.Context, app *model.Application, cfg *config.Config, target *config.KubernetesDeploymentTarget, repo git.Repo, headCommit git.Commit, watchingResourceKinds []provider.APIVersionKind) ([]provider.Manifest, error) {
 	var (
 		manifestCache = provider.AppManifestsCache{
 			AppID:  app.Id,
@@ -216,15 +262,13 @@ func (d *detector) loadHeadManifests(ctx context.Context, app *model.Application
 		repoDir = repo.GetPath()
 		appDir  = filepath.Join(repoDir, app.GitPath.Path)
 	)
+	if target != nil {
+		manifestCache.AppID = provider.TargetCacheID(app.Id, target.Name)
+	}
 
 	manifests, ok := manifestCache.Get(headCommit.Hash)
 	if !ok {
 		// When the manifests were not in the cache we have to load them.
-		cfg, err := d.loadDeploymentConfiguration(repoDir, app)
-		if err != nil {
-			return nil, fmt.Errorf("failed to load deployment configuration: %w", err)
-		}
-
 		gds, ok := cfg.GetGenericDeployment()
 		if !ok {
 			return nil, fmt.Errorf("unsupport application kind %s", cfg.Kind)
@@ -263,7 +307,17 @@ func (d *detector) loadHeadManifests(ctx context.Context, app *model.Application
 			}
 		}
 
-		loader := provider.NewManifestLoader(app.Name, appDir, repoDir, app.GitPath.ConfigFilename, cfg.KubernetesDeploymentSpec.Input, d.logger)
+		input, ok := deploymentInput(cfg)
+		if !ok {
+			return nil, fmt.Errorf("unsupport application kind %s", cfg.Kind)
+		}
+		in := *input
+		if target != nil {
+			appDir = filepath.Join(appDir, target.KustomizeDir)
+			in = target.ApplyTo(in)
+		}
+		loader := provider.NewManifestLoader(app.Name, appDir, repoDir, app.GitPath.ConfigFilename, in, d.logger)
+		var err error
 		manifests, err = loader.LoadManifests(ctx)
 		if err != nil {
 			err = fmt.Errorf("failed to load new manifests: %w", err)
@@ -319,16 +373,29 @@ func (d *detector) loadDeploymentConfiguration(repoPath string, app *model.Appli
 		return nil, fmt.Errorf("application in deployment configuration file is not match, got: %s, expected: %s", appKind, app.Kind)
 	}
 
-	if cfg.KubernetesDeploymentSpec != nil && cfg.KubernetesDeploymentSpec.Input.HelmChart != nil {
-		chartRepoName := cfg.KubernetesDeploymentSpec.Input.HelmChart.Repository
+	if input, ok := deploymentInput(cfg); ok && input.HelmChart != nil {
+		chartRepoName := input.HelmChart.Repository
 		if chartRepoName != "" {
-			cfg.KubernetesDeploymentSpec.Input.HelmChart.Insecure = d.config.IsInsecureChartRepository(chartRepoName)
+			input.HelmChart.Insecure = d.config.IsInsecureChartRepository(chartRepoName)
 		}
 	}
 
 	return cfg, nil
 }
 
+// deploymentInput returns the input used to load the manifests of
+// both Kubernetes and Crossplane applications.
+func deploymentInput(cfg *config.Config) (*config.KubernetesDeploymentInput, bool) {
+	switch {
+	case cfg.KubernetesDeploymentSpec != nil:
+		return &cfg.KubernetesDeploymentSpec.Input, true
+	case cfg.CrossplaneDeploymentSpec != nil:
+		return &cfg.CrossplaneDeploymentSpec.Input, true
+	default:
+		return nil, false
+	}
+}
+
 func (d *detector) ProviderName() string {
 	return d.provider.Name
 }
@@ -340,6 +407,10 @@ func filterIgnoringManifests(manifests []provider.Manifest) []provider.Manifest
 		if annotations[provider.LabelIgnoreDriftDirection] == provider.IgnoreDriftDetectionTrue {
 			continue
 		}
+		// Hooks are one-off jobs run while deploying so they are not compared.
+		if _, ok := annotations[provider.AnnotationHook]; ok {
+			continue
+		}
 		out = append(out, m)
 	}
 	return out
@@ -383,3 +454,39 @@ func makeSyncState(r *provider.DiffListResult, commit string) model.ApplicationS
 		Timestamp:   time.Now().Unix(),
 	}
 }
+
+type targetSyncState struct {
+	target string
+	state  model.ApplicationSyncState
+}
+
+// mergeTargetSyncStates aggregates the sync states of all targets into the one of the application.
+// The application is out of sync when any of its targets is out of sync.
+func mergeTargetSyncStates(states []targetSyncState) model.ApplicationSyncState {
+	var (
+		outOfSync []string
+		b         strings.Builder
+	)
+	for _, s := range states {
+		if s.state.Status == model.ApplicationSyncStatus_SYNCED {
+			continue
+		}
+		outOfSync = append(outOfSync, s.target)
+		b.WriteString(fmt.Sprintf("# Target %s: %s\n\n", s.target, s.state.ShortReason))
+		b.WriteString(s.state.Reason)
+		b.WriteString("\n")
+	}
+
+	if len(outOfSync) == 0 {
+		return model.ApplicationSyncState{
+			Status:    model.ApplicationSyncStatus_SYNCED,
+			Timestamp: time.Now().Unix(),
+		}
+	}
+	return model.ApplicationSyncState{
+		Status:      model.ApplicationSyncStatus_OUT_OF_SYNC,
+		ShortReason: fmt.Sprintf("%d of %d targets are not synced: %s", len(outOfSync), len(states), strings.Join(outOfSync, ", ")),
+		Reason:      b.String(),
+		Timestamp:   time.Now().Unix(),
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+func TestMergeTargetSyncStates(t *testing.T) {
+	synced := model.ApplicationSyncState{Status: model.ApplicationSyncStatus_SYNCED}
+	outOfSync := model.ApplicationSyncState{
+		Status:      model.ApplicationSyncStatus_OUT_OF_SYNC,
+		ShortReason: "There are 1 manifests not synced (1 adds, 0 deletes, 0 changes)",
+		Reason:      "diff",
+	}
+
+	got := mergeTargetSyncStates([]targetSyncState{
+		{target: "asia", state: synced},
+		{target: "us", state: synced},
+	})
+	assert.Equal(t, model.ApplicationSyncStatus_SYNCED, got.Status)
+
+	got = mergeTargetSyncStates([]targetSyncState{
+		{target: "asia", state: synced},
+		{target: "eu", state: outOfSync},
+		{target: "us", state: outOfSync},
+	})
+	assert.Equal(t, model.ApplicationSyncStatus_OUT_OF_SYNC, got.Status)
+	assert.Equal(t, "2 of 3 targets are not synced: eu, us", got.ShortReason)
+	assert.Contains(t, got.Reason, "# Target eu: There are 1 manifests not synced")
+	assert.Contains(t, got.Reason, "# Target us: There are 1 manifests not synced")
+	assert.NotContains(t, got.Reason, "asia")
+}
@@ -13,3 +13,176 @@
 // limitations under the License.
 
 package terraform
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/pipe-cd/pipe/pkg/app/piped/livestatestore/terraform"
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+const maxReportedResources = 20
+
+type applicationLister interface {
+	ListByCloudProvider(name string) []*model.Application
+}
+
+type reporter interface {
+	ReportApplicationSyncState(ctx context.Context, appID string, state model.ApplicationSyncState) error
+}
+
+type detector struct {
+	provider    config.PipedCloudProvider
+	appLister   applicationLister
+	stateGetter terraform.Getter
+	reporter    reporter
+	interval    time.Duration
+	logger      *zap.Logger
+}
+
+func NewDetector(
+	cp config.PipedCloudProvider,
+	appLister applicationLister,
+	stateGetter terraform.Getter,
+	reporter reporter,
+	logger *zap.Logger,
+) *detector {
+
+	logger = logger.Named("terraform-detector").With(
+		zap.String("cloud-provider", cp.Name),
+	)
+	return &detector{
+		provider:    cp,
+		appLister:   appLister,
+		stateGetter: stateGetter,
+		reporter:    reporter,
+		interval:    time.Minute,
+		logger:      logger,
+	}
+}
+
+func (d *detector) Run(ctx context.Context) error {
+	d.logger.Info("start running drift detector for terraform applications")
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+L:
+	for {
+		select {
+		case <-ticker.C:
+			d.check(ctx)
+
+		case <-ctx.Done():
+			break L
+		}
+	}
+
+	d.logger.Info("drift detector for terraform applications has been stopped")
+	return nil
+}
+
+func (d *detector) check(ctx context.Context) {
+	apps := d.appLister.ListByCloudProvider(d.provider.Name)
+	for _, app := range apps {
+		if app.Kind != model.ApplicationKind_TERRAFORM {
+			continue
+		}
+		state, ok := d.stateGetter.GetTerraformAppLiveState(app.Id)
+		if !ok {
+			d.logger.Info(fmt.Sprintf("no live state of terraform application %s to check", app.Id))
+			continue
+		}
+		syncState := makeSyncState(&state)
+		if err := d.reporter.ReportApplicationSyncState(ctx, app.Id, syncState); err != nil {
+			d.logger.Error(fmt.Sprintf("failed to report sync state of application: %s", app.Id), zap.Error(err))
+		}
+	}
+}
+
+func (d *detector) ProviderName() string {
+	return d.provider.Name
+}
+
+// makeSyncState decides the sync state from the resources drifted from the configuration
+// at the most recently deployed commit and the changes made to the application after that commit.
+func makeSyncState(state *terraform.AppState) model.ApplicationSyncState {
+	var (
+		resources  = state.Resources
+		commit     = shortHash(state.CommitHash)
+		headCommit = shortHash(state.HeadCommitHash)
+	)
+
+	if len(resources) == 0 {
+		if state.HasUndeployedChanges {
+			return model.ApplicationSyncState{
+				Status:      model.ApplicationSyncStatus_OUT_OF_SYNC,
+				ShortReason: "The changes in Git have not been applied yet",
+				Reason:      fmt.Sprintf("The configuration was changed at head commit %s after it was applied at commit %s.\n", headCommit, commit),
+				Timestamp:   time.Now().Unix(),
+			}
+		}
+		return model.ApplicationSyncState{
+			Status:      model.ApplicationSyncStatus_SYNCED,
+			ShortReason: "",
+			Reason:      "",
+			Timestamp:   time.Now().Unix(),
+		}
+	}
+
+	var updates, deletes int
+	for _, r := range resources {
+		if hasAction(r, "delete") {
+			deletes++
+		} else {
+			updates++
+		}
+	}
+	shortReason := fmt.Sprintf("There are %d resources changed outside of Terraform (%d changes, %d deletes)", len(resources), updates, deletes)
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Resources changed outside of Terraform since they were applied with the configuration at commit %s:\n\n", commit))
+	for i, r := range resources {
+		if i >= maxReportedResources {
+			b.WriteString(fmt.Sprintf("... (omitted %d other resources)\n", len(resources)-maxReportedResources))
+			break
+		}
+		if hasAction(r, "delete") {
+			b.WriteString(fmt.Sprintf("- %s: deleted\n", r.Address))
+			continue
+		}
+		b.WriteString(fmt.Sprintf("- %s: changed attributes [%s]\n", r.Address, strings.Join(r.ChangedAttributes, ", ")))
+	}
+	if state.HasUndeployedChanges {
+		b.WriteString(fmt.Sprintf("\nIn addition, the configuration was changed at head commit %s after it was applied.\n", headCommit))
+	}
+
+	return model.ApplicationSyncState{
+		Status:      model.ApplicationSyncStatus_OUT_OF_SYNC,
+		ShortReason: shortReason,
+		Reason:      b.String(),
+		Timestamp:   time.Now().Unix(),
+	}
+}
+
+func shortHash(commit string) string {
+	if len(commit) >= 7 {
+		return commit[:7]
+	}
+	return commit
+}
+
+func hasAction(r *model.TerraformResourceState, action string) bool {
+	for _, a := range r.Actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terraform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pipe-cd/pipe/pkg/app/piped/livestatestore/terraform"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+func TestMakeSyncState(t *testing.T) {
+	const (
+		deployedCommit = "0123456789abcdef"
+		headCommit     = "fedcba9876543210"
+	)
+	drifted := []*model.TerraformResourceState{
+		{Address: "aws_instance.web", Actions: []string{"update"}, ChangedAttributes: []string{"tags"}},
+		{Address: "aws_s3_bucket.logs", Actions: []string{"delete"}},
+	}
+
+	testcases := []struct {
+		name           string
+		state          *terraform.AppState
+		expectedStatus model.ApplicationSyncStatus
+		expectedReason []string
+	}{
+		{
+			name: "synced",
+			state: &terraform.AppState{
+				CommitHash:     deployedCommit,
+				HeadCommitHash: headCommit,
+			},
+			expectedStatus: model.ApplicationSyncStatus_SYNCED,
+		},
+		{
+			name: "undeployed changes",
+			state: &terraform.AppState{
+				CommitHash:           deployedCommit,
+				HeadCommitHash:       headCommit,
+				HasUndeployedChanges: true,
+			},
+			expectedStatus: model.ApplicationSyncStatus_OUT_OF_SYNC,
+			expectedReason: []string{"head commit fedcba9", "applied at commit 0123456"},
+		},
+		{
+			name: "drifted resources",
+			state: &terraform.AppState{
+				Resources:      drifted,
+				CommitHash:     deployedCommit,
+				HeadCommitHash: deployedCommit,
+			},
+			expectedStatus: model.ApplicationSyncStatus_OUT_OF_SYNC,
+			expectedReason: []string{"at commit 0123456", "aws_instance.web: changed attributes [tags]", "aws_s3_bucket.logs: deleted"},
+		},
+		{
+			name: "drifted resources and undeployed changes",
+			state: &terraform.AppState{
+				Resources:            drifted,
+				CommitHash:           deployedCommit,
+				HeadCommitHash:       headCommit,
+				HasUndeployedChanges: true,
+			},
+			expectedStatus: model.ApplicationSyncStatus_OUT_OF_SYNC,
+			expectedReason: []string{"at commit 0123456", "head commit fedcba9"},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := makeSyncState(tc.state)
+			assert.Equal(t, tc.expectedStatus, got.Status)
+			for _, r := range tc.expectedReason {
+				assert.Contains(t, got.Reason, r)
+			}
+		})
+	}
+}
@@ -24,7 +24,6 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
-	"strconv"
 	"sync"
 	"time"
 
@@ -277,7 +276,7 @@ func modifyYAML(path, field, newValue string) ([]byte, bool, error) {
 	if err != nil {
 		return nil, false, fmt.Errorf("failed to get value at %s in %s: %w", field, path, err)
 	}
-	value, err := convertStr(v)
+	value, err := yamlprocessor.ToString(v)
 	if err != nil {
 		return nil, false, fmt.Errorf("a value of unknown type is defined at %s in %s: %w", field, path, err)
 	}
@@ -293,24 +292,3 @@ func modifyYAML(path, field, newValue string) ([]byte, bool, error) {
 	}
 	return newYml, false, nil
 }
-
-// convertStr converts a given value into a string.
-func convertStr(value interface{}) (out string, err error) {
-	switch v := value.(type) {
-	case string:
-		out = v
-	case int:
-		out = strconv.Itoa(v)
-	case int64:
-		out = strconv.FormatInt(v, 10)
-	case uint64:
-		out = strconv.FormatUint(v, 10)
-	case float64:
-		out = strconv.FormatFloat(v, 'f', -1, 64)
-	case bool:
-		out = strconv.FormatBool(v)
-	default:
-		err = fmt.Errorf("failed to convert %T into string", v)
-	}
-	return
-}
@@ -20,65 +20,6 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func TestConvertStr(t *testing.T) {
-	testcases := []struct {
-		name    string
-		value   interface{}
-		want    string
-		wantErr bool
-	}{
-		{
-			name:    "string",
-			value:   "value",
-			want:    "value",
-			wantErr: false,
-		},
-		{
-			name:    "int",
-			value:   1,
-			want:    "1",
-			wantErr: false,
-		},
-		{
-			name:    "int64",
-			value:   int64(1),
-			want:    "1",
-			wantErr: false,
-		},
-		{
-			name:    "uint64",
-			value:   uint64(1),
-			want:    "1",
-			wantErr: false,
-		},
-		{
-			name:    "float64",
-			value:   1.1,
-			want:    "1.1",
-			wantErr: false,
-		},
-		{
-			name:    "bool",
-			value:   true,
-			want:    "true",
-			wantErr: false,
-		},
-		{
-			name:    "map",
-			value:   make(map[string]interface{}),
-			want:    "",
-			wantErr: true,
-		},
-	}
-	for _, tc := range testcases {
-		t.Run(tc.name, func(t *testing.T) {
-			got, err := convertStr(tc.value)
-			assert.Equal(t, tc.wantErr, err != nil)
-			assert.Equal(t, tc.want, got)
-		})
-	}
-}
-
 func TestModifyYAML(t *testing.T) {
 	testcases := []struct {
 		name         string
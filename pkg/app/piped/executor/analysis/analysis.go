@@ -23,6 +23,7 @@ import (
 	"text/template"
 	"time"
 
+	"go.uber.org/atomic"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 
@@ -64,6 +65,9 @@ type templateArgs struct {
 	App struct {
 		Name string
 		Env  string
+		// The URL to access the canary version directly.
+		// It is set only for the Cloud Run applications using canaryTrafficTag.
+		CanaryURL string
 	}
 	K8s struct {
 		Namespace string
@@ -83,6 +87,11 @@ func (e *Executor) Execute(sig executor.StopSignal) model.StageStatus {
 		return model.StageStatus_STAGE_FAILURE
 	}
 
+	// The stage was skipped before starting, e.g. while fast-forwarding.
+	if e.CheckSkipCommand(ctx) {
+		return model.StageStatus_STAGE_SKIPPED
+	}
+
 	ds, err := e.RunningDSP.Get(ctx, e.LogPersister)
 	if err != nil {
 		e.LogPersister.Errorf("Failed to prepare running deploy source data (%v)", err)
@@ -150,28 +159,66 @@ func (e *Executor) Execute(sig executor.StopSignal) model.StageStatus {
 		})
 	}
 
-	if err := eg.Wait(); err != nil {
+	// Stop all analyzers as soon as a SKIP_STAGE command was received.
+	var (
+		skipped     = atomic.NewBool(false)
+		watcherDone = make(chan struct{})
+	)
+	go func() {
+		defer close(watcherDone)
+		ticker := time.NewTicker(skipCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if e.CheckSkipCommand(ctx) {
+					skipped.Store(true)
+					cancel()
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	err = eg.Wait()
+	cancel()
+	<-watcherDone
+
+	if err != nil {
 		e.LogPersister.Errorf("Analysis failed: %s", err.Error())
 		return model.StageStatus_STAGE_FAILURE
 	}
 
-	status := executor.DetermineStageStatus(sig.Signal(), e.Stage.Status, model.StageStatus_STAGE_SUCCESS)
+	got := model.StageStatus_STAGE_SUCCESS
+	if skipped.Load() {
+		got = model.StageStatus_STAGE_SKIPPED
+	}
+	status := executor.DetermineStageStatus(sig.Signal(), e.Stage.Status, got)
 	if status == model.StageStatus_STAGE_SUCCESS {
 		e.LogPersister.Success("All analyses were successful.")
 	}
 	return status
 }
 
-const elapsedTimeKey = "elapsedTime"
+const (
+	elapsedTimeKey    = "elapsedTime"
+	skipCheckInterval = 5 * time.Second
+)
 
 // saveElapsedTime stores the elapsed time of analysis stage into metadata persister.
 // The analysis stage can be restarted from the middle even if it ends unexpectedly,
 // that's why count should be stored.
 func (e *Executor) saveElapsedTime(ctx context.Context) {
 	elapsedTime := time.Since(e.startTime) + e.previousElapsedTime
-	metadata := map[string]string{
-		elapsedTimeKey: elapsedTime.String(),
+	metadata := make(map[string]string)
+	if ori, ok := e.MetadataStore.GetStageMetadata(e.Stage.Id); ok {
+		for k, v := range ori {
+			metadata[k] = v
+		}
 	}
+	metadata[elapsedTimeKey] = elapsedTime.String()
 	if err := e.MetadataStore.SetStageMetadata(ctx, e.Stage.Id, metadata); err != nil {
 		e.Logger.Error("failed to store metadata", zap.Error(err))
 	}
@@ -340,11 +387,11 @@ func (e *Executor) getHTTPConfig(templatableCfg *config.TemplatableAnalysisHTTP,
 func (e *Executor) render(templateCfg config.AnalysisTemplateSpec, customArgs map[string]string) (*config.AnalysisTemplateSpec, error) {
 	args := templateArgs{
 		Args: customArgs,
-		App: struct {
-			Name string
-			Env  string
-			// TODO: Populate Env
-		}{Name: e.Application.Name, Env: ""},
+	}
+	// TODO: Populate Env
+	args.App.Name = e.Application.Name
+	if url, ok := e.MetadataStore.Get(model.DeploymentCanaryURLMetadataKey); ok {
+		args.App.CanaryURL = url
 	}
 	if e.config.Kind == config.KindKubernetesApp {
 		namespace := "default"
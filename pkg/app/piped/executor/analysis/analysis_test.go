@@ -0,0 +1,102 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analysis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pipe-cd/pipe/pkg/app/piped/executor"
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+type fakeMetadataStore struct {
+	metadata map[string]string
+}
+
+func (s *fakeMetadataStore) Get(key string) (string, bool) {
+	v, ok := s.metadata[key]
+	return v, ok
+}
+
+func (s *fakeMetadataStore) Set(_ context.Context, key, value string) error {
+	s.metadata[key] = value
+	return nil
+}
+
+func (s *fakeMetadataStore) GetStageMetadata(_ string) (map[string]string, bool) {
+	return nil, false
+}
+
+func (s *fakeMetadataStore) SetStageMetadata(_ context.Context, _ string, _ map[string]string) error {
+	return nil
+}
+
+func TestRender(t *testing.T) {
+	templateCfg := config.AnalysisTemplateSpec{
+		Metrics: map[string]config.AnalysisMetrics{
+			"error_rate": {
+				Provider: "prometheus",
+				Query:    `http_requests_total{app="{{ .App.Name }}",url="{{ .App.CanaryURL }}",code="{{ .Args.code }}"}`,
+			},
+		},
+		HTTPs: map[string]config.AnalysisHTTP{
+			"health": {
+				URL:    "{{ .App.CanaryURL }}/healthz",
+				Method: "GET",
+			},
+		},
+	}
+	testcases := []struct {
+		name        string
+		metadata    map[string]string
+		wantQuery   string
+		wantHTTPURL string
+	}{
+		{
+			name:        "no canary url",
+			metadata:    map[string]string{},
+			wantQuery:   `http_requests_total{app="app-name",url="",code="500"}`,
+			wantHTTPURL: "/healthz",
+		},
+		{
+			name: "canary url is available",
+			metadata: map[string]string{
+				model.DeploymentCanaryURLMetadataKey: "https://canary---app-name-abc.a.run.app",
+			},
+			wantQuery:   `http_requests_total{app="app-name",url="https://canary---app-name-abc.a.run.app",code="500"}`,
+			wantHTTPURL: "https://canary---app-name-abc.a.run.app/healthz",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := &Executor{
+				Input: executor.Input{
+					Application:   &model.Application{Name: "app-name"},
+					MetadataStore: &fakeMetadataStore{metadata: tc.metadata},
+				},
+				config: &config.Config{Kind: config.KindCloudRunApp},
+			}
+			got, err := e.render(templateCfg, map[string]string{"code": "500"})
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantQuery, got.Metrics["error_rate"].Query)
+			assert.Equal(t, tc.wantHTTPURL, got.HTTPs["health"].URL)
+		})
+	}
+}
@@ -71,6 +71,10 @@ func (a *analyzer) run(ctx context.Context) error {
 			if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == context.DeadlineExceeded {
 				return nil
 			}
+			// The analysis was stopped by the parent, e.g. the stage was skipped.
+			if errors.Is(err, context.Canceled) && ctx.Err() == context.Canceled {
+				return nil
+			}
 			if errors.Is(err, metrics.ErrNoDataFound) && a.skipOnNoData {
 				a.logPersister.Infof("[%s] The query result evaluation was skipped because \"skipOnNoData\" is true even though no data returned. Reason: %v. Performed query: %q", a.id, err, a.query)
 				continue
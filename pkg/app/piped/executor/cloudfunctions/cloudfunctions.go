@@ -0,0 +1,283 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudfunctions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/cloudfunctions"
+	"github.com/pipe-cd/pipe/pkg/app/piped/deploysource"
+	"github.com/pipe-cd/pipe/pkg/app/piped/executor"
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+type registerer interface {
+	Register(stage model.Stage, f executor.Factory) error
+	RegisterRollback(kind model.ApplicationKind, f executor.Factory) error
+}
+
+func Register(r registerer) {
+	f := func(in executor.Input) executor.Executor {
+		return &deployExecutor{
+			Input: in,
+		}
+	}
+	r.Register(model.StageCloudFunctionsSync, f)
+	r.Register(model.StageCloudFunctionsPromote, f)
+
+	r.RegisterRollback(model.ApplicationKind_CLOUDFUNCTIONS, func(in executor.Input) executor.Executor {
+		return &rollbackExecutor{
+			Input: in,
+		}
+	})
+}
+
+func findCloudProvider(in *executor.Input) (name string, cfg *config.CloudProviderCloudFunctionsConfig, found bool) {
+	name = in.Application.CloudProvider
+	if name == "" {
+		in.LogPersister.Error("Missing the CloudProvider name in the application configuration")
+		return
+	}
+
+	cp, ok := in.PipedConfig.FindCloudProvider(name, model.CloudProviderCloudFunctions)
+	if !ok {
+		in.LogPersister.Errorf("The specified cloud provider %q was not found in piped configuration", name)
+		return
+	}
+
+	cfg = cp.CloudFunctionsConfig
+	found = true
+	return
+}
+
+func newClient(ctx context.Context, in *executor.Input) (provider.Client, bool) {
+	cloudProviderName, cloudProviderCfg, found := findCloudProvider(in)
+	if !found {
+		return nil, false
+	}
+
+	client, err := provider.DefaultRegistry().Client(ctx, cloudProviderName, cloudProviderCfg, in.Logger)
+	if err != nil {
+		in.LogPersister.Errorf("Unable to create Cloud Functions client for the provider %s (%v)", cloudProviderName, err)
+		return nil, false
+	}
+	return client, true
+}
+
+// loadFunctionManifest loads the function manifest at the given deploy source
+// and attaches the labels used by PipeCD to find the function of the application.
+func loadFunctionManifest(in *executor.Input, functionManifestFile string, ds *deploysource.DeploySource) (provider.FunctionManifest, bool) {
+	in.LogPersister.Infof("Loading function manifest at commit %s", ds.Revision)
+
+	fm, err := provider.LoadFunctionManifest(ds.AppDir, functionManifestFile)
+	if err != nil {
+		in.LogPersister.Errorf("Failed to load function manifest (%v)", err)
+		return provider.FunctionManifest{}, false
+	}
+	fm.Spec.Labels = provider.MakeLabels(fm.Spec.Labels, in.PipedConfig.PipedID, in.Deployment.ApplicationId, ds.Revision)
+
+	in.LogPersister.Infof("Successfully loaded the function manifest at commit %s", ds.Revision)
+	return fm, true
+}
+
+// sync deploys the given function manifest and routes all traffic to the new revision.
+// The function will be created when it does not exist yet.
+func sync(ctx context.Context, in *executor.Input, client provider.Client, fm provider.FunctionManifest) bool {
+	name := fm.Spec.Name
+	in.LogPersister.Infof("Start syncing the function %s", name)
+
+	_, err := client.GetFunction(ctx, name)
+	if errors.Is(err, provider.ErrNotFound) {
+		in.LogPersister.Infof("Function %s was not found, a new function will be created", name)
+		fn, err := client.CreateFunction(ctx, fm)
+		if err != nil {
+			in.LogPersister.Errorf("Failed to create the function %s (%v)", name, err)
+			return false
+		}
+		in.LogPersister.Successf("Successfully created the function %s with revision %s", name, fn.LatestRevision())
+		return true
+	}
+	if err != nil {
+		in.LogPersister.Errorf("Unable to get the function %s (%v)", name, err)
+		return false
+	}
+
+	if _, ok := recordRunningRevision(ctx, in, client, name); !ok {
+		return false
+	}
+
+	fn, err := client.UpdateFunction(ctx, fm, true)
+	if err != nil {
+		in.LogPersister.Errorf("Failed to update the function %s (%v)", name, err)
+		return false
+	}
+
+	in.LogPersister.Successf("Successfully updated the function %s and configured all traffic to revision %s", name, fn.LatestRevision())
+	return true
+}
+
+// promote routes the given percentage of traffic to the revision deployed by this deployment
+// and the rest to the revision running before the deployment.
+// The new revision is deployed at the first promotion and reused by the subsequent ones.
+func promote(ctx context.Context, in *executor.Input, client provider.Client, fm provider.FunctionManifest, percent int) bool {
+	name := fm.Spec.Name
+	newRevisionKeyName := fmt.Sprintf("%s-new-revision", name)
+
+	revision, ok := in.MetadataStore.Get(newRevisionKeyName)
+	if !ok {
+		_, err := client.GetFunction(ctx, name)
+		if errors.Is(err, provider.ErrNotFound) {
+			in.LogPersister.Errorf("Function %s was not found, it must be deployed by a quick sync before being promoted", name)
+			return false
+		}
+		if err != nil {
+			in.LogPersister.Errorf("Unable to get the function %s (%v)", name, err)
+			return false
+		}
+
+		if _, ok := recordRunningRevision(ctx, in, client, name); !ok {
+			return false
+		}
+
+		in.LogPersister.Infof("Start deploying a new revision of the function %s without routing traffic to it", name)
+		fn, err := client.UpdateFunction(ctx, fm, false)
+		if err != nil {
+			in.LogPersister.Errorf("Failed to update the function %s (%v)", name, err)
+			return false
+		}
+		revision = fn.LatestRevision()
+		if err := in.MetadataStore.Set(ctx, newRevisionKeyName, revision); err != nil {
+			in.LogPersister.Errorf("Unable to store the new revision of the function %s to metadata store (%v)", name, err)
+			return false
+		}
+		in.LogPersister.Successf("Successfully deployed revision %s", revision)
+	}
+
+	runningRevision, ok := in.MetadataStore.Get(runningRevisionKeyName(name))
+	if !ok {
+		in.LogPersister.Errorf("Unable to find the running revision of the function %s", name)
+		return false
+	}
+
+	traffics := makePromoteTraffics(revision, runningRevision, percent)
+	in.LogPersister.Info("Start configuring traffic percentages")
+	for _, t := range traffics {
+		in.LogPersister.Infof("  %s: %d", t.RevisionName, t.Percent)
+	}
+	if err := client.UpdateTraffic(ctx, name, traffics); err != nil {
+		in.LogPersister.Errorf("Failed to update the traffic of the function %s (%v)", name, err)
+		return false
+	}
+
+	in.LogPersister.Successf("Successfully promoted revision %s to %d%% of traffic", revision, percent)
+	return true
+}
+
+// rollback routes all traffic back to the revision running before the deployment if it was recorded,
+// and then redeploys the given function manifest of the running commit.
+func rollback(ctx context.Context, in *executor.Input, client provider.Client, fm provider.FunctionManifest) bool {
+	name := fm.Spec.Name
+
+	if revision, ok := in.MetadataStore.Get(runningRevisionKeyName(name)); ok {
+		in.LogPersister.Infof("Start routing all traffic back to revision %s", revision)
+		traffics := []provider.RevisionTraffic{
+			{
+				RevisionName: revision,
+				Percent:      100,
+			},
+		}
+		if err := client.UpdateTraffic(ctx, name, traffics); err != nil {
+			in.LogPersister.Errorf("Failed to update the traffic of the function %s (%v)", name, err)
+			return false
+		}
+		in.LogPersister.Successf("Successfully routed all traffic to revision %s", revision)
+	}
+
+	in.LogPersister.Infof("Start redeploying the function %s at the running commit", name)
+	fn, err := client.UpdateFunction(ctx, fm, true)
+	if err != nil {
+		in.LogPersister.Errorf("Failed to update the function %s (%v)", name, err)
+		return false
+	}
+
+	in.LogPersister.Successf("Successfully rolled back the function %s to revision %s", name, fn.LatestRevision())
+	return true
+}
+
+func runningRevisionKeyName(name string) string {
+	return fmt.Sprintf("%s-running-revision", name)
+}
+
+// recordRunningRevision stores the revision receiving the most traffic into the metadata store
+// so that the traffic can be routed back to it while rolling back.
+// The one recorded by the previous stages is kept as is.
+func recordRunningRevision(ctx context.Context, in *executor.Input, client provider.Client, name string) (string, bool) {
+	key := runningRevisionKeyName(name)
+	if revision, ok := in.MetadataStore.Get(key); ok {
+		return revision, true
+	}
+
+	traffics, err := client.GetTraffic(ctx, name)
+	if err != nil {
+		in.LogPersister.Errorf("Unable to get the traffic of the function %s (%v)", name, err)
+		return "", false
+	}
+
+	var running provider.RevisionTraffic
+	for _, t := range traffics {
+		if t.Percent > running.Percent {
+			running = t
+		}
+	}
+	if running.RevisionName == "" {
+		in.LogPersister.Errorf("Unable to find the revision receiving traffic of the function %s", name)
+		return "", false
+	}
+
+	if err := in.MetadataStore.Set(ctx, key, running.RevisionName); err != nil {
+		in.LogPersister.Errorf("Unable to store the running revision of the function %s to metadata store (%v)", name, err)
+		return "", false
+	}
+	return running.RevisionName, true
+}
+
+func makePromoteTraffics(revision, runningRevision string, percent int) []provider.RevisionTraffic {
+	if revision == runningRevision {
+		return []provider.RevisionTraffic{
+			{
+				RevisionName: revision,
+				Percent:      100,
+			},
+		}
+	}
+
+	traffics := make([]provider.RevisionTraffic, 0, 2)
+	if percent > 0 {
+		traffics = append(traffics, provider.RevisionTraffic{
+			RevisionName: revision,
+			Percent:      percent,
+		})
+	}
+	if percent < 100 {
+		traffics = append(traffics, provider.RevisionTraffic{
+			RevisionName: runningRevision,
+			Percent:      100 - percent,
+		})
+	}
+	return traffics
+}
@@ -0,0 +1,172 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudfunctions
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/cloudfunctions"
+	"github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/cloudfunctions/cloudfunctionsfake"
+	"github.com/pipe-cd/pipe/pkg/app/piped/executor"
+)
+
+type fakeLogPersister struct{}
+
+func (l *fakeLogPersister) Write(_ []byte) (int, error)         { return 0, nil }
+func (l *fakeLogPersister) Info(_ string)                       {}
+func (l *fakeLogPersister) Infof(_ string, _ ...interface{})    {}
+func (l *fakeLogPersister) Success(_ string)                    {}
+func (l *fakeLogPersister) Successf(_ string, _ ...interface{}) {}
+func (l *fakeLogPersister) Error(_ string)                      {}
+func (l *fakeLogPersister) Errorf(_ string, _ ...interface{})   {}
+
+type fakeMetadataStore struct {
+	values map[string]string
+}
+
+func (m *fakeMetadataStore) Get(key string) (string, bool) {
+	v, ok := m.values[key]
+	return v, ok
+}
+
+func (m *fakeMetadataStore) Set(_ context.Context, key, value string) error {
+	m.values[key] = value
+	return nil
+}
+
+func (m *fakeMetadataStore) GetStageMetadata(_ string) (map[string]string, bool) { return nil, false }
+func (m *fakeMetadataStore) SetStageMetadata(_ context.Context, _ string, _ map[string]string) error {
+	return nil
+}
+
+func newTestInput() *executor.Input {
+	return &executor.Input{
+		LogPersister:  &fakeLogPersister{},
+		MetadataStore: &fakeMetadataStore{values: make(map[string]string)},
+		Logger:        zap.NewNop(),
+	}
+}
+
+func newTestManifest(object string) provider.FunctionManifest {
+	return provider.FunctionManifest{
+		Kind:       "CloudFunction",
+		APIVersion: "pipecd.dev/v1beta1",
+		Spec: provider.FunctionManifestSpec{
+			Name:       "hello",
+			Runtime:    "go116",
+			EntryPoint: "Hello",
+			Source: provider.FunctionSource{
+				Bucket: "pipecd-functions",
+				Object: object,
+			},
+		},
+	}
+}
+
+func TestSyncAndRollback(t *testing.T) {
+	ctx := context.Background()
+	client := cloudfunctionsfake.NewClient("project", "region")
+
+	// The function is created at the first sync.
+	in := newTestInput()
+	require.True(t, sync(ctx, in, client, newTestManifest("v1.zip")))
+	traffics, err := client.GetTraffic(ctx, "hello")
+	require.NoError(t, err)
+	assert.Equal(t, []provider.RevisionTraffic{{RevisionName: "hello-00001", Percent: 100}}, traffics)
+
+	// All traffic is routed to the new revision.
+	in = newTestInput()
+	require.True(t, sync(ctx, in, client, newTestManifest("v2.zip")))
+	traffics, err = client.GetTraffic(ctx, "hello")
+	require.NoError(t, err)
+	assert.Equal(t, []provider.RevisionTraffic{{RevisionName: "hello-00002", Percent: 100}}, traffics)
+
+	running, ok := in.MetadataStore.Get(runningRevisionKeyName("hello"))
+	require.True(t, ok)
+	assert.Equal(t, "hello-00001", running)
+
+	// The function is redeployed with the manifest of the running commit.
+	require.True(t, rollback(ctx, in, client, newTestManifest("v1.zip")))
+	fn, err := client.GetFunction(ctx, "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "v1.zip", fn.BuildConfig.Source.StorageSource.Object)
+	traffics, err = client.GetTraffic(ctx, "hello")
+	require.NoError(t, err)
+	assert.Equal(t, []provider.RevisionTraffic{{RevisionName: "hello-00003", Percent: 100}}, traffics)
+}
+
+func TestPromote(t *testing.T) {
+	ctx := context.Background()
+	client := cloudfunctionsfake.NewClient("project", "region")
+	in := newTestInput()
+
+	// The function must exist before being promoted.
+	require.False(t, promote(ctx, in, client, newTestManifest("v1.zip"), 10))
+
+	_, err := client.CreateFunction(ctx, newTestManifest("v1.zip"))
+	require.NoError(t, err)
+
+	require.True(t, promote(ctx, in, client, newTestManifest("v2.zip"), 10))
+	traffics, err := client.GetTraffic(ctx, "hello")
+	require.NoError(t, err)
+	assert.Equal(t, []provider.RevisionTraffic{
+		{RevisionName: "hello-00002", Percent: 10},
+		{RevisionName: "hello-00001", Percent: 90},
+	}, traffics)
+
+	// The same revision is promoted again without being redeployed.
+	require.True(t, promote(ctx, in, client, newTestManifest("v2.zip"), 100))
+	traffics, err = client.GetTraffic(ctx, "hello")
+	require.NoError(t, err)
+	assert.Equal(t, []provider.RevisionTraffic{{RevisionName: "hello-00002", Percent: 100}}, traffics)
+}
+
+func TestMakePromoteTraffics(t *testing.T) {
+	testcases := []struct {
+		name     string
+		percent  int
+		expected []provider.RevisionTraffic
+	}{
+		{
+			name:    "split",
+			percent: 30,
+			expected: []provider.RevisionTraffic{
+				{RevisionName: "new", Percent: 30},
+				{RevisionName: "running", Percent: 70},
+			},
+		},
+		{
+			name:     "no traffic to new revision",
+			percent:  0,
+			expected: []provider.RevisionTraffic{{RevisionName: "running", Percent: 100}},
+		},
+		{
+			name:     "all traffic to new revision",
+			percent:  100,
+			expected: []provider.RevisionTraffic{{RevisionName: "new", Percent: 100}},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := makePromoteTraffics("new", "running", tc.percent)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
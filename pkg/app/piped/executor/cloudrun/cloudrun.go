@@ -16,6 +16,7 @@ package cloudrun
 
 import (
 	"context"
+	"time"
 
 	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/cloudrun"
 	"github.com/pipe-cd/pipe/pkg/app/piped/deploysource"
@@ -24,6 +25,11 @@ import (
 	"github.com/pipe-cd/pipe/pkg/model"
 )
 
+const (
+	trafficCheckInterval = 5 * time.Second
+	trafficCheckTimeout  = 10 * time.Minute
+)
+
 type registerer interface {
 	Register(stage model.Stage, f executor.Factory) error
 	RegisterRollback(kind model.ApplicationKind, f executor.Factory) error
@@ -53,6 +59,12 @@ func loadServiceManifest(in *executor.Input, serviceManifestFile string, ds *dep
 		in.LogPersister.Errorf("Failed to load service manifest (%v)", err)
 		return provider.ServiceManifest{}, false
 	}
+	sm.AddLabels(map[string]string{
+		provider.LabelManagedBy:   provider.ManagedByPiped,
+		provider.LabelPiped:       in.PipedConfig.PipedID,
+		provider.LabelApplication: in.Deployment.ApplicationId,
+		provider.LabelCommitHash:  ds.Revision,
+	})
 
 	in.LogPersister.Infof("Successfully loaded the service manifest at commit %s", ds.Revision)
 	return sm, true
@@ -107,32 +119,74 @@ func configureServiceManifest(in *executor.Input, sm provider.ServiceManifest, r
 	return true
 }
 
-func apply(ctx context.Context, in *executor.Input, cloudProviderName string, cloudProviderCfg *config.CloudProviderCloudRunConfig, sm provider.ServiceManifest) bool {
+func apply(ctx context.Context, in *executor.Input, cloudProviderName string, cloudProviderCfg *config.CloudProviderCloudRunConfig, sm provider.ServiceManifest, traffics []provider.RevisionTraffic) (*provider.Service, bool) {
 	in.LogPersister.Info("Start applying the service manifest")
 	client, err := provider.DefaultRegistry().Client(ctx, cloudProviderName, cloudProviderCfg, in.Logger)
 	if err != nil {
 		in.LogPersister.Errorf("Unable to create ClourRun client for the provider (%v)", err)
-		return false
+		return nil, false
 	}
 
-	_, err = client.Update(ctx, sm)
+	svc, err := client.Update(ctx, sm)
 	if err == nil {
 		in.LogPersister.Infof("Successfully updated the service %s", sm.Name)
-		return true
+		return waitForTrafficApplied(ctx, in, client, sm.Name, svc, traffics)
 	}
 
 	if err != provider.ErrServiceNotFound {
 		in.LogPersister.Errorf("Failed to update the service %s (%v)", sm.Name, err)
-		return false
+		return nil, false
 	}
 
 	in.LogPersister.Infof("Service %s was not found, a new service will be created", sm.Name)
 
-	if _, err := client.Create(ctx, sm); err != nil {
+	svc, err = client.Create(ctx, sm)
+	if err != nil {
 		in.LogPersister.Errorf("Failed to create the service %s (%v)", sm.Name, err)
-		return false
+		return nil, false
 	}
 
 	in.LogPersister.Infof("Successfully created the service %s", sm.Name)
-	return true
+	return waitForTrafficApplied(ctx, in, client, sm.Name, svc, traffics)
+}
+
+// waitForTrafficApplied polls the service until it has been reconciled to the applied generation
+// and is routing traffic as configured. The service observed at last is returned.
+func waitForTrafficApplied(ctx context.Context, in *executor.Input, client provider.Client, name string, svc *provider.Service, traffics []provider.RevisionTraffic) (*provider.Service, bool) {
+	var generation int64
+	if svc.Metadata != nil {
+		generation = svc.Metadata.Generation
+	}
+	in.LogPersister.Infof("Waiting for the service %s to apply the configured traffic", name)
+
+	ctx, cancel := context.WithTimeout(ctx, trafficCheckTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(trafficCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		applied, err := svc.CheckTrafficApplied(generation, traffics)
+		if err != nil {
+			in.LogPersister.Errorf("Failed to apply the configured traffic to the service %s (%v)", name, err)
+			return nil, false
+		}
+		if applied {
+			in.LogPersister.Successf("The service %s is routing traffic as configured", name)
+			return svc, true
+		}
+
+		select {
+		case <-ctx.Done():
+			in.LogPersister.Errorf("The configured traffic was not applied to the service %s before the deadline (%v)", name, ctx.Err())
+			return nil, false
+		case <-ticker.C:
+		}
+
+		svc, err = client.Get(ctx, name)
+		if err != nil {
+			in.LogPersister.Errorf("Unable to get the service %s (%v)", name, err)
+			return nil, false
+		}
+	}
 }
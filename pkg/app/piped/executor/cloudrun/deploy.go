@@ -27,7 +27,9 @@ import (
 	"go.uber.org/zap"
 )
 
-const promotePercentageMetadataKey = "promote-percentage"
+const (
+	promotePercentageMetadataKey = "promote-percentage"
+)
 
 type deployExecutor struct {
 	executor.Input
@@ -100,7 +102,7 @@ func (e *deployExecutor) ensureSync(ctx context.Context) model.StageStatus {
 		return model.StageStatus_STAGE_FAILURE
 	}
 
-	if !apply(ctx, &e.Input, e.cloudProviderName, e.cloudProviderCfg, sm) {
+	if _, ok := apply(ctx, &e.Input, e.cloudProviderName, e.cloudProviderCfg, sm, traffics); !ok {
 		return model.StageStatus_STAGE_FAILURE
 	}
 
@@ -163,6 +165,7 @@ func (e *deployExecutor) ensurePromote(ctx context.Context) model.StageStatus {
 		{
 			RevisionName: revision,
 			Percent:      options.Percent.Int(),
+			Tag:          e.deployCfg.Input.CanaryTrafficTag,
 		},
 		{
 			RevisionName: lastDeployedRevision,
@@ -173,10 +176,24 @@ func (e *deployExecutor) ensurePromote(ctx context.Context) model.StageStatus {
 		return model.StageStatus_STAGE_FAILURE
 	}
 
-	if !apply(ctx, &e.Input, e.cloudProviderName, e.cloudProviderCfg, sm) {
+	svc, ok := apply(ctx, &e.Input, e.cloudProviderName, e.cloudProviderCfg, sm, traffics)
+	if !ok {
 		return model.StageStatus_STAGE_FAILURE
 	}
 
-	// TODO: Wait to ensure the traffic was fully configured.
+	if tag := e.deployCfg.Input.CanaryTrafficTag; tag != "" {
+		url, ok := svc.FindTrafficTagURL(tag)
+		if !ok {
+			e.LogPersister.Errorf("Unable to find the URL of traffic tag %s", tag)
+			return model.StageStatus_STAGE_FAILURE
+		}
+		e.LogPersister.Infof("The new revision can be accessed directly via %s", url)
+		// Save it to the deployment metadata to be used by the following stages such as ANALYSIS.
+		if err := e.MetadataStore.Set(ctx, model.DeploymentCanaryURLMetadataKey, url); err != nil {
+			e.LogPersister.Errorf("Unable to save the canary URL to the deployment metadata (%v)", err)
+			return model.StageStatus_STAGE_FAILURE
+		}
+	}
+
 	return model.StageStatus_STAGE_SUCCESS
 }
@@ -89,7 +89,7 @@ func (e *rollbackExecutor) ensureRollback(ctx context.Context) model.StageStatus
 		return model.StageStatus_STAGE_FAILURE
 	}
 
-	if !apply(ctx, &e.Input, cloudProviderName, cloudProviderCfg, sm) {
+	if _, ok := apply(ctx, &e.Input, cloudProviderName, cloudProviderCfg, sm, traffics); !ok {
 		return model.StageStatus_STAGE_FAILURE
 	}
 
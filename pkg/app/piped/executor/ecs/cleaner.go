@@ -0,0 +1,109 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ecs
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"go.uber.org/zap"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/ecs"
+	"github.com/pipe-cd/pipe/pkg/app/piped/executor"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+// cleaner deregisters the task definition revisions those are no longer needed
+// after the deployment succeeded.
+type cleaner struct {
+	executor.Input
+}
+
+// Clean records the revision of task definition deployed by this deployment to the service
+// and deregisters the revisions those were not deployed by the latest successful deployments.
+func (c *cleaner) Clean(ctx context.Context) error {
+	ds, err := c.TargetDSP.GetReadOnly(ctx, ioutil.Discard)
+	if err != nil {
+		return fmt.Errorf("failed to prepare target deploy source data: %w", err)
+	}
+	deployCfg := ds.DeploymentConfig.ECSDeploymentSpec
+	if deployCfg == nil {
+		return fmt.Errorf("malformed deployment configuration: missing ECSDeploymentSpec")
+	}
+	limit := deployCfg.Input.TaskDefinitionRevisionHistoryLimit
+	if limit <= 0 {
+		return nil
+	}
+
+	taskDefinitionArn, ok := c.MetadataStore.Get(primaryTaskDefinitionArnKeyName)
+	if !ok {
+		c.Logger.Info("skip cleaning up task definitions since no PRIMARY task set was created by this deployment")
+		return nil
+	}
+	serviceArn, ok := c.MetadataStore.Get(primaryServiceArnKeyName)
+	if !ok {
+		return fmt.Errorf("missing the service of PRIMARY task set in metadata")
+	}
+	family, revision, err := provider.ParseTaskDefinitionArn(taskDefinitionArn)
+	if err != nil {
+		return err
+	}
+
+	cp, ok := c.PipedConfig.FindCloudProvider(c.Application.CloudProvider, model.CloudProviderECS)
+	if !ok {
+		return fmt.Errorf("cloud provider %s was not found in piped configuration", c.Application.CloudProvider)
+	}
+	client, err := provider.DefaultRegistry().Client(cp.Name, cp.ECSConfig, c.Logger)
+	if err != nil {
+		return fmt.Errorf("failed to create ECS client for the provider %s: %w", cp.Name, err)
+	}
+
+	// Record the revision to the service since the revisions deployed by the previous deployments
+	// can not be determined from the task definitions themselves.
+	tags, err := client.ListTags(ctx, serviceArn)
+	if err != nil {
+		return err
+	}
+	var recorded []int
+	for _, t := range tags {
+		if aws.ToString(t.Key) == provider.LabelTaskDefinitionRevisions {
+			recorded = provider.ParseTaskDefinitionRevisions(aws.ToString(t.Value))
+			break
+		}
+	}
+	kept := provider.AddTaskDefinitionRevision(recorded, revision, limit)
+	err = client.AddTags(ctx, serviceArn, []types.Tag{{
+		Key:   aws.String(provider.LabelTaskDefinitionRevisions),
+		Value: aws.String(provider.FormatTaskDefinitionRevisions(kept)),
+	}})
+	if err != nil {
+		return err
+	}
+
+	arns, err := client.ListTaskDefinitionArns(ctx, family)
+	if err != nil {
+		return err
+	}
+	for _, arn := range provider.FindOutdatedTaskDefinitionArns(arns, family, kept) {
+		if err := client.DeregisterTaskDefinition(ctx, arn); err != nil {
+			return err
+		}
+		c.Logger.Info("deregistered an outdated ECS task definition", zap.String("arn", arn))
+	}
+	return nil
+}
@@ -36,6 +36,9 @@ import (
 const (
 	canaryTaskSetARNKeyName = "canary-taskset-arn"
 	canaryServiceKeyName    = "canary-service-object"
+	// The task definition used by the PRIMARY task set and the service running it.
+	primaryTaskDefinitionArnKeyName = "primary-task-definition-arn"
+	primaryServiceArnKeyName        = "primary-service-arn"
 	// Stage metadata keys.
 	trafficRoutePrimaryMetadataKey = "primary-percentage"
 	trafficRouteCanaryMetadataKey  = "canary-percentage"
@@ -45,6 +48,7 @@ const (
 type registerer interface {
 	Register(stage model.Stage, f executor.Factory) error
 	RegisterRollback(kind model.ApplicationKind, f executor.Factory) error
+	RegisterCleaner(kind model.ApplicationKind, f executor.CleanerFactory) error
 }
 
 func Register(r registerer) {
@@ -64,6 +68,11 @@ func Register(r registerer) {
 			Input: in,
 		}
 	})
+	r.RegisterCleaner(model.ApplicationKind_ECS, func(in executor.Input) executor.Cleaner {
+		return &cleaner{
+			Input: in,
+		}
+	})
 }
 
 func findCloudProvider(in *executor.Input) (name string, cfg *config.CloudProviderECSConfig, found bool) {
@@ -97,17 +106,22 @@ func loadServiceDefinition(in *executor.Input, serviceDefinitionFile string, ds
 	return serviceDefinition, true
 }
 
-func loadTaskDefinition(in *executor.Input, taskDefinitionFile string, ds *deploysource.DeploySource) (types.TaskDefinition, bool) {
+func loadTaskDefinition(in *executor.Input, taskDefinitionFile string, ds *deploysource.DeploySource) (types.TaskDefinition, []types.Tag, bool) {
 	in.LogPersister.Infof("Loading task definition manifest at commit %s", ds.Revision)
 
 	taskDefinition, err := provider.LoadTaskDefinition(ds.AppDir, taskDefinitionFile)
 	if err != nil {
 		in.LogPersister.Errorf("Failed to load ECS task definition (%v)", err)
-		return types.TaskDefinition{}, false
+		return types.TaskDefinition{}, nil, false
+	}
+	tags, err := provider.LoadTaskDefinitionTags(ds.AppDir, taskDefinitionFile)
+	if err != nil {
+		in.LogPersister.Errorf("Failed to load tags of ECS task definition (%v)", err)
+		return types.TaskDefinition{}, nil, false
 	}
 
 	in.LogPersister.Infof("Successfully loaded the ECS task definition at commit %s", ds.Revision)
-	return taskDefinition, true
+	return taskDefinition, provider.MakeTags(tags, in.PipedConfig.PipedID, in.Deployment.ApplicationId, ds.Revision), true
 }
 
 func loadTargetGroups(in *executor.Input, deployCfg *config.ECSDeploymentSpec, ds *deploysource.DeploySource) (*types.LoadBalancer, *types.LoadBalancer, bool) {
@@ -123,8 +137,8 @@ func loadTargetGroups(in *executor.Input, deployCfg *config.ECSDeploymentSpec, d
 	return primary, canary, true
 }
 
-func applyTaskDefinition(ctx context.Context, cli provider.Client, taskDefinition types.TaskDefinition) (*types.TaskDefinition, error) {
-	td, err := cli.RegisterTaskDefinition(ctx, taskDefinition)
+func applyTaskDefinition(ctx context.Context, cli provider.Client, taskDefinition types.TaskDefinition, tags []types.Tag) (*types.TaskDefinition, error) {
+	td, err := cli.RegisterTaskDefinition(ctx, taskDefinition, tags)
 	if err != nil {
 		return nil, err
 	}
@@ -153,7 +167,7 @@ func applyServiceDefinition(ctx context.Context, cli provider.Client, serviceDef
 	return service, nil
 }
 
-func createPrimaryTaskSet(ctx context.Context, client provider.Client, service types.Service, taskDef types.TaskDefinition, targetGroup types.LoadBalancer) error {
+func createPrimaryTaskSet(ctx context.Context, client provider.Client, service types.Service, taskDef types.TaskDefinition, targetGroup types.LoadBalancer, tags []types.Tag) error {
 	// Get current PRIMARY task set.
 	prevPrimaryTaskSet, err := client.GetPrimaryTaskSet(ctx, service)
 	// Ignore error in case it's not found error, the prevPrimaryTaskSet doesn't exist for newly created Service.
@@ -164,7 +178,7 @@ func createPrimaryTaskSet(ctx context.Context, client provider.Client, service t
 	// Create a task set in the specified cluster and service.
 	// In case of creating Primary taskset, the number of desired tasks scale is always set to 100
 	// which means we create as many tasks as the current primary taskset has.
-	taskSet, err := client.CreateTaskSet(ctx, service, taskDef, targetGroup, 100)
+	taskSet, err := client.CreateTaskSet(ctx, service, taskDef, targetGroup, 100, tags)
 	if err != nil {
 		return err
 	}
@@ -184,7 +198,22 @@ func createPrimaryTaskSet(ctx context.Context, client provider.Client, service t
 	return nil
 }
 
-func sync(ctx context.Context, in *executor.Input, cloudProviderName string, cloudProviderCfg *config.CloudProviderECSConfig, taskDefinition types.TaskDefinition, serviceDefinition types.Service, targetGroup types.LoadBalancer) bool {
+// savePrimaryTaskDefinition saves the task definition used by the PRIMARY task set
+// and the service running it to the deployment metadata,
+// so that its revision can be recorded after the deployment succeeded.
+func savePrimaryTaskDefinition(ctx context.Context, in *executor.Input, service types.Service, taskDefinition types.TaskDefinition) bool {
+	if err := in.MetadataStore.Set(ctx, primaryTaskDefinitionArnKeyName, *taskDefinition.TaskDefinitionArn); err != nil {
+		in.LogPersister.Errorf("Unable to store the task definition of PRIMARY task set to metadata store: %v", err)
+		return false
+	}
+	if err := in.MetadataStore.Set(ctx, primaryServiceArnKeyName, *service.ServiceArn); err != nil {
+		in.LogPersister.Errorf("Unable to store the service of PRIMARY task set to metadata store: %v", err)
+		return false
+	}
+	return true
+}
+
+func sync(ctx context.Context, in *executor.Input, cloudProviderName string, cloudProviderCfg *config.CloudProviderECSConfig, taskDefinition types.TaskDefinition, tags []types.Tag, serviceDefinition types.Service, targetGroup types.LoadBalancer) bool {
 	client, err := provider.DefaultRegistry().Client(cloudProviderName, cloudProviderCfg, in.Logger)
 	if err != nil {
 		in.LogPersister.Errorf("Unable to create ECS client for the provider %s: %v", cloudProviderName, err)
@@ -192,7 +221,7 @@ func sync(ctx context.Context, in *executor.Input, cloudProviderName string, clo
 	}
 
 	in.LogPersister.Infof("Start applying the ECS task definition")
-	td, err := applyTaskDefinition(ctx, client, taskDefinition)
+	td, err := applyTaskDefinition(ctx, client, taskDefinition, tags)
 	if err != nil {
 		in.LogPersister.Errorf("Failed to register ECS task definition of family %s: %v", *taskDefinition.Family, err)
 		return false
@@ -206,16 +235,19 @@ func sync(ctx context.Context, in *executor.Input, cloudProviderName string, clo
 	}
 
 	in.LogPersister.Infof("Start rolling out ECS task set")
-	if err := createPrimaryTaskSet(ctx, client, *service, *td, targetGroup); err != nil {
+	if err := createPrimaryTaskSet(ctx, client, *service, *td, targetGroup, tags); err != nil {
 		in.LogPersister.Errorf("Failed to rolling out ECS task set for service %s: %v", *serviceDefinition.ServiceName, err)
 		return false
 	}
+	if !savePrimaryTaskDefinition(ctx, in, *service, *td) {
+		return false
+	}
 
 	in.LogPersister.Infof("Successfully applied the service definition and the task definition for ECS service %s and task definition of family %s", *serviceDefinition.ServiceName, *taskDefinition.Family)
 	return true
 }
 
-func rollout(ctx context.Context, in *executor.Input, cloudProviderName string, cloudProviderCfg *config.CloudProviderECSConfig, taskDefinition types.TaskDefinition, serviceDefinition types.Service, targetGroup types.LoadBalancer) bool {
+func rollout(ctx context.Context, in *executor.Input, cloudProviderName string, cloudProviderCfg *config.CloudProviderECSConfig, taskDefinition types.TaskDefinition, tags []types.Tag, serviceDefinition types.Service, targetGroup types.LoadBalancer) bool {
 	client, err := provider.DefaultRegistry().Client(cloudProviderName, cloudProviderCfg, in.Logger)
 	if err != nil {
 		in.LogPersister.Errorf("Unable to create ECS client for the provider %s: %v", cloudProviderName, err)
@@ -223,7 +255,7 @@ func rollout(ctx context.Context, in *executor.Input, cloudProviderName string,
 	}
 
 	in.LogPersister.Infof("Start applying the ECS task definition")
-	td, err := applyTaskDefinition(ctx, client, taskDefinition)
+	td, err := applyTaskDefinition(ctx, client, taskDefinition, tags)
 	if err != nil {
 		in.LogPersister.Errorf("Failed to register ECS task definition of family %s: %v", *taskDefinition.Family, err)
 		return false
@@ -240,10 +272,13 @@ func rollout(ctx context.Context, in *executor.Input, cloudProviderName string,
 	in.LogPersister.Infof("Start rolling out ECS task set")
 	if in.StageConfig.Name == model.StageECSPrimaryRollout {
 		// Create PRIMARY task set in case of Primary rollout.
-		if err := createPrimaryTaskSet(ctx, client, *service, *td, targetGroup); err != nil {
+		if err := createPrimaryTaskSet(ctx, client, *service, *td, targetGroup, tags); err != nil {
 			in.LogPersister.Errorf("Failed to rolling out ECS task set for service %s: %v", *serviceDefinition.ServiceName, err)
 			return false
 		}
+		if !savePrimaryTaskDefinition(ctx, in, *service, *td) {
+			return false
+		}
 	} else {
 		// Load Canary rollout stage options to get scale configuration.
 		options := in.StageConfig.ECSCanaryRolloutStageOptions
@@ -260,7 +295,7 @@ func rollout(ctx context.Context, in *executor.Input, cloudProviderName string,
 		}
 
 		// Create ACTIVE task set in case of Canary rollout.
-		taskSet, err := client.CreateTaskSet(ctx, *service, *td, targetGroup, options.Scale.Int())
+		taskSet, err := client.CreateTaskSet(ctx, *service, *td, targetGroup, options.Scale.Int(), tags)
 		if err != nil {
 			in.LogPersister.Errorf("Failed to create ECS task set for service %s: %v", *serviceDefinition.ServiceName, err)
 			return false
@@ -349,13 +384,14 @@ func routing(ctx context.Context, in *executor.Input, cloudProviderName string,
 		in.Logger.Error("Failed to store traffic routing config to metadata store", zap.Error(err))
 	}
 
-	currListenerArn, err := client.GetListener(ctx, primaryTargetGroup)
+	listenerArns, err := client.GetListenerArns(ctx, primaryTargetGroup)
 	if err != nil {
-		in.LogPersister.Errorf("Failed to get current active listener: %v", err)
+		in.LogPersister.Errorf("Failed to get current active listeners: %v", err)
 		return false
 	}
 
-	if err := client.ModifyListener(ctx, currListenerArn, routingTrafficCfg); err != nil {
+	in.LogPersister.Infof("Routing traffic to the target groups via %d listeners", len(listenerArns))
+	if err := client.ModifyListeners(ctx, listenerArns, routingTrafficCfg); err != nil {
 		in.LogPersister.Errorf("Failed to routing traffic to CANARY variant: %v", err)
 		return false
 	}
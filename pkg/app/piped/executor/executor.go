@@ -34,6 +34,17 @@ type Executor interface {
 
 type Factory func(in Input) Executor
 
+// Cleaner cleans up the resources those are no longer needed
+// after the deployment has completed successfully.
+type Cleaner interface {
+	Clean(ctx context.Context) error
+}
+
+// CleanerFactory creates a Cleaner from the given input.
+// Since the cleaner runs outside of the stages, the stage-specific fields
+// such as Stage, StageConfig, CommandLister and LogPersister are not set.
+type CleanerFactory func(in Input) Cleaner
+
 type LogPersister interface {
 	Write(log []byte) (int, error)
 	Info(log string)
@@ -58,6 +69,23 @@ type CommandLister interface {
 
 type AppLiveResourceLister interface {
 	ListKubernetesResources() ([]provider.Manifest, bool)
+	// ListKubernetesResourcesInCloudProvider lists the live resources of the application
+	// running in the cluster of the given cloud provider.
+	ListKubernetesResourcesInCloudProvider(cloudProvider string) ([]provider.Manifest, bool)
+}
+
+type Notifier interface {
+	Notify(event model.NotificationEvent)
+}
+
+type SecretDecrypter interface {
+	Decrypt(string) (string, error)
+}
+
+type Promoter interface {
+	// Promote does the given promotions of the release deployed by the given deployment
+	// and returns their results. The appDir is the application directory at the deployed commit.
+	Promote(ctx context.Context, d *model.Deployment, appDir string, promotions []config.Promotion) []*model.Promotion
 }
 
 type Input struct {
@@ -65,6 +93,7 @@ type Input struct {
 	StageConfig config.PipelineStage
 	// Readonly deployment model.
 	Deployment            *model.Deployment
+	EnvName               string
 	Application           *model.Application
 	PipedConfig           *config.PipedSpec
 	TargetDSP             deploysource.Provider
@@ -74,7 +103,14 @@ type Input struct {
 	MetadataStore         MetadataStore
 	AppManifestsCache     cache.Cache
 	AppLiveResourceLister AppLiveResourceLister
-	Logger                *zap.Logger
+	Notifier              Notifier
+	// Nil means the secret management is not configured for this piped.
+	SecretDecrypter SecretDecrypter
+	Promoter        Promoter
+	Logger          *zap.Logger
+	// The directory to store the files shared by all stages of the deployment.
+	// It is removed after the deployment was completed.
+	WorkingDir string
 }
 
 func DetermineStageStatus(sig StopSignalType, ori, got model.StageStatus) model.StageStatus {
@@ -41,7 +41,7 @@ func (e *deployExecutor) ensureCanaryRollout(ctx context.Context) model.StageSta
 	e.LogPersister.Infof("Loading manifests at commit %s for handling", e.commit)
 	manifests, err := loadManifests(
 		ctx,
-		e.Deployment.ApplicationId,
+		e.manifestsCacheID(),
 		e.commit,
 		e.AppManifestsCache,
 		e.provider,
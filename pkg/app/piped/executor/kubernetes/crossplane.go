@@ -0,0 +1,303 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/kubernetes"
+	"github.com/pipe-cd/pipe/pkg/app/piped/executor"
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+const (
+	crossplaneCheckInterval = 10 * time.Second
+)
+
+// crossplaneExecutor deploys the Crossplane resources by applying them to the Kubernetes cluster
+// where Crossplane is running, and then waits until they were provisioned.
+type crossplaneExecutor struct {
+	executor.Input
+
+	commit    string
+	deployCfg *config.CrossplaneDeploymentSpec
+	provider  provider.Provider
+}
+
+func (e *crossplaneExecutor) Execute(sig executor.StopSignal) model.StageStatus {
+	ctx := sig.Context()
+	e.commit = e.Deployment.Trigger.Commit.Hash
+
+	ds, err := e.TargetDSP.Get(ctx, e.LogPersister)
+	if err != nil {
+		e.LogPersister.Errorf("Failed to prepare target deploy source data (%v)", err)
+		return model.StageStatus_STAGE_FAILURE
+	}
+
+	e.deployCfg = ds.DeploymentConfig.CrossplaneDeploymentSpec
+	if e.deployCfg == nil {
+		e.LogPersister.Error("Malformed deployment configuration: missing CrossplaneDeploymentSpec")
+		return model.StageStatus_STAGE_FAILURE
+	}
+
+	if e.deployCfg.Input.HelmChart != nil {
+		chartRepoName := e.deployCfg.Input.HelmChart.Repository
+		if chartRepoName != "" {
+			e.deployCfg.Input.HelmChart.Insecure = e.PipedConfig.IsInsecureChartRepository(chartRepoName)
+		}
+	}
+
+	e.provider = provider.NewProvider(e.Deployment.ApplicationName, ds.AppDir, ds.RepoDir, e.Deployment.GitPath.ConfigFilename, e.deployCfg.Input, e.Logger)
+	e.Logger.Info("start executing crossplane stage",
+		zap.String("stage-name", e.Stage.Name),
+		zap.String("app-dir", ds.AppDir),
+	)
+
+	var (
+		originalStatus = e.Stage.Status
+		status         model.StageStatus
+	)
+
+	switch model.Stage(e.Stage.Name) {
+	case model.StageCrossplaneSync:
+		status = e.ensureCrossplaneSync(ctx)
+
+	default:
+		e.LogPersister.Errorf("Unsupported stage %s for crossplane application", e.Stage.Name)
+		return model.StageStatus_STAGE_FAILURE
+	}
+
+	return executor.DetermineStageStatus(sig.Signal(), originalStatus, status)
+}
+
+func (e *crossplaneExecutor) ensureCrossplaneSync(ctx context.Context) model.StageStatus {
+	// The options of the predefined quick sync stage are configured at the quickSync field.
+	options := e.StageConfig.CrossplaneSyncStageOptions
+	if options == nil {
+		options = &e.deployCfg.QuickSync
+	}
+
+	// Load the manifests at the specified commit.
+	e.LogPersister.Infof("Loading manifests at commit %s for handling", e.commit)
+	manifests, err := loadManifests(
+		ctx,
+		e.Deployment.ApplicationId,
+		e.commit,
+		e.AppManifestsCache,
+		e.provider,
+		e.Logger,
+	)
+	if err != nil {
+		e.LogPersister.Errorf("Failed while loading manifests (%v)", err)
+		return model.StageStatus_STAGE_FAILURE
+	}
+	e.LogPersister.Successf("Successfully loaded %d manifests", len(manifests))
+
+	// Because the loaded manifests are read-only
+	// we duplicate them to avoid updating the shared manifests data in cache.
+	manifests = duplicateManifests(manifests, "")
+
+	// Add builtin annotations for tracking application live state.
+	addBuiltinAnnontations(
+		manifests,
+		primaryVariant,
+		e.commit,
+		e.PipedConfig.PipedID,
+		e.Deployment.ApplicationId,
+	)
+
+	if err := applyManifests(ctx, e.provider, manifests, e.deployCfg.Input.Namespace, e.LogPersister); err != nil {
+		return model.StageStatus_STAGE_FAILURE
+	}
+
+	if err := waitForCrossplaneResources(ctx, e.provider, manifests, options.Timeout.Duration(), e.LogPersister); err != nil {
+		return model.StageStatus_STAGE_FAILURE
+	}
+
+	if !options.Prune {
+		e.LogPersister.Info("Resource GC was skipped because prune was not configured")
+		return model.StageStatus_STAGE_SUCCESS
+	}
+
+	// Find the running resources that are not defined in Git for removing.
+	e.LogPersister.Info("Start finding all running resources but no longer defined in Git")
+	liveResources, ok := e.AppLiveResourceLister.ListKubernetesResources()
+	if !ok {
+		e.LogPersister.Info("There is no data about live resource so no resource will be removed")
+		return model.StageStatus_STAGE_SUCCESS
+	}
+
+	removeKeys := findRemoveResources(manifests, liveResources)
+	if len(removeKeys) == 0 {
+		e.LogPersister.Info("There are no live resources should be removed")
+		return model.StageStatus_STAGE_SUCCESS
+	}
+	e.LogPersister.Infof("Found %d live resources that are no longer defined in Git", len(removeKeys))
+
+	// Crossplane deletes the external resources as well
+	// unless their deletion policy is specified as Orphan.
+	if err := deleteResources(ctx, e.provider, removeKeys, e.LogPersister); err != nil {
+		return model.StageStatus_STAGE_FAILURE
+	}
+
+	return model.StageStatus_STAGE_SUCCESS
+}
+
+type crossplaneRollbackExecutor struct {
+	executor.Input
+}
+
+func (e *crossplaneRollbackExecutor) Execute(sig executor.StopSignal) model.StageStatus {
+	var (
+		ctx            = sig.Context()
+		originalStatus = e.Stage.Status
+		status         model.StageStatus
+	)
+
+	switch model.Stage(e.Stage.Name) {
+	case model.StageRollback:
+		status = e.ensureRollback(ctx)
+
+	default:
+		e.LogPersister.Errorf("Unsupported stage %s for crossplane application", e.Stage.Name)
+		return model.StageStatus_STAGE_FAILURE
+	}
+
+	return executor.DetermineStageStatus(sig.Signal(), originalStatus, status)
+}
+
+func (e *crossplaneRollbackExecutor) ensureRollback(ctx context.Context) model.StageStatus {
+	// There is nothing to do if this is the first deployment.
+	if e.Deployment.RunningCommitHash == "" {
+		e.LogPersister.Errorf("Unable to determine the last deployed commit to rollback. It seems this is the first deployment.")
+		return model.StageStatus_STAGE_FAILURE
+	}
+
+	ds, err := e.RunningDSP.Get(ctx, e.LogPersister)
+	if err != nil {
+		e.LogPersister.Errorf("Failed to prepare running deploy source data (%v)", err)
+		return model.StageStatus_STAGE_FAILURE
+	}
+
+	deployCfg := ds.DeploymentConfig.CrossplaneDeploymentSpec
+	if deployCfg == nil {
+		e.LogPersister.Error("Malformed deployment configuration: missing CrossplaneDeploymentSpec")
+		return model.StageStatus_STAGE_FAILURE
+	}
+
+	if deployCfg.Input.HelmChart != nil {
+		chartRepoName := deployCfg.Input.HelmChart.Repository
+		if chartRepoName != "" {
+			deployCfg.Input.HelmChart.Insecure = e.PipedConfig.IsInsecureChartRepository(chartRepoName)
+		}
+	}
+
+	p := provider.NewProvider(e.Deployment.ApplicationName, ds.AppDir, ds.RepoDir, e.Deployment.GitPath.ConfigFilename, deployCfg.Input, e.Logger)
+
+	// Load the manifests at the running commit.
+	e.LogPersister.Infof("Loading manifests at running commit %s for handling", e.Deployment.RunningCommitHash)
+	manifests, err := loadManifests(ctx, e.Deployment.ApplicationId, e.Deployment.RunningCommitHash, e.AppManifestsCache, p, e.Logger)
+	if err != nil {
+		e.LogPersister.Errorf("Failed while loading running manifests (%v)", err)
+		return model.StageStatus_STAGE_FAILURE
+	}
+	e.LogPersister.Successf("Successfully loaded %d manifests", len(manifests))
+
+	// Because the loaded manifests are read-only
+	// we duplicate them to avoid updating the shared manifests data in cache.
+	manifests = duplicateManifests(manifests, "")
+
+	// Add builtin annotations for tracking application live state.
+	addBuiltinAnnontations(
+		manifests,
+		primaryVariant,
+		e.Deployment.RunningCommitHash,
+		e.PipedConfig.PipedID,
+		e.Deployment.ApplicationId,
+	)
+
+	// Reapply all resources at the running commit to revert their specs.
+	if err := applyManifests(ctx, p, manifests, deployCfg.Input.Namespace, e.LogPersister); err != nil {
+		return model.StageStatus_STAGE_FAILURE
+	}
+
+	if err := waitForCrossplaneResources(ctx, p, manifests, deployCfg.QuickSync.Timeout.Duration(), e.LogPersister); err != nil {
+		return model.StageStatus_STAGE_FAILURE
+	}
+
+	return model.StageStatus_STAGE_SUCCESS
+}
+
+// waitForCrossplaneResources waits until all resources reconciled by Crossplane
+// became ready and synced and all other resources became healthy.
+func waitForCrossplaneResources(ctx context.Context, applier provider.Applier, manifests []provider.Manifest, timeout time.Duration, lp executor.LogPersister) error {
+	lp.Infof("Waiting for %d resources to be ready and synced", len(manifests))
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(crossplaneCheckInterval)
+	defer ticker.Stop()
+
+	pendings := manifests
+	for {
+		remainings := make([]provider.Manifest, 0, len(pendings))
+		descs := make(map[string]string, len(pendings))
+		for _, m := range pendings {
+			live, err := applier.Get(ctx, m.Key)
+			if err != nil {
+				descs[m.Key.ReadableString()] = fmt.Sprintf("unable to get the resource (%v)", err)
+				remainings = append(remainings, m)
+				continue
+			}
+			status, desc := provider.DetermineManifestHealth(live)
+			if status == model.KubernetesResourceState_HEALTHY {
+				lp.Successf("- %s is ready", m.Key.ReadableString())
+				continue
+			}
+			// The resources reconciled by Crossplane must be marked as ready explicitly
+			// while the others whose health cannot be determined are treated as healthy.
+			if status == model.KubernetesResourceState_OTHER || provider.IsCrossplaneReconciledResource(live) {
+				if desc == "" || status == model.KubernetesResourceState_UNKNOWN {
+					desc = "waiting for the resource to be reconciled by Crossplane"
+				}
+				descs[m.Key.ReadableString()] = desc
+				remainings = append(remainings, m)
+				continue
+			}
+			lp.Successf("- %s was applied", m.Key.ReadableString())
+		}
+		if len(remainings) == 0 {
+			lp.Successf("All %d resources are ready", len(manifests))
+			return nil
+		}
+		pendings = remainings
+
+		select {
+		case <-ctx.Done():
+			lp.Errorf("%d resources did not become ready before the deadline", len(pendings))
+			for key, desc := range descs {
+				lp.Errorf("- %s: %s", key, desc)
+			}
+			return fmt.Errorf("%d resources did not become ready (%w)", len(pendings), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
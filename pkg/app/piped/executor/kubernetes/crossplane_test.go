@@ -0,0 +1,108 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/kubernetes"
+	"github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/kubernetes/providertest"
+)
+
+func TestWaitForCrossplaneResources(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	manifests, err := provider.ParseManifests(`
+apiVersion: s3.aws.crossplane.io/v1beta1
+kind: Bucket
+metadata:
+  name: bucket
+spec:
+  forProvider:
+    locationConstraint: us-east-1
+---
+apiVersion: aws.crossplane.io/v1beta1
+kind: ProviderConfig
+metadata:
+  name: default
+spec:
+  credentials:
+    source: InjectedIdentity
+`)
+	require.NoError(t, err)
+	require.Len(t, manifests, 2)
+
+	liveManifests, err := provider.ParseManifests(`
+apiVersion: s3.aws.crossplane.io/v1beta1
+kind: Bucket
+metadata:
+  name: bucket
+spec:
+  forProvider:
+    locationConstraint: us-east-1
+status:
+  conditions:
+  - type: Ready
+    status: "True"
+  - type: Synced
+    status: "True"
+---
+apiVersion: s3.aws.crossplane.io/v1beta1
+kind: Bucket
+metadata:
+  name: bucket
+spec:
+  forProvider:
+    locationConstraint: us-east-1
+`)
+	require.NoError(t, err)
+	var (
+		readyBucket       = liveManifests[0]
+		reconcilingBucket = liveManifests[1]
+	)
+
+	testcases := []struct {
+		name        string
+		liveBucket  provider.Manifest
+		expectedErr bool
+	}{
+		{
+			name:       "all resources are ready",
+			liveBucket: readyBucket,
+		},
+		{
+			name:        "bucket has not been reconciled yet",
+			liveBucket:  reconcilingBucket,
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := providertest.NewMockProvider(ctrl)
+			p.EXPECT().Get(gomock.Any(), manifests[0].Key).Return(tc.liveBucket, nil).AnyTimes()
+			p.EXPECT().Get(gomock.Any(), manifests[1].Key).Return(manifests[1], nil)
+
+			err := waitForCrossplaneResources(context.Background(), p, manifests, 10*time.Millisecond, &fakeLogPersister{})
+			assert.Equal(t, tc.expectedErr, err != nil)
+		})
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/kubernetes"
+	"github.com/pipe-cd/pipe/pkg/app/piped/executor"
+)
+
+type hookType string
+
+const (
+	// Hooks run before applying the manifests.
+	hookPreSync hookType = "PreSync"
+	// Hooks run after all manifests were successfully applied.
+	hookPostSync hookType = "PostSync"
+	// Hooks run when the sync was failed.
+	hookSyncFail hookType = "SyncFail"
+
+	hookCheckInterval = 5 * time.Second
+	hookTimeout       = time.Hour
+)
+
+// hookManifests groups the hook manifests by their hook type.
+type hookManifests map[hookType][]provider.Manifest
+
+// separateHookManifests splits the given manifests into the application manifests
+// and the hook manifests specified by the pipecd.dev/hook annotation.
+func separateHookManifests(manifests []provider.Manifest) ([]provider.Manifest, hookManifests, error) {
+	var (
+		apps  = make([]provider.Manifest, 0, len(manifests))
+		hooks = make(hookManifests)
+	)
+	for _, m := range manifests {
+		value, ok := m.GetAnnotations()[provider.AnnotationHook]
+		if !ok {
+			apps = append(apps, m)
+			continue
+		}
+		if m.Key.Kind != provider.KindJob {
+			return nil, nil, fmt.Errorf("hook %s must be a %s but got %s", m.Key.Name, provider.KindJob, m.Key.Kind)
+		}
+		for _, t := range strings.Split(value, ",") {
+			t := hookType(strings.TrimSpace(t))
+			switch t {
+			case hookPreSync, hookPostSync, hookSyncFail:
+				hooks[t] = append(hooks[t], m)
+			default:
+				return nil, nil, fmt.Errorf("unsupported hook type %q was specified in %s", t, m.Key.ReadableString())
+			}
+		}
+	}
+	return apps, hooks, nil
+}
+
+// runHooks runs the given hook jobs one by one and waits for their completion.
+// An error is returned right after a hook has been failed.
+func runHooks(ctx context.Context, applier provider.Applier, t hookType, hooks []provider.Manifest, lp executor.LogPersister) error {
+	if len(hooks) == 0 {
+		return nil
+	}
+	lp.Infof("Start running %d %s hooks", len(hooks), t)
+
+	for _, h := range hooks {
+		if err := runHook(ctx, applier, h, lp); err != nil {
+			lp.Errorf("%s hook %s was failed (%v)", t, h.Key.Name, err)
+			return err
+		}
+		lp.Successf("- %s hook %s was completed", t, h.Key.Name)
+	}
+
+	lp.Successf("Successfully ran %d %s hooks", len(hooks), t)
+	return nil
+}
+
+func runHook(ctx context.Context, applier provider.Applier, hook provider.Manifest, lp executor.LogPersister) error {
+	// Because a completed job will not be run again by re-applying the same manifest,
+	// the one created in the previous deployments has to be deleted first.
+	if err := applier.Delete(ctx, hook.Key); err != nil && !errors.Is(err, provider.ErrNotFound) {
+		return fmt.Errorf("unable to delete the previous job (%w)", err)
+	}
+	if err := applier.ApplyManifest(ctx, hook); err != nil {
+		return fmt.Errorf("unable to apply the job (%w)", err)
+	}
+	lp.Infof("- started job %s, waiting for its completion", hook.Key.ReadableString())
+
+	ctx, cancel := context.WithTimeout(ctx, hookTimeout)
+	defer cancel()
+
+	waitErr := waitForJobCompletion(ctx, applier, hook.Key)
+
+	// Copy the logs of the job into the stage log even if it was failed
+	// since they are the most important thing to find out the reason.
+	logs, err := applier.Logs(ctx, hook.Key)
+	if err != nil {
+		lp.Errorf("Unable to get the logs of job %s (%v)", hook.Key.Name, err)
+	}
+	for _, line := range strings.Split(strings.TrimRight(logs, "\n"), "\n") {
+		if line != "" {
+			lp.Info(line)
+		}
+	}
+
+	return waitErr
+}
+
+func waitForJobCompletion(ctx context.Context, applier provider.Applier, key provider.ResourceKey) error {
+	ticker := time.NewTicker(hookCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("job was not completed before the deadline (%w)", ctx.Err())
+		case <-ticker.C:
+		}
+
+		m, err := applier.Get(ctx, key)
+		if err != nil {
+			return fmt.Errorf("unable to get the job (%w)", err)
+		}
+		completed, err := determineJobStatus(m)
+		if err != nil {
+			return err
+		}
+		if completed {
+			return nil
+		}
+	}
+}
+
+// determineJobStatus reports whether the given job was completed successfully.
+// An error is returned when the job was failed.
+func determineJobStatus(m provider.Manifest) (bool, error) {
+	var job batchv1.Job
+	if err := m.ConvertToStructuredObject(&job); err != nil {
+		return false, err
+	}
+	for _, c := range job.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobComplete:
+			return true, nil
+		case batchv1.JobFailed:
+			return false, fmt.Errorf("job was failed: %s %s", c.Reason, c.Message)
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,194 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/kubernetes"
+)
+
+func TestSeparateHookManifests(t *testing.T) {
+	testcases := []struct {
+		name          string
+		manifests     string
+		expectedApps  []string
+		expectedHooks map[hookType][]string
+		expectedErr   bool
+	}{
+		{
+			name: "no hook",
+			manifests: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: simple
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: simple
+`,
+			expectedApps:  []string{"simple", "simple"},
+			expectedHooks: map[hookType][]string{},
+		},
+		{
+			name: "multiple hooks",
+			manifests: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: simple
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+  annotations:
+    pipecd.dev/hook: PreSync
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: notify
+  annotations:
+    pipecd.dev/hook: PostSync, SyncFail
+`,
+			expectedApps: []string{"simple"},
+			expectedHooks: map[hookType][]string{
+				hookPreSync:  {"migrate"},
+				hookPostSync: {"notify"},
+				hookSyncFail: {"notify"},
+			},
+		},
+		{
+			name: "unsupported hook type",
+			manifests: `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+  annotations:
+    pipecd.dev/hook: PreDelete
+`,
+			expectedErr: true,
+		},
+		{
+			name: "hook is not a job",
+			manifests: `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: migrate
+  annotations:
+    pipecd.dev/hook: PreSync
+`,
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			manifests, err := provider.ParseManifests(tc.manifests)
+			require.NoError(t, err)
+
+			apps, hooks, err := separateHookManifests(manifests)
+			assert.Equal(t, tc.expectedErr, err != nil)
+			if err != nil {
+				return
+			}
+
+			appNames := make([]string, 0, len(apps))
+			for _, m := range apps {
+				appNames = append(appNames, m.Key.Name)
+			}
+			assert.Equal(t, tc.expectedApps, appNames)
+
+			hookNames := make(map[hookType][]string, len(hooks))
+			for typ, hs := range hooks {
+				for _, m := range hs {
+					hookNames[typ] = append(hookNames[typ], m.Key.Name)
+				}
+			}
+			assert.Equal(t, tc.expectedHooks, hookNames)
+		})
+	}
+}
+
+func TestDetermineJobStatus(t *testing.T) {
+	testcases := []struct {
+		name              string
+		manifest          string
+		expectedCompleted bool
+		expectedErr       bool
+	}{
+		{
+			name: "running",
+			manifest: `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+status:
+  active: 1
+`,
+		},
+		{
+			name: "completed",
+			manifest: `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+status:
+  conditions:
+  - type: Complete
+    status: "True"
+`,
+			expectedCompleted: true,
+		},
+		{
+			name: "failed",
+			manifest: `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+status:
+  conditions:
+  - type: Failed
+    status: "True"
+    reason: BackoffLimitExceeded
+    message: Job has reached the specified backoff limit
+`,
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			manifests, err := provider.ParseManifests(tc.manifest)
+			require.NoError(t, err)
+			require.Equal(t, 1, len(manifests))
+
+			completed, err := determineJobStatus(manifests[0])
+			assert.Equal(t, tc.expectedCompleted, completed)
+			assert.Equal(t, tc.expectedErr, err != nil)
+		})
+	}
+}
@@ -18,7 +18,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"strings"
+	"sync"
 
 	"go.uber.org/zap"
 	appsv1 "k8s.io/api/apps/v1"
@@ -26,6 +28,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/kubernetes"
+	"github.com/pipe-cd/pipe/pkg/app/piped/deploysource"
 	"github.com/pipe-cd/pipe/pkg/app/piped/executor"
 	"github.com/pipe-cd/pipe/pkg/cache"
 	"github.com/pipe-cd/pipe/pkg/config"
@@ -42,6 +45,9 @@ type deployExecutor struct {
 	commit    string
 	deployCfg *config.KubernetesDeploymentSpec
 	provider  provider.Provider
+	// The target being handled by this executor.
+	// Nil means the application has no target.
+	target *config.KubernetesDeploymentTarget
 }
 
 type registerer interface {
@@ -70,6 +76,17 @@ func Register(r registerer) {
 			Input: in,
 		}
 	})
+
+	r.Register(model.StageCrossplaneSync, func(in executor.Input) executor.Executor {
+		return &crossplaneExecutor{
+			Input: in,
+		}
+	})
+	r.RegisterRollback(model.ApplicationKind_CROSSPLANE, func(in executor.Input) executor.Executor {
+		return &crossplaneRollbackExecutor{
+			Input: in,
+		}
+	})
 }
 
 func (e *deployExecutor) Execute(sig executor.StopSignal) model.StageStatus {
@@ -95,7 +112,6 @@ func (e *deployExecutor) Execute(sig executor.StopSignal) model.StageStatus {
 		}
 	}
 
-	e.provider = provider.NewProvider(e.Deployment.ApplicationName, ds.AppDir, ds.RepoDir, e.Deployment.GitPath.ConfigFilename, e.deployCfg.Input, e.Logger)
 	e.Logger.Info("start executing kubernetes stage",
 		zap.String("stage-name", e.Stage.Name),
 		zap.String("app-dir", ds.AppDir),
@@ -106,34 +122,97 @@ func (e *deployExecutor) Execute(sig executor.StopSignal) model.StageStatus {
 		status         model.StageStatus
 	)
 
+	if !isSupportedStage(model.Stage(e.Stage.Name)) {
+		e.LogPersister.Errorf("Unsupported stage %s for kubernetes application", e.Stage.Name)
+		return model.StageStatus_STAGE_FAILURE
+	}
+
+	if len(e.deployCfg.Targets) == 0 {
+		e.provider = provider.NewProvider(e.Deployment.ApplicationName, ds.AppDir, ds.RepoDir, e.Deployment.GitPath.ConfigFilename, e.deployCfg.Input, e.Logger)
+		status = e.executeStage(ctx)
+	} else {
+		status = e.executeOnTargets(ctx, ds)
+	}
+
+	return executor.DetermineStageStatus(sig.Signal(), originalStatus, status)
+}
+
+// executeOnTargets executes the stage on all targets of the application
+// by using a dedicated executor for each target.
+func (e *deployExecutor) executeOnTargets(ctx context.Context, ds *deploysource.DeploySource) model.StageStatus {
+	var mu sync.Mutex
+	return runOnTargets(ctx, e.deployCfg.Targets, e.deployCfg.TargetsExecution, e.LogPersister, func(ctx context.Context, t config.KubernetesDeploymentTarget) model.StageStatus {
+		in := makeTargetInput(e.Input, t, &mu)
+		p, err := newTargetProvider(in, ds, e.deployCfg.Input, t)
+		if err != nil {
+			in.LogPersister.Errorf("Unable to prepare the provider for target (%v)", err)
+			return model.StageStatus_STAGE_FAILURE
+		}
+
+		cfg := *e.deployCfg
+		cfg.Input = t.ApplyTo(cfg.Input)
+		te := &deployExecutor{
+			Input:     in,
+			commit:    e.commit,
+			deployCfg: &cfg,
+			provider:  p,
+			target:    &t,
+		}
+		in.LogPersister.Infof("Start executing on cluster of cloud provider %s", t.CloudProvider)
+		return te.executeStage(ctx)
+	})
+}
+
+func isSupportedStage(stage model.Stage) bool {
+	switch stage {
+	case model.StageK8sSync,
+		model.StageK8sPrimaryRollout,
+		model.StageK8sCanaryRollout,
+		model.StageK8sCanaryClean,
+		model.StageK8sBaselineRollout,
+		model.StageK8sBaselineClean,
+		model.StageK8sTrafficRouting:
+		return true
+	default:
+		return false
+	}
+}
+
+func (e *deployExecutor) executeStage(ctx context.Context) model.StageStatus {
 	switch model.Stage(e.Stage.Name) {
 	case model.StageK8sSync:
-		status = e.ensureSync(ctx)
+		return e.ensureSync(ctx)
 
 	case model.StageK8sPrimaryRollout:
-		status = e.ensurePrimaryRollout(ctx)
+		return e.ensurePrimaryRollout(ctx)
 
 	case model.StageK8sCanaryRollout:
-		status = e.ensureCanaryRollout(ctx)
+		return e.ensureCanaryRollout(ctx)
 
 	case model.StageK8sCanaryClean:
-		status = e.ensureCanaryClean(ctx)
+		return e.ensureCanaryClean(ctx)
 
 	case model.StageK8sBaselineRollout:
-		status = e.ensureBaselineRollout(ctx)
+		return e.ensureBaselineRollout(ctx)
 
 	case model.StageK8sBaselineClean:
-		status = e.ensureBaselineClean(ctx)
+		return e.ensureBaselineClean(ctx)
 
 	case model.StageK8sTrafficRouting:
-		status = e.ensureTrafficRouting(ctx)
+		return e.ensureTrafficRouting(ctx)
 
 	default:
 		e.LogPersister.Errorf("Unsupported stage %s for kubernetes application", e.Stage.Name)
 		return model.StageStatus_STAGE_FAILURE
 	}
+}
 
-	return executor.DetermineStageStatus(sig.Signal(), originalStatus, status)
+// manifestsCacheID returns the ID used to cache the manifests rendered for this executor.
+func (e *deployExecutor) manifestsCacheID() string {
+	if e.target != nil {
+		return provider.TargetCacheID(e.Deployment.ApplicationId, e.target.Name)
+	}
+	return e.Deployment.ApplicationId
 }
 
 func (e *deployExecutor) loadRunningManifests(ctx context.Context) (manifests []provider.Manifest, err error) {
@@ -150,9 +229,13 @@ func (e *deployExecutor) loadRunningManifests(ctx context.Context) (manifests []
 				return nil, err
 			}
 
+			appDir := ds.AppDir
+			if e.target != nil {
+				appDir = filepath.Join(appDir, e.target.KustomizeDir)
+			}
 			loader := provider.NewManifestLoader(
 				e.Deployment.ApplicationName,
-				ds.AppDir,
+				appDir,
 				ds.RepoDir,
 				e.Deployment.GitPath.ConfigFilename,
 				e.deployCfg.Input,
@@ -162,7 +245,7 @@ func (e *deployExecutor) loadRunningManifests(ctx context.Context) (manifests []
 		},
 	}
 
-	return loadManifests(ctx, e.Deployment.ApplicationId, commit, e.AppManifestsCache, loader, e.Logger)
+	return loadManifests(ctx, e.manifestsCacheID(), commit, e.AppManifestsCache, loader, e.Logger)
 }
 
 type manifestsLoadFunc struct {
@@ -213,12 +296,29 @@ func applyManifests(ctx context.Context, applier provider.Applier, manifests []p
 	} else {
 		lp.Infof("Start applying %d manifests to %q namespace", len(manifests), namespace)
 	}
-	for _, m := range manifests {
-		if err := applier.ApplyManifest(ctx, m); err != nil {
-			lp.Errorf("Failed to apply manifest: %s (%v)", m.Key.ReadableString(), err)
-			return err
+	waves, err := groupManifestsByWave(manifests)
+	if err != nil {
+		lp.Errorf("Unable to determine the order of applying manifests (%v)", err)
+		return err
+	}
+	for i, wave := range waves {
+		if len(waves) > 1 {
+			lp.Infof("Start applying wave %d/%d that contains %d manifests", i+1, len(waves), len(wave))
+		}
+		for _, m := range wave {
+			if err := applier.ApplyManifest(ctx, m); err != nil {
+				lp.Errorf("Failed to apply manifest: %s (%v)", m.Key.ReadableString(), err)
+				return err
+			}
+			lp.Successf("- applied manifest: %s", m.Key.ReadableString())
+		}
+		// The next wave can be applied only after all resources of this wave became healthy.
+		if i < len(waves)-1 {
+			lp.Infof("Waiting for the resources of wave %d/%d to be healthy", i+1, len(waves))
+			if err := waitForHealthy(ctx, applier, wave, lp); err != nil {
+				return err
+			}
 		}
-		lp.Successf("- applied manifest: %s", m.Key.ReadableString())
 	}
 	lp.Successf("Successfully applied %d manifests", len(manifests))
 	return nil
@@ -234,7 +334,7 @@ func deleteResources(ctx context.Context, applier provider.Applier, resources []
 	lp.Infof("Start deleting %d resources", len(resources))
 	var deletedCount int
 
-	for _, k := range resources {
+	for _, k := range sortKeysForDeletion(resources) {
 		err := applier.Delete(ctx, k)
 		if err == nil {
 			lp.Successf("- deleted resource: %s", k.ReadableString())
@@ -39,7 +39,7 @@ func (e *deployExecutor) ensurePrimaryRollout(ctx context.Context) model.StageSt
 	e.LogPersister.Infof("Loading manifests at trigered commit %s for handling", e.commit)
 	manifests, err := loadManifests(
 		ctx,
-		e.Deployment.ApplicationId,
+		e.manifestsCacheID(),
 		e.commit,
 		e.AppManifestsCache,
 		e.provider,
@@ -51,6 +51,13 @@ func (e *deployExecutor) ensurePrimaryRollout(ctx context.Context) model.StageSt
 	}
 	e.LogPersister.Successf("Successfully loaded %d manifests", len(manifests))
 
+	// Separate the hooks from the manifests of application.
+	appManifests, hooks, err := separateHookManifests(manifests)
+	if err != nil {
+		e.LogPersister.Errorf("Invalid hook manifest (%v)", err)
+		return model.StageStatus_STAGE_FAILURE
+	}
+
 	var primaryManifests []provider.Manifest
 	routingMethod := config.DetermineKubernetesTrafficRoutingMethod(e.deployCfg.TrafficRouting)
 
@@ -58,7 +65,7 @@ func (e *deployExecutor) ensurePrimaryRollout(ctx context.Context) model.StageSt
 	// In case of routing by Pod selector,
 	// all manifests can be used as primary manifests.
 	case config.KubernetesTrafficRoutingMethodPodSelector:
-		primaryManifests = manifests
+		primaryManifests = appManifests
 
 	// In case of routing by Istio,
 	// VirtualService manifest will be used to manipulate the traffic ratio.
@@ -69,15 +76,15 @@ func (e *deployExecutor) ensurePrimaryRollout(ctx context.Context) model.StageSt
 		if istioCfg == nil {
 			istioCfg = &config.IstioTrafficRouting{}
 		}
-		trafficRoutingManifests, err := findIstioVirtualServiceManifests(manifests, istioCfg.VirtualService)
+		trafficRoutingManifests, err := findIstioVirtualServiceManifests(appManifests, istioCfg.VirtualService)
 		if err != nil {
 			e.LogPersister.Errorf("Failed while finding traffic routing manifest: (%v)", err)
 			return model.StageStatus_STAGE_FAILURE
 		}
 		// Then remove them from the list of primary manifests.
 		if len(trafficRoutingManifests) > 0 {
-			primaryManifests = make([]provider.Manifest, 0, len(manifests)-1)
-			for _, m := range manifests {
+			primaryManifests = make([]provider.Manifest, 0, len(appManifests)-1)
+			for _, m := range appManifests {
 				if m.Key == trafficRoutingManifests[0].Key {
 					continue
 				}
@@ -130,13 +137,34 @@ func (e *deployExecutor) ensurePrimaryRollout(ctx context.Context) model.StageSt
 		return model.StageStatus_STAGE_FAILURE
 	}
 
+	// Because the loaded manifests are read-only
+	// we duplicate the hooks before adding the builtin annotations to them.
+	for t, hs := range hooks {
+		hooks[t] = duplicateManifests(hs, "")
+		addBuiltinAnnontations(
+			hooks[t],
+			primaryVariant,
+			e.commit,
+			e.PipedConfig.PipedID,
+			e.Deployment.ApplicationId,
+		)
+	}
+
+	if err := runHooks(ctx, e.provider, hookPreSync, hooks[hookPreSync], e.LogPersister); err != nil {
+		return e.handleSyncFailure(ctx, hooks)
+	}
+
 	// Start applying all manifests to add or update running resources.
 	e.LogPersister.Info("Start rolling out PRIMARY variant...")
 	if err := applyManifests(ctx, e.provider, primaryManifests, e.deployCfg.Input.Namespace, e.LogPersister); err != nil {
-		return model.StageStatus_STAGE_FAILURE
+		return e.handleSyncFailure(ctx, hooks)
 	}
 	e.LogPersister.Success("Successfully rolled out PRIMARY variant")
 
+	if err := runHooks(ctx, e.provider, hookPostSync, hooks[hookPostSync], e.LogPersister); err != nil {
+		return e.handleSyncFailure(ctx, hooks)
+	}
+
 	if !options.Prune {
 		e.LogPersister.Info("Resource GC was skipped because sync.prune was not configured")
 		return model.StageStatus_STAGE_SUCCESS
@@ -179,17 +207,24 @@ func (e *deployExecutor) ensurePrimaryRollout(ctx context.Context) model.StageSt
 
 func findRemoveManifests(prevs []provider.Manifest, curs []provider.Manifest, namespace string) []provider.ResourceKey {
 	var (
-		keys       = make(map[provider.ResourceKey]struct{}, len(curs))
-		removeKeys = make([]provider.ResourceKey, 0)
+		keys    = make(map[provider.ResourceKey]struct{}, len(curs))
+		removes = make([]provider.Manifest, 0)
 	)
 	for _, m := range curs {
 		keys[m.Key] = struct{}{}
 	}
 	for _, m := range prevs {
-		key := m.Key
-		if _, ok := keys[key]; ok {
+		if _, ok := keys[m.Key]; ok {
 			continue
 		}
+		removes = append(removes, m)
+	}
+
+	// Remove the resources in the reverse order of applying.
+	sortManifestsByReverseWave(removes)
+	removeKeys := make([]provider.ResourceKey, 0, len(removes))
+	for _, m := range removes {
+		key := m.Key
 		if key.Namespace == "" {
 			key.Namespace = namespace
 		}
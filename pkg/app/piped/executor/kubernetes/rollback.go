@@ -17,11 +17,13 @@ package kubernetes
 import (
 	"context"
 	"strings"
+	"sync"
 
 	"go.uber.org/zap"
 
 	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/kubernetes"
 	"github.com/pipe-cd/pipe/pkg/app/piped/executor"
+	"github.com/pipe-cd/pipe/pkg/config"
 	"github.com/pipe-cd/pipe/pkg/model"
 )
 
@@ -74,18 +76,48 @@ func (e *rollbackExecutor) ensureRollback(ctx context.Context) model.StageStatus
 		}
 	}
 
-	p := provider.NewProvider(e.Deployment.ApplicationName, ds.AppDir, ds.RepoDir, e.Deployment.GitPath.ConfigFilename, deployCfg.Input, e.Logger)
 	e.Logger.Info("start executing kubernetes stage",
 		zap.String("stage-name", e.Stage.Name),
 		zap.String("app-dir", ds.AppDir),
 	)
 
+	if len(deployCfg.Targets) == 0 {
+		p := provider.NewProvider(e.Deployment.ApplicationName, ds.AppDir, ds.RepoDir, e.Deployment.GitPath.ConfigFilename, deployCfg.Input, e.Logger)
+		return e.rollback(ctx, p, deployCfg, e.Deployment.ApplicationId)
+	}
+
+	// All targets are always rolled back even if some of them failed
+	// to revert as many clusters as possible.
+	var mu sync.Mutex
+	execution := config.KubernetesTargetsExecution{
+		Parallel:      deployCfg.TargetsExecution.Parallel,
+		FailurePolicy: config.KubernetesTargetsFailurePolicyContinue,
+	}
+	return runOnTargets(ctx, deployCfg.Targets, execution, e.LogPersister, func(ctx context.Context, t config.KubernetesDeploymentTarget) model.StageStatus {
+		in := makeTargetInput(e.Input, t, &mu)
+		p, err := newTargetProvider(in, ds, deployCfg.Input, t)
+		if err != nil {
+			in.LogPersister.Errorf("Unable to prepare the provider for target (%v)", err)
+			return model.StageStatus_STAGE_FAILURE
+		}
+
+		cfg := *deployCfg
+		cfg.Input = t.ApplyTo(cfg.Input)
+		te := &rollbackExecutor{
+			Input: in,
+		}
+		return te.rollback(ctx, p, &cfg, provider.TargetCacheID(e.Deployment.ApplicationId, t.Name))
+	})
+}
+
+// rollback reverts the resources applied by the given provider to the state at the running commit.
+func (e *rollbackExecutor) rollback(ctx context.Context, p provider.Provider, deployCfg *config.KubernetesDeploymentSpec, manifestsCacheID string) model.StageStatus {
 	// Firstly, we reapply all manifests at running commit
 	// to revert PRIMARY resources and TRAFFIC ROUTING resources.
 
 	// Load the manifests at the specified commit.
 	e.LogPersister.Infof("Loading manifests at running commit %s for handling", e.Deployment.RunningCommitHash)
-	manifests, err := loadManifests(ctx, e.Deployment.ApplicationId, e.Deployment.RunningCommitHash, e.AppManifestsCache, p, e.Logger)
+	manifests, err := loadManifests(ctx, manifestsCacheID, e.Deployment.RunningCommitHash, e.AppManifestsCache, p, e.Logger)
 	if err != nil {
 		e.LogPersister.Errorf("Failed while loading running manifests (%v)", err)
 		return model.StageStatus_STAGE_FAILURE
@@ -123,6 +155,13 @@ func (e *rollbackExecutor) ensureRollback(ctx context.Context) model.StageStatus
 		return model.StageStatus_STAGE_FAILURE
 	}
 
+	// The hooks are not run again while rolling back.
+	manifests, _, err = separateHookManifests(manifests)
+	if err != nil {
+		e.LogPersister.Errorf("Invalid hook manifest (%v)", err)
+		return model.StageStatus_STAGE_FAILURE
+	}
+
 	// Start applying all manifests to add or update running resources.
 	if err := applyManifests(ctx, p, manifests, deployCfg.Input.Namespace, e.LogPersister); err != nil {
 		return model.StageStatus_STAGE_FAILURE
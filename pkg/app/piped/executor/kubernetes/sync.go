@@ -27,7 +27,7 @@ func (e *deployExecutor) ensureSync(ctx context.Context) model.StageStatus {
 	e.LogPersister.Infof("Loading manifests at commit %s for handling", e.commit)
 	manifests, err := loadManifests(
 		ctx,
-		e.Deployment.ApplicationId,
+		e.manifestsCacheID(),
 		e.commit,
 		e.AppManifestsCache,
 		e.provider,
@@ -43,6 +43,13 @@ func (e *deployExecutor) ensureSync(ctx context.Context) model.StageStatus {
 	// we duplicate them to avoid updating the shared manifests data in cache.
 	manifests = duplicateManifests(manifests, "")
 
+	// Separate the hooks from the manifests of application.
+	appManifests, hooks, err := separateHookManifests(manifests)
+	if err != nil {
+		e.LogPersister.Errorf("Invalid hook manifest (%v)", err)
+		return model.StageStatus_STAGE_FAILURE
+	}
+
 	// When addVariantLabelToSelector is true, ensure that all workloads
 	// have the variant label in their selector.
 	if e.deployCfg.QuickSync.AddVariantLabelToSelector {
@@ -70,9 +77,17 @@ func (e *deployExecutor) ensureSync(ctx context.Context) model.StageStatus {
 		return model.StageStatus_STAGE_FAILURE
 	}
 
+	if err := runHooks(ctx, e.provider, hookPreSync, hooks[hookPreSync], e.LogPersister); err != nil {
+		return e.handleSyncFailure(ctx, hooks)
+	}
+
 	// Start applying all manifests to add or update running resources.
-	if err := applyManifests(ctx, e.provider, manifests, e.deployCfg.Input.Namespace, e.LogPersister); err != nil {
-		return model.StageStatus_STAGE_FAILURE
+	if err := applyManifests(ctx, e.provider, appManifests, e.deployCfg.Input.Namespace, e.LogPersister); err != nil {
+		return e.handleSyncFailure(ctx, hooks)
+	}
+
+	if err := runHooks(ctx, e.provider, hookPostSync, hooks[hookPostSync], e.LogPersister); err != nil {
+		return e.handleSyncFailure(ctx, hooks)
 	}
 
 	if !e.deployCfg.QuickSync.Prune {
@@ -108,16 +123,24 @@ func (e *deployExecutor) ensureSync(ctx context.Context) model.StageStatus {
 
 	// Start deleting all running resources that are not defined in Git.
 	if err := deleteResources(ctx, e.provider, removeKeys, e.LogPersister); err != nil {
-		return model.StageStatus_STAGE_FAILURE
+		return e.handleSyncFailure(ctx, hooks)
 	}
 
 	return model.StageStatus_STAGE_SUCCESS
 }
 
+// handleSyncFailure runs the SyncFail hooks and returns the failure status.
+func (e *deployExecutor) handleSyncFailure(ctx context.Context, hooks hookManifests) model.StageStatus {
+	// The stage is failed anyway, and the error of hook was already
+	// written into the stage log, so it can be ignored here.
+	runHooks(ctx, e.provider, hookSyncFail, hooks[hookSyncFail], e.LogPersister)
+	return model.StageStatus_STAGE_FAILURE
+}
+
 func findRemoveResources(manifests []provider.Manifest, liveResources []provider.Manifest) []provider.ResourceKey {
 	var (
-		keys       = make(map[provider.ResourceKey]struct{}, len(manifests))
-		removeKeys = make([]provider.ResourceKey, 0)
+		keys    = make(map[provider.ResourceKey]struct{}, len(manifests))
+		removes = make([]provider.Manifest, 0)
 	)
 	for _, m := range manifests {
 		key := m.Key
@@ -130,8 +153,14 @@ func findRemoveResources(manifests []provider.Manifest, liveResources []provider
 		if _, ok := keys[key]; ok {
 			continue
 		}
-		key.Namespace = m.Key.Namespace
-		removeKeys = append(removeKeys, key)
+		removes = append(removes, m)
+	}
+
+	// Remove the resources in the reverse order of applying.
+	sortManifestsByReverseWave(removes)
+	removeKeys := make([]provider.ResourceKey, 0, len(removes))
+	for _, m := range removes {
+		removeKeys = append(removeKeys, m.Key)
 	}
 	return removeKeys
 }
@@ -0,0 +1,224 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/kubernetes"
+	"github.com/pipe-cd/pipe/pkg/app/piped/deploysource"
+	"github.com/pipe-cd/pipe/pkg/app/piped/executor"
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+// runOnTargets runs the given function on all targets by following the specified execution policy.
+// The aggregated status is success only when the function succeeded on all targets.
+func runOnTargets(ctx context.Context, targets []config.KubernetesDeploymentTarget, policy config.KubernetesTargetsExecution, lp executor.LogPersister, run func(context.Context, config.KubernetesDeploymentTarget) model.StageStatus) model.StageStatus {
+	failFast := policy.FailurePolicy != config.KubernetesTargetsFailurePolicyContinue
+	statuses := make([]model.StageStatus, len(targets))
+
+	if policy.Parallel {
+		lp.Infof("Start running on %d targets in parallel", len(targets))
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		var wg sync.WaitGroup
+		for i := range targets {
+			i := i
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				statuses[i] = run(ctx, targets[i])
+				if statuses[i] != model.StageStatus_STAGE_SUCCESS && failFast {
+					cancel()
+				}
+			}()
+		}
+		wg.Wait()
+	} else {
+		lp.Infof("Start running on %d targets one by one", len(targets))
+		for i := range targets {
+			if ctx.Err() != nil {
+				break
+			}
+			statuses[i] = run(ctx, targets[i])
+			if statuses[i] != model.StageStatus_STAGE_SUCCESS && failFast {
+				break
+			}
+		}
+	}
+
+	var failed, skipped []string
+	for i, s := range statuses {
+		switch s {
+		case model.StageStatus_STAGE_SUCCESS:
+		case model.StageStatus_STAGE_NOT_STARTED_YET:
+			skipped = append(skipped, targets[i].Name)
+		default:
+			failed = append(failed, targets[i].Name)
+		}
+	}
+	if len(skipped) > 0 {
+		lp.Infof("Skipped running on targets: %s", strings.Join(skipped, ", "))
+	}
+	if len(failed) > 0 || len(skipped) > 0 {
+		if len(failed) > 0 {
+			lp.Errorf("Failed on targets: %s", strings.Join(failed, ", "))
+		}
+		return model.StageStatus_STAGE_FAILURE
+	}
+	lp.Successf("Successfully ran on all %d targets", len(targets))
+	return model.StageStatus_STAGE_SUCCESS
+}
+
+// makeTargetInput returns a copy of the given input whose logs, metadata and live resources
+// are isolated for the given target.
+// The given mutex must be shared by all targets to serialize the updates of stage metadata.
+func makeTargetInput(in executor.Input, t config.KubernetesDeploymentTarget, mu *sync.Mutex) executor.Input {
+	in.LogPersister = targetLogPersister{
+		LogPersister: in.LogPersister,
+		prefix:       fmt.Sprintf("[%s] ", t.Name),
+	}
+	in.MetadataStore = targetMetadataStore{
+		MetadataStore: in.MetadataStore,
+		prefix:        t.Name + "/",
+		mu:            mu,
+	}
+	in.AppLiveResourceLister = targetLiveResourceLister{
+		AppLiveResourceLister: in.AppLiveResourceLister,
+		cloudProvider:         t.CloudProvider,
+	}
+	in.Logger = in.Logger.With(zap.String("target", t.Name))
+	return in
+}
+
+// newTargetProvider returns a provider to render the manifests for the given target and apply them to its cluster.
+func newTargetProvider(in executor.Input, ds *deploysource.DeploySource, input config.KubernetesDeploymentInput, t config.KubernetesDeploymentTarget) (provider.Provider, error) {
+	cp, ok := in.PipedConfig.FindCloudProvider(t.CloudProvider, model.CloudProviderKubernetes)
+	if !ok {
+		return nil, fmt.Errorf("cloud provider %s of target %s was not found", t.CloudProvider, t.Name)
+	}
+	return provider.NewProvider(
+		in.Deployment.ApplicationName,
+		filepath.Join(ds.AppDir, t.KustomizeDir),
+		ds.RepoDir,
+		in.Deployment.GitPath.ConfigFilename,
+		t.ApplyTo(input),
+		in.Logger,
+		provider.WithCluster(cp.KubernetesConfig),
+	), nil
+}
+
+type targetLogPersister struct {
+	executor.LogPersister
+	prefix string
+}
+
+func (lp targetLogPersister) Write(log []byte) (int, error) {
+	if _, err := lp.LogPersister.Write(append([]byte(lp.prefix), log...)); err != nil {
+		return 0, err
+	}
+	return len(log), nil
+}
+
+func (lp targetLogPersister) Info(log string) {
+	lp.LogPersister.Info(lp.prefix + log)
+}
+
+func (lp targetLogPersister) Infof(format string, a ...interface{}) {
+	lp.LogPersister.Info(lp.prefix + fmt.Sprintf(format, a...))
+}
+
+func (lp targetLogPersister) Success(log string) {
+	lp.LogPersister.Success(lp.prefix + log)
+}
+
+func (lp targetLogPersister) Successf(format string, a ...interface{}) {
+	lp.LogPersister.Success(lp.prefix + fmt.Sprintf(format, a...))
+}
+
+func (lp targetLogPersister) Error(log string) {
+	lp.LogPersister.Error(lp.prefix + log)
+}
+
+func (lp targetLogPersister) Errorf(format string, a ...interface{}) {
+	lp.LogPersister.Error(lp.prefix + fmt.Sprintf(format, a...))
+}
+
+// targetMetadataStore prefixes all keys by the target name
+// to avoid the metadata of a target being overwritten by the others.
+type targetMetadataStore struct {
+	executor.MetadataStore
+	prefix string
+	mu     *sync.Mutex
+}
+
+func (s targetMetadataStore) Get(key string) (string, bool) {
+	return s.MetadataStore.Get(s.prefix + key)
+}
+
+func (s targetMetadataStore) Set(ctx context.Context, key, value string) error {
+	return s.MetadataStore.Set(ctx, s.prefix+key, value)
+}
+
+func (s targetMetadataStore) GetStageMetadata(stageID string) (map[string]string, bool) {
+	all, ok := s.MetadataStore.GetStageMetadata(stageID)
+	if !ok {
+		return nil, false
+	}
+	metadata := make(map[string]string)
+	for k, v := range all {
+		if strings.HasPrefix(k, s.prefix) {
+			metadata[strings.TrimPrefix(k, s.prefix)] = v
+		}
+	}
+	return metadata, len(metadata) > 0
+}
+
+// SetStageMetadata replaces the stage metadata of this target
+// while keeping the ones of the other targets.
+func (s targetMetadataStore) SetStageMetadata(ctx context.Context, stageID string, metadata map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	merged := make(map[string]string, len(metadata))
+	if all, ok := s.MetadataStore.GetStageMetadata(stageID); ok {
+		for k, v := range all {
+			if !strings.HasPrefix(k, s.prefix) {
+				merged[k] = v
+			}
+		}
+	}
+	for k, v := range metadata {
+		merged[s.prefix+k] = v
+	}
+	return s.MetadataStore.SetStageMetadata(ctx, stageID, merged)
+}
+
+type targetLiveResourceLister struct {
+	executor.AppLiveResourceLister
+	cloudProvider string
+}
+
+func (l targetLiveResourceLister) ListKubernetesResources() ([]provider.Manifest, bool) {
+	return l.AppLiveResourceLister.ListKubernetesResourcesInCloudProvider(l.cloudProvider)
+}
@@ -0,0 +1,154 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+type stageMetadataStore struct {
+	fakeMetadataStore
+	stageMetadata map[string]map[string]string
+}
+
+func (m *stageMetadataStore) GetStageMetadata(stageID string) (map[string]string, bool) {
+	md, ok := m.stageMetadata[stageID]
+	return md, ok
+}
+
+func (m *stageMetadataStore) SetStageMetadata(_ context.Context, stageID string, metadata map[string]string) error {
+	m.stageMetadata[stageID] = metadata
+	return nil
+}
+
+func TestRunOnTargets(t *testing.T) {
+	targets := []config.KubernetesDeploymentTarget{
+		{Name: "asia"},
+		{Name: "eu"},
+		{Name: "us"},
+	}
+	testcases := []struct {
+		name        string
+		policy      config.KubernetesTargetsExecution
+		failed      string
+		expected    model.StageStatus
+		expectedRun []string
+	}{
+		{
+			name:        "sequential: all succeeded",
+			policy:      config.KubernetesTargetsExecution{FailurePolicy: config.KubernetesTargetsFailurePolicyFailFast},
+			expected:    model.StageStatus_STAGE_SUCCESS,
+			expectedRun: []string{"asia", "eu", "us"},
+		},
+		{
+			name:        "sequential: fail fast",
+			policy:      config.KubernetesTargetsExecution{FailurePolicy: config.KubernetesTargetsFailurePolicyFailFast},
+			failed:      "eu",
+			expected:    model.StageStatus_STAGE_FAILURE,
+			expectedRun: []string{"asia", "eu"},
+		},
+		{
+			name:        "sequential: continue",
+			policy:      config.KubernetesTargetsExecution{FailurePolicy: config.KubernetesTargetsFailurePolicyContinue},
+			failed:      "eu",
+			expected:    model.StageStatus_STAGE_FAILURE,
+			expectedRun: []string{"asia", "eu", "us"},
+		},
+		{
+			name:        "parallel: all succeeded",
+			policy:      config.KubernetesTargetsExecution{Parallel: true, FailurePolicy: config.KubernetesTargetsFailurePolicyFailFast},
+			expected:    model.StageStatus_STAGE_SUCCESS,
+			expectedRun: []string{"asia", "eu", "us"},
+		},
+		{
+			name:        "parallel: continue",
+			policy:      config.KubernetesTargetsExecution{Parallel: true, FailurePolicy: config.KubernetesTargetsFailurePolicyContinue},
+			failed:      "us",
+			expected:    model.StageStatus_STAGE_FAILURE,
+			expectedRun: []string{"asia", "eu", "us"},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				mu  sync.Mutex
+				run = make([]string, 0, len(targets))
+			)
+			status := runOnTargets(context.Background(), targets, tc.policy, &fakeLogPersister{}, func(_ context.Context, target config.KubernetesDeploymentTarget) model.StageStatus {
+				mu.Lock()
+				run = append(run, target.Name)
+				mu.Unlock()
+				if target.Name == tc.failed {
+					return model.StageStatus_STAGE_FAILURE
+				}
+				return model.StageStatus_STAGE_SUCCESS
+			})
+			assert.Equal(t, tc.expected, status)
+			assert.ElementsMatch(t, tc.expectedRun, run)
+		})
+	}
+}
+
+func TestRunOnTargetsParallelFailFast(t *testing.T) {
+	targets := []config.KubernetesDeploymentTarget{
+		{Name: "asia"},
+		{Name: "us"},
+	}
+	policy := config.KubernetesTargetsExecution{Parallel: true, FailurePolicy: config.KubernetesTargetsFailurePolicyFailFast}
+
+	status := runOnTargets(context.Background(), targets, policy, &fakeLogPersister{}, func(ctx context.Context, target config.KubernetesDeploymentTarget) model.StageStatus {
+		if target.Name == "asia" {
+			return model.StageStatus_STAGE_FAILURE
+		}
+		// The running targets must be cancelled when one of them failed.
+		<-ctx.Done()
+		return model.StageStatus_STAGE_CANCELLED
+	})
+	assert.Equal(t, model.StageStatus_STAGE_FAILURE, status)
+}
+
+func TestTargetMetadataStore(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		mu   sync.Mutex
+		base = &stageMetadataStore{stageMetadata: make(map[string]map[string]string)}
+		asia = targetMetadataStore{MetadataStore: base, prefix: "asia/", mu: &mu}
+		us   = targetMetadataStore{MetadataStore: base, prefix: "us/", mu: &mu}
+	)
+
+	require.NoError(t, asia.SetStageMetadata(ctx, "stage-1", map[string]string{"primary-percentage": "100"}))
+	require.NoError(t, us.SetStageMetadata(ctx, "stage-1", map[string]string{"primary-percentage": "50"}))
+	require.NoError(t, us.SetStageMetadata(ctx, "stage-1", map[string]string{"primary-percentage": "0"}))
+
+	assert.Equal(t, map[string]string{
+		"asia/primary-percentage": "100",
+		"us/primary-percentage":   "0",
+	}, base.stageMetadata["stage-1"])
+
+	md, ok := asia.GetStageMetadata("stage-1")
+	require.True(t, ok)
+	assert.Equal(t, map[string]string{"primary-percentage": "100"}, md)
+
+	_, ok = asia.GetStageMetadata("stage-2")
+	assert.False(t, ok)
+}
@@ -51,7 +51,7 @@ func (e *deployExecutor) ensureTrafficRouting(ctx context.Context) model.StageSt
 	e.LogPersister.Infof("Loading manifests at commit %s for handling", commitHash)
 	manifests, err := loadManifests(
 		ctx,
-		e.Deployment.ApplicationId,
+		e.manifestsCacheID(),
 		e.commit,
 		e.AppManifestsCache,
 		e.provider,
@@ -0,0 +1,154 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/kubernetes"
+	"github.com/pipe-cd/pipe/pkg/app/piped/executor"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+const (
+	waveCheckInterval = 5 * time.Second
+	waveHealthTimeout = 10 * time.Minute
+)
+
+// groupManifestsByWave groups the given manifests into the waves in the order they should be applied.
+// Namespaces and CustomResourceDefinitions are always placed in the first wave
+// so that the resources using them can be applied after they became ready.
+// The rest are grouped by the number specified in their pipecd.dev/sync-wave annotation
+// and sorted in ascending order of that number. Zero is used when the annotation is not specified.
+// The loaded order of manifests is kept inside each wave.
+func groupManifestsByWave(manifests []provider.Manifest) ([][]provider.Manifest, error) {
+	var (
+		prerequisites []provider.Manifest
+		waves         = make(map[int][]provider.Manifest)
+		numbers       = make([]int, 0)
+	)
+	for _, m := range manifests {
+		if m.Key.IsNamespace() || m.Key.IsCustomResourceDefinition() {
+			prerequisites = append(prerequisites, m)
+			continue
+		}
+		n, err := determineSyncWave(m)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := waves[n]; !ok {
+			numbers = append(numbers, n)
+		}
+		waves[n] = append(waves[n], m)
+	}
+	sort.Ints(numbers)
+
+	out := make([][]provider.Manifest, 0, len(numbers)+1)
+	if len(prerequisites) > 0 {
+		out = append(out, prerequisites)
+	}
+	for _, n := range numbers {
+		out = append(out, waves[n])
+	}
+	return out, nil
+}
+
+func determineSyncWave(m provider.Manifest) (int, error) {
+	value, ok := m.GetAnnotations()[provider.AnnotationSyncWave]
+	if !ok {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s annotation %q in %s: it must be an integer", provider.AnnotationSyncWave, value, m.Key.ReadableString())
+	}
+	return n, nil
+}
+
+// sortManifestsByReverseWave sorts the given manifests in the reverse order of their waves.
+// The invalid wave numbers are treated as zero because those manifests are being removed.
+func sortManifestsByReverseWave(manifests []provider.Manifest) {
+	sort.SliceStable(manifests, func(i, j int) bool {
+		wi, _ := determineSyncWave(manifests[i])
+		wj, _ := determineSyncWave(manifests[j])
+		return wi > wj
+	})
+}
+
+// sortKeysForDeletion returns a copy of the given keys sorted so that
+// CustomResourceDefinitions and Namespaces are deleted after all other resources.
+func sortKeysForDeletion(keys []provider.ResourceKey) []provider.ResourceKey {
+	priority := func(k provider.ResourceKey) int {
+		switch {
+		case k.IsNamespace():
+			return 2
+		case k.IsCustomResourceDefinition():
+			return 1
+		default:
+			return 0
+		}
+	}
+	out := make([]provider.ResourceKey, len(keys))
+	copy(out, keys)
+	sort.SliceStable(out, func(i, j int) bool {
+		return priority(out[i]) < priority(out[j])
+	})
+	return out
+}
+
+// waitForHealthy waits until all resources of the given manifests became healthy.
+// The resources whose health cannot be determined are treated as healthy.
+func waitForHealthy(ctx context.Context, applier provider.Applier, manifests []provider.Manifest, lp executor.LogPersister) error {
+	ctx, cancel := context.WithTimeout(ctx, waveHealthTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(waveCheckInterval)
+	defer ticker.Stop()
+
+	pendings := manifests
+	for {
+		remainings := make([]provider.Manifest, 0, len(pendings))
+		var lastDesc string
+		for _, m := range pendings {
+			live, err := applier.Get(ctx, m.Key)
+			if err != nil {
+				lastDesc = fmt.Sprintf("unable to get %s (%v)", m.Key.ReadableString(), err)
+				remainings = append(remainings, m)
+				continue
+			}
+			status, desc := provider.DetermineManifestHealth(live)
+			if status == model.KubernetesResourceState_OTHER {
+				lastDesc = fmt.Sprintf("%s: %s", m.Key.ReadableString(), desc)
+				remainings = append(remainings, m)
+			}
+		}
+		if len(remainings) == 0 {
+			return nil
+		}
+		pendings = remainings
+
+		select {
+		case <-ctx.Done():
+			lp.Errorf("%d resources did not become healthy before the deadline, e.g. %s", len(pendings), lastDesc)
+			return fmt.Errorf("%d resources did not become healthy (%w)", len(pendings), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/kubernetes"
+)
+
+func TestGroupManifestsByWave(t *testing.T) {
+	testcases := []struct {
+		name        string
+		manifests   string
+		expected    [][]string
+		expectedErr bool
+	}{
+		{
+			name: "no wave",
+			manifests: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: simple
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: simple
+`,
+			expected: [][]string{
+				{"Deployment/simple", "Service/simple"},
+			},
+		},
+		{
+			name: "prerequisites and multiple waves",
+			manifests: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: simple
+  annotations:
+    pipecd.dev/sync-wave: "1"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  annotations:
+    pipecd.dev/sync-wave: "-1"
+---
+apiVersion: example.com/v1
+kind: Foo
+metadata:
+  name: foo
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: foos.example.com
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: simple
+`,
+			expected: [][]string{
+				{"CustomResourceDefinition/foos.example.com", "Namespace/simple"},
+				{"ConfigMap/config"},
+				{"Foo/foo"},
+				{"Deployment/simple"},
+			},
+		},
+		{
+			name: "invalid wave",
+			manifests: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: simple
+  annotations:
+    pipecd.dev/sync-wave: first
+`,
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			manifests, err := provider.ParseManifests(tc.manifests)
+			require.NoError(t, err)
+
+			waves, err := groupManifestsByWave(manifests)
+			assert.Equal(t, tc.expectedErr, err != nil)
+			if err != nil {
+				return
+			}
+
+			got := make([][]string, 0, len(waves))
+			for _, wave := range waves {
+				names := make([]string, 0, len(wave))
+				for _, m := range wave {
+					names = append(names, m.Key.Kind+"/"+m.Key.Name)
+				}
+				got = append(got, names)
+			}
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestSortManifestsByReverseWave(t *testing.T) {
+	manifests, err := provider.ParseManifests(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  annotations:
+    pipecd.dev/sync-wave: "-1"
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: first
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: last
+  annotations:
+    pipecd.dev/sync-wave: "2"
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: first
+`)
+	require.NoError(t, err)
+
+	sortManifestsByReverseWave(manifests)
+	got := make([]string, 0, len(manifests))
+	for _, m := range manifests {
+		got = append(got, m.Key.Kind+"/"+m.Key.Name)
+	}
+	assert.Equal(t, []string{"Deployment/last", "Deployment/first", "Service/first", "ConfigMap/config"}, got)
+}
+
+func TestSortKeysForDeletion(t *testing.T) {
+	keys := []provider.ResourceKey{
+		{APIVersion: "v1", Kind: "Namespace", Name: "simple"},
+		{APIVersion: "apiextensions.k8s.io/v1", Kind: "CustomResourceDefinition", Name: "foos.example.com"},
+		{APIVersion: "example.com/v1", Kind: "Foo", Name: "foo"},
+		{APIVersion: "apps/v1", Kind: "Deployment", Name: "simple"},
+	}
+	expected := []provider.ResourceKey{
+		{APIVersion: "example.com/v1", Kind: "Foo", Name: "foo"},
+		{APIVersion: "apps/v1", Kind: "Deployment", Name: "simple"},
+		{APIVersion: "apiextensions.k8s.io/v1", Kind: "CustomResourceDefinition", Name: "foos.example.com"},
+		{APIVersion: "v1", Kind: "Namespace", Name: "simple"},
+	}
+
+	got := sortKeysForDeletion(keys)
+	assert.Equal(t, expected, got)
+	// The given keys must not be modified.
+	assert.Equal(t, "Namespace", keys[0].Kind)
+}
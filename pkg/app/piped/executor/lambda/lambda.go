@@ -76,6 +76,7 @@ func loadFunctionManifest(in *executor.Input, functionManifestFile string, ds *d
 		in.LogPersister.Errorf("Failed to load lambda function manifest (%v)", err)
 		return provider.FunctionManifest{}, false
 	}
+	fm.Spec.Tags = provider.MakeTags(fm.Spec.Tags, in.PipedConfig.PipedID, in.Deployment.ApplicationId, ds.Revision)
 
 	in.LogPersister.Infof("Successfully loaded the lambda function manifest at commit %s", ds.Revision)
 	return fm, true
@@ -0,0 +1,88 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nomad
+
+import (
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/nomad"
+	"github.com/pipe-cd/pipe/pkg/app/piped/deploysource"
+	"github.com/pipe-cd/pipe/pkg/app/piped/executor"
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+type deployExecutor struct {
+	executor.Input
+
+	deploySource *deploysource.DeploySource
+	deployCfg    *config.NomadDeploymentSpec
+	client       provider.Client
+}
+
+func (e *deployExecutor) Execute(sig executor.StopSignal) model.StageStatus {
+	ctx := sig.Context()
+	ds, err := e.TargetDSP.GetReadOnly(ctx, e.LogPersister)
+	if err != nil {
+		e.LogPersister.Errorf("Failed to prepare target deploy source data (%v)", err)
+		return model.StageStatus_STAGE_FAILURE
+	}
+
+	e.deploySource = ds
+	e.deployCfg = ds.DeploymentConfig.NomadDeploymentSpec
+	if e.deployCfg == nil {
+		e.LogPersister.Error("Malformed deployment configuration: missing NomadDeploymentSpec")
+		return model.StageStatus_STAGE_FAILURE
+	}
+
+	if e.client == nil {
+		var ok bool
+		if e.client, ok = newClient(&e.Input); !ok {
+			return model.StageStatus_STAGE_FAILURE
+		}
+	}
+
+	job, ok := loadJob(ctx, &e.Input, e.client, e.deployCfg.Input.JobFile, e.deploySource)
+	if !ok {
+		return model.StageStatus_STAGE_FAILURE
+	}
+
+	var (
+		originalStatus = e.Stage.Status
+		status         model.StageStatus
+	)
+
+	switch model.Stage(e.Stage.Name) {
+	case model.StageNomadSync:
+		status = toStageStatus(sync(ctx, &e.Input, e.client, job))
+
+	case model.StageNomadCanaryRollout:
+		status = toStageStatus(canaryRollout(ctx, &e.Input, e.client, job))
+
+	case model.StageNomadPromote:
+		status = toStageStatus(promote(ctx, &e.Input, e.client, job))
+
+	default:
+		e.LogPersister.Errorf("Unsupported stage %s for nomad application", e.Stage.Name)
+		return model.StageStatus_STAGE_FAILURE
+	}
+
+	return executor.DetermineStageStatus(sig.Signal(), originalStatus, status)
+}
+
+func toStageStatus(ok bool) model.StageStatus {
+	if ok {
+		return model.StageStatus_STAGE_SUCCESS
+	}
+	return model.StageStatus_STAGE_FAILURE
+}
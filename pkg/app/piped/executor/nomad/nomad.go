@@ -0,0 +1,305 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nomad
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/nomad"
+	"github.com/pipe-cd/pipe/pkg/app/piped/deploysource"
+	"github.com/pipe-cd/pipe/pkg/app/piped/executor"
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+const (
+	deploymentCheckInterval = 5 * time.Second
+	deploymentTimeout       = time.Hour
+)
+
+type registerer interface {
+	Register(stage model.Stage, f executor.Factory) error
+	RegisterRollback(kind model.ApplicationKind, f executor.Factory) error
+}
+
+func Register(r registerer) {
+	f := func(in executor.Input) executor.Executor {
+		return &deployExecutor{
+			Input: in,
+		}
+	}
+	r.Register(model.StageNomadSync, f)
+	r.Register(model.StageNomadCanaryRollout, f)
+	r.Register(model.StageNomadPromote, f)
+
+	r.RegisterRollback(model.ApplicationKind_NOMAD, func(in executor.Input) executor.Executor {
+		return &rollbackExecutor{
+			Input: in,
+		}
+	})
+}
+
+func findCloudProvider(in *executor.Input) (name string, cfg *config.CloudProviderNomadConfig, found bool) {
+	name = in.Application.CloudProvider
+	if name == "" {
+		in.LogPersister.Error("Missing the CloudProvider name in the application configuration")
+		return
+	}
+
+	cp, ok := in.PipedConfig.FindCloudProvider(name, model.CloudProviderNomad)
+	if !ok {
+		in.LogPersister.Errorf("The specified cloud provider %q was not found in piped configuration", name)
+		return
+	}
+
+	cfg = cp.NomadConfig
+	found = true
+	return
+}
+
+func newClient(in *executor.Input) (provider.Client, bool) {
+	cloudProviderName, cloudProviderCfg, found := findCloudProvider(in)
+	if !found {
+		return nil, false
+	}
+
+	client, err := provider.DefaultRegistry().Client(cloudProviderName, cloudProviderCfg, in.Logger)
+	if err != nil {
+		in.LogPersister.Errorf("Unable to create Nomad client for the provider %s (%v)", cloudProviderName, err)
+		return nil, false
+	}
+	return client, true
+}
+
+// loadJob loads the job file at the given deploy source
+// and attaches the meta used by PipeCD to find the job of the application.
+func loadJob(ctx context.Context, in *executor.Input, client provider.Client, jobFile string, ds *deploysource.DeploySource) (provider.Job, bool) {
+	in.LogPersister.Infof("Loading job file at commit %s", ds.Revision)
+
+	data, err := provider.LoadJobFile(ds.AppDir, jobFile)
+	if err != nil {
+		in.LogPersister.Errorf("Failed to load job file (%v)", err)
+		return nil, false
+	}
+
+	job, err := client.ParseJob(ctx, data)
+	if err != nil {
+		in.LogPersister.Errorf("Failed to parse job file (%v)", err)
+		return nil, false
+	}
+	job.SetMeta(provider.MakeMeta(job.Meta(), in.PipedConfig.PipedID, in.Deployment.ApplicationId, ds.Revision))
+
+	in.LogPersister.Infof("Successfully loaded the job %s at commit %s", job.ID(), ds.Revision)
+	return job, true
+}
+
+// sync registers the given job and waits until its deployment has been completed.
+// The canaries placed by the deployment are promoted automatically after they became healthy.
+func sync(ctx context.Context, in *executor.Input, client provider.Client, job provider.Job) bool {
+	registered, ok := registerJob(ctx, in, client, job)
+	if !ok {
+		return false
+	}
+	if !registered.RequiresDeployment() {
+		in.LogPersister.Successf("Successfully registered %s job %s", registered.Type(), registered.ID())
+		return true
+	}
+
+	_, err := waitForDeployment(ctx, in, client, registered, func(d *provider.Deployment) (bool, error) {
+		if d.Status == provider.DeploymentStatusSuccessful {
+			return true, nil
+		}
+		if d.RequiresPromotion() && d.CanariesHealthy() {
+			in.LogPersister.Infof("Promoting the healthy canaries of deployment %s", d.ID)
+			if err := client.PromoteDeployment(ctx, d.ID); err != nil {
+				return false, fmt.Errorf("unable to promote deployment %s (%w)", d.ID, err)
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		in.LogPersister.Errorf("Failed to deploy version %d of job %s (%v)", registered.Version(), registered.ID(), err)
+		return false
+	}
+
+	in.LogPersister.Successf("Successfully deployed version %d of job %s", registered.Version(), registered.ID())
+	return true
+}
+
+// canaryRollout registers the given job and waits until the canaries of its deployment became healthy.
+// The deployment is recorded to be promoted by the subsequent NOMAD_PROMOTE stage.
+func canaryRollout(ctx context.Context, in *executor.Input, client provider.Client, job provider.Job) bool {
+	if !job.HasCanary() {
+		in.LogPersister.Errorf("Job %s must specify the number of canaries in its update strategy to do canary rollout", job.ID())
+		return false
+	}
+
+	registered, ok := registerJob(ctx, in, client, job)
+	if !ok {
+		return false
+	}
+
+	d, err := waitForDeployment(ctx, in, client, registered, func(d *provider.Deployment) (bool, error) {
+		return d.Status == provider.DeploymentStatusSuccessful || d.CanariesHealthy(), nil
+	})
+	if err != nil {
+		in.LogPersister.Errorf("Failed to roll out canaries of job %s (%v)", registered.ID(), err)
+		return false
+	}
+
+	if err := in.MetadataStore.Set(ctx, canaryDeploymentKeyName(registered.ID()), d.ID); err != nil {
+		in.LogPersister.Errorf("Unable to store the canary deployment of job %s to metadata store (%v)", registered.ID(), err)
+		return false
+	}
+
+	if d.Status == provider.DeploymentStatusSuccessful {
+		in.LogPersister.Successf("Deployment %s has already been completed since its canaries were promoted automatically", d.ID)
+		return true
+	}
+	for name, s := range d.TaskGroups {
+		if s.DesiredCanaries > 0 {
+			in.LogPersister.Infof("  %s: %d healthy canaries", name, s.HealthyAllocs)
+		}
+	}
+	in.LogPersister.Successf("Successfully rolled out canaries of job %s in deployment %s", registered.ID(), d.ID)
+	return true
+}
+
+// promote promotes the canaries placed by the NOMAD_CANARY_ROLLOUT stage
+// and waits until the new version has been fully rolled out.
+func promote(ctx context.Context, in *executor.Input, client provider.Client, job provider.Job) bool {
+	deploymentID, ok := in.MetadataStore.Get(canaryDeploymentKeyName(job.ID()))
+	if !ok {
+		in.LogPersister.Errorf("Unable to find the canary deployment of job %s, NOMAD_CANARY_ROLLOUT stage must be run before promoting", job.ID())
+		return false
+	}
+
+	d, err := client.GetLatestDeployment(ctx, job.ID())
+	if err != nil {
+		in.LogPersister.Errorf("Unable to get the latest deployment of job %s (%v)", job.ID(), err)
+		return false
+	}
+	if d.ID != deploymentID {
+		in.LogPersister.Errorf("Deployment %s was superseded by deployment %s", deploymentID, d.ID)
+		return false
+	}
+
+	if d.RequiresPromotion() {
+		in.LogPersister.Infof("Promoting the canaries of deployment %s", d.ID)
+		if err := client.PromoteDeployment(ctx, d.ID); err != nil {
+			in.LogPersister.Errorf("Failed to promote deployment %s (%v)", d.ID, err)
+			return false
+		}
+	}
+
+	job, err = client.GetJob(ctx, job.ID())
+	if err != nil {
+		in.LogPersister.Errorf("Unable to get job %s (%v)", job.ID(), err)
+		return false
+	}
+	_, err = waitForDeployment(ctx, in, client, job, func(d *provider.Deployment) (bool, error) {
+		return d.Status == provider.DeploymentStatusSuccessful, nil
+	})
+	if err != nil {
+		in.LogPersister.Errorf("Failed to complete deployment %s (%v)", deploymentID, err)
+		return false
+	}
+
+	in.LogPersister.Successf("Successfully promoted deployment %s", deploymentID)
+	return true
+}
+
+// rollback stops the deployment in progress and then registers the given job of the running commit again.
+func rollback(ctx context.Context, in *executor.Input, client provider.Client, job provider.Job) bool {
+	d, err := client.GetLatestDeployment(ctx, job.ID())
+	if err != nil && !errors.Is(err, provider.ErrNotFound) {
+		in.LogPersister.Errorf("Unable to get the latest deployment of job %s (%v)", job.ID(), err)
+		return false
+	}
+	if err == nil && d.IsActive() {
+		in.LogPersister.Infof("Marking the deployment %s in progress as failed", d.ID)
+		if err := client.FailDeployment(ctx, d.ID); err != nil {
+			in.LogPersister.Errorf("Failed to mark deployment %s as failed (%v)", d.ID, err)
+			return false
+		}
+	}
+
+	in.LogPersister.Infof("Start registering job %s at the running commit", job.ID())
+	return sync(ctx, in, client, job)
+}
+
+func registerJob(ctx context.Context, in *executor.Input, client provider.Client, job provider.Job) (provider.Job, bool) {
+	in.LogPersister.Infof("Start registering job %s", job.ID())
+	registered, err := client.RegisterJob(ctx, job)
+	if err != nil {
+		in.LogPersister.Errorf("Failed to register job %s (%v)", job.ID(), err)
+		return nil, false
+	}
+	in.LogPersister.Infof("Successfully registered version %d of job %s", registered.Version(), registered.ID())
+	return registered, true
+}
+
+// waitForDeployment polls the deployment of the given job version until the check function reports it is done.
+// An error is returned when the deployment was failed or cancelled.
+func waitForDeployment(ctx context.Context, in *executor.Input, client provider.Client, job provider.Job, check func(*provider.Deployment) (bool, error)) (*provider.Deployment, error) {
+	ctx, cancel := context.WithTimeout(ctx, deploymentTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(deploymentCheckInterval)
+	defer ticker.Stop()
+
+	var lastStatus string
+	for {
+		d, err := client.GetLatestDeployment(ctx, job.ID())
+		switch {
+		case errors.Is(err, provider.ErrNotFound):
+			// The deployment has not been created yet.
+		case err != nil:
+			return nil, fmt.Errorf("unable to get the latest deployment (%w)", err)
+		case d.JobVersion < job.Version():
+			// The deployment for the registered version has not been created yet.
+		case d.JobVersion > job.Version():
+			return nil, fmt.Errorf("version %d was superseded by version %d", job.Version(), d.JobVersion)
+		default:
+			if d.IsFailed() {
+				return nil, fmt.Errorf("deployment %s was %s: %s", d.ID, d.Status, d.StatusDescription)
+			}
+			if desc := fmt.Sprintf("%s: %s", d.Status, d.StatusDescription); desc != lastStatus {
+				in.LogPersister.Infof("Deployment %s is %s", d.ID, desc)
+				lastStatus = desc
+			}
+			done, err := check(d)
+			if err != nil {
+				return nil, err
+			}
+			if done {
+				return d, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("deployment was not completed before the deadline (%w)", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+func canaryDeploymentKeyName(jobID string) string {
+	return fmt.Sprintf("%s-canary-deployment", jobID)
+}
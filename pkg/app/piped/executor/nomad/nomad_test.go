@@ -0,0 +1,179 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nomad
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/nomad"
+	"github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/nomad/nomadfake"
+	"github.com/pipe-cd/pipe/pkg/app/piped/executor"
+	"github.com/pipe-cd/pipe/pkg/config"
+)
+
+type fakeLogPersister struct{}
+
+func (l *fakeLogPersister) Write(_ []byte) (int, error)         { return 0, nil }
+func (l *fakeLogPersister) Info(_ string)                       {}
+func (l *fakeLogPersister) Infof(_ string, _ ...interface{})    {}
+func (l *fakeLogPersister) Success(_ string)                    {}
+func (l *fakeLogPersister) Successf(_ string, _ ...interface{}) {}
+func (l *fakeLogPersister) Error(_ string)                      {}
+func (l *fakeLogPersister) Errorf(_ string, _ ...interface{})   {}
+
+type fakeMetadataStore struct {
+	values map[string]string
+}
+
+func (m *fakeMetadataStore) Get(key string) (string, bool) {
+	v, ok := m.values[key]
+	return v, ok
+}
+
+func (m *fakeMetadataStore) Set(_ context.Context, key, value string) error {
+	m.values[key] = value
+	return nil
+}
+
+func (m *fakeMetadataStore) GetStageMetadata(_ string) (map[string]string, bool) { return nil, false }
+func (m *fakeMetadataStore) SetStageMetadata(_ context.Context, _ string, _ map[string]string) error {
+	return nil
+}
+
+func newTestInput() *executor.Input {
+	return &executor.Input{
+		LogPersister:  &fakeLogPersister{},
+		MetadataStore: &fakeMetadataStore{values: make(map[string]string)},
+		Logger:        zap.NewNop(),
+	}
+}
+
+func newTestClient(t *testing.T) (provider.Client, *nomadfake.Server) {
+	s := nomadfake.NewServer()
+	t.Cleanup(s.Close)
+
+	client, err := provider.NewClient(&config.CloudProviderNomadConfig{Address: s.URL}, zap.NewNop())
+	require.NoError(t, err)
+	return client, s
+}
+
+func newTestJob(t *testing.T, client provider.Client, image string, canary int) provider.Job {
+	data := fmt.Sprintf(`{
+  "Job": {
+    "ID": "web",
+    "Type": "service",
+    "TaskGroups": [
+      {
+        "Name": "web",
+        "Count": 3,
+        "Update": {"Canary": %d},
+        "Tasks": [{"Name": "server", "Driver": "docker", "Config": {"image": %q}}]
+      }
+    ]
+  }
+}`, canary, image)
+	job, err := client.ParseJob(context.Background(), []byte(data))
+	require.NoError(t, err)
+	return job
+}
+
+func TestSyncAndRollback(t *testing.T) {
+	ctx := context.Background()
+	client, server := newTestClient(t)
+	in := newTestInput()
+
+	require.True(t, sync(ctx, in, client, newTestJob(t, client, "web:v1", 0)))
+	require.True(t, sync(ctx, in, client, newTestJob(t, client, "web:v2", 0)))
+
+	// Registering the same job again does not create a new deployment.
+	require.True(t, sync(ctx, in, client, newTestJob(t, client, "web:v2", 0)))
+	deployments := server.Deployments("web")
+	require.Len(t, deployments, 2)
+	assert.Equal(t, uint64(1), deployments[1].JobVersion)
+	assert.Equal(t, provider.DeploymentStatusSuccessful, deployments[1].Status)
+
+	// The canaries placed by the job are promoted automatically.
+	require.True(t, sync(ctx, in, client, newTestJob(t, client, "web:v3", 1)))
+	deployments = server.Deployments("web")
+	require.Len(t, deployments, 3)
+	assert.Equal(t, provider.DeploymentStatusSuccessful, deployments[2].Status)
+	assert.True(t, deployments[2].TaskGroups["web"].Promoted)
+
+	// The job of the running commit is registered as a new version.
+	require.True(t, rollback(ctx, in, client, newTestJob(t, client, "web:v1", 0)))
+	job, err := client.GetJob(ctx, "web")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), job.Version())
+	version, err := provider.FindArtifactVersion(mustMarshal(t, job))
+	require.NoError(t, err)
+	assert.Equal(t, "v1", version)
+}
+
+func TestCanaryRolloutAndPromote(t *testing.T) {
+	ctx := context.Background()
+	client, server := newTestClient(t)
+	in := newTestInput()
+
+	require.True(t, sync(ctx, in, client, newTestJob(t, client, "web:v1", 0)))
+
+	// The job must place canaries to do canary rollout.
+	require.False(t, canaryRollout(ctx, in, client, newTestJob(t, client, "web:v2", 0)))
+
+	// The canary rollout must be done before promoting.
+	require.False(t, promote(ctx, in, client, newTestJob(t, client, "web:v2", 1)))
+
+	job := newTestJob(t, client, "web:v2", 1)
+	require.True(t, canaryRollout(ctx, in, client, job))
+	deployments := server.Deployments("web")
+	require.Len(t, deployments, 2)
+	assert.Equal(t, provider.DeploymentStatusRunning, deployments[1].Status)
+	deploymentID, ok := in.MetadataStore.Get(canaryDeploymentKeyName("web"))
+	require.True(t, ok)
+	assert.Equal(t, deployments[1].ID, deploymentID)
+
+	require.True(t, promote(ctx, in, client, job))
+	deployments = server.Deployments("web")
+	assert.Equal(t, provider.DeploymentStatusSuccessful, deployments[1].Status)
+	assert.True(t, deployments[1].TaskGroups["web"].Promoted)
+}
+
+func TestRollbackCanary(t *testing.T) {
+	ctx := context.Background()
+	client, server := newTestClient(t)
+	in := newTestInput()
+
+	require.True(t, sync(ctx, in, client, newTestJob(t, client, "web:v1", 0)))
+	require.True(t, canaryRollout(ctx, in, client, newTestJob(t, client, "web:v2", 1)))
+
+	// The deployment of the canaries is stopped before rolling back.
+	require.True(t, rollback(ctx, in, client, newTestJob(t, client, "web:v1", 0)))
+	deployments := server.Deployments("web")
+	require.Len(t, deployments, 3)
+	assert.Equal(t, provider.DeploymentStatusFailed, deployments[1].Status)
+	assert.Equal(t, provider.DeploymentStatusSuccessful, deployments[2].Status)
+}
+
+func mustMarshal(t *testing.T, job provider.Job) []byte {
+	data, err := json.Marshal(job)
+	require.NoError(t, err)
+	return data
+}
@@ -0,0 +1,109 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promote
+
+import (
+	"fmt"
+
+	"github.com/pipe-cd/pipe/pkg/app/piped/executor"
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+type Executor struct {
+	executor.Input
+}
+
+type registerer interface {
+	Register(stage model.Stage, f executor.Factory) error
+}
+
+// Register registers this executor factory into a given registerer.
+func Register(r registerer) {
+	f := func(in executor.Input) executor.Executor {
+		return &Executor{
+			Input: in,
+		}
+	}
+	r.Register(model.StagePromote, f)
+}
+
+// Execute promotes the deployed release by the promotions specified in the stage configuration.
+// Since the release has already been deployed, a failed promotion is only reported
+// and does not fail the stage so that the deployment is not rolled back.
+func (e *Executor) Execute(sig executor.StopSignal) model.StageStatus {
+	var (
+		ctx            = sig.Context()
+		originalStatus = e.Stage.Status
+	)
+
+	opts := e.StageConfig.PromoteStageOptions
+	if opts == nil {
+		e.LogPersister.Errorf("Malformed configuration for stage %s", e.Stage.Name)
+		return model.StageStatus_STAGE_FAILURE
+	}
+
+	ds, err := e.TargetDSP.GetReadOnly(ctx, e.LogPersister)
+	if err != nil {
+		e.LogPersister.Errorf("Failed to prepare target deploy source data (%v)", err)
+		return model.StageStatus_STAGE_FAILURE
+	}
+
+	promotions, err := findPromotions(ds.GenericDeploymentConfig.Promotions, opts.Promotions)
+	if err != nil {
+		e.LogPersister.Errorf("Unable to find the promotions (%v)", err)
+		return model.StageStatus_STAGE_FAILURE
+	}
+	if len(promotions) == 0 {
+		e.LogPersister.Info("There is no promotion to do")
+		return model.StageStatus_STAGE_SUCCESS
+	}
+
+	e.LogPersister.Infof("Start promoting the deployed release by %d promotion(s)", len(promotions))
+	failed := 0
+	for _, p := range e.Promoter.Promote(ctx, e.Deployment, ds.AppDir, promotions) {
+		switch p.Status {
+		case model.PromotionStatus_PROMOTION_SUCCESS:
+			e.LogPersister.Successf("Promotion %s: %s", p.Name, p.StatusReason)
+		case model.PromotionStatus_PROMOTION_SKIPPED:
+			e.LogPersister.Infof("Promotion %s was skipped: %s", p.Name, p.StatusReason)
+		default:
+			e.LogPersister.Errorf("Promotion %s failed: %s", p.Name, p.StatusReason)
+			failed++
+		}
+	}
+	if failed > 0 {
+		e.LogPersister.Errorf("%d promotion(s) failed. They can be found in the promotion history and need to be done again by hand", failed)
+	}
+
+	return executor.DetermineStageStatus(sig.Signal(), originalStatus, model.StageStatus_STAGE_SUCCESS)
+}
+
+// findPromotions returns the promotions of the given names in the defined order.
+// All defined promotions are returned when no name was given.
+func findPromotions(defined []config.Promotion, names []string) ([]config.Promotion, error) {
+	if len(names) == 0 {
+		return defined, nil
+	}
+	out := make([]config.Promotion, 0, len(names))
+	for _, name := range names {
+		p, ok := config.FindPromotion(defined, name)
+		if !ok {
+			return nil, fmt.Errorf("promotion %s is not defined in the deployment configuration", name)
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
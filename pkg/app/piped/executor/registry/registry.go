@@ -20,10 +20,14 @@ import (
 
 	"github.com/pipe-cd/pipe/pkg/app/piped/executor"
 	"github.com/pipe-cd/pipe/pkg/app/piped/executor/analysis"
+	"github.com/pipe-cd/pipe/pkg/app/piped/executor/cloudfunctions"
 	"github.com/pipe-cd/pipe/pkg/app/piped/executor/cloudrun"
 	"github.com/pipe-cd/pipe/pkg/app/piped/executor/ecs"
 	"github.com/pipe-cd/pipe/pkg/app/piped/executor/kubernetes"
 	"github.com/pipe-cd/pipe/pkg/app/piped/executor/lambda"
+	"github.com/pipe-cd/pipe/pkg/app/piped/executor/nomad"
+	"github.com/pipe-cd/pipe/pkg/app/piped/executor/promote"
+	"github.com/pipe-cd/pipe/pkg/app/piped/executor/scriptrun"
 	"github.com/pipe-cd/pipe/pkg/app/piped/executor/terraform"
 	"github.com/pipe-cd/pipe/pkg/app/piped/executor/wait"
 	"github.com/pipe-cd/pipe/pkg/app/piped/executor/waitapproval"
@@ -33,11 +37,13 @@ import (
 type Registry interface {
 	Executor(stage model.Stage, in executor.Input) (executor.Executor, bool)
 	RollbackExecutor(kind model.ApplicationKind, in executor.Input) (executor.Executor, bool)
+	Cleaner(kind model.ApplicationKind, in executor.Input) (executor.Cleaner, bool)
 }
 
 type registry struct {
 	factories         map[model.Stage]executor.Factory
 	rollbackFactories map[model.ApplicationKind]executor.Factory
+	cleanerFactories  map[model.ApplicationKind]executor.CleanerFactory
 	mu                sync.RWMutex
 }
 
@@ -63,6 +69,17 @@ func (r *registry) RegisterRollback(kind model.ApplicationKind, f executor.Facto
 	return nil
 }
 
+func (r *registry) RegisterCleaner(kind model.ApplicationKind, f executor.CleanerFactory) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.cleanerFactories[kind]; ok {
+		return fmt.Errorf("cleaner for %s application kind has already been registered", kind.String())
+	}
+	r.cleanerFactories[kind] = f
+	return nil
+}
+
 func (r *registry) Executor(stage model.Stage, in executor.Input) (executor.Executor, bool) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -85,9 +102,21 @@ func (r *registry) RollbackExecutor(kind model.ApplicationKind, in executor.Inpu
 	return f(in), true
 }
 
+func (r *registry) Cleaner(kind model.ApplicationKind, in executor.Input) (executor.Cleaner, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	f, ok := r.cleanerFactories[kind]
+	if !ok {
+		return nil, false
+	}
+	return f(in), true
+}
+
 var defaultRegistry = &registry{
 	factories:         make(map[model.Stage]executor.Factory),
 	rollbackFactories: make(map[model.ApplicationKind]executor.Factory),
+	cleanerFactories:  make(map[model.ApplicationKind]executor.CleanerFactory),
 }
 
 func DefaultRegistry() Registry {
@@ -97,11 +126,15 @@ func DefaultRegistry() Registry {
 // init registers all built-in executors to the default registry.
 func init() {
 	analysis.Register(defaultRegistry)
+	cloudfunctions.Register(defaultRegistry)
 	cloudrun.Register(defaultRegistry)
 	kubernetes.Register(defaultRegistry)
 	lambda.Register(defaultRegistry)
+	nomad.Register(defaultRegistry)
 	terraform.Register(defaultRegistry)
 	ecs.Register(defaultRegistry)
+	promote.Register(defaultRegistry)
+	scriptrun.Register(defaultRegistry)
 	wait.Register(defaultRegistry)
 	waitapproval.Register(defaultRegistry)
 }
@@ -0,0 +1,185 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scriptrun
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/pipe-cd/pipe/pkg/app/piped/executor"
+	"github.com/pipe-cd/pipe/pkg/app/piped/sourcedecrypter"
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+// The environment variables of piped process given to the script.
+// The others such as the credentials of the cloud providers are never given.
+var inheritedEnvKeys = []string{"PATH", "HOME"}
+
+type Executor struct {
+	executor.Input
+}
+
+type registerer interface {
+	Register(stage model.Stage, f executor.Factory) error
+}
+
+// Register registers this executor factory into a given registerer.
+func Register(r registerer) {
+	f := func(in executor.Input) executor.Executor {
+		return &Executor{
+			Input: in,
+		}
+	}
+	r.Register(model.StageScriptRun, f)
+	r.Register(model.StageScriptRunRollback, f)
+}
+
+// Execute runs the specified script until its completion or the StopSignal has emitted.
+func (e *Executor) Execute(sig executor.StopSignal) model.StageStatus {
+	var (
+		ctx            = sig.Context()
+		originalStatus = e.Stage.Status
+		status         model.StageStatus
+	)
+
+	opts := e.StageConfig.ScriptRunStageOptions
+	if opts == nil {
+		e.LogPersister.Errorf("Malformed configuration for stage %s", e.Stage.Name)
+		return model.StageStatus_STAGE_FAILURE
+	}
+
+	switch model.Stage(e.Stage.Name) {
+	case model.StageScriptRun:
+		status = e.runScript(ctx, opts.Run, *opts)
+
+	case model.StageScriptRunRollback:
+		status = e.runScript(ctx, opts.OnRollback, *opts)
+
+	default:
+		e.LogPersister.Errorf("Unsupported stage %s", e.Stage.Name)
+		return model.StageStatus_STAGE_FAILURE
+	}
+
+	return executor.DetermineStageStatus(sig.Signal(), originalStatus, status)
+}
+
+func (e *Executor) runScript(ctx context.Context, script string, opts config.ScriptRunStageOptions) model.StageStatus {
+	if script == "" {
+		e.LogPersister.Info("There is no script to run")
+		return model.StageStatus_STAGE_SUCCESS
+	}
+
+	ds, err := e.TargetDSP.Get(ctx, e.LogPersister)
+	if err != nil {
+		e.LogPersister.Errorf("Failed to prepare target deploy source data (%v)", err)
+		return model.StageStatus_STAGE_FAILURE
+	}
+
+	env, err := e.buildEnv(opts.Env, ds.GenericDeploymentConfig.Encryption)
+	if err != nil {
+		e.LogPersister.Errorf("Failed to prepare the environment variables (%v)", err)
+		return model.StageStatus_STAGE_FAILURE
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout.Duration())
+	defer cancel()
+
+	cmd := exec.Command("/bin/sh", "-c", script)
+	cmd.Dir = ds.AppDir
+	cmd.Env = env
+	cmd.Stdout = e.LogPersister
+	cmd.Stderr = e.LogPersister
+	// Run the script in its own process group to kill all the processes started by it
+	// when the stage was stopped or timed out. Otherwise the remaining ones keep the output open
+	// and waiting for the command would not return.
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	e.LogPersister.Infof("Start running the script with timeout %v", opts.Timeout.Duration())
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		e.LogPersister.Errorf("Failed to start the script (%v)", err)
+		return model.StageStatus_STAGE_FAILURE
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			// The negative pid means all processes in the process group.
+			syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		case <-done:
+		}
+	}()
+
+	if err := cmd.Wait(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			e.LogPersister.Errorf("The script was not completed within %v", opts.Timeout.Duration())
+			return model.StageStatus_STAGE_FAILURE
+		}
+		e.LogPersister.Errorf("Failed while running the script (%v)", err)
+		return model.StageStatus_STAGE_FAILURE
+	}
+
+	e.LogPersister.Successf("Successfully ran the script in %v", time.Since(start).Round(time.Second))
+	return model.StageStatus_STAGE_SUCCESS
+}
+
+// buildEnv returns the environment variables for running the script.
+// In addition to PATH and HOME of piped process, some information about the deployment
+// are given with the SR_ prefix, and then the specified ones are added.
+func (e *Executor) buildEnv(values map[string]string, enc *config.SecretEncryption) ([]string, error) {
+	if len(values) > 0 && enc != nil && len(enc.EncryptedSecrets) > 0 {
+		if e.SecretDecrypter == nil {
+			return nil, fmt.Errorf("unable to use the encrypted secrets because the secret management is not configured in piped")
+		}
+		rendered, err := sourcedecrypter.RenderSecrets(values, *enc, e.SecretDecrypter)
+		if err != nil {
+			return nil, err
+		}
+		values = rendered
+	}
+
+	env := make([]string, 0, len(inheritedEnvKeys)+5+len(values))
+	for _, k := range inheritedEnvKeys {
+		if v, ok := os.LookupEnv(k); ok {
+			env = append(env, k+"="+v)
+		}
+	}
+	env = append(env,
+		"SR_DEPLOYMENT_ID="+e.Deployment.Id,
+		"SR_APPLICATION_ID="+e.Deployment.ApplicationId,
+		"SR_APPLICATION_NAME="+e.Deployment.ApplicationName,
+		"SR_TRIGGERED_COMMIT_HASH="+e.Deployment.Trigger.Commit.Hash,
+		"SR_RUNNING_COMMIT_HASH="+e.Deployment.RunningCommitHash,
+	)
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		env = append(env, k+"="+values[k])
+	}
+	return env, nil
+}
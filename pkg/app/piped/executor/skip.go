@@ -0,0 +1,53 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+// CheckSkipCommand checks whether a SKIP_STAGE command was sent to the running stage.
+// When one was found, the user who sent it will be saved into the stage metadata
+// and the command will be reported as handled.
+func (in *Input) CheckSkipCommand(ctx context.Context) bool {
+	for _, cmd := range in.CommandLister.ListCommands() {
+		if cmd.GetSkipStage() == nil {
+			continue
+		}
+
+		metadata := make(map[string]string)
+		if ori, ok := in.MetadataStore.GetStageMetadata(in.Stage.Id); ok {
+			for k, v := range ori {
+				metadata[k] = v
+			}
+		}
+		metadata[model.StageSkippedByMetadataKey] = cmd.Commander
+		if err := in.MetadataStore.SetStageMetadata(ctx, in.Stage.Id, metadata); err != nil {
+			in.LogPersister.Errorf("Unable to save the skipper information to deployment, %v", err)
+			return false
+		}
+
+		if err := cmd.Report(ctx, model.CommandStatus_COMMAND_SUCCEEDED, nil, nil); err != nil {
+			in.Logger.Error("failed to report handled command", zap.Error(err))
+		}
+		in.LogPersister.Infof("Skipped by %s", cmd.Commander)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+type fakeLogPersister struct{}
+
+func (l *fakeLogPersister) Write(_ []byte) (int, error)         { return 0, nil }
+func (l *fakeLogPersister) Info(_ string)                       {}
+func (l *fakeLogPersister) Infof(_ string, _ ...interface{})    {}
+func (l *fakeLogPersister) Success(_ string)                    {}
+func (l *fakeLogPersister) Successf(_ string, _ ...interface{}) {}
+func (l *fakeLogPersister) Error(_ string)                      {}
+func (l *fakeLogPersister) Errorf(_ string, _ ...interface{})   {}
+
+type fakeMetadataStore struct {
+	stageMetadata map[string]string
+}
+
+func (m *fakeMetadataStore) Get(_ string) (string, bool)              { return "", false }
+func (m *fakeMetadataStore) Set(_ context.Context, _, _ string) error { return nil }
+func (m *fakeMetadataStore) GetStageMetadata(_ string) (map[string]string, bool) {
+	return m.stageMetadata, m.stageMetadata != nil
+}
+func (m *fakeMetadataStore) SetStageMetadata(_ context.Context, _ string, metadata map[string]string) error {
+	m.stageMetadata = metadata
+	return nil
+}
+
+type fakeCommandLister struct {
+	commands []model.ReportableCommand
+}
+
+func (l *fakeCommandLister) ListCommands() []model.ReportableCommand {
+	return l.commands
+}
+
+func TestCheckSkipCommand(t *testing.T) {
+	makeCommand := func(commander string, reported map[string]model.CommandStatus, skip *model.Command_SkipStage, approve *model.Command_ApproveStage) model.ReportableCommand {
+		return model.ReportableCommand{
+			Command: &model.Command{
+				Commander:    commander,
+				SkipStage:    skip,
+				ApproveStage: approve,
+			},
+			Report: func(_ context.Context, status model.CommandStatus, _ map[string]string, _ []byte) error {
+				reported[commander] = status
+				return nil
+			},
+		}
+	}
+
+	testcases := []struct {
+		name             string
+		metadata         map[string]string
+		commands         func(reported map[string]model.CommandStatus) []model.ReportableCommand
+		expected         bool
+		expectedReported map[string]model.CommandStatus
+		expectedMetadata map[string]string
+	}{
+		{
+			name: "no command",
+			commands: func(_ map[string]model.CommandStatus) []model.ReportableCommand {
+				return nil
+			},
+			expectedReported: map[string]model.CommandStatus{},
+		},
+		{
+			name: "no skip command",
+			commands: func(reported map[string]model.CommandStatus) []model.ReportableCommand {
+				return []model.ReportableCommand{
+					makeCommand("alice", reported, nil, &model.Command_ApproveStage{}),
+				}
+			},
+			expectedReported: map[string]model.CommandStatus{},
+		},
+		{
+			name:     "skipped",
+			metadata: map[string]string{"startTime": "1"},
+			commands: func(reported map[string]model.CommandStatus) []model.ReportableCommand {
+				return []model.ReportableCommand{
+					makeCommand("alice", reported, nil, &model.Command_ApproveStage{}),
+					makeCommand("bob", reported, &model.Command_SkipStage{}, nil),
+				}
+			},
+			expected: true,
+			expectedReported: map[string]model.CommandStatus{
+				"bob": model.CommandStatus_COMMAND_SUCCEEDED,
+			},
+			expectedMetadata: map[string]string{
+				"startTime":                     "1",
+				model.StageSkippedByMetadataKey: "bob",
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			reported := make(map[string]model.CommandStatus)
+			ms := &fakeMetadataStore{stageMetadata: tc.metadata}
+			in := &Input{
+				Stage:         &model.PipelineStage{Id: "stage-id"},
+				CommandLister: &fakeCommandLister{commands: tc.commands(reported)},
+				LogPersister:  &fakeLogPersister{},
+				MetadataStore: ms,
+				Logger:        zap.NewNop(),
+			}
+
+			skipped := in.CheckSkipCommand(context.Background())
+			assert.Equal(t, tc.expected, skipped)
+			assert.Equal(t, tc.expectedReported, reported)
+			if tc.expectedMetadata != nil {
+				assert.Equal(t, tc.expectedMetadata, ms.stageMetadata)
+			}
+		})
+	}
+}
@@ -16,6 +16,12 @@ package terraform
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 
 	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/terraform"
 	"github.com/pipe-cd/pipe/pkg/app/piped/executor"
@@ -23,6 +29,17 @@ import (
 	"github.com/pipe-cd/pipe/pkg/model"
 )
 
+const (
+	// The name of the file to store the plan to be applied.
+	planFileName = "pipecd.tfplan"
+	// The name of the directory under the working directory of the deployment
+	// to keep the plan file created by TERRAFORM_PLAN stage.
+	planDirName = "terraform"
+	// The metadata key to store the SHA256 digest of the plan file created by TERRAFORM_PLAN stage.
+	// TERRAFORM_APPLY stage uses it to ensure that it applies exactly the planned file.
+	planDigestMetadataKey = "terraform-plan-sha256"
+)
+
 type deployExecutor struct {
 	executor.Input
 
@@ -109,7 +126,8 @@ func (e *deployExecutor) ensureSync(ctx context.Context) model.StageStatus {
 		return model.StageStatus_STAGE_FAILURE
 	}
 
-	planResult, err := cmd.Plan(ctx, e.LogPersister)
+	planFile := filepath.Join(e.appDir, planFileName)
+	planResult, err := cmd.SavePlan(ctx, e.LogPersister, planFile)
 	if err != nil {
 		e.LogPersister.Errorf("Failed to plan (%v)", err)
 		return model.StageStatus_STAGE_FAILURE
@@ -122,13 +140,7 @@ func (e *deployExecutor) ensureSync(ctx context.Context) model.StageStatus {
 
 	e.LogPersister.Infof("Detected %d add, %d change, %d destroy. Those changes will be applied automatically.", planResult.Adds, planResult.Changes, planResult.Destroys)
 
-	if err := cmd.Apply(ctx, e.LogPersister); err != nil {
-		e.LogPersister.Errorf("Failed to apply changes (%v)", err)
-		return model.StageStatus_STAGE_FAILURE
-	}
-
-	e.LogPersister.Success("Successfully applied changes")
-	return model.StageStatus_STAGE_SUCCESS
+	return e.applySavedPlan(ctx, cmd, planFile)
 }
 
 func (e *deployExecutor) ensurePlan(ctx context.Context) model.StageStatus {
@@ -152,18 +164,46 @@ func (e *deployExecutor) ensurePlan(ctx context.Context) model.StageStatus {
 		return model.StageStatus_STAGE_FAILURE
 	}
 
-	planResult, err := cmd.Plan(ctx, e.LogPersister)
+	planFile := filepath.Join(e.appDir, planFileName)
+	planResult, err := cmd.SavePlan(ctx, e.LogPersister, planFile)
 	if err != nil {
 		e.LogPersister.Errorf("Failed to plan (%v)", err)
 		return model.StageStatus_STAGE_FAILURE
 	}
 
+	if !planResult.NoChanges() {
+		e.LogPersister.Infof("Detected %d add, %d change, %d destroy.", planResult.Adds, planResult.Changes, planResult.Destroys)
+
+		plan, err := cmd.ShowPlan(ctx, planFile)
+		if err != nil {
+			e.LogPersister.Errorf("Failed to read the planned changes (%v)", err)
+			return model.StageStatus_STAGE_FAILURE
+		}
+		if opts := e.StageConfig.TerraformPlanStageOptions; opts != nil {
+			if ok := e.checkPolicies(plan, opts.Policies); !ok {
+				return model.StageStatus_STAGE_FAILURE
+			}
+		}
+	}
+
+	// Keep the plan file in the working directory of the deployment
+	// since TERRAFORM_APPLY stage works on its own copy of the deploy source.
+	digest, err := keepPlanFile(planFile, e.keptPlanFile())
+	if err != nil {
+		e.LogPersister.Errorf("Failed to keep the plan file (%v)", err)
+		return model.StageStatus_STAGE_FAILURE
+	}
+	if err := e.MetadataStore.Set(ctx, planDigestMetadataKey, digest); err != nil {
+		e.LogPersister.Errorf("Failed to save the digest of the plan file to metadata store (%v)", err)
+		return model.StageStatus_STAGE_FAILURE
+	}
+
 	if planResult.NoChanges() {
 		e.LogPersister.Success("No changes to apply")
 		return model.StageStatus_STAGE_SUCCESS
 	}
 
-	e.LogPersister.Successf("Detected %d add, %d change, %d destroy.", planResult.Adds, planResult.Changes, planResult.Destroys)
+	e.LogPersister.Success("Successfully planned changes")
 	return model.StageStatus_STAGE_SUCCESS
 }
 
@@ -188,7 +228,36 @@ func (e *deployExecutor) ensureApply(ctx context.Context) model.StageStatus {
 		return model.StageStatus_STAGE_FAILURE
 	}
 
-	if err := cmd.Apply(ctx, e.LogPersister); err != nil {
+	digest, ok := e.MetadataStore.Get(planDigestMetadataKey)
+	if !ok {
+		// There was no TERRAFORM_PLAN stage before so plan and apply the changes right now.
+		planFile := filepath.Join(e.appDir, planFileName)
+		planResult, err := cmd.SavePlan(ctx, e.LogPersister, planFile)
+		if err != nil {
+			e.LogPersister.Errorf("Failed to plan (%v)", err)
+			return model.StageStatus_STAGE_FAILURE
+		}
+		if planResult.NoChanges() {
+			e.LogPersister.Success("No changes to apply")
+			return model.StageStatus_STAGE_SUCCESS
+		}
+		return e.applySavedPlan(ctx, cmd, planFile)
+	}
+
+	planFile := e.keptPlanFile()
+	if err := verifyPlanFile(planFile, digest); err != nil {
+		e.LogPersister.Errorf("Unable to use the plan file created by TERRAFORM_PLAN stage (%v)", err)
+		e.LogPersister.Error("It might have been lost because piped was restarted. Please trigger a new deployment to plan again")
+		return model.StageStatus_STAGE_FAILURE
+	}
+	e.LogPersister.Info("Applying the plan file created by TERRAFORM_PLAN stage. Terraform will reject it if the state was changed after planning")
+
+	return e.applySavedPlan(ctx, cmd, planFile)
+}
+
+// applySavedPlan applies the changes stored in the given plan file.
+func (e *deployExecutor) applySavedPlan(ctx context.Context, cmd *provider.Terraform, planFile string) model.StageStatus {
+	if err := cmd.ApplyPlan(ctx, e.LogPersister, planFile); err != nil {
 		e.LogPersister.Errorf("Failed to apply changes (%v)", err)
 		return model.StageStatus_STAGE_FAILURE
 	}
@@ -196,3 +265,64 @@ func (e *deployExecutor) ensureApply(ctx context.Context) model.StageStatus {
 	e.LogPersister.Success("Successfully applied changes")
 	return model.StageStatus_STAGE_SUCCESS
 }
+
+func (e *deployExecutor) checkPolicies(plan *provider.Plan, policies []config.TerraformPlanPolicy) bool {
+	if len(policies) == 0 {
+		return true
+	}
+	e.LogPersister.Infof("Checking the planned changes against %d policies", len(policies))
+
+	violations, err := checkPolicies(policies, e.appDir, plan.JSON)
+	if err != nil {
+		e.LogPersister.Errorf("Failed to check the policies (%v)", err)
+		return false
+	}
+	if len(violations) == 0 {
+		e.LogPersister.Info("All policies were satisfied")
+		return true
+	}
+
+	for _, v := range violations {
+		e.LogPersister.Errorf("Policy %q was violated", v)
+	}
+	e.LogPersister.Errorf("The planned changes violated %d policies", len(violations))
+	return false
+}
+
+// keptPlanFile returns the path where the plan file created by TERRAFORM_PLAN stage is kept.
+func (e *deployExecutor) keptPlanFile() string {
+	return filepath.Join(e.WorkingDir, planDirName, planFileName)
+}
+
+// keepPlanFile copies the given plan file to the destination
+// and returns the SHA256 digest of its content.
+func keepPlanFile(src, dst string) (string, error) {
+	data, err := ioutil.ReadFile(src)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0700); err != nil {
+		return "", err
+	}
+	if err := ioutil.WriteFile(dst, data, 0600); err != nil {
+		return "", err
+	}
+	return planDigest(data), nil
+}
+
+// verifyPlanFile checks whether the content of the given plan file has the given digest.
+func verifyPlanFile(planFile, digest string) error {
+	data, err := ioutil.ReadFile(planFile)
+	if err != nil {
+		return err
+	}
+	if got := planDigest(data); got != digest {
+		return fmt.Errorf("the digest of the plan file was %s, expected %s", got, digest)
+	}
+	return nil
+}
+
+func planDigest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,48 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package terraform
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeepPlanFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "terraform-plan")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, "app", planFileName)
+	require.NoError(t, os.MkdirAll(filepath.Dir(src), 0700))
+	require.NoError(t, ioutil.WriteFile(src, []byte("plan"), 0600))
+
+	dst := filepath.Join(dir, "working", planDirName, planFileName)
+	digest, err := keepPlanFile(src, dst)
+	require.NoError(t, err)
+	assert.Equal(t, "64879f7d6b960a01909762d911a32d4582c20010c5641ee90278b644a9e3b525", digest)
+	assert.NoError(t, verifyPlanFile(dst, digest))
+
+	// Changed after planning.
+	require.NoError(t, ioutil.WriteFile(dst, []byte("another plan"), 0600))
+	assert.Error(t, verifyPlanFile(dst, digest))
+
+	// Lost after planning.
+	require.NoError(t, os.Remove(dst))
+	assert.Error(t, verifyPlanFile(dst, digest))
+}
@@ -0,0 +1,106 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+
+	"github.com/pipe-cd/pipe/pkg/config"
+)
+
+// The name of the variable to refer the planned changes in policy expressions.
+const planVariableName = "plan"
+
+// checkPolicies evaluates the given policies against the plan in the JSON format
+// and returns the names of the violated policies.
+// The policy files are loaded from the given application directory.
+func checkPolicies(policies []config.TerraformPlanPolicy, appDir string, planJSON []byte) ([]string, error) {
+	var plan map[string]interface{}
+	if err := json.Unmarshal(planJSON, &plan); err != nil {
+		return nil, fmt.Errorf("unable to parse plan json (%w)", err)
+	}
+	// Terraform omits these fields when there is no change,
+	// so make sure they always exist to be able to be referred in the expressions.
+	for _, k := range []string{"resource_changes", "resource_drift"} {
+		if _, ok := plan[k]; !ok {
+			plan[k] = []interface{}{}
+		}
+	}
+
+	env, err := cel.NewEnv(
+		cel.Declarations(
+			decls.NewVar(planVariableName, decls.NewMapType(decls.String, decls.Dyn)),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create policy environment (%w)", err)
+	}
+
+	var violations []string
+	for _, p := range policies {
+		expr, err := loadPolicyExpression(p, appDir)
+		if err != nil {
+			return nil, err
+		}
+		ok, err := evaluatePolicy(env, expr, plan)
+		if err != nil {
+			return nil, fmt.Errorf("unable to evaluate policy %q (%w)", p.Name, err)
+		}
+		if !ok {
+			violations = append(violations, p.Name)
+		}
+	}
+	return violations, nil
+}
+
+func loadPolicyExpression(p config.TerraformPlanPolicy, appDir string) (string, error) {
+	if p.Condition != "" {
+		return p.Condition, nil
+	}
+	data, err := os.ReadFile(filepath.Join(appDir, p.File))
+	if err != nil {
+		return "", fmt.Errorf("unable to read file %s of policy %q (%w)", p.File, p.Name, err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func evaluatePolicy(env *cel.Env, expr string, plan map[string]interface{}) (bool, error) {
+	ast, iss := env.Compile(expr)
+	if iss.Err() != nil {
+		return false, iss.Err()
+	}
+	prg, err := env.Program(ast)
+	if err != nil {
+		return false, err
+	}
+	out, _, err := prg.Eval(map[string]interface{}{
+		planVariableName: plan,
+	})
+	if err != nil {
+		return false, err
+	}
+	ok, isBool := out.Value().(bool)
+	if !isBool {
+		return false, fmt.Errorf("the expression must be evaluated to a bool value but got %v", out.Value())
+	}
+	return ok, nil
+}
@@ -0,0 +1,143 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terraform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pipe-cd/pipe/pkg/config"
+)
+
+func TestCheckPolicies(t *testing.T) {
+	plan := []byte(`{
+  "format_version": "0.1",
+  "resource_changes": [
+    {"address": "aws_instance.web", "type": "aws_instance", "change": {"actions": ["create"]}},
+    {"address": "aws_db_instance.main", "type": "aws_db_instance", "change": {"actions": ["delete", "create"]}},
+    {"address": "aws_s3_bucket.logs", "type": "aws_s3_bucket", "change": {"actions": ["delete"]}},
+    {"address": "aws_iam_role.app", "type": "aws_iam_role", "change": {"actions": ["no-op"]}}
+  ]
+}`)
+
+	testcases := []struct {
+		name        string
+		policies    []config.TerraformPlanPolicy
+		expected    []string
+		expectedErr bool
+	}{
+		{
+			name: "no policy",
+		},
+		{
+			name: "no destroy of db instances",
+			policies: []config.TerraformPlanPolicy{
+				{
+					Name:      "no-db-destroy",
+					Condition: `!plan.resource_changes.exists(c, c.type.startsWith("aws_db_") && "delete" in c.change.actions)`,
+				},
+			},
+			expected: []string{"no-db-destroy"},
+		},
+		{
+			name: "policy loaded from file",
+			policies: []config.TerraformPlanPolicy{
+				{
+					Name: "no-db-destroy",
+					File: "testdata/policies/no-db-destroy.cel",
+				},
+			},
+			expected: []string{"no-db-destroy"},
+		},
+		{
+			name: "max destroys satisfied",
+			policies: []config.TerraformPlanPolicy{
+				{
+					Name:      "max-destroys",
+					Condition: `plan.resource_changes.filter(c, "delete" in c.change.actions).size() <= 2`,
+				},
+			},
+		},
+		{
+			name: "max destroys violated",
+			policies: []config.TerraformPlanPolicy{
+				{
+					Name:      "max-destroys",
+					Condition: `plan.resource_changes.filter(c, "delete" in c.change.actions).size() <= 1`,
+				},
+				{
+					Name:      "no-iam-changes",
+					Condition: `plan.resource_changes.all(c, c.type != "aws_iam_role" || c.change.actions == ["no-op"])`,
+				},
+			},
+			expected: []string{"max-destroys"},
+		},
+		{
+			name: "invalid expression",
+			policies: []config.TerraformPlanPolicy{
+				{
+					Name:      "invalid",
+					Condition: `plan.resource_changes.size() +`,
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "non-bool expression",
+			policies: []config.TerraformPlanPolicy{
+				{
+					Name:      "non-bool",
+					Condition: `plan.resource_changes.size()`,
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "missing policy file",
+			policies: []config.TerraformPlanPolicy{
+				{
+					Name: "missing",
+					File: "testdata/policies/missing.cel",
+				},
+			},
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			violations, err := checkPolicies(tc.policies, ".", plan)
+			if tc.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, violations)
+		})
+	}
+}
+
+func TestCheckPoliciesWithoutChanges(t *testing.T) {
+	policies := []config.TerraformPlanPolicy{
+		{
+			Name:      "max-destroys",
+			Condition: `plan.resource_changes.filter(c, "delete" in c.change.actions).size() <= 1`,
+		},
+	}
+	violations, err := checkPolicies(policies, ".", []byte(`{"format_version": "0.1"}`))
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+}
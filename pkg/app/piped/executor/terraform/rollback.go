@@ -15,13 +15,34 @@
 package terraform
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
 
 	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/terraform"
 	"github.com/pipe-cd/pipe/pkg/app/piped/executor"
+	"github.com/pipe-cd/pipe/pkg/config"
 	"github.com/pipe-cd/pipe/pkg/model"
 )
 
+const (
+	// The name of the file to store the plan to roll back the changes.
+	rollbackPlanFileName = "pipecd-rollback.tfplan"
+	// The name of the file to store the plan to verify the rolled back state.
+	verifyPlanFileName = "pipecd-rollback-verify.tfplan"
+	// How long to wait for the state lock held by the other operations.
+	rollbackLockTimeout = 5 * time.Minute
+
+	// The stage metadata keys to show what was done by the rollback.
+	rollbackCommitMetadataKey     = "rollback-commit"
+	revertedResourcesMetadataKey  = "reverted-resources"
+	remainingResourcesMetadataKey = "remaining-resources"
+)
+
 type rollbackExecutor struct {
 	executor.Input
 }
@@ -45,6 +66,9 @@ func (e *rollbackExecutor) Execute(sig executor.StopSignal) model.StageStatus {
 	return executor.DetermineStageStatus(sig.Signal(), originalStatus, status)
 }
 
+// ensureRollback applies the configuration at the commit of the most recently successful deployment
+// with the same workspace and variables, then verifies that no changes remain.
+// Since it plans against the refreshed state, the resources changed by a partially applied deployment are reverted as well.
 func (e *rollbackExecutor) ensureRollback(ctx context.Context) model.StageStatus {
 	// There is nothing to do if this is the first deployment.
 	if e.Deployment.RunningCommitHash == "" {
@@ -78,12 +102,16 @@ func (e *rollbackExecutor) ensureRollback(ctx context.Context) model.StageStatus
 	vars = append(vars, cloudProviderCfg.Vars...)
 	vars = append(vars, deployCfg.Input.Vars...)
 
-	e.LogPersister.Infof("Start rolling back to the state defined at commit %s", e.Deployment.RunningCommitHash)
+	e.LogPersister.Infof("Start rolling back to the state defined at commit %s of the most recently successful deployment", e.Deployment.RunningCommitHash)
+	metadata := map[string]string{
+		rollbackCommitMetadataKey: e.Deployment.RunningCommitHash,
+	}
 	cmd := provider.NewTerraform(
 		terraformPath,
 		ds.AppDir,
 		provider.WithVars(vars),
 		provider.WithVarFiles(deployCfg.Input.VarFiles),
+		provider.WithLockTimeout(rollbackLockTimeout),
 	)
 
 	if ok := showUsingVersion(ctx, cmd, e.LogPersister); !ok {
@@ -99,11 +127,96 @@ func (e *rollbackExecutor) ensureRollback(ctx context.Context) model.StageStatus
 		return model.StageStatus_STAGE_FAILURE
 	}
 
-	if err := cmd.Apply(ctx, e.LogPersister); err != nil {
-		e.LogPersister.Errorf("Failed to apply changes (%v)", err)
+	planFile := filepath.Join(ds.AppDir, rollbackPlanFileName)
+	planResult, err := cmd.SavePlan(ctx, e.LogPersister, planFile)
+	if err != nil {
+		e.LogPersister.Errorf("Failed to plan the rollback (%v)", err)
+		return model.StageStatus_STAGE_FAILURE
+	}
+	if planResult.NoChanges() {
+		e.saveStageMetadata(ctx, metadata)
+		e.LogPersister.Success("No changes to roll back")
+		return model.StageStatus_STAGE_SUCCESS
+	}
+	e.LogPersister.Infof("Detected %d add, %d change, %d destroy to roll back", planResult.Adds, planResult.Changes, planResult.Destroys)
+
+	plan, err := cmd.ShowPlan(ctx, planFile)
+	if err != nil {
+		e.LogPersister.Errorf("Failed to read the rollback plan (%v)", err)
+		return model.StageStatus_STAGE_FAILURE
+	}
+	reverted := describeResourceChanges(plan.ResourceChanges)
+	metadata[revertedResourcesMetadataKey] = strings.Join(reverted, ", ")
+	e.saveStageMetadata(ctx, metadata)
+
+	var out bytes.Buffer
+	if err := cmd.ApplyPlan(ctx, io.MultiWriter(e.LogPersister, &out), planFile); err != nil {
+		e.LogPersister.Errorf("Failed to apply the rollback plan (%v)", err)
+		if lockID, locked := provider.FindStateLockError(out.String()); locked {
+			e.LogPersister.Errorf("The state was locked by another operation for more than %v. "+
+				"If no other operation is running, release the lock by %q and retry the deployment", rollbackLockTimeout, "terraform force-unlock "+lockID)
+		}
+		// Find out which resources were left unreverted since the apply might have been partially done.
+		e.reportRemainingChanges(ctx, cmd, ds.AppDir, metadata)
+		return model.StageStatus_STAGE_FAILURE
+	}
+	e.LogPersister.Infof("Successfully applied the rollback plan, reverted %d resources: %s", len(reverted), strings.Join(reverted, ", "))
+
+	// Make sure that the resources have been recovered to the state defined at the rolled back commit.
+	e.LogPersister.Info("Verifying the rolled back state")
+	if remaining := e.reportRemainingChanges(ctx, cmd, ds.AppDir, metadata); remaining != 0 {
+		if remaining > 0 {
+			e.LogPersister.Errorf("The rolled back state still has %d changes to the configuration at commit %s", remaining, e.Deployment.RunningCommitHash)
+		}
 		return model.StageStatus_STAGE_FAILURE
 	}
 
 	e.LogPersister.Success("Successfully rolled back the changes")
 	return model.StageStatus_STAGE_SUCCESS
 }
+
+// reportRemainingChanges plans again to find the changes those have not been rolled back yet
+// and saves them to the stage metadata. The number of the remaining changes is returned,
+// or -1 when it could not be determined.
+func (e *rollbackExecutor) reportRemainingChanges(ctx context.Context, cmd *provider.Terraform, appDir string, metadata map[string]string) int {
+	planFile := filepath.Join(appDir, verifyPlanFileName)
+	result, err := cmd.SavePlan(ctx, e.LogPersister, planFile)
+	if err != nil {
+		e.LogPersister.Errorf("Failed to plan to find the changes those have not been rolled back (%v)", err)
+		return -1
+	}
+	if result.NoChanges() {
+		return 0
+	}
+
+	plan, err := cmd.ShowPlan(ctx, planFile)
+	if err != nil {
+		e.LogPersister.Errorf("Failed to read the changes those have not been rolled back (%v)", err)
+		return -1
+	}
+	remaining := describeResourceChanges(plan.ResourceChanges)
+	e.LogPersister.Errorf("The following resources have not been rolled back: %s", strings.Join(remaining, ", "))
+
+	metadata[remainingResourcesMetadataKey] = strings.Join(remaining, ", ")
+	e.saveStageMetadata(ctx, metadata)
+	return len(remaining)
+}
+
+func (e *rollbackExecutor) saveStageMetadata(ctx context.Context, metadata map[string]string) {
+	if err := e.MetadataStore.SetStageMetadata(ctx, e.Stage.Id, metadata); err != nil {
+		e.LogPersister.Errorf("Unable to save the stage metadata (%v)", err)
+	}
+}
+
+// describeResourceChanges returns the addresses of the changed resources along with their actions,
+// e.g. "aws_instance.web[0] (delete, create)". The resources without any change are excluded.
+func describeResourceChanges(changes []provider.ResourceChange) []string {
+	out := make([]string, 0, len(changes))
+	for _, c := range changes {
+		if !c.HasAction(config.TerraformActionCreate) && !c.HasAction(config.TerraformActionUpdate) && !c.HasAction(config.TerraformActionDelete) {
+			continue
+		}
+		out = append(out, fmt.Sprintf("%s (%s)", c.Address, strings.Join(c.Actions, ", ")))
+	}
+	return out
+}
@@ -0,0 +1,40 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terraform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/terraform"
+)
+
+func TestDescribeResourceChanges(t *testing.T) {
+	changes := []provider.ResourceChange{
+		{Address: "aws_instance.web[0]", Type: "aws_instance", Actions: []string{"update"}},
+		{Address: "aws_db_instance.main", Type: "aws_db_instance", Actions: []string{"delete", "create"}},
+		{Address: "aws_iam_role.app", Type: "aws_iam_role", Actions: []string{"no-op"}},
+		{Address: "data.aws_ami.ubuntu", Type: "aws_ami", Actions: []string{"read"}},
+		{Address: "aws_s3_bucket.logs", Type: "aws_s3_bucket", Actions: []string{"create"}},
+	}
+	expected := []string{
+		"aws_instance.web[0] (update)",
+		"aws_db_instance.main (delete, create)",
+		"aws_s3_bucket.logs (create)",
+	}
+	assert.Equal(t, expected, describeResourceChanges(changes))
+	assert.Empty(t, describeResourceChanges(nil))
+}
@@ -26,9 +26,10 @@ import (
 )
 
 const (
-	defaultDuration = time.Minute
-	logInterval     = 10 * time.Second
-	startTimeKey    = "startTime"
+	defaultDuration   = time.Minute
+	logInterval       = 10 * time.Second
+	skipCheckInterval = 5 * time.Second
+	startTimeKey      = "startTime"
 )
 
 type Executor struct {
@@ -64,6 +65,11 @@ func (e *Executor) Execute(sig executor.StopSignal) model.StageStatus {
 	}
 	totalDuration := duration
 
+	// The stage was skipped before starting, e.g. while fast-forwarding.
+	if e.CheckSkipCommand(sig.Context()) {
+		return model.StageStatus_STAGE_SKIPPED
+	}
+
 	// Retrieve the saved startTime from the previous run.
 	startTime := e.retrieveStartTime()
 	if !startTime.IsZero() {
@@ -82,6 +88,9 @@ func (e *Executor) Execute(sig executor.StopSignal) model.StageStatus {
 	ticker := time.NewTicker(logInterval)
 	defer ticker.Stop()
 
+	skipTicker := time.NewTicker(skipCheckInterval)
+	defer skipTicker.Stop()
+
 	e.LogPersister.Infof("Waiting for %v...", duration)
 	for {
 		select {
@@ -92,6 +101,11 @@ func (e *Executor) Execute(sig executor.StopSignal) model.StageStatus {
 		case <-ticker.C:
 			e.LogPersister.Infof("%v elapsed...", time.Since(startTime))
 
+		case <-skipTicker.C:
+			if e.CheckSkipCommand(sig.Context()) {
+				return model.StageStatus_STAGE_SKIPPED
+			}
+
 		case s := <-sig.Ch():
 			switch s {
 			case executor.StopSignalCancel:
@@ -122,9 +136,13 @@ func (e *Executor) retrieveStartTime() (t time.Time) {
 }
 
 func (e *Executor) saveStartTime(ctx context.Context, t time.Time) {
-	metadata := map[string]string{
-		startTimeKey: strconv.FormatInt(t.Unix(), 10),
+	metadata := make(map[string]string)
+	if ori, ok := e.MetadataStore.GetStageMetadata(e.Stage.Id); ok {
+		for k, v := range ori {
+			metadata[k] = v
+		}
 	}
+	metadata[startTimeKey] = strconv.FormatInt(t.Unix(), 10)
 	if err := e.MetadataStore.SetStageMetadata(ctx, e.Stage.Id, metadata); err != nil {
 		e.Logger.Error("failed to store metadata", zap.Error(err))
 	}
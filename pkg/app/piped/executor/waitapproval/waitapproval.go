@@ -16,6 +16,7 @@ package waitapproval
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -26,10 +27,16 @@ import (
 
 const (
 	approvedByKey = "ApprovedBy"
+	rejectedByKey = "RejectedBy"
+	// The key of stage metadata for the comment of an approval or rejection
+	// is a concatenation of this prefix and the commander name.
+	commentKeyPrefix = "Comment/"
 )
 
 type Executor struct {
 	executor.Input
+
+	approvers []string
 }
 
 type registerer interface {
@@ -46,24 +53,30 @@ func Register(r registerer) {
 	r.Register(model.StageWaitApproval, f)
 }
 
-// Execute starts waiting until an approval from one of the specified users.
+// Execute starts waiting until enough approvals from the specified users
+// or a rejection from one of them.
 func (e *Executor) Execute(sig executor.StopSignal) model.StageStatus {
 	var (
 		originalStatus = e.Stage.Status
 		ctx            = sig.Context()
 		ticker         = time.NewTicker(5 * time.Second)
+		opts           = e.StageConfig.WaitApprovalStageOptions
 	)
 	defer ticker.Stop()
-	timeout := e.StageConfig.WaitApprovalStageOptions.Timeout.Duration()
+	timeout := opts.Timeout.Duration()
 	timer := time.NewTimer(timeout)
 
-	e.LogPersister.Info("Waiting for an approval...")
+	// Restore the approvals handled before restarting.
+	if md, ok := e.MetadataStore.GetStageMetadata(e.Stage.Id); ok && md[approvedByKey] != "" {
+		e.approvers = strings.Split(md[approvedByKey], ",")
+	}
+
+	e.LogPersister.Infof("Waiting for %d approval(s)...", opts.MinApproverNum)
 	for {
 		select {
 		case <-ticker.C:
-			if commander, ok := e.checkApproval(ctx); ok {
-				e.LogPersister.Infof("Got an approval from %s", commander)
-				return model.StageStatus_STAGE_SUCCESS
+			if status, ok := e.checkCommands(ctx); ok {
+				return status
 			}
 
 		case s := <-sig.Ch():
@@ -82,35 +95,132 @@ func (e *Executor) Execute(sig executor.StopSignal) model.StageStatus {
 	}
 }
 
-func (e *Executor) checkApproval(ctx context.Context) (string, bool) {
-	var approveCmd *model.ReportableCommand
-	commands := e.CommandLister.ListCommands()
+// checkCommands handles all approve and reject commands sent to this stage.
+// The returned boolean is true when the stage got its final status.
+func (e *Executor) checkCommands(ctx context.Context) (model.StageStatus, bool) {
+	minApproverNum := e.StageConfig.WaitApprovalStageOptions.MinApproverNum
+
+	for _, cmd := range e.CommandLister.ListCommands() {
+		var (
+			approve = cmd.GetApproveStage()
+			reject  = cmd.GetRejectStage()
+		)
+		if approve == nil && reject == nil {
+			continue
+		}
+
+		if !e.isAllowed(cmd) {
+			e.LogPersister.Errorf("%s is not allowed to approve or reject this stage", cmd.Commander)
+			e.reportCommand(ctx, cmd, model.CommandStatus_COMMAND_FAILED)
+			continue
+		}
+
+		if reject != nil {
+			if err := e.saveMetadata(ctx, rejectedByKey, cmd.Commander, cmd.Commander, reject.Comment); err != nil {
+				e.LogPersister.Errorf("Unabled to save rejecter information to deployment, %v", err)
+				return model.StageStatus_STAGE_RUNNING, false
+			}
+			e.reportCommand(ctx, cmd, model.CommandStatus_COMMAND_SUCCEEDED)
+			if reject.Comment != "" {
+				e.LogPersister.Errorf("Got a rejection from %s: %s", cmd.Commander, reject.Comment)
+			} else {
+				e.LogPersister.Errorf("Got a rejection from %s", cmd.Commander)
+			}
+			return model.StageStatus_STAGE_FAILURE, true
+		}
+
+		if contains(e.approvers, cmd.Commander) {
+			e.LogPersister.Infof("%s has already approved this stage", cmd.Commander)
+			e.reportCommand(ctx, cmd, model.CommandStatus_COMMAND_SUCCEEDED)
+			continue
+		}
+
+		approvers := append(e.approvers, cmd.Commander)
+		if err := e.saveMetadata(ctx, approvedByKey, strings.Join(approvers, ","), cmd.Commander, approve.Comment); err != nil {
+			e.LogPersister.Errorf("Unabled to save approver information to deployment, %v", err)
+			return model.StageStatus_STAGE_RUNNING, false
+		}
+		e.approvers = approvers
+		e.reportCommand(ctx, cmd, model.CommandStatus_COMMAND_SUCCEEDED)
+		e.notifyApproval(cmd.Commander)
 
-	for i, cmd := range commands {
-		if cmd.GetApproveStage() != nil {
-			approveCmd = &commands[i]
-			break
+		if approve.Comment != "" {
+			e.LogPersister.Infof("Got an approval from %s (%d/%d): %s", cmd.Commander, len(approvers), minApproverNum, approve.Comment)
+		} else {
+			e.LogPersister.Infof("Got an approval from %s (%d/%d)", cmd.Commander, len(approvers), minApproverNum)
+		}
+		if len(approvers) >= minApproverNum {
+			return model.StageStatus_STAGE_SUCCESS, true
 		}
 	}
-	if approveCmd == nil {
-		return "", false
+	return model.StageStatus_STAGE_RUNNING, false
+}
+
+// isAllowed checks whether the commander of the given command is one of the configured approvers.
+// An approver in "org/team" format matches all members of that team.
+func (e *Executor) isAllowed(cmd model.ReportableCommand) bool {
+	approvers := e.StageConfig.WaitApprovalStageOptions.Approvers
+	if len(approvers) == 0 {
+		return true
 	}
 
-	metadata := map[string]string{
-		approvedByKey: approveCmd.Commander,
+	var teams []string
+	if v := cmd.Metadata[model.CommanderTeamsMetadataKey]; v != "" {
+		teams = strings.Split(v, ",")
+	}
+	for _, a := range approvers {
+		if strings.Contains(a, "/") {
+			if contains(teams, a) {
+				return true
+			}
+			continue
+		}
+		if a == cmd.Commander {
+			return true
+		}
 	}
+	return false
+}
+
+func (e *Executor) saveMetadata(ctx context.Context, key, value, commander, comment string) error {
+	metadata := make(map[string]string)
 	if ori, ok := e.MetadataStore.GetStageMetadata(e.Stage.Id); ok {
 		for k, v := range ori {
 			metadata[k] = v
 		}
 	}
-	if err := e.MetadataStore.SetStageMetadata(ctx, e.Stage.Id, metadata); err != nil {
-		e.LogPersister.Errorf("Unabled to save approver information to deployment, %v", err)
-		return "", false
+	metadata[key] = value
+	if comment != "" {
+		metadata[commentKeyPrefix+commander] = comment
 	}
+	return e.MetadataStore.SetStageMetadata(ctx, e.Stage.Id, metadata)
+}
 
-	if err := approveCmd.Report(ctx, model.CommandStatus_COMMAND_SUCCEEDED, nil, nil); err != nil {
+func (e *Executor) reportCommand(ctx context.Context, cmd model.ReportableCommand, status model.CommandStatus) {
+	if err := cmd.Report(ctx, status, nil, nil); err != nil {
 		e.Logger.Error("failed to report handled command", zap.Error(err))
 	}
-	return approveCmd.Commander, true
+}
+
+func (e *Executor) notifyApproval(approver string) {
+	if e.Notifier == nil {
+		return
+	}
+	e.Notifier.Notify(model.NotificationEvent{
+		Type: model.NotificationEventType_EVENT_DEPLOYMENT_APPROVED,
+		Metadata: &model.NotificationEventDeploymentApproved{
+			Deployment: e.Deployment,
+			EnvName:    e.EnvName,
+			Approver:   approver,
+		},
+	})
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,241 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package waitapproval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/pipe-cd/pipe/pkg/app/piped/executor"
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+type fakeLogPersister struct{}
+
+func (l *fakeLogPersister) Write(_ []byte) (int, error)         { return 0, nil }
+func (l *fakeLogPersister) Info(_ string)                       {}
+func (l *fakeLogPersister) Infof(_ string, _ ...interface{})    {}
+func (l *fakeLogPersister) Success(_ string)                    {}
+func (l *fakeLogPersister) Successf(_ string, _ ...interface{}) {}
+func (l *fakeLogPersister) Error(_ string)                      {}
+func (l *fakeLogPersister) Errorf(_ string, _ ...interface{})   {}
+
+type fakeMetadataStore struct {
+	stageMetadata map[string]string
+}
+
+func (m *fakeMetadataStore) Get(_ string) (string, bool)              { return "", false }
+func (m *fakeMetadataStore) Set(_ context.Context, _, _ string) error { return nil }
+func (m *fakeMetadataStore) GetStageMetadata(_ string) (map[string]string, bool) {
+	return m.stageMetadata, m.stageMetadata != nil
+}
+func (m *fakeMetadataStore) SetStageMetadata(_ context.Context, _ string, metadata map[string]string) error {
+	m.stageMetadata = metadata
+	return nil
+}
+
+type fakeCommandLister struct {
+	commands []model.ReportableCommand
+}
+
+func (l *fakeCommandLister) ListCommands() []model.ReportableCommand {
+	return l.commands
+}
+
+type fakeNotifier struct {
+	events []model.NotificationEvent
+}
+
+func (n *fakeNotifier) Notify(event model.NotificationEvent) {
+	n.events = append(n.events, event)
+}
+
+func makeCommand(commander, teams string, reported map[string]model.CommandStatus, approve *model.Command_ApproveStage, reject *model.Command_RejectStage) model.ReportableCommand {
+	cmd := &model.Command{
+		Id:           commander,
+		Commander:    commander,
+		ApproveStage: approve,
+		RejectStage:  reject,
+	}
+	if teams != "" {
+		cmd.Metadata = map[string]string{model.CommanderTeamsMetadataKey: teams}
+	}
+	return model.ReportableCommand{
+		Command: cmd,
+		Report: func(_ context.Context, status model.CommandStatus, _ map[string]string, _ []byte) error {
+			reported[commander] = status
+			return nil
+		},
+	}
+}
+
+func TestCheckCommands(t *testing.T) {
+	approve := func(comment string) *model.Command_ApproveStage {
+		return &model.Command_ApproveStage{Comment: comment}
+	}
+	reject := func(comment string) *model.Command_RejectStage {
+		return &model.Command_RejectStage{Comment: comment}
+	}
+
+	testcases := []struct {
+		name             string
+		approvers        []string
+		minApproverNum   int
+		commands         func(reported map[string]model.CommandStatus) []model.ReportableCommand
+		expectedStatus   model.StageStatus
+		expectedDone     bool
+		expectedReported map[string]model.CommandStatus
+		expectedMetadata map[string]string
+		expectedEvents   int
+	}{
+		{
+			name:           "no command",
+			minApproverNum: 1,
+			commands: func(_ map[string]model.CommandStatus) []model.ReportableCommand {
+				return nil
+			},
+			expectedStatus:   model.StageStatus_STAGE_RUNNING,
+			expectedReported: map[string]model.CommandStatus{},
+		},
+		{
+			name:           "approved by anyone",
+			minApproverNum: 1,
+			commands: func(reported map[string]model.CommandStatus) []model.ReportableCommand {
+				return []model.ReportableCommand{
+					makeCommand("alice", "", reported, approve("lgtm"), nil),
+				}
+			},
+			expectedStatus: model.StageStatus_STAGE_SUCCESS,
+			expectedDone:   true,
+			expectedReported: map[string]model.CommandStatus{
+				"alice": model.CommandStatus_COMMAND_SUCCEEDED,
+			},
+			expectedMetadata: map[string]string{
+				approvedByKey:   "alice",
+				"Comment/alice": "lgtm",
+			},
+			expectedEvents: 1,
+		},
+		{
+			name:           "not enough approvals",
+			approvers:      []string{"alice", "bob", "carol"},
+			minApproverNum: 3,
+			commands: func(reported map[string]model.CommandStatus) []model.ReportableCommand {
+				return []model.ReportableCommand{
+					makeCommand("alice", "", reported, approve(""), nil),
+					makeCommand("bob", "", reported, approve(""), nil),
+				}
+			},
+			expectedStatus: model.StageStatus_STAGE_RUNNING,
+			expectedReported: map[string]model.CommandStatus{
+				"alice": model.CommandStatus_COMMAND_SUCCEEDED,
+				"bob":   model.CommandStatus_COMMAND_SUCCEEDED,
+			},
+			expectedMetadata: map[string]string{
+				approvedByKey: "alice,bob",
+			},
+			expectedEvents: 2,
+		},
+		{
+			name:           "approved by a team member while a non-approver was ignored",
+			approvers:      []string{"alice", "pipe-cd/sre"},
+			minApproverNum: 2,
+			commands: func(reported map[string]model.CommandStatus) []model.ReportableCommand {
+				return []model.ReportableCommand{
+					makeCommand("mallory", "pipe-cd/dev", reported, approve(""), nil),
+					makeCommand("alice", "", reported, approve(""), nil),
+					makeCommand("bob", "pipe-cd/dev,pipe-cd/sre", reported, approve(""), nil),
+				}
+			},
+			expectedStatus: model.StageStatus_STAGE_SUCCESS,
+			expectedDone:   true,
+			expectedReported: map[string]model.CommandStatus{
+				"mallory": model.CommandStatus_COMMAND_FAILED,
+				"alice":   model.CommandStatus_COMMAND_SUCCEEDED,
+				"bob":     model.CommandStatus_COMMAND_SUCCEEDED,
+			},
+			expectedMetadata: map[string]string{
+				approvedByKey: "alice,bob",
+			},
+			expectedEvents: 2,
+		},
+		{
+			name:           "rejected",
+			approvers:      []string{"alice", "bob"},
+			minApproverNum: 2,
+			commands: func(reported map[string]model.CommandStatus) []model.ReportableCommand {
+				return []model.ReportableCommand{
+					makeCommand("alice", "", reported, approve(""), nil),
+					makeCommand("bob", "", reported, nil, reject("broken")),
+				}
+			},
+			expectedStatus: model.StageStatus_STAGE_FAILURE,
+			expectedDone:   true,
+			expectedReported: map[string]model.CommandStatus{
+				"alice": model.CommandStatus_COMMAND_SUCCEEDED,
+				"bob":   model.CommandStatus_COMMAND_SUCCEEDED,
+			},
+			expectedMetadata: map[string]string{
+				approvedByKey: "alice",
+				rejectedByKey: "bob",
+				"Comment/bob": "broken",
+			},
+			expectedEvents: 1,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				reported = make(map[string]model.CommandStatus)
+				ms       = &fakeMetadataStore{}
+				n        = &fakeNotifier{}
+			)
+			e := &Executor{
+				Input: executor.Input{
+					Stage: &model.PipelineStage{Id: "stage-id"},
+					StageConfig: config.PipelineStage{
+						WaitApprovalStageOptions: &config.WaitApprovalStageOptions{
+							Approvers:      tc.approvers,
+							MinApproverNum: tc.minApproverNum,
+						},
+					},
+					Deployment:    &model.Deployment{Id: "deployment-id"},
+					EnvName:       "dev",
+					CommandLister: &fakeCommandLister{commands: tc.commands(reported)},
+					LogPersister:  &fakeLogPersister{},
+					MetadataStore: ms,
+					Notifier:      n,
+					Logger:        zap.NewNop(),
+				},
+			}
+
+			status, done := e.checkCommands(context.Background())
+			assert.Equal(t, tc.expectedStatus, status)
+			assert.Equal(t, tc.expectedDone, done)
+			assert.Equal(t, tc.expectedReported, reported)
+			assert.Equal(t, tc.expectedMetadata, ms.stageMetadata)
+			require.Len(t, n.events, tc.expectedEvents)
+			for _, event := range n.events {
+				assert.Equal(t, model.NotificationEventType_EVENT_DEPLOYMENT_APPROVED, event.Type)
+			}
+		})
+	}
+}
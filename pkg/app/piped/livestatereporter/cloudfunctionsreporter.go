@@ -0,0 +1,118 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package livestatereporter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/pipe-cd/pipe/pkg/app/api/service/pipedservice"
+	"github.com/pipe-cd/pipe/pkg/app/piped/livestatestore/cloudfunctions"
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+type cloudFunctionsReporter struct {
+	provider              config.PipedCloudProvider
+	appLister             applicationLister
+	stateGetter           cloudfunctions.Getter
+	apiClient             apiClient
+	snapshotFlushInterval time.Duration
+	logger                *zap.Logger
+
+	snapshotVersions map[string]model.ApplicationLiveStateVersion
+}
+
+func newCloudFunctionsReporter(cp config.PipedCloudProvider, appLister applicationLister, stateGetter cloudfunctions.Getter, apiClient apiClient, logger *zap.Logger) *cloudFunctionsReporter {
+	logger = logger.Named("cloudfunctions-reporter").With(
+		zap.String("cloud-provider", cp.Name),
+	)
+	return &cloudFunctionsReporter{
+		provider:              cp,
+		appLister:             appLister,
+		stateGetter:           stateGetter,
+		apiClient:             apiClient,
+		snapshotFlushInterval: time.Minute,
+		logger:                logger,
+		snapshotVersions:      make(map[string]model.ApplicationLiveStateVersion),
+	}
+}
+
+func (r *cloudFunctionsReporter) Run(ctx context.Context) error {
+	r.logger.Info("start running app live state reporter")
+
+	ticker := time.NewTicker(r.snapshotFlushInterval)
+	defer ticker.Stop()
+
+L:
+	for {
+		select {
+		case <-ticker.C:
+			r.flushSnapshots(ctx)
+
+		case <-ctx.Done():
+			break L
+		}
+	}
+
+	r.logger.Info("app live state reporter has been stopped")
+	return nil
+}
+
+func (r *cloudFunctionsReporter) flushSnapshots(ctx context.Context) error {
+	apps := r.appLister.ListByCloudProvider(r.provider.Name)
+	for _, app := range apps {
+		state, ok := r.stateGetter.GetCloudFunctionsAppLiveState(app.Id)
+		if !ok {
+			continue
+		}
+		// Skip the ones which have not been refreshed since the last report.
+		if v, ok := r.snapshotVersions[app.Id]; ok && !v.IsBefore(state.Version) {
+			continue
+		}
+
+		snapshot := &model.ApplicationLiveStateSnapshot{
+			ApplicationId:  app.Id,
+			EnvId:          app.EnvId,
+			PipedId:        app.PipedId,
+			ProjectId:      app.ProjectId,
+			Kind:           app.Kind,
+			Cloudfunctions: state.State,
+			Version:        &state.Version,
+		}
+		snapshot.DetermineAppHealthStatus()
+		req := &pipedservice.ReportApplicationLiveStateRequest{
+			Snapshot: snapshot,
+		}
+
+		if _, err := r.apiClient.ReportApplicationLiveState(ctx, req); err != nil {
+			r.logger.Error("failed to report application live state",
+				zap.String("application-id", app.Id),
+				zap.Error(err),
+			)
+			continue
+		}
+		r.snapshotVersions[app.Id] = state.Version
+		r.logger.Info(fmt.Sprintf("successfully reported application live state for application: %s", app.Id))
+	}
+	return nil
+}
+
+func (r *cloudFunctionsReporter) ProviderName() string {
+	return r.provider.Name
+}
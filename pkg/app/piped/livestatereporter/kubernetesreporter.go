@@ -17,6 +17,7 @@ package livestatereporter
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"go.uber.org/zap"
@@ -35,6 +36,8 @@ type kubernetesReporter struct {
 	provider              config.PipedCloudProvider
 	appLister             applicationLister
 	stateGetter           kubernetes.Getter
+	clusterGetters        map[string]kubernetes.Getter
+	clusterNames          []string
 	eventIterator         kubernetes.EventIterator
 	apiClient             apiClient
 	flushInterval         time.Duration
@@ -44,14 +47,27 @@ type kubernetesReporter struct {
 	snapshotVersions map[string]model.ApplicationLiveStateVersion
 }
 
-func newKubernetesReporter(cp config.PipedCloudProvider, appLister applicationLister, stateGetter kubernetes.Getter, apiClient apiClient, logger *zap.Logger) *kubernetesReporter {
+// newKubernetesReporter creates a reporter for the applications of the given cloud provider.
+// The clusterGetters contains the live state getters of all Kubernetes cloud providers
+// that are used to aggregate the resources of the applications deployed to multiple clusters.
+func newKubernetesReporter(cp config.PipedCloudProvider, appLister applicationLister, stateGetter kubernetes.Getter, clusterGetters map[string]kubernetes.Getter, apiClient apiClient, logger *zap.Logger) *kubernetesReporter {
 	logger = logger.Named("kubernetes-reporter").With(
 		zap.String("cloud-provider", cp.Name),
 	)
+	clusterNames := make([]string, 0, len(clusterGetters))
+	for name := range clusterGetters {
+		if name != cp.Name {
+			clusterNames = append(clusterNames, name)
+		}
+	}
+	sort.Strings(clusterNames)
+
 	return &kubernetesReporter{
 		provider:              cp,
 		appLister:             appLister,
 		stateGetter:           stateGetter,
+		clusterGetters:        clusterGetters,
+		clusterNames:          clusterNames,
 		eventIterator:         stateGetter.NewEventIterator(),
 		apiClient:             apiClient,
 		flushInterval:         5 * time.Second,
@@ -102,7 +118,7 @@ func (r *kubernetesReporter) flushSnapshots(ctx context.Context) error {
 	// send multiple application states in one request.
 	apps := r.appLister.ListByCloudProvider(r.provider.Name)
 	for _, app := range apps {
-		state, ok := r.stateGetter.GetKubernetesAppLiveState(app.Id)
+		state, ok := r.getAppLiveState(app.Id)
 		if !ok {
 			r.logger.Info(fmt.Sprintf("no app state of kubernetes application %s to report", app.Id))
 			continue
@@ -137,6 +153,33 @@ func (r *kubernetesReporter) flushSnapshots(ctx context.Context) error {
 	return nil
 }
 
+// getAppLiveState returns the live state of the given application
+// by aggregating its resources running in all clusters.
+func (r *kubernetesReporter) getAppLiveState(appID string) (kubernetes.AppState, bool) {
+	state, ok := r.stateGetter.GetKubernetesAppLiveState(appID)
+	setCloudProvider(state.Resources, r.provider.Name)
+
+	for _, name := range r.clusterNames {
+		s, found := r.clusterGetters[name].GetKubernetesAppLiveState(appID)
+		if !found || len(s.Resources) == 0 {
+			continue
+		}
+		setCloudProvider(s.Resources, name)
+		state.Resources = append(state.Resources, s.Resources...)
+		if !ok {
+			state.Version = s.Version
+			ok = true
+		}
+	}
+	return state, ok
+}
+
+func setCloudProvider(resources []*model.KubernetesResourceState, cloudProvider string) {
+	for _, r := range resources {
+		r.CloudProvider = cloudProvider
+	}
+}
+
 func (r *kubernetesReporter) flushEvents(ctx context.Context) error {
 	events := r.eventIterator.Next(maxNumEventsPerRequest)
 	if len(events) == 0 {
@@ -149,6 +192,10 @@ func (r *kubernetesReporter) flushEvents(ctx context.Context) error {
 		if ok && event.SnapshotVersion.IsBefore(snapshotVersion) {
 			continue
 		}
+		// Copy the state to avoid updating the one shared with the livestatestore.
+		state := *event.State
+		state.CloudProvider = r.provider.Name
+		events[i].State = &state
 		filteredEvents = append(filteredEvents, &events[i])
 	}
 	if len(filteredEvents) == 0 {
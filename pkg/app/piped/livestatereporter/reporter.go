@@ -27,6 +27,7 @@ import (
 
 	"github.com/pipe-cd/pipe/pkg/app/api/service/pipedservice"
 	"github.com/pipe-cd/pipe/pkg/app/piped/livestatestore"
+	"github.com/pipe-cd/pipe/pkg/app/piped/livestatestore/kubernetes"
 	"github.com/pipe-cd/pipe/pkg/config"
 	"github.com/pipe-cd/pipe/pkg/model"
 )
@@ -60,15 +61,67 @@ func NewReporter(appLister applicationLister, stateGetter livestatestore.Getter,
 		logger:    logger.Named("live-state-reporter"),
 	}
 
+	// The live state getters of all Kubernetes clusters
+	// to aggregate the resources of multi-cluster applications.
+	kubernetesGetters := make(map[string]kubernetes.Getter)
+	for _, cp := range cfg.CloudProviders {
+		if cp.Type != model.CloudProviderKubernetes {
+			continue
+		}
+		if sg, ok := stateGetter.KubernetesGetter(cp.Name); ok {
+			kubernetesGetters[cp.Name] = sg
+		}
+	}
+
 	for _, cp := range cfg.CloudProviders {
 		switch cp.Type {
 		case model.CloudProviderKubernetes:
-			sg, ok := stateGetter.KubernetesGetter(cp.Name)
+			sg, ok := kubernetesGetters[cp.Name]
+			if !ok {
+				r.logger.Error(fmt.Sprintf("unable to find live state getter for cloud provider: %s", cp.Name))
+				continue
+			}
+			r.reporters = append(r.reporters, newKubernetesReporter(cp, appLister, sg, kubernetesGetters, apiClient, logger))
+
+		case model.CloudProviderTerraform:
+			sg, ok := stateGetter.TerraformGetter(cp.Name)
+			if !ok {
+				r.logger.Error(fmt.Sprintf("unable to find live state getter for cloud provider: %s", cp.Name))
+				continue
+			}
+			r.reporters = append(r.reporters, newTerraformReporter(cp, appLister, sg, apiClient, logger))
+
+		case model.CloudProviderCloudRun:
+			sg, ok := stateGetter.CloudRunGetter(cp.Name)
+			if !ok {
+				r.logger.Error(fmt.Sprintf("unable to find live state getter for cloud provider: %s", cp.Name))
+				continue
+			}
+			r.reporters = append(r.reporters, newCloudRunReporter(cp, appLister, sg, apiClient, logger))
+
+		case model.CloudProviderLambda:
+			sg, ok := stateGetter.LambdaGetter(cp.Name)
+			if !ok {
+				r.logger.Error(fmt.Sprintf("unable to find live state getter for cloud provider: %s", cp.Name))
+				continue
+			}
+			r.reporters = append(r.reporters, newLambdaReporter(cp, appLister, sg, apiClient, logger))
+
+		case model.CloudProviderCloudFunctions:
+			sg, ok := stateGetter.CloudFunctionsGetter(cp.Name)
+			if !ok {
+				r.logger.Error(fmt.Sprintf("unable to find live state getter for cloud provider: %s", cp.Name))
+				continue
+			}
+			r.reporters = append(r.reporters, newCloudFunctionsReporter(cp, appLister, sg, apiClient, logger))
+
+		case model.CloudProviderNomad:
+			sg, ok := stateGetter.NomadGetter(cp.Name)
 			if !ok {
 				r.logger.Error(fmt.Sprintf("unable to find live state getter for cloud provider: %s", cp.Name))
 				continue
 			}
-			r.reporters = append(r.reporters, newKubernetesReporter(cp, appLister, sg, apiClient, logger))
+			r.reporters = append(r.reporters, newNomadReporter(cp, appLister, sg, apiClient, logger))
 
 		default:
 		}
@@ -85,6 +138,7 @@ func (r *reporter) Run(ctx context.Context) error {
 		time.Sleep(time.Duration(i) * 10 * time.Second)
 		r.logger.Info(fmt.Sprintf("starting app live state reporter for cloud provider: %s", reporter.ProviderName()))
 
+		reporter := reporter
 		group.Go(func() error {
 			return reporter.Run(ctx)
 		})
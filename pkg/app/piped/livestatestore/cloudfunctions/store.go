@@ -0,0 +1,191 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudfunctions
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/cloudfunctions"
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+type applicationLister interface {
+	List() []*model.Application
+}
+
+type Getter interface {
+	GetCloudFunctionsAppLiveState(appID string) (AppState, bool)
+}
+
+type AppState struct {
+	State   *model.CloudFunctionsApplicationLiveState
+	Version model.ApplicationLiveStateVersion
+}
+
+// Store periodically fetches the functions deployed by this piped
+// and keeps their states grouped by application.
+type Store struct {
+	cloudProvider string
+	config        *config.CloudProviderCloudFunctionsConfig
+	appLister     applicationLister
+	pipedID       string
+	client        provider.Client
+	interval      time.Duration
+	logger        *zap.Logger
+
+	apps map[string]AppState
+	mu   sync.RWMutex
+}
+
+func NewStore(cfg *config.CloudProviderCloudFunctionsConfig, cloudProvider string, appLister applicationLister, pipedID string, logger *zap.Logger) *Store {
+	logger = logger.Named("cloudfunctions").
+		With(zap.String("cloud-provider", cloudProvider))
+
+	return &Store{
+		cloudProvider: cloudProvider,
+		config:        cfg,
+		appLister:     appLister,
+		pipedID:       pipedID,
+		interval:      time.Minute,
+		logger:        logger,
+		apps:          make(map[string]AppState),
+	}
+}
+
+func (s *Store) Run(ctx context.Context) error {
+	s.logger.Info("start running cloudfunctions app state store")
+
+	client, err := provider.DefaultRegistry().Client(ctx, s.cloudProvider, s.config, s.logger)
+	if err != nil {
+		s.logger.Error("failed to create cloudfunctions client", zap.Error(err))
+		return err
+	}
+	s.client = client
+
+	// Do the first check right after starting.
+	s.check(ctx)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+L:
+	for {
+		select {
+		case <-ticker.C:
+			s.check(ctx)
+
+		case <-ctx.Done():
+			break L
+		}
+	}
+
+	s.logger.Info("cloudfunctions app state store has been stopped")
+	return nil
+}
+
+func (s *Store) GetCloudFunctionsAppLiveState(appID string) (AppState, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state, ok := s.apps[appID]
+	return state, ok
+}
+
+func (s *Store) check(ctx context.Context) {
+	appIDs := s.listApplicationIDs()
+	if len(appIDs) == 0 {
+		return
+	}
+
+	functions, err := s.client.ListFunctions(ctx)
+	if err != nil {
+		s.logger.Error("failed to list functions", zap.Error(err))
+		return
+	}
+
+	apps := make(map[string]AppState, len(appIDs))
+	for _, fn := range functions {
+		if fn.Labels[provider.LabelManagedBy] != provider.ManagedByPiped || fn.Labels[provider.LabelPiped] != s.pipedID {
+			continue
+		}
+		appID := fn.Labels[provider.LabelApplication]
+		if _, ok := appIDs[appID]; !ok {
+			continue
+		}
+
+		traffics, err := s.client.GetTraffic(ctx, fn.ShortName())
+		if err != nil {
+			s.logger.Error(fmt.Sprintf("failed to get traffic of function %s", fn.ShortName()), zap.Error(err))
+			continue
+		}
+
+		apps[appID] = AppState{
+			State: makeLiveState(fn, traffics),
+			Version: model.ApplicationLiveStateVersion{
+				Timestamp: time.Now().Unix(),
+			},
+		}
+	}
+
+	s.mu.Lock()
+	s.apps = apps
+	s.mu.Unlock()
+}
+
+// listApplicationIDs returns the IDs of all cloudfunctions applications those should be handled by this store.
+func (s *Store) listApplicationIDs() map[string]struct{} {
+	var (
+		apps = s.appLister.List()
+		ids  = make(map[string]struct{})
+	)
+	for _, app := range apps {
+		if app.Kind != model.ApplicationKind_CLOUDFUNCTIONS || app.CloudProvider != s.cloudProvider {
+			continue
+		}
+		ids[app.Id] = struct{}{}
+	}
+	return ids
+}
+
+func makeLiveState(fn *provider.Function, traffics []provider.RevisionTraffic) *model.CloudFunctionsApplicationLiveState {
+	state := &model.CloudFunctionsApplicationLiveState{
+		FunctionName:   fn.ShortName(),
+		State:          fn.State,
+		LatestRevision: fn.LatestRevision(),
+		Traffics:       make([]*model.CloudFunctionsRevisionTraffic, 0, len(traffics)),
+	}
+	if fn.ServiceConfig != nil {
+		state.Url = fn.ServiceConfig.URI
+	}
+	for _, m := range fn.StateMessages {
+		state.StateMessages = append(state.StateMessages, m.Message)
+	}
+	for _, t := range traffics {
+		state.Traffics = append(state.Traffics, &model.CloudFunctionsRevisionTraffic{
+			Revision: t.RevisionName,
+			Percent:  int32(t.Percent),
+		})
+	}
+	if t, err := time.Parse(time.RFC3339Nano, fn.UpdateTime); err == nil {
+		state.UpdatedAt = t.Unix()
+	}
+	return state
+}
@@ -16,36 +16,282 @@ package cloudrun
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
 
 	"go.uber.org/zap"
 
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/cloudrun"
 	"github.com/pipe-cd/pipe/pkg/config"
 	"github.com/pipe-cd/pipe/pkg/model"
 )
 
+const (
+	annotationMinScale = "autoscaling.knative.dev/minScale"
+	annotationMaxScale = "autoscaling.knative.dev/maxScale"
+)
+
 type applicationLister interface {
 	List() []*model.Application
 }
 
-type Store struct {
-	logger *zap.Logger
+type Getter interface {
+	GetCloudRunAppLiveState(appID string) (AppState, bool)
 }
 
-type Getter interface {
+type AppState struct {
+	State   *model.CloudRunApplicationLiveState
+	Version model.ApplicationLiveStateVersion
 }
 
-func NewStore(cfg *config.CloudProviderCloudRunConfig, cloudProvider string, appLister applicationLister, logger *zap.Logger) *Store {
+// Store periodically fetches the services deployed by this piped
+// and keeps their states grouped by application.
+type Store struct {
+	cloudProvider string
+	config        *config.CloudProviderCloudRunConfig
+	appLister     applicationLister
+	pipedID       string
+	client        provider.Client
+	interval      time.Duration
+	logger        *zap.Logger
+
+	apps map[string]AppState
+	mu   sync.RWMutex
+}
+
+func NewStore(cfg *config.CloudProviderCloudRunConfig, cloudProvider string, appLister applicationLister, pipedID string, logger *zap.Logger) *Store {
 	logger = logger.Named("cloudrun").
 		With(zap.String("cloud-provider", cloudProvider))
 
 	return &Store{
-		logger: logger,
+		cloudProvider: cloudProvider,
+		config:        cfg,
+		appLister:     appLister,
+		pipedID:       pipedID,
+		interval:      time.Minute,
+		logger:        logger,
+		apps:          make(map[string]AppState),
 	}
 }
 
 func (s *Store) Run(ctx context.Context) error {
 	s.logger.Info("start running cloudrun app state store")
 
+	client, err := provider.DefaultRegistry().Client(ctx, s.cloudProvider, s.config, s.logger)
+	if err != nil {
+		s.logger.Error("failed to create cloudrun client", zap.Error(err))
+		return err
+	}
+	s.client = client
+
+	// Do the first check right after starting.
+	s.check(ctx)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+L:
+	for {
+		select {
+		case <-ticker.C:
+			s.check(ctx)
+
+		case <-ctx.Done():
+			break L
+		}
+	}
+
 	s.logger.Info("cloudrun app state store has been stopped")
 	return nil
 }
+
+func (s *Store) GetCloudRunAppLiveState(appID string) (AppState, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state, ok := s.apps[appID]
+	return state, ok
+}
+
+func (s *Store) check(ctx context.Context) {
+	appIDs := s.listApplicationIDs()
+	if len(appIDs) == 0 {
+		return
+	}
+
+	selector := fmt.Sprintf("%s=%s,%s=%s", provider.LabelManagedBy, provider.ManagedByPiped, provider.LabelPiped, s.pipedID)
+	services, err := s.client.List(ctx, selector)
+	if err != nil {
+		s.logger.Error("failed to list services", zap.Error(err))
+		return
+	}
+
+	apps := make(map[string]AppState, len(appIDs))
+	for _, svc := range services {
+		if svc.Metadata == nil {
+			continue
+		}
+		appID := svc.Metadata.Labels[provider.LabelApplication]
+		if _, ok := appIDs[appID]; !ok {
+			continue
+		}
+
+		revisions, err := s.client.ListRevisions(ctx, svc.Metadata.Name)
+		if err != nil {
+			s.logger.Error(fmt.Sprintf("failed to list revisions of service %s", svc.Metadata.Name), zap.Error(err))
+			continue
+		}
+
+		apps[appID] = AppState{
+			State: makeLiveState(svc, revisions),
+			Version: model.ApplicationLiveStateVersion{
+				Timestamp: time.Now().Unix(),
+			},
+		}
+	}
+
+	s.mu.Lock()
+	s.apps = apps
+	s.mu.Unlock()
+}
+
+// listApplicationIDs returns the IDs of all cloudrun applications those should be handled by this store.
+func (s *Store) listApplicationIDs() map[string]struct{} {
+	var (
+		apps = s.appLister.List()
+		ids  = make(map[string]struct{})
+	)
+	for _, app := range apps {
+		if app.Kind != model.ApplicationKind_CLOUDRUN || app.CloudProvider != s.cloudProvider {
+			continue
+		}
+		ids[app.Id] = struct{}{}
+	}
+	return ids
+}
+
+func makeLiveState(svc *provider.Service, revisions []*provider.Revision) *model.CloudRunApplicationLiveState {
+	state := &model.CloudRunApplicationLiveState{
+		Service: makeServiceState(svc),
+	}
+
+	// Only the revisions serving traffic and the latest one are kept
+	// since the old revisions are not removed by Cloud Run.
+	keep := map[string]struct{}{
+		state.Service.LatestCreatedRevision: {},
+		state.Service.LatestReadyRevision:   {},
+	}
+	if svc.Status != nil {
+		for _, t := range svc.Status.Traffic {
+			if t == nil {
+				continue
+			}
+			state.Traffics = append(state.Traffics, &model.CloudRunTrafficTarget{
+				Revision:       t.RevisionName,
+				Percent:        int32(t.Percent),
+				Tag:            t.Tag,
+				Url:            t.Url,
+				LatestRevision: t.LatestRevision,
+			})
+			keep[t.RevisionName] = struct{}{}
+		}
+	}
+	for _, r := range revisions {
+		if r.Metadata == nil {
+			continue
+		}
+		if _, ok := keep[r.Metadata.Name]; !ok {
+			continue
+		}
+		state.Revisions = append(state.Revisions, makeRevisionState(r))
+	}
+	sort.Slice(state.Revisions, func(i, j int) bool {
+		return state.Revisions[i].CreatedAt > state.Revisions[j].CreatedAt
+	})
+
+	state.HealthStatus, state.HealthDescription = determineHealth(state)
+	return state
+}
+
+func makeServiceState(svc *provider.Service) *model.CloudRunServiceState {
+	state := &model.CloudRunServiceState{}
+	if m := svc.Metadata; m != nil {
+		state.Name = m.Name
+		state.Generation = m.Generation
+		state.CommitHash = m.Labels[provider.LabelCommitHash]
+		state.CreatedAt = parseTimestamp(m.CreationTimestamp)
+	}
+	if st := svc.Status; st != nil {
+		state.Url = st.Url
+		state.ObservedGeneration = st.ObservedGeneration
+		state.LatestCreatedRevision = st.LatestCreatedRevisionName
+		state.LatestReadyRevision = st.LatestReadyRevisionName
+	}
+	if c := svc.ReadyCondition(); c != nil {
+		state.Ready = c.Status
+		state.ReadyReason = c.Reason
+		state.ReadyMessage = c.Message
+	}
+	return state
+}
+
+func makeRevisionState(r *provider.Revision) *model.CloudRunRevisionState {
+	state := &model.CloudRunRevisionState{
+		Name:      r.Metadata.Name,
+		MinScale:  r.Metadata.Annotations[annotationMinScale],
+		MaxScale:  r.Metadata.Annotations[annotationMaxScale],
+		CreatedAt: parseTimestamp(r.Metadata.CreationTimestamp),
+	}
+	if r.Spec != nil {
+		state.ContainerConcurrency = r.Spec.ContainerConcurrency
+		if len(r.Spec.Containers) > 0 && r.Spec.Containers[0] != nil {
+			state.Image = r.Spec.Containers[0].Image
+		}
+	}
+	if c := r.ReadyCondition(); c != nil {
+		state.Ready = c.Status
+		state.ReadyReason = c.Reason
+		state.ReadyMessage = c.Message
+	}
+	return state
+}
+
+// determineHealth returns HEALTHY only when the service has been reconciled to its latest generation
+// and all the revisions receiving traffic are ready.
+func determineHealth(state *model.CloudRunApplicationLiveState) (model.CloudRunApplicationLiveState_HealthStatus, string) {
+	svc := state.Service
+	if svc.ObservedGeneration < svc.Generation {
+		return model.CloudRunApplicationLiveState_OTHER, fmt.Sprintf("Service %s is being reconciled to generation %d", svc.Name, svc.Generation)
+	}
+	if svc.Ready != "True" {
+		return model.CloudRunApplicationLiveState_OTHER, fmt.Sprintf("Service %s is not ready: %s %s", svc.Name, svc.ReadyReason, svc.ReadyMessage)
+	}
+
+	revisions := make(map[string]*model.CloudRunRevisionState, len(state.Revisions))
+	for _, r := range state.Revisions {
+		revisions[r.Name] = r
+	}
+	for _, t := range state.Traffics {
+		if t.Percent == 0 {
+			continue
+		}
+		r, ok := revisions[t.Revision]
+		if !ok {
+			return model.CloudRunApplicationLiveState_OTHER, fmt.Sprintf("Revision %s receiving %d%% of traffic was not found", t.Revision, t.Percent)
+		}
+		if r.Ready != "True" {
+			return model.CloudRunApplicationLiveState_OTHER, fmt.Sprintf("Revision %s receiving %d%% of traffic is not ready: %s %s", r.Name, t.Percent, r.ReadyReason, r.ReadyMessage)
+		}
+	}
+	return model.CloudRunApplicationLiveState_HEALTHY, ""
+}
+
+func parseTimestamp(v string) int64 {
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return 0
+	}
+	return t.Unix()
+}
@@ -0,0 +1,167 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudrun
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/run/v1"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/cloudrun"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+func TestMakeLiveState(t *testing.T) {
+	makeRevision := func(name, created, ready string) *provider.Revision {
+		return &provider.Revision{
+			Metadata: &run.ObjectMeta{
+				Name:              name,
+				CreationTimestamp: created,
+				Annotations:       map[string]string{"autoscaling.knative.dev/maxScale": "10"},
+			},
+			Spec: &run.RevisionSpec{
+				ContainerConcurrency: 80,
+				Containers:           []*run.Container{{Image: "gcr.io/pipecd/helloworld:" + name}},
+			},
+			Status: &run.RevisionStatus{
+				Conditions: []*run.GoogleCloudRunV1Condition{
+					{Type: "Ready", Status: ready, Reason: "ContainerMissing"},
+				},
+			},
+		}
+	}
+	makeService := func(generation int64, ready string, traffics ...*run.TrafficTarget) *provider.Service {
+		return &provider.Service{
+			Metadata: &run.ObjectMeta{
+				Name:       "helloworld",
+				Generation: 2,
+				Labels:     map[string]string{provider.LabelCommitHash: "commit-hash"},
+			},
+			Status: &run.ServiceStatus{
+				Url:                       "https://helloworld-abc.a.run.app",
+				ObservedGeneration:        generation,
+				LatestCreatedRevisionName: "v2",
+				LatestReadyRevisionName:   "v2",
+				Conditions: []*run.GoogleCloudRunV1Condition{
+					{Type: "Ready", Status: ready},
+				},
+				Traffic: traffics,
+			},
+		}
+	}
+	revisions := []*provider.Revision{
+		makeRevision("v0", "2021-07-01T00:00:00Z", "True"),
+		makeRevision("v1", "2021-07-02T00:00:00Z", "True"),
+		makeRevision("v2", "2021-07-03T00:00:00Z", "True"),
+	}
+
+	testcases := []struct {
+		name              string
+		service           *provider.Service
+		revisions         []*provider.Revision
+		expectedStatus    model.CloudRunApplicationLiveState_HealthStatus
+		expectedRevisions []string
+	}{
+		{
+			name: "healthy",
+			service: makeService(2, "True",
+				&run.TrafficTarget{RevisionName: "v2", Percent: 10, Tag: "canary"},
+				&run.TrafficTarget{RevisionName: "v1", Percent: 90},
+			),
+			revisions:         revisions,
+			expectedStatus:    model.CloudRunApplicationLiveState_HEALTHY,
+			expectedRevisions: []string{"v2", "v1"},
+		},
+		{
+			name: "not reconciled yet",
+			service: makeService(1, "True",
+				&run.TrafficTarget{RevisionName: "v1", Percent: 100},
+			),
+			revisions:         revisions,
+			expectedStatus:    model.CloudRunApplicationLiveState_OTHER,
+			expectedRevisions: []string{"v2", "v1"},
+		},
+		{
+			name: "service is not ready",
+			service: makeService(2, "False",
+				&run.TrafficTarget{RevisionName: "v1", Percent: 100},
+			),
+			revisions:         revisions,
+			expectedStatus:    model.CloudRunApplicationLiveState_OTHER,
+			expectedRevisions: []string{"v2", "v1"},
+		},
+		{
+			name: "revision receiving traffic is not ready",
+			service: makeService(2, "True",
+				&run.TrafficTarget{RevisionName: "v2", Percent: 10},
+				&run.TrafficTarget{RevisionName: "v1", Percent: 90},
+			),
+			revisions: []*provider.Revision{
+				makeRevision("v1", "2021-07-02T00:00:00Z", "True"),
+				makeRevision("v2", "2021-07-03T00:00:00Z", "False"),
+			},
+			expectedStatus:    model.CloudRunApplicationLiveState_OTHER,
+			expectedRevisions: []string{"v2", "v1"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			state := makeLiveState(tc.service, tc.revisions)
+			assert.Equal(t, tc.expectedStatus, state.HealthStatus)
+			assert.Equal(t, tc.expectedStatus == model.CloudRunApplicationLiveState_HEALTHY, state.HealthDescription == "")
+
+			names := make([]string, 0, len(state.Revisions))
+			for _, r := range state.Revisions {
+				names = append(names, r.Name)
+			}
+			assert.Equal(t, tc.expectedRevisions, names)
+		})
+	}
+}
+
+func TestMakeRevisionState(t *testing.T) {
+	r := &provider.Revision{
+		Metadata: &run.ObjectMeta{
+			Name:              "helloworld-v010-1234567",
+			CreationTimestamp: "2021-07-01T00:00:00Z",
+			Annotations: map[string]string{
+				"autoscaling.knative.dev/minScale": "1",
+				"autoscaling.knative.dev/maxScale": "10",
+			},
+		},
+		Spec: &run.RevisionSpec{
+			ContainerConcurrency: 80,
+			Containers:           []*run.Container{{Image: "gcr.io/pipecd/helloworld:v0.1.0"}},
+		},
+		Status: &run.RevisionStatus{
+			Conditions: []*run.GoogleCloudRunV1Condition{
+				{Type: "Active", Status: "False"},
+				{Type: "Ready", Status: "True"},
+			},
+		},
+	}
+	expected := &model.CloudRunRevisionState{
+		Name:                 "helloworld-v010-1234567",
+		Image:                "gcr.io/pipecd/helloworld:v0.1.0",
+		ContainerConcurrency: 80,
+		MinScale:             "1",
+		MaxScale:             "10",
+		Ready:                "True",
+		CreatedAt:            1625097600,
+	}
+	assert.Equal(t, expected, makeRevisionState(r))
+}
@@ -17,6 +17,7 @@ package kubernetes
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -79,6 +80,12 @@ var (
 		"ClusterRoleBinding":       {},
 		"CustomResourceDefinition": {},
 	}
+	// The resources of these groups are watched to show the live state of Crossplane applications.
+	// They contain the managed resources of Crossplane providers.
+	crossplaneGroupSuffixes = []string{
+		"crossplane.io",
+		"upbound.io",
+	}
 	ignoreResourceKeys = map[string]struct{}{
 		"v1:Service:default:kubernetes":               {},
 		"v1:Service:kube-system:heapster":             {},
@@ -395,6 +402,10 @@ func (m *resourceMatcher) Match(gvk schema.GroupVersionKind) bool {
 		return true
 	}
 
+	if isCrossplaneGroup(gv.Group) {
+		return true
+	}
+
 	// Check the predefined list.
 	if _, ok := kindWhitelist[gvk.Kind]; !ok {
 		return false
@@ -408,3 +419,12 @@ func (m *resourceMatcher) Match(gvk schema.GroupVersionKind) bool {
 
 	return true
 }
+
+func isCrossplaneGroup(group string) bool {
+	for _, suffix := range crossplaneGroupSuffixes {
+		if group == suffix || strings.HasSuffix(group, "."+suffix) {
+			return true
+		}
+	}
+	return false
+}
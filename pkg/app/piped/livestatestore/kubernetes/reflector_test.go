@@ -34,10 +34,13 @@ func TestResourceMatcher(t *testing.T) {
 			name: "empty config",
 			cfg:  config.KubernetesAppStateInformer{},
 			gvks: map[schema.GroupVersionKind]bool{
-				schema.GroupVersionKind{"pipecd.dev", "v1beta1", "Foo"}:       false,
-				schema.GroupVersionKind{"", "v1", "Foo"}:                      false,
-				schema.GroupVersionKind{"", "v1", "Service"}:                  true,
-				schema.GroupVersionKind{"networking.k8s.io", "v1", "Ingress"}: true,
+				schema.GroupVersionKind{"pipecd.dev", "v1beta1", "Foo"}:              false,
+				schema.GroupVersionKind{"", "v1", "Foo"}:                             false,
+				schema.GroupVersionKind{"", "v1", "Service"}:                         true,
+				schema.GroupVersionKind{"networking.k8s.io", "v1", "Ingress"}:        true,
+				schema.GroupVersionKind{"s3.aws.crossplane.io", "v1beta1", "Bucket"}: true,
+				schema.GroupVersionKind{"rds.aws.upbound.io", "v1beta1", "Instance"}: true,
+				schema.GroupVersionKind{"fakecrossplane.io", "v1", "Foo"}:            false,
 			},
 		},
 		{
@@ -60,12 +63,14 @@ func TestResourceMatcher(t *testing.T) {
 				ExcludeResources: []config.KubernetesResourceMatcher{
 					{APIVersion: "networking.k8s.io/v1"},
 					{APIVersion: "apps/v1", Kind: "Deployment"},
+					{APIVersion: "s3.aws.crossplane.io/v1beta1"},
 				},
 			},
 			gvks: map[schema.GroupVersionKind]bool{
-				schema.GroupVersionKind{"apps", "v1", "ReplicaSet"}:           true,
-				schema.GroupVersionKind{"apps", "v1", "Deployment"}:           false,
-				schema.GroupVersionKind{"networking.k8s.io", "v1", "Ingress"}: false,
+				schema.GroupVersionKind{"s3.aws.crossplane.io", "v1beta1", "Bucket"}: false,
+				schema.GroupVersionKind{"apps", "v1", "ReplicaSet"}:                  true,
+				schema.GroupVersionKind{"apps", "v1", "Deployment"}:                  false,
+				schema.GroupVersionKind{"networking.k8s.io", "v1", "Ingress"}:        false,
 			},
 		},
 	}
@@ -16,36 +16,337 @@ package lambda
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
 
 	"go.uber.org/zap"
 
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/lambda"
 	"github.com/pipe-cd/pipe/pkg/config"
 	"github.com/pipe-cd/pipe/pkg/model"
 )
 
+// The version name of the unpublished version of a function.
+const latestVersion = "$LATEST"
+
 type applicationLister interface {
 	List() []*model.Application
 }
 
-type Store struct {
-	logger *zap.Logger
+// functionApp represents the application a function belongs to.
+// An empty ID is used for the functions not deployed by this piped.
+type functionApp struct {
+	appID        string
+	lastModified time.Time
 }
 
 type Getter interface {
+	GetLambdaAppLiveState(appID string) (AppState, bool)
+}
+
+type AppState struct {
+	State   *model.LambdaApplicationLiveState
+	Version model.ApplicationLiveStateVersion
+}
+
+// Store periodically fetches the functions deployed by this piped
+// and keeps their states grouped by application.
+type Store struct {
+	cloudProvider string
+	config        *config.CloudProviderLambdaConfig
+	appLister     applicationLister
+	pipedID       string
+	client        provider.Client
+	interval      time.Duration
+	logger        *zap.Logger
+
+	// Map from the ARN of a function to the application it belongs to.
+	// The tags of a function are fetched again only after it was modified.
+	functionApps map[string]functionApp
+
+	apps map[string]AppState
+	mu   sync.RWMutex
 }
 
-func NewStore(cfg *config.CloudProviderLambdaConfig, cloudProvider string, appLister applicationLister, logger *zap.Logger) *Store {
+func NewStore(cfg *config.CloudProviderLambdaConfig, cloudProvider string, appLister applicationLister, pipedID string, logger *zap.Logger) *Store {
 	logger = logger.Named("lambda").
 		With(zap.String("cloud-provider", cloudProvider))
 
 	return &Store{
-		logger: logger,
+		cloudProvider: cloudProvider,
+		config:        cfg,
+		appLister:     appLister,
+		pipedID:       pipedID,
+		interval:      time.Minute,
+		logger:        logger,
+		functionApps:  make(map[string]functionApp),
+		apps:          make(map[string]AppState),
 	}
 }
 
 func (s *Store) Run(ctx context.Context) error {
 	s.logger.Info("start running lambda app state store")
 
+	client, err := provider.DefaultRegistry().Client(s.cloudProvider, s.config, s.logger)
+	if err != nil {
+		s.logger.Error("failed to create lambda client", zap.Error(err))
+		return err
+	}
+	s.client = client
+
+	// Do the first check right after starting.
+	s.check(ctx)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+L:
+	for {
+		select {
+		case <-ticker.C:
+			s.check(ctx)
+
+		case <-ctx.Done():
+			break L
+		}
+	}
+
 	s.logger.Info("lambda app state store has been stopped")
 	return nil
 }
+
+func (s *Store) GetLambdaAppLiveState(appID string) (AppState, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state, ok := s.apps[appID]
+	return state, ok
+}
+
+func (s *Store) check(ctx context.Context) {
+	appIDs := s.listApplicationIDs()
+	if len(appIDs) == 0 {
+		return
+	}
+
+	functions, err := s.client.ListFunctions(ctx)
+	if err != nil {
+		s.logger.Error("failed to list functions", zap.Error(err))
+		return
+	}
+
+	var (
+		apps         = make(map[string]AppState, len(appIDs))
+		functionApps = make(map[string]functionApp, len(functions))
+	)
+	for _, fn := range functions {
+		fa, ok := s.functionApps[fn.ARN]
+		if !ok || !fa.lastModified.Equal(fn.LastModified) {
+			tags, err := s.client.ListTags(ctx, fn.ARN)
+			if err != nil {
+				s.logger.Error(fmt.Sprintf("failed to list tags of function %s", fn.Name), zap.Error(err))
+				continue
+			}
+			fa = functionApp{lastModified: fn.LastModified}
+			if tags[provider.TagManagedBy] == provider.ManagedByPiped && tags[provider.TagPiped] == s.pipedID {
+				fa.appID = tags[provider.TagApplication]
+			}
+		}
+		functionApps[fn.ARN] = fa
+
+		appID := fa.appID
+		if _, ok := appIDs[appID]; !ok {
+			continue
+		}
+		state, err := s.fetchLiveState(ctx, fn)
+		if err != nil {
+			s.logger.Error(fmt.Sprintf("failed to fetch live state of function %s", fn.Name), zap.Error(err))
+			continue
+		}
+		apps[appID] = AppState{
+			State: state,
+			Version: model.ApplicationLiveStateVersion{
+				Timestamp: time.Now().Unix(),
+			},
+		}
+	}
+	s.functionApps = functionApps
+
+	s.mu.Lock()
+	s.apps = apps
+	s.mu.Unlock()
+}
+
+// fetchLiveState fetches the alias of the given function and the versions referenced by it.
+func (s *Store) fetchLiveState(ctx context.Context, fn provider.FunctionState) (*model.LambdaApplicationLiveState, error) {
+	var (
+		alias    *provider.Alias
+		pc       *provider.ProvisionedConcurrency
+		versions []provider.FunctionState
+	)
+
+	a, err := s.client.GetAlias(ctx, fn.Name)
+	switch {
+	case err == nil:
+		alias = &a
+	case !errors.Is(err, provider.ErrNotFound):
+		return nil, err
+	}
+
+	if alias != nil {
+		for _, t := range alias.Traffic {
+			v, err := s.client.GetFunctionState(ctx, fn.Name, t.Version)
+			if err != nil {
+				return nil, err
+			}
+			versions = append(versions, v)
+		}
+
+		p, err := s.client.GetProvisionedConcurrency(ctx, fn.Name, alias.Name)
+		switch {
+		case err == nil:
+			pc = &p
+		case !errors.Is(err, provider.ErrNotFound):
+			return nil, err
+		}
+	}
+
+	reserved, _, err := s.client.GetReservedConcurrency(ctx, fn.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return makeLiveState(fn, alias, pc, versions, reserved), nil
+}
+
+// listApplicationIDs returns the IDs of all lambda applications those should be handled by this store.
+func (s *Store) listApplicationIDs() map[string]struct{} {
+	var (
+		apps = s.appLister.List()
+		ids  = make(map[string]struct{})
+	)
+	for _, app := range apps {
+		if app.Kind != model.ApplicationKind_LAMBDA || app.CloudProvider != s.cloudProvider {
+			continue
+		}
+		ids[app.Id] = struct{}{}
+	}
+	return ids
+}
+
+func makeLiveState(fn provider.FunctionState, alias *provider.Alias, pc *provider.ProvisionedConcurrency, versions []provider.FunctionState, reserved int32) *model.LambdaApplicationLiveState {
+	state := &model.LambdaApplicationLiveState{
+		Function:            makeFunctionState(fn),
+		ReservedConcurrency: reserved,
+	}
+	if alias != nil {
+		state.Alias = &model.LambdaAliasState{
+			Name: alias.Name,
+			Arn:  alias.ARN,
+		}
+		for _, t := range alias.Traffic {
+			state.Alias.Traffics = append(state.Alias.Traffics, &model.LambdaVersionTraffic{
+				Version: t.Version,
+				Percent: t.Percent,
+			})
+		}
+		// Show the version receiving the most traffic first.
+		sort.Slice(state.Alias.Traffics, func(i, j int) bool {
+			a, b := state.Alias.Traffics[i], state.Alias.Traffics[j]
+			if a.Percent != b.Percent {
+				return a.Percent > b.Percent
+			}
+			return a.Version < b.Version
+		})
+		if pc != nil {
+			state.Alias.ProvisionedConcurrency = &model.LambdaProvisionedConcurrency{
+				Requested:    pc.Requested,
+				Available:    pc.Available,
+				Allocated:    pc.Allocated,
+				Status:       pc.Status,
+				StatusReason: pc.StatusReason,
+			}
+		}
+	}
+	for _, v := range versions {
+		state.Versions = append(state.Versions, makeFunctionState(v))
+	}
+	sort.Slice(state.Versions, func(i, j int) bool {
+		return state.Versions[i].UpdatedAt > state.Versions[j].UpdatedAt
+	})
+
+	state.HealthStatus, state.HealthDescription = determineHealth(state)
+	return state
+}
+
+func makeFunctionState(fn provider.FunctionState) *model.LambdaFunctionState {
+	state := &model.LambdaFunctionState{
+		Name:                   fn.Name,
+		Arn:                    fn.ARN,
+		Version:                fn.Version,
+		Description:            fn.Description,
+		Runtime:                fn.Runtime,
+		Memory:                 fn.Memory,
+		Timeout:                fn.Timeout,
+		CodeSha256:             fn.CodeSHA256,
+		State:                  fn.State,
+		StateReason:            fn.StateReason,
+		LastUpdateStatus:       fn.LastUpdateStatus,
+		LastUpdateStatusReason: fn.LastUpdateStatusReason,
+	}
+	if !fn.LastModified.IsZero() {
+		state.UpdatedAt = fn.LastModified.Unix()
+	}
+	return state
+}
+
+// determineHealth returns HEALTHY only when the function and all the versions receiving traffic are active,
+// and the provisioned concurrency, if any, has been allocated.
+func determineHealth(state *model.LambdaApplicationLiveState) (model.LambdaApplicationLiveState_HealthStatus, string) {
+	if desc, ok := checkFunctionState(state.Function); !ok {
+		return model.LambdaApplicationLiveState_OTHER, desc
+	}
+	if state.Alias == nil {
+		return model.LambdaApplicationLiveState_OTHER, fmt.Sprintf("Alias of function %s was not found", state.Function.Name)
+	}
+
+	versions := make(map[string]*model.LambdaFunctionState, len(state.Versions))
+	for _, v := range state.Versions {
+		versions[v.Version] = v
+	}
+	for _, t := range state.Alias.Traffics {
+		if t.Percent == 0 {
+			continue
+		}
+		v, ok := versions[t.Version]
+		if !ok {
+			return model.LambdaApplicationLiveState_OTHER, fmt.Sprintf("Version %s receiving %v%% of traffic was not found", t.Version, t.Percent)
+		}
+		if desc, ok := checkFunctionState(v); !ok {
+			return model.LambdaApplicationLiveState_OTHER, desc
+		}
+	}
+
+	if pc := state.Alias.ProvisionedConcurrency; pc != nil && pc.Status != provider.ProvisionedConcurrencyReady {
+		return model.LambdaApplicationLiveState_OTHER, fmt.Sprintf("Provisioned concurrency of alias %s is %s: %s", state.Alias.Name, pc.Status, pc.StatusReason)
+	}
+	return model.LambdaApplicationLiveState_HEALTHY, ""
+}
+
+func checkFunctionState(fn *model.LambdaFunctionState) (string, bool) {
+	name := fn.Name
+	if fn.Version != "" && fn.Version != latestVersion {
+		name = fmt.Sprintf("%s:%s", fn.Name, fn.Version)
+	}
+	// The state is not reported for the functions those were created before the states were introduced.
+	if fn.State != "" && fn.State != provider.FunctionStateActive {
+		return fmt.Sprintf("Function %s is in %s state: %s", name, fn.State, fn.StateReason), false
+	}
+	if fn.LastUpdateStatus != "" && fn.LastUpdateStatus != provider.FunctionLastUpdateSuccessful {
+		return fmt.Sprintf("Last update of function %s is %s: %s", name, fn.LastUpdateStatus, fn.LastUpdateStatusReason), false
+	}
+	return "", true
+}
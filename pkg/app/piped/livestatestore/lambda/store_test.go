@@ -0,0 +1,140 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambda
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/lambda"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+func TestMakeLiveState(t *testing.T) {
+	makeFunction := func(version, state, lastUpdateStatus string, lastModified time.Time) provider.FunctionState {
+		return provider.FunctionState{
+			Name:             "SimpleFunction",
+			ARN:              "arn:aws:lambda:ap-northeast-1:123456789012:function:SimpleFunction",
+			Version:          version,
+			State:            state,
+			LastUpdateStatus: lastUpdateStatus,
+			LastModified:     lastModified,
+		}
+	}
+	var (
+		now      = time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+		function = makeFunction("$LATEST", "Active", "Successful", now)
+		v1       = makeFunction("1", "Active", "Successful", now.Add(-time.Hour))
+		v2       = makeFunction("2", "Active", "Successful", now)
+		alias    = &provider.Alias{
+			Name: "Service",
+			ARN:  "arn:aws:lambda:ap-northeast-1:123456789012:function:SimpleFunction:Service",
+			Traffic: provider.RoutingTrafficConfig{
+				provider.TrafficPrimaryVersionKeyName:   {Version: "1", Percent: 90},
+				provider.TrafficSecondaryVersionKeyName: {Version: "2", Percent: 10},
+			},
+		}
+	)
+
+	testcases := []struct {
+		name           string
+		function       provider.FunctionState
+		alias          *provider.Alias
+		pc             *provider.ProvisionedConcurrency
+		versions       []provider.FunctionState
+		expectedStatus model.LambdaApplicationLiveState_HealthStatus
+	}{
+		{
+			name:           "healthy",
+			function:       function,
+			alias:          alias,
+			pc:             &provider.ProvisionedConcurrency{Requested: 5, Available: 5, Allocated: 5, Status: "READY"},
+			versions:       []provider.FunctionState{v1, v2},
+			expectedStatus: model.LambdaApplicationLiveState_HEALTHY,
+		},
+		{
+			name:           "function is pending",
+			function:       makeFunction("$LATEST", "Pending", "Successful", now),
+			alias:          alias,
+			versions:       []provider.FunctionState{v1, v2},
+			expectedStatus: model.LambdaApplicationLiveState_OTHER,
+		},
+		{
+			name:           "last update was failed",
+			function:       makeFunction("$LATEST", "Active", "Failed", now),
+			alias:          alias,
+			versions:       []provider.FunctionState{v1, v2},
+			expectedStatus: model.LambdaApplicationLiveState_OTHER,
+		},
+		{
+			name:           "alias was not found",
+			function:       function,
+			expectedStatus: model.LambdaApplicationLiveState_OTHER,
+		},
+		{
+			name:           "version receiving traffic is inactive",
+			function:       function,
+			alias:          alias,
+			versions:       []provider.FunctionState{v1, makeFunction("2", "Inactive", "Successful", now)},
+			expectedStatus: model.LambdaApplicationLiveState_OTHER,
+		},
+		{
+			name:           "provisioned concurrency is being allocated",
+			function:       function,
+			alias:          alias,
+			pc:             &provider.ProvisionedConcurrency{Requested: 5, Available: 2, Allocated: 2, Status: "IN_PROGRESS"},
+			versions:       []provider.FunctionState{v1, v2},
+			expectedStatus: model.LambdaApplicationLiveState_OTHER,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			state := makeLiveState(tc.function, tc.alias, tc.pc, tc.versions, 10)
+			assert.Equal(t, tc.expectedStatus, state.HealthStatus)
+			assert.Equal(t, tc.expectedStatus == model.LambdaApplicationLiveState_HEALTHY, state.HealthDescription == "")
+			assert.Equal(t, int32(10), state.ReservedConcurrency)
+		})
+	}
+}
+
+func TestMakeLiveStateTraffics(t *testing.T) {
+	var (
+		now      = time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+		function = provider.FunctionState{Name: "SimpleFunction", Version: "$LATEST", State: "Active", LastModified: now}
+		alias    = &provider.Alias{
+			Name: "Service",
+			Traffic: provider.RoutingTrafficConfig{
+				provider.TrafficPrimaryVersionKeyName:   {Version: "1", Percent: 25},
+				provider.TrafficSecondaryVersionKeyName: {Version: "2", Percent: 75},
+			},
+		}
+		versions = []provider.FunctionState{
+			{Name: "SimpleFunction", Version: "1", State: "Active", LastModified: now.Add(-time.Hour)},
+			{Name: "SimpleFunction", Version: "2", State: "Active", LastModified: now},
+		}
+	)
+
+	state := makeLiveState(function, alias, nil, versions, 0)
+	assert.Equal(t, []*model.LambdaVersionTraffic{
+		{Version: "2", Percent: 75},
+		{Version: "1", Percent: 25},
+	}, state.Alias.Traffics)
+	assert.Equal(t, "2", state.Versions[0].Version)
+	assert.Equal(t, "1", state.Versions[1].Version)
+	assert.Equal(t, now.Unix(), state.Function.UpdatedAt)
+}
@@ -26,11 +26,14 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/kubernetes"
+	"github.com/pipe-cd/pipe/pkg/app/piped/livestatestore/cloudfunctions"
 	"github.com/pipe-cd/pipe/pkg/app/piped/livestatestore/cloudrun"
 	"github.com/pipe-cd/pipe/pkg/app/piped/livestatestore/kubernetes"
 	"github.com/pipe-cd/pipe/pkg/app/piped/livestatestore/lambda"
+	"github.com/pipe-cd/pipe/pkg/app/piped/livestatestore/nomad"
 	"github.com/pipe-cd/pipe/pkg/app/piped/livestatestore/terraform"
 	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/git"
 	"github.com/pipe-cd/pipe/pkg/model"
 )
 
@@ -38,10 +41,20 @@ type applicationLister interface {
 	List() []*model.Application
 }
 
+type gitClient interface {
+	Clone(ctx context.Context, repoID, remote, branch, destination string) (git.Repo, error)
+}
+
+type secretDecrypter interface {
+	Decrypt(string) (string, error)
+}
+
 type Getter interface {
+	CloudFunctionsGetter(cloudProvider string) (cloudfunctions.Getter, bool)
 	CloudRunGetter(cloudProvider string) (cloudrun.Getter, bool)
 	KubernetesGetter(cloudProvider string) (kubernetes.Getter, bool)
 	LambdaGetter(cloudProvider string) (lambda.Getter, bool)
+	NomadGetter(cloudProvider string) (nomad.Getter, bool)
 	TerraformGetter(cloudProvider string) (terraform.Getter, bool)
 }
 
@@ -57,14 +70,27 @@ type kubernetesStore interface {
 
 type terraformStore interface {
 	Run(ctx context.Context) error
+	terraform.Getter
 }
 
 type cloudRunStore interface {
 	Run(ctx context.Context) error
+	cloudrun.Getter
 }
 
 type lambdaStore interface {
 	Run(ctx context.Context) error
+	lambda.Getter
+}
+
+type cloudFunctionsStore interface {
+	Run(ctx context.Context) error
+	cloudfunctions.Getter
+}
+
+type nomadStore interface {
+	Run(ctx context.Context) error
+	nomad.Getter
 }
 
 // store manages a list of particular stores for all cloud providers.
@@ -77,21 +103,27 @@ type store struct {
 	cloudrunStores map[string]cloudRunStore
 	// Map thats contains a list of lambdaStore where key is the cloud provider name.
 	lambdaStores map[string]lambdaStore
+	// Map thats contains a list of cloudFunctionsStore where key is the cloud provider name.
+	cloudFunctionsStores map[string]cloudFunctionsStore
+	// Map thats contains a list of nomadStore where key is the cloud provider name.
+	nomadStores map[string]nomadStore
 
 	gracePeriod time.Duration
 	logger      *zap.Logger
 }
 
-func NewStore(cfg *config.PipedSpec, appLister applicationLister, gracePeriod time.Duration, logger *zap.Logger) Store {
+func NewStore(cfg *config.PipedSpec, appLister applicationLister, gitClient gitClient, sd secretDecrypter, gracePeriod time.Duration, logger *zap.Logger) Store {
 	logger = logger.Named("livestatestore")
 
 	s := &store{
-		kubernetesStores: make(map[string]kubernetesStore),
-		terraformStores:  make(map[string]terraformStore),
-		cloudrunStores:   make(map[string]cloudRunStore),
-		lambdaStores:     make(map[string]lambdaStore),
-		gracePeriod:      gracePeriod,
-		logger:           logger,
+		kubernetesStores:     make(map[string]kubernetesStore),
+		terraformStores:      make(map[string]terraformStore),
+		cloudrunStores:       make(map[string]cloudRunStore),
+		lambdaStores:         make(map[string]lambdaStore),
+		cloudFunctionsStores: make(map[string]cloudFunctionsStore),
+		nomadStores:          make(map[string]nomadStore),
+		gracePeriod:          gracePeriod,
+		logger:               logger,
 	}
 	for _, cp := range cfg.CloudProviders {
 		switch cp.Type {
@@ -100,16 +132,24 @@ func NewStore(cfg *config.PipedSpec, appLister applicationLister, gracePeriod ti
 			s.kubernetesStores[cp.Name] = store
 
 		case model.CloudProviderTerraform:
-			store := terraform.NewStore(cp.TerraformConfig, cp.Name, appLister, logger)
+			store := terraform.NewStore(cp.TerraformConfig, cp.Name, appLister, gitClient, cfg, sd, logger)
 			s.terraformStores[cp.Name] = store
 
 		case model.CloudProviderCloudRun:
-			store := cloudrun.NewStore(cp.CloudRunConfig, cp.Name, appLister, logger)
+			store := cloudrun.NewStore(cp.CloudRunConfig, cp.Name, appLister, cfg.PipedID, logger)
 			s.cloudrunStores[cp.Name] = store
 
 		case model.CloudProviderLambda:
-			store := lambda.NewStore(cp.LambdaConfig, cp.Name, appLister, logger)
+			store := lambda.NewStore(cp.LambdaConfig, cp.Name, appLister, cfg.PipedID, logger)
 			s.lambdaStores[cp.Name] = store
+
+		case model.CloudProviderCloudFunctions:
+			store := cloudfunctions.NewStore(cp.CloudFunctionsConfig, cp.Name, appLister, cfg.PipedID, logger)
+			s.cloudFunctionsStores[cp.Name] = store
+
+		case model.CloudProviderNomad:
+			store := nomad.NewStore(cp.NomadConfig, cp.Name, appLister, cfg.PipedID, logger)
+			s.nomadStores[cp.Name] = store
 		}
 	}
 
@@ -133,15 +173,31 @@ func (s *store) Run(ctx context.Context) error {
 		})
 	}
 
-	for i := range s.cloudrunStores {
+	for _, cs := range s.cloudrunStores {
+		cs := cs
+		group.Go(func() error {
+			return cs.Run(ctx)
+		})
+	}
+
+	for _, ls := range s.lambdaStores {
+		ls := ls
+		group.Go(func() error {
+			return ls.Run(ctx)
+		})
+	}
+
+	for _, cs := range s.cloudFunctionsStores {
+		cs := cs
 		group.Go(func() error {
-			return s.cloudrunStores[i].Run(ctx)
+			return cs.Run(ctx)
 		})
 	}
 
-	for i := range s.lambdaStores {
+	for _, ns := range s.nomadStores {
+		ns := ns
 		group.Go(func() error {
-			return s.lambdaStores[i].Run(ctx)
+			return ns.Run(ctx)
 		})
 	}
 
@@ -158,6 +214,11 @@ func (s *store) Getter() Getter {
 	return s
 }
 
+func (s *store) CloudFunctionsGetter(cloudProvider string) (cloudfunctions.Getter, bool) {
+	ks, ok := s.cloudFunctionsStores[cloudProvider]
+	return ks, ok
+}
+
 func (s *store) CloudRunGetter(cloudProvider string) (cloudrun.Getter, bool) {
 	ks, ok := s.cloudrunStores[cloudProvider]
 	return ks, ok
@@ -173,6 +234,11 @@ func (s *store) LambdaGetter(cloudProvider string) (lambda.Getter, bool) {
 	return ks, ok
 }
 
+func (s *store) NomadGetter(cloudProvider string) (nomad.Getter, bool) {
+	ks, ok := s.nomadStores[cloudProvider]
+	return ks, ok
+}
+
 func (s *store) TerraformGetter(cloudProvider string) (terraform.Getter, bool) {
 	ks, ok := s.terraformStores[cloudProvider]
 	return ks, ok
@@ -0,0 +1,208 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nomad
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/nomad"
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+type applicationLister interface {
+	List() []*model.Application
+}
+
+type Getter interface {
+	GetNomadAppLiveState(appID string) (AppState, bool)
+}
+
+type AppState struct {
+	State   *model.NomadApplicationLiveState
+	Version model.ApplicationLiveStateVersion
+}
+
+// Store periodically fetches the jobs registered by this piped
+// and keeps their states grouped by application.
+type Store struct {
+	cloudProvider string
+	config        *config.CloudProviderNomadConfig
+	appLister     applicationLister
+	pipedID       string
+	client        provider.Client
+	interval      time.Duration
+	logger        *zap.Logger
+
+	apps map[string]AppState
+	mu   sync.RWMutex
+}
+
+func NewStore(cfg *config.CloudProviderNomadConfig, cloudProvider string, appLister applicationLister, pipedID string, logger *zap.Logger) *Store {
+	logger = logger.Named("nomad").
+		With(zap.String("cloud-provider", cloudProvider))
+
+	return &Store{
+		cloudProvider: cloudProvider,
+		config:        cfg,
+		appLister:     appLister,
+		pipedID:       pipedID,
+		interval:      time.Minute,
+		logger:        logger,
+		apps:          make(map[string]AppState),
+	}
+}
+
+func (s *Store) Run(ctx context.Context) error {
+	s.logger.Info("start running nomad app state store")
+
+	client, err := provider.DefaultRegistry().Client(s.cloudProvider, s.config, s.logger)
+	if err != nil {
+		s.logger.Error("failed to create nomad client", zap.Error(err))
+		return err
+	}
+	s.client = client
+
+	// Do the first check right after starting.
+	s.check(ctx)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+L:
+	for {
+		select {
+		case <-ticker.C:
+			s.check(ctx)
+
+		case <-ctx.Done():
+			break L
+		}
+	}
+
+	s.logger.Info("nomad app state store has been stopped")
+	return nil
+}
+
+func (s *Store) GetNomadAppLiveState(appID string) (AppState, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state, ok := s.apps[appID]
+	return state, ok
+}
+
+func (s *Store) check(ctx context.Context) {
+	appIDs := s.listApplicationIDs()
+	if len(appIDs) == 0 {
+		return
+	}
+
+	jobs, err := s.client.ListJobs(ctx)
+	if err != nil {
+		s.logger.Error("failed to list jobs", zap.Error(err))
+		return
+	}
+
+	apps := make(map[string]AppState, len(appIDs))
+	for _, job := range jobs {
+		if job.Meta[provider.MetaManagedBy] != provider.ManagedByPiped || job.Meta[provider.MetaPiped] != s.pipedID {
+			continue
+		}
+		appID := job.Meta[provider.MetaApplication]
+		if _, ok := appIDs[appID]; !ok {
+			continue
+		}
+
+		deployment, err := s.client.GetLatestDeployment(ctx, job.ID)
+		if err != nil && !errors.Is(err, provider.ErrNotFound) {
+			s.logger.Error(fmt.Sprintf("failed to get the latest deployment of job %s", job.ID), zap.Error(err))
+			continue
+		}
+
+		apps[appID] = AppState{
+			State: makeLiveState(job, deployment),
+			Version: model.ApplicationLiveStateVersion{
+				Timestamp: time.Now().Unix(),
+			},
+		}
+	}
+
+	s.mu.Lock()
+	s.apps = apps
+	s.mu.Unlock()
+}
+
+// listApplicationIDs returns the IDs of all nomad applications those should be handled by this store.
+func (s *Store) listApplicationIDs() map[string]struct{} {
+	var (
+		apps = s.appLister.List()
+		ids  = make(map[string]struct{})
+	)
+	for _, app := range apps {
+		if app.Kind != model.ApplicationKind_NOMAD || app.CloudProvider != s.cloudProvider {
+			continue
+		}
+		ids[app.Id] = struct{}{}
+	}
+	return ids
+}
+
+// makeLiveState converts the given job and its latest deployment into the live state.
+// The deployment can be nil when the job has no deployment.
+func makeLiveState(job *provider.JobStub, deployment *provider.Deployment) *model.NomadApplicationLiveState {
+	state := &model.NomadApplicationLiveState{
+		JobId:      job.ID,
+		Type:       job.Type,
+		Status:     job.Status,
+		Version:    job.Version,
+		CommitHash: job.Meta[provider.MetaCommitHash],
+	}
+	if job.JobSummary != nil {
+		names := make([]string, 0, len(job.JobSummary.Summary))
+		for name := range job.JobSummary.Summary {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			s := job.JobSummary.Summary[name]
+			state.TaskGroups = append(state.TaskGroups, &model.NomadTaskGroupState{
+				Name:     name,
+				Queued:   int32(s.Queued),
+				Starting: int32(s.Starting),
+				Running:  int32(s.Running),
+				Failed:   int32(s.Failed),
+				Lost:     int32(s.Lost),
+				Complete: int32(s.Complete),
+			})
+		}
+	}
+	if deployment != nil {
+		state.LatestDeployment = &model.NomadDeploymentState{
+			Id:                deployment.ID,
+			JobVersion:        deployment.JobVersion,
+			Status:            deployment.Status,
+			StatusDescription: deployment.StatusDescription,
+		}
+	}
+	return state
+}
@@ -0,0 +1,91 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nomad
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/nomad"
+	"github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/nomad/nomadfake"
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+type fakeApplicationLister struct {
+	apps []*model.Application
+}
+
+func (l *fakeApplicationLister) List() []*model.Application {
+	return l.apps
+}
+
+func TestStoreCheck(t *testing.T) {
+	ctx := context.Background()
+	server := nomadfake.NewServer()
+	defer server.Close()
+
+	client, err := provider.NewClient(&config.CloudProviderNomadConfig{Address: server.URL}, zap.NewNop())
+	require.NoError(t, err)
+
+	registerJob := func(id string, meta map[string]string) {
+		job := provider.Job{
+			"ID":   id,
+			"Type": provider.JobTypeService,
+			"TaskGroups": []interface{}{
+				map[string]interface{}{"Name": "web", "Count": 2},
+			},
+		}
+		job.SetMeta(meta)
+		_, err := client.RegisterJob(ctx, job)
+		require.NoError(t, err)
+	}
+	registerJob("web", provider.MakeMeta(nil, "piped-id", "app-id", "commit-hash"))
+	// Registered by another piped.
+	registerJob("api", provider.MakeMeta(nil, "other-piped-id", "other-app-id", "commit-hash"))
+	// Registered outside of PipeCD.
+	registerJob("batch", nil)
+
+	store := NewStore(&config.CloudProviderNomadConfig{}, "nomad", &fakeApplicationLister{
+		apps: []*model.Application{
+			{Id: "app-id", Kind: model.ApplicationKind_NOMAD, CloudProvider: "nomad"},
+			{Id: "other-app-id", Kind: model.ApplicationKind_NOMAD, CloudProvider: "nomad"},
+		},
+	}, "piped-id", zap.NewNop())
+	store.client = client
+	store.check(ctx)
+
+	_, ok := store.GetNomadAppLiveState("other-app-id")
+	assert.False(t, ok)
+
+	state, ok := store.GetNomadAppLiveState("app-id")
+	require.True(t, ok)
+	require.NotNil(t, state.State.LatestDeployment)
+	assert.Equal(t, provider.DeploymentStatusSuccessful, state.State.LatestDeployment.Status)
+	state.State.LatestDeployment = nil
+	assert.Equal(t, &model.NomadApplicationLiveState{
+		JobId:      "web",
+		Type:       provider.JobTypeService,
+		Status:     "running",
+		CommitHash: "commit-hash",
+		TaskGroups: []*model.NomadTaskGroupState{
+			{Name: "web", Running: 2},
+		},
+	}, state.State)
+}
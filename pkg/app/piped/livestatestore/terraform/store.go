@@ -15,11 +15,23 @@
 package terraform
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	"go.uber.org/zap"
 
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/terraform"
+	"github.com/pipe-cd/pipe/pkg/app/piped/sourcedecrypter"
+	"github.com/pipe-cd/pipe/pkg/app/piped/toolregistry"
 	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/git"
 	"github.com/pipe-cd/pipe/pkg/model"
 )
 
@@ -27,25 +39,317 @@ type applicationLister interface {
 	List() []*model.Application
 }
 
-type Store struct {
-	logger *zap.Logger
+type gitClient interface {
+	Clone(ctx context.Context, repoID, remote, branch, destination string) (git.Repo, error)
+}
+
+type secretDecrypter interface {
+	Decrypt(string) (string, error)
 }
 
 type Getter interface {
+	GetTerraformAppLiveState(appID string) (AppState, bool)
+}
+
+type AppState struct {
+	// The list of resources that were changed outside of Terraform.
+	Resources []*model.TerraformResourceState
+	// The commit hash of the configuration used to detect the changes.
+	// It is the commit of the most recently successful deployment.
+	CommitHash string
+	// The head commit hash of the repository at the time of detection.
+	HeadCommitHash string
+	// Whether the files of the application were changed between CommitHash and HeadCommitHash.
+	HasUndeployedChanges bool
+	Version              model.ApplicationLiveStateVersion
 }
 
-func NewStore(cfg *config.CloudProviderTerraformConfig, cloudProvider string, appLister applicationLister, logger *zap.Logger) *Store {
+// Store periodically runs a refresh-only plan for all terraform applications
+// to find out the resources that were changed outside of Terraform.
+type Store struct {
+	cloudProvider   string
+	config          *config.CloudProviderTerraformConfig
+	appLister       applicationLister
+	gitClient       gitClient
+	pipedConfig     *config.PipedSpec
+	secretDecrypter secretDecrypter
+	interval        time.Duration
+	logger          *zap.Logger
+
+	gitRepos map[string]git.Repo
+	apps     map[string]AppState
+	mu       sync.RWMutex
+}
+
+func NewStore(
+	cfg *config.CloudProviderTerraformConfig,
+	cloudProvider string,
+	appLister applicationLister,
+	gitClient gitClient,
+	pipedConfig *config.PipedSpec,
+	sd secretDecrypter,
+	logger *zap.Logger,
+) *Store {
+
 	logger = logger.Named("terraform").
 		With(zap.String("cloud-provider", cloudProvider))
 
 	return &Store{
-		logger: logger,
+		cloudProvider:   cloudProvider,
+		config:          cfg,
+		appLister:       appLister,
+		gitClient:       gitClient,
+		pipedConfig:     pipedConfig,
+		secretDecrypter: sd,
+		interval:        10 * time.Minute,
+		logger:          logger,
+		gitRepos:        make(map[string]git.Repo),
+		apps:            make(map[string]AppState),
 	}
 }
 
 func (s *Store) Run(ctx context.Context) error {
 	s.logger.Info("start running terraform app state store")
 
+	// Do the first check right after starting.
+	s.check(ctx)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+L:
+	for {
+		select {
+		case <-ticker.C:
+			s.check(ctx)
+
+		case <-ctx.Done():
+			break L
+		}
+	}
+
 	s.logger.Info("terraform app state store has been stopped")
 	return nil
 }
+
+func (s *Store) GetTerraformAppLiveState(appID string) (AppState, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state, ok := s.apps[appID]
+	return state, ok
+}
+
+func (s *Store) check(ctx context.Context) {
+	appsByRepo := s.listGroupedApplication()
+
+	for repoID, apps := range appsByRepo {
+		gitRepo, ok := s.gitRepos[repoID]
+		if !ok {
+			// Clone repository for the first time.
+			repoCfg, ok := s.pipedConfig.GetRepository(repoID)
+			if !ok {
+				s.logger.Error(fmt.Sprintf("repository %s was not found in piped configuration", repoID))
+				continue
+			}
+			gr, err := s.gitClient.Clone(ctx, repoID, repoCfg.Remote, repoCfg.Branch, "")
+			if err != nil {
+				s.logger.Error("failed to clone repository",
+					zap.String("repo-id", repoID),
+					zap.Error(err),
+				)
+				continue
+			}
+			gitRepo = gr
+			s.gitRepos[repoID] = gitRepo
+		}
+
+		// Fetch the latest commit to use its configuration.
+		branch := gitRepo.GetClonedBranch()
+		if err := gitRepo.Pull(ctx, branch); err != nil {
+			s.logger.Error("failed to update repository branch",
+				zap.String("repo-id", repoID),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		headCommit, err := gitRepo.GetLatestCommit(ctx)
+		if err != nil {
+			s.logger.Error("failed to get head commit hash",
+				zap.String("repo-id", repoID),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		for _, app := range apps {
+			// The resources are compared with the configuration which was actually applied.
+			deployedCommit := app.GetMostRecentlySuccessfulDeployment().GetTrigger().GetCommit().GetHash()
+			if deployedCommit == "" {
+				s.logger.Info(fmt.Sprintf("application %s has never been deployed successfully", app.Id))
+				continue
+			}
+
+			resources, err := s.detectDriftedResources(ctx, app, gitRepo, deployedCommit)
+			if err != nil {
+				s.logger.Error(fmt.Sprintf("failed to detect drifted resources of application: %s", app.Id), zap.Error(err))
+				continue
+			}
+
+			var undeployed bool
+			if deployedCommit != headCommit.Hash {
+				changedFiles, err := gitRepo.ChangedFiles(ctx, deployedCommit, headCommit.Hash)
+				if err != nil {
+					s.logger.Error(fmt.Sprintf("failed to list the changed files of application: %s", app.Id), zap.Error(err))
+					continue
+				}
+				undeployed = isAppChanged(app.GitPath.Path, changedFiles)
+			}
+
+			now := time.Now()
+			s.mu.Lock()
+			s.apps[app.Id] = AppState{
+				Resources:            resources,
+				CommitHash:           deployedCommit,
+				HeadCommitHash:       headCommit.Hash,
+				HasUndeployedChanges: undeployed,
+				Version: model.ApplicationLiveStateVersion{
+					Timestamp: now.Unix(),
+				},
+			}
+			s.mu.Unlock()
+			s.logger.Info(fmt.Sprintf("application %s has %d drifted resources at commit %s", app.Id, len(resources), deployedCommit))
+		}
+	}
+}
+
+// detectDriftedResources runs a refresh-only plan with the configuration at the given commit
+// and returns the resources those were changed outside of Terraform.
+func (s *Store) detectDriftedResources(ctx context.Context, app *model.Application, repo git.Repo, commit string) ([]*model.TerraformResourceState, error) {
+	// We have to copy repository into another directory because
+	// running terraform init and decrypting the secrets change the git repository.
+	dir, err := ioutil.TempDir("", "terraform-livestate")
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare a temporary directory for git repository (%w)", err)
+	}
+	defer os.RemoveAll(dir)
+
+	repo, err = repo.Copy(filepath.Join(dir, "repo"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy the cloned git repository (%w)", err)
+	}
+	if err := repo.Checkout(ctx, commit); err != nil {
+		return nil, fmt.Errorf("failed to checkout commit %s (%w)", commit, err)
+	}
+	var (
+		repoDir = repo.GetPath()
+		appDir  = filepath.Join(repoDir, app.GitPath.Path)
+	)
+
+	cfg, err := config.LoadFromYAML(filepath.Join(repoDir, app.GitPath.GetDeploymentConfigFilePath()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load deployment configuration (%w)", err)
+	}
+	deployCfg := cfg.TerraformDeploymentSpec
+	if deployCfg == nil {
+		return nil, fmt.Errorf("malformed deployment configuration: missing TerraformDeploymentSpec")
+	}
+
+	if s.secretDecrypter != nil {
+		if len(deployCfg.SealedSecrets) > 0 {
+			if err := sourcedecrypter.DecryptSealedSecrets(appDir, deployCfg.SealedSecrets, s.secretDecrypter); err != nil {
+				return nil, fmt.Errorf("failed to decrypt sealed secrets (%w)", err)
+			}
+		}
+		if deployCfg.Encryption != nil {
+			if err := sourcedecrypter.DecryptSecrets(appDir, *deployCfg.Encryption, s.secretDecrypter); err != nil {
+				return nil, fmt.Errorf("failed to decrypt secrets (%w)", err)
+			}
+		}
+	}
+
+	terraformPath, _, err := toolregistry.DefaultRegistry().Terraform(ctx, deployCfg.Input.TerraformVersion)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find required terraform %q (%w)", deployCfg.Input.TerraformVersion, err)
+	}
+
+	vars := make([]string, 0, len(s.config.Vars)+len(deployCfg.Input.Vars))
+	vars = append(vars, s.config.Vars...)
+	vars = append(vars, deployCfg.Input.Vars...)
+
+	cmd := provider.NewTerraform(
+		terraformPath,
+		appDir,
+		provider.WithoutColor(),
+		provider.WithVars(vars),
+		provider.WithVarFiles(deployCfg.Input.VarFiles),
+	)
+
+	var buf bytes.Buffer
+	if err := cmd.Init(ctx, &buf); err != nil {
+		return nil, fmt.Errorf("failed to init: %s (%w)", buf.String(), err)
+	}
+	if ws := deployCfg.Input.Workspace; ws != "" {
+		if err := cmd.SelectWorkspace(ctx, ws); err != nil {
+			return nil, err
+		}
+	}
+
+	buf.Reset()
+	planFile := filepath.Join(dir, "refresh-only.tfplan")
+	if err := cmd.SaveRefreshOnlyPlan(ctx, &buf, planFile); err != nil {
+		return nil, fmt.Errorf("failed to plan: %s (%w)", buf.String(), err)
+	}
+
+	plan, err := cmd.ShowPlan(ctx, planFile)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	resources := make([]*model.TerraformResourceState, 0, len(plan.ResourceDrifts))
+	for _, d := range plan.ResourceDrifts {
+		resources = append(resources, &model.TerraformResourceState{
+			Address:           d.Address,
+			Type:              d.Type,
+			Name:              d.Name,
+			Actions:           d.Actions,
+			ChangedAttributes: d.ChangedAttributes,
+			DetectedAt:        now,
+		})
+	}
+	return resources, nil
+}
+
+// isAppChanged reports whether any of the given changed files is placed in the application directory.
+func isAppChanged(appPath string, changedFiles []string) bool {
+	// The application is placed at the root of the repository.
+	if appPath == "" || appPath == "." {
+		return len(changedFiles) > 0
+	}
+	dir := strings.TrimSuffix(appPath, "/") + "/"
+	for _, f := range changedFiles {
+		if strings.HasPrefix(f, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// listGroupedApplication retrieves all terraform applications those should be handled by this store
+// and then groups them by repoID.
+func (s *Store) listGroupedApplication() map[string][]*model.Application {
+	var (
+		apps = s.appLister.List()
+		m    = make(map[string][]*model.Application)
+	)
+	for _, app := range apps {
+		if app.Kind != model.ApplicationKind_TERRAFORM || app.CloudProvider != s.cloudProvider {
+			continue
+		}
+		repoID := app.GitPath.Repo.Id
+		m[repoID] = append(m[repoID], app)
+	}
+	return m
+}
@@ -37,7 +37,7 @@ type apiClient interface {
 
 type Persister interface {
 	Run(ctx context.Context) error
-	StageLogPersister(deploymentID, stageID string) StageLogPersister
+	StageLogPersister(deploymentID, stageID string, retriedCount int32) StageLogPersister
 }
 
 type StageLogPersister interface {
@@ -54,6 +54,7 @@ type StageLogPersister interface {
 type key struct {
 	DeploymentID string
 	StageID      string
+	RetriedCount int32
 }
 
 type persister struct {
@@ -107,14 +108,17 @@ L:
 }
 
 // StageLogPersister creates a child persister instance for a specific stage.
-func (p *persister) StageLogPersister(deploymentID, stageID string) StageLogPersister {
+// The logs of each retry of the stage are persisted separately.
+func (p *persister) StageLogPersister(deploymentID, stageID string, retriedCount int32) StageLogPersister {
 	k := key{
 		DeploymentID: deploymentID,
 		StageID:      stageID,
+		RetriedCount: retriedCount,
 	}
 	logger := p.logger.With(
 		zap.String("deployment-id", deploymentID),
 		zap.String("stage-id", stageID),
+		zap.Int32("retried-count", retriedCount),
 	)
 	sp := &stageLogPersister{
 		key:                     k,
@@ -175,6 +179,7 @@ func (p *persister) reportStageLogs(ctx context.Context, k key, blocks []*model.
 	req := &pipedservice.ReportStageLogsRequest{
 		DeploymentId: k.DeploymentID,
 		StageId:      k.StageID,
+		RetriedCount: k.RetriedCount,
 		Blocks:       blocks,
 	}
 	if _, err := p.apiClient.ReportStageLogs(ctx, req); err != nil {
@@ -191,6 +196,7 @@ func (p *persister) reportStageLogsFromLastCheckpoint(ctx context.Context, k key
 	req := &pipedservice.ReportStageLogsFromLastCheckpointRequest{
 		DeploymentId: k.DeploymentID,
 		StageId:      k.StageID,
+		RetriedCount: k.RetriedCount,
 		Blocks:       blocks,
 		Completed:    completed,
 	}
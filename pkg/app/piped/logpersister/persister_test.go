@@ -66,8 +66,8 @@ func TestPersister(t *testing.T) {
 	require.Equal(t, 0, apiClient.NumberOfReportStageLogsFromLastCheckpoint())
 	assert.Equal(t, 0, num)
 
-	sp1 := p.StageLogPersister("deployment-1", "stage-1")
-	p.StageLogPersister("deployment-2", "stage-2")
+	sp1 := p.StageLogPersister("deployment-1", "stage-1", 0)
+	p.StageLogPersister("deployment-2", "stage-2", 1)
 
 	num = p.flushAll(context.TODO())
 	require.Equal(t, 0, apiClient.NumberOfReportStageLogs())
@@ -170,6 +170,12 @@ func (s *slack) buildSlackMessage(event model.NotificationEvent, webURL string)
 		text = md.Summary
 		generateDeploymentEventData(md.Deployment, md.EnvName)
 
+	case model.NotificationEventType_EVENT_DEPLOYMENT_APPROVED:
+		md := event.Metadata.(*model.NotificationEventDeploymentApproved)
+		title = fmt.Sprintf("Deployment for %q was approved", md.Deployment.ApplicationName)
+		text = fmt.Sprintf("Approved by %s", md.Approver)
+		generateDeploymentEventData(md.Deployment, md.EnvName)
+
 	case model.NotificationEventType_EVENT_DEPLOYMENT_SUCCEEDED:
 		md := event.Metadata.(*model.NotificationEventDeploymentSucceeded)
 		title = fmt.Sprintf("Deployment for %q was completed successfully", md.Deployment.ApplicationName)
@@ -65,3 +65,45 @@ func TestBuildProgressivePipeline(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildProgressivePipelineRequires(t *testing.T) {
+	pp := &config.DeploymentPipeline{
+		Stages: []config.PipelineStage{
+			{
+				Id:   "canary",
+				Name: model.StageK8sCanaryRollout,
+			},
+			{
+				Id:    "analysis",
+				Name:  model.StageAnalysis,
+				Needs: []string{"canary"},
+			},
+			{
+				Id:    "smoke-test",
+				Name:  model.StageScriptRun,
+				Needs: []string{"canary"},
+			},
+			{
+				Name:  model.StageK8sPrimaryRollout,
+				Needs: []string{"analysis", "smoke-test"},
+			},
+			{
+				Name: model.StageK8sCanaryClean,
+			},
+		},
+	}
+	gotStages := buildProgressivePipeline(pp, false, time.Now())
+
+	got := make(map[string][]string, len(gotStages))
+	for _, s := range gotStages {
+		got[s.Id] = s.Requires
+	}
+	want := map[string][]string{
+		"canary":     nil,
+		"analysis":   {"canary"},
+		"smoke-test": {"canary"},
+		"stage-3":    {"analysis", "smoke-test"},
+		"stage-4":    {"stage-3"},
+	}
+	assert.Equal(t, want, got)
+}
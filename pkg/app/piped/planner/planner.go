@@ -21,7 +21,9 @@ package planner
 
 import (
 	"context"
+	"fmt"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -68,3 +70,45 @@ func MakeInitialStageMetadata(cfg config.PipelineStage) map[string]string {
 		return nil
 	}
 }
+
+// MakeStageRequires returns the IDs of the stages required by the given stage configuration.
+// A stage without any needs requires the stage declared right before it.
+func MakeStageRequires(cfg config.PipelineStage, preStageID string) []string {
+	if len(cfg.Needs) > 0 {
+		return cfg.Needs
+	}
+	if preStageID == "" {
+		return nil
+	}
+	return []string{preStageID}
+}
+
+// MakeScriptRunRollbackStages makes the invisible stages for running the rollback scripts
+// of all SCRIPT_RUN stages in the given pipeline. They are executed after the ROLLBACK stage
+// in the reverse order, and only the ones whose SCRIPT_RUN stage has been started are executed.
+func MakeScriptRunRollbackStages(stages []config.PipelineStage, now time.Time) []*model.PipelineStage {
+	out := make([]*model.PipelineStage, 0)
+	for i := len(stages) - 1; i >= 0; i-- {
+		s := stages[i]
+		if s.Name != model.StageScriptRun || s.ScriptRunStageOptions == nil || s.ScriptRunStageOptions.OnRollback == "" {
+			continue
+		}
+		id := s.Id
+		if id == "" {
+			id = fmt.Sprintf("stage-%d", i)
+		}
+		out = append(out, &model.PipelineStage{
+			Id:   fmt.Sprintf("%s-rollback", id),
+			Name: model.StageScriptRunRollback.String(),
+			Desc: fmt.Sprintf("Rollback of %s", id),
+			// The same index with the SCRIPT_RUN stage is used to share its configuration.
+			Index:      int32(i),
+			Predefined: false,
+			Visible:    false,
+			Status:     model.StageStatus_STAGE_NOT_STARTED_YET,
+			CreatedAt:  now.Unix(),
+			UpdatedAt:  now.Unix(),
+		})
+	}
+	return out
+}
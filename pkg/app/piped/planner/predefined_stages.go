@@ -20,12 +20,15 @@ import (
 )
 
 const (
-	PredefinedStageK8sSync       = "K8sSync"
-	PredefinedStageTerraformSync = "TerraformSync"
-	PredefinedStageCloudRunSync  = "CloudRunSync"
-	PredefinedStageLambdaSync    = "LambdaSync"
-	PredefinedStageECSSync       = "ECSSync"
-	PredefinedStageRollback      = "Rollback"
+	PredefinedStageK8sSync            = "K8sSync"
+	PredefinedStageTerraformSync      = "TerraformSync"
+	PredefinedStageCrossplaneSync     = "CrossplaneSync"
+	PredefinedStageCloudRunSync       = "CloudRunSync"
+	PredefinedStageLambdaSync         = "LambdaSync"
+	PredefinedStageECSSync            = "ECSSync"
+	PredefinedStageCloudFunctionsSync = "CloudFunctionsSync"
+	PredefinedStageNomadSync          = "NomadSync"
+	PredefinedStageRollback           = "Rollback"
 )
 
 var predefinedStages = map[string]config.PipelineStage{
@@ -39,6 +42,11 @@ var predefinedStages = map[string]config.PipelineStage{
 		Name: model.StageTerraformSync,
 		Desc: "Sync by automatically applying any detected changes",
 	},
+	PredefinedStageCrossplaneSync: {
+		Id:   PredefinedStageCrossplaneSync,
+		Name: model.StageCrossplaneSync,
+		Desc: "Sync by applying all resources and waiting for them to be ready",
+	},
 	PredefinedStageCloudRunSync: {
 		Id:   PredefinedStageCloudRunSync,
 		Name: model.StageCloudRunSync,
@@ -54,6 +62,16 @@ var predefinedStages = map[string]config.PipelineStage{
 		Name: model.StageECSSync,
 		Desc: "Deploy the new version and configure all traffic to it",
 	},
+	PredefinedStageCloudFunctionsSync: {
+		Id:   PredefinedStageCloudFunctionsSync,
+		Name: model.StageCloudFunctionsSync,
+		Desc: "Deploy the new version and configure all traffic to it",
+	},
+	PredefinedStageNomadSync: {
+		Id:   PredefinedStageNomadSync,
+		Name: model.StageNomadSync,
+		Desc: "Register the new version of the job and wait for its deployment",
+	},
 	PredefinedStageRollback: {
 		Id:   PredefinedStageRollback,
 		Name: model.StageRollback,
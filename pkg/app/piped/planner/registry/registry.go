@@ -19,10 +19,13 @@ import (
 	"sync"
 
 	"github.com/pipe-cd/pipe/pkg/app/piped/planner"
+	"github.com/pipe-cd/pipe/pkg/app/piped/planner/cloudfunctions"
 	"github.com/pipe-cd/pipe/pkg/app/piped/planner/cloudrun"
+	"github.com/pipe-cd/pipe/pkg/app/piped/planner/crossplane"
 	"github.com/pipe-cd/pipe/pkg/app/piped/planner/ecs"
 	"github.com/pipe-cd/pipe/pkg/app/piped/planner/kubernetes"
 	"github.com/pipe-cd/pipe/pkg/app/piped/planner/lambda"
+	"github.com/pipe-cd/pipe/pkg/app/piped/planner/nomad"
 	"github.com/pipe-cd/pipe/pkg/app/piped/planner/terraform"
 	"github.com/pipe-cd/pipe/pkg/model"
 )
@@ -69,8 +72,11 @@ func DefaultRegistry() Registry {
 // init registers all planners to the default registry.
 func init() {
 	cloudrun.Register(defaultRegistry)
+	crossplane.Register(defaultRegistry)
 	kubernetes.Register(defaultRegistry)
 	lambda.Register(defaultRegistry)
 	terraform.Register(defaultRegistry)
 	ecs.Register(defaultRegistry)
+	cloudfunctions.Register(defaultRegistry)
+	nomad.Register(defaultRegistry)
 }
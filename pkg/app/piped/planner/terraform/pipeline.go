@@ -88,9 +88,7 @@ func buildProgressivePipeline(pp *config.DeploymentPipeline, autoRollback bool,
 			CreatedAt:  now.Unix(),
 			UpdatedAt:  now.Unix(),
 		}
-		if preStageID != "" {
-			stage.Requires = []string{preStageID}
-		}
+		stage.Requires = planner.MakeStageRequires(s, preStageID)
 		preStageID = id
 		out = append(out, stage)
 	}
@@ -107,6 +105,7 @@ func buildProgressivePipeline(pp *config.DeploymentPipeline, autoRollback bool,
 			CreatedAt:  now.Unix(),
 			UpdatedAt:  now.Unix(),
 		})
+		out = append(out, planner.MakeScriptRunRollbackStages(pp.Stages, now)...)
 	}
 
 	return out
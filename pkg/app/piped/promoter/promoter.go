@@ -0,0 +1,252 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package promoter provides a way to promote a successfully deployed release
+// of an application to the configuration of other applications, e.g. the ones of the next environment,
+// by pushing a commit that copies the specified values to their configuration files.
+package promoter
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	"github.com/pipe-cd/pipe/pkg/app/api/service/pipedservice"
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/git"
+	"github.com/pipe-cd/pipe/pkg/model"
+	"github.com/pipe-cd/pipe/pkg/yamlprocessor"
+)
+
+const (
+	// The promotion name, the promoted commit and the application name are supposed.
+	defaultCommitMessageFormat = "Promote %s of application %s by promotion %q"
+	// The promotion name and the promoted commit are supposed.
+	newBranchFormat = "promotion/%s/%s"
+)
+
+type apiClient interface {
+	ReportPromotion(ctx context.Context, req *pipedservice.ReportPromotionRequest, opts ...grpc.CallOption) (*pipedservice.ReportPromotionResponse, error)
+}
+
+type gitClient interface {
+	Clone(ctx context.Context, repoID, remote, branch, destination string) (git.Repo, error)
+}
+
+type Promoter interface {
+	// Promote does all given promotions of the release deployed by the given deployment
+	// and returns their results those were also reported to the control-plane.
+	// The appDir is the application directory at the deployed commit.
+	Promote(ctx context.Context, d *model.Deployment, appDir string, promotions []config.Promotion) []*model.Promotion
+}
+
+type promoter struct {
+	pipedConfig *config.PipedSpec
+	apiClient   apiClient
+	gitClient   gitClient
+	workingDir  string
+	logger      *zap.Logger
+}
+
+// NewPromoter returns a Promoter that places the cloned repositories under the given working directory.
+func NewPromoter(cfg *config.PipedSpec, apiClient apiClient, gitClient gitClient, workingDir string, logger *zap.Logger) Promoter {
+	return &promoter{
+		pipedConfig: cfg,
+		apiClient:   apiClient,
+		gitClient:   gitClient,
+		workingDir:  workingDir,
+		logger:      logger.Named("promoter"),
+	}
+}
+
+func (p *promoter) Promote(ctx context.Context, d *model.Deployment, appDir string, promotions []config.Promotion) []*model.Promotion {
+	results := make([]*model.Promotion, 0, len(promotions))
+	for _, pr := range promotions {
+		promotion := p.promote(ctx, d, appDir, pr)
+		results = append(results, promotion)
+		logger := p.logger.With(
+			zap.String("deployment-id", d.Id),
+			zap.String("promotion", pr.Name),
+			zap.String("status", promotion.Status.String()),
+		)
+		if promotion.Status == model.PromotionStatus_PROMOTION_FAILURE {
+			logger.Error("failed to promote the deployed release", zap.String("reason", promotion.StatusReason))
+		} else {
+			logger.Info("promoted the deployed release")
+		}
+
+		if err := p.reportPromotion(ctx, promotion); err != nil {
+			logger.Error("failed to report promotion", zap.Error(err))
+		}
+	}
+	return results
+}
+
+// promote pushes a commit to apply the values of the given promotion
+// and returns the result of the promotion.
+func (p *promoter) promote(ctx context.Context, d *model.Deployment, appDir string, pr config.Promotion) *model.Promotion {
+	promotion := &model.Promotion{
+		Id:               uuid.New().String(),
+		Name:             pr.Name,
+		ProjectId:        d.ProjectId,
+		PipedId:          d.PipedId,
+		ApplicationId:    d.ApplicationId,
+		DeploymentId:     d.Id,
+		SourceCommitHash: d.Trigger.Commit.Hash,
+		RepoId:           pr.RepoID,
+	}
+	if promotion.RepoId == "" {
+		promotion.RepoId = d.GitPath.Repo.Id
+	}
+	fail := func(format string, a ...interface{}) *model.Promotion {
+		promotion.Status = model.PromotionStatus_PROMOTION_FAILURE
+		promotion.StatusReason = fmt.Sprintf(format, a...)
+		return promotion
+	}
+
+	values, err := readValues(appDir, pr.Replacements)
+	if err != nil {
+		return fail("Unable to read the values to be promoted (%v)", err)
+	}
+
+	repoCfg, ok := p.pipedConfig.GetRepository(promotion.RepoId)
+	if !ok {
+		return fail("Repository %q is not found in the piped config", promotion.RepoId)
+	}
+	dst, err := ioutil.TempDir(p.workingDir, "promotion")
+	if err != nil {
+		return fail("Unable to create a temporary directory (%v)", err)
+	}
+	repo, err := p.gitClient.Clone(ctx, repoCfg.RepoID, repoCfg.Remote, repoCfg.Branch, dst)
+	if err != nil {
+		return fail("Unable to clone repository %s (%v)", repoCfg.RepoID, err)
+	}
+	defer repo.Clean()
+
+	changes, err := makeChanges(repo.GetPath(), pr.Replacements, values)
+	if err != nil {
+		return fail("Unable to update the target files (%v)", err)
+	}
+	if len(changes) == 0 {
+		promotion.Status = model.PromotionStatus_PROMOTION_SKIPPED
+		promotion.StatusReason = "All target files are already up-to-date"
+		return promotion
+	}
+
+	shortHash := d.Trigger.Commit.Hash
+	if len(shortHash) > 7 {
+		shortHash = shortHash[:7]
+	}
+	branch := repo.GetClonedBranch()
+	if pr.NewBranch {
+		branch = fmt.Sprintf(newBranchFormat, pr.Name, shortHash)
+	}
+	commitMsg := pr.CommitMessage
+	if commitMsg == "" {
+		commitMsg = fmt.Sprintf(defaultCommitMessageFormat, shortHash, d.ApplicationName, pr.Name)
+	}
+	if err := repo.CommitChanges(ctx, branch, commitMsg, pr.NewBranch, changes); err != nil {
+		return fail("Unable to commit the changes (%v)", err)
+	}
+	if err := repo.Push(ctx, branch); err != nil {
+		return fail("Unable to push the changes to branch %s (%v)", branch, err)
+	}
+	commit, err := repo.GetLatestCommit(ctx)
+	if err != nil {
+		return fail("Unable to get the promotion commit (%v)", err)
+	}
+
+	promotion.Branch = branch
+	promotion.CommitHash = commit.Hash
+	promotion.ChangedFiles = make([]string, 0, len(changes))
+	for f := range changes {
+		promotion.ChangedFiles = append(promotion.ChangedFiles, f)
+	}
+	sort.Strings(promotion.ChangedFiles)
+	promotion.Status = model.PromotionStatus_PROMOTION_SUCCESS
+	promotion.StatusReason = fmt.Sprintf("Pushed commit %s to branch %s", commit.Hash, branch)
+	return promotion
+}
+
+func (p *promoter) reportPromotion(ctx context.Context, promotion *model.Promotion) error {
+	var (
+		err   error
+		req   = &pipedservice.ReportPromotionRequest{Promotion: promotion}
+		retry = pipedservice.NewRetry(10)
+	)
+	for retry.WaitNext(ctx) {
+		if _, err = p.apiClient.ReportPromotion(ctx, req); err == nil {
+			return nil
+		}
+		err = fmt.Errorf("failed to report promotion: %w", err)
+	}
+	return err
+}
+
+// readValues returns the values of the source fields of the given replacements.
+func readValues(appDir string, replacements []config.PromotionReplacement) ([]string, error) {
+	values := make([]string, 0, len(replacements))
+	for _, r := range replacements {
+		yml, err := ioutil.ReadFile(filepath.Join(appDir, r.Source.File))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file %s: %w", r.Source.File, err)
+		}
+		v, err := yamlprocessor.GetValue(yml, r.Source.YAMLField)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get value at %s in %s: %w", r.Source.YAMLField, r.Source.File, err)
+		}
+		value, err := yamlprocessor.ToString(v)
+		if err != nil {
+			return nil, fmt.Errorf("a value of unknown type is defined at %s in %s: %w", r.Source.YAMLField, r.Source.File, err)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// makeChanges writes the given values into the target fields of the given replacements
+// and returns the new content of the files those were changed.
+func makeChanges(repoDir string, replacements []config.PromotionReplacement, values []string) (map[string][]byte, error) {
+	changes := make(map[string][]byte)
+	for i, r := range replacements {
+		yml, ok := changes[r.Target.File]
+		if !ok {
+			var err error
+			yml, err = ioutil.ReadFile(filepath.Join(repoDir, r.Target.File))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read file %s: %w", r.Target.File, err)
+			}
+		}
+		v, err := yamlprocessor.GetValue(yml, r.Target.YAMLField)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get value at %s in %s: %w", r.Target.YAMLField, r.Target.File, err)
+		}
+		if cur, err := yamlprocessor.ToString(v); err == nil && cur == values[i] {
+			// Already up-to-date.
+			continue
+		}
+		newYml, err := yamlprocessor.ReplaceValue(yml, r.Target.YAMLField, values[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to replace value at %s in %s: %w", r.Target.YAMLField, r.Target.File, err)
+		}
+		changes[r.Target.File] = newYml
+	}
+	return changes, nil
+}
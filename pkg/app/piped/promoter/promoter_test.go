@@ -0,0 +1,100 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promoter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pipe-cd/pipe/pkg/config"
+)
+
+func TestReadValues(t *testing.T) {
+	testcases := []struct {
+		name         string
+		replacements []config.PromotionReplacement
+		want         []string
+		wantErr      bool
+	}{
+		{
+			name: "string and int values",
+			replacements: []config.PromotionReplacement{
+				{Source: config.PromotionField{File: "deployment.yaml", YAMLField: "$.spec.image"}},
+				{Source: config.PromotionField{File: "deployment.yaml", YAMLField: "$.spec.replicas"}},
+			},
+			want: []string{"gcr.io/pipecd/helloworld:v0.2.0", "2"},
+		},
+		{
+			name: "non-scalar value",
+			replacements: []config.PromotionReplacement{
+				{Source: config.PromotionField{File: "deployment.yaml", YAMLField: "$.spec"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing file",
+			replacements: []config.PromotionReplacement{
+				{Source: config.PromotionField{File: "missing.yaml", YAMLField: "$.spec.image"}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := readValues("testdata/app", tc.replacements)
+			assert.Equal(t, tc.wantErr, err != nil)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestMakeChanges(t *testing.T) {
+	testcases := []struct {
+		name         string
+		replacements []config.PromotionReplacement
+		values       []string
+		want         map[string][]byte
+	}{
+		{
+			name: "already up-to-date",
+			replacements: []config.PromotionReplacement{
+				{Target: config.PromotionField{File: "prod/deployment.yaml", YAMLField: "$.spec.image"}},
+				{Target: config.PromotionField{File: "prod/deployment.yaml", YAMLField: "$.spec.replicas"}},
+			},
+			values: []string{"gcr.io/pipecd/helloworld:v0.1.0", "2"},
+			want:   map[string][]byte{},
+		},
+		{
+			name: "outdated",
+			replacements: []config.PromotionReplacement{
+				{Target: config.PromotionField{File: "prod/deployment.yaml", YAMLField: "$.spec.image"}},
+				{Target: config.PromotionField{File: "prod/deployment.yaml", YAMLField: "$.spec.replicas"}},
+			},
+			values: []string{"gcr.io/pipecd/helloworld:v0.2.0", "3"},
+			want: map[string][]byte{
+				"prod/deployment.yaml": []byte("spec:\n  replicas: 3\n  image: gcr.io/pipecd/helloworld:v0.2.0"),
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := makeChanges("testdata/repo", tc.replacements, tc.values)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"text/template"
 
 	"github.com/pipe-cd/pipe/pkg/config"
@@ -33,19 +34,12 @@ func DecryptSecrets(appDir string, enc config.SecretEncryption, dcr secretDecryp
 		return nil
 	}
 	if len(enc.EncryptedSecrets) == 0 {
-		return fmt.Errorf("no encrypted secret was specified to decrypt (%w)", enc.DecryptionTargets)
+		return fmt.Errorf("no encrypted secret was specified to decrypt (%v)", enc.DecryptionTargets)
 	}
 
-	secrets := make(map[string]string, len(enc.EncryptedSecrets))
-	for k, v := range enc.EncryptedSecrets {
-		ds, err := dcr.Decrypt(v)
-		if err != nil {
-			return fmt.Errorf("failed to decrypt %s secret (%w)", k, err)
-		}
-		secrets[k] = ds
-	}
-	data := map[string](map[string]string){
-		"encryptedSecrets": secrets,
+	data, err := makeTemplateData(enc, dcr)
+	if err != nil {
+		return err
 	}
 
 	for _, t := range enc.DecryptionTargets {
@@ -76,6 +70,43 @@ func DecryptSecrets(appDir string, enc config.SecretEncryption, dcr secretDecryp
 	return nil
 }
 
+// RenderSecrets renders the given values which may use the encrypted secrets
+// in the same syntax with the decryption targets, e.g. {{ .encryptedSecrets.password }}.
+func RenderSecrets(values map[string]string, enc config.SecretEncryption, dcr secretDecrypter) (map[string]string, error) {
+	data, err := makeTemplateData(enc, dcr)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]string, len(values))
+	for k, v := range values {
+		tmpl, err := template.New(k).Option("missingkey=error").Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse value of %s (%w)", k, err)
+		}
+		var b strings.Builder
+		if err := tmpl.Execute(&b, data); err != nil {
+			return nil, fmt.Errorf("failed to render value of %s (%w)", k, err)
+		}
+		out[k] = b.String()
+	}
+	return out, nil
+}
+
+func makeTemplateData(enc config.SecretEncryption, dcr secretDecrypter) (map[string](map[string]string), error) {
+	secrets := make(map[string]string, len(enc.EncryptedSecrets))
+	for k, v := range enc.EncryptedSecrets {
+		ds, err := dcr.Decrypt(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt %s secret (%w)", k, err)
+		}
+		secrets[k] = ds
+	}
+	return map[string](map[string]string){
+		"encryptedSecrets": secrets,
+	}, nil
+}
+
 func DecryptSealedSecrets(appDir string, secrets []config.SealedSecretMapping, dcr secretDecrypter) error {
 	for _, s := range secrets {
 		secretPath := filepath.Join(appDir, s.Path)
@@ -256,3 +256,56 @@ data:
 		string(data),
 	)
 }
+
+func TestRenderSecrets(t *testing.T) {
+	dcr := testSecretDecrypter{
+		prefix: "decrypted-",
+	}
+	enc := config.SecretEncryption{
+		EncryptedSecrets: map[string]string{
+			"password": "encrypted-password",
+		},
+	}
+
+	testcases := []struct {
+		name        string
+		values      map[string]string
+		expected    map[string]string
+		expectedErr bool
+	}{
+		{
+			name: "no secret",
+			values: map[string]string{
+				"USER": "pipecd",
+			},
+			expected: map[string]string{
+				"USER": "pipecd",
+			},
+		},
+		{
+			name: "using secret",
+			values: map[string]string{
+				"USER":     "pipecd",
+				"PASSWORD": "{{ .encryptedSecrets.password }}",
+			},
+			expected: map[string]string{
+				"USER":     "pipecd",
+				"PASSWORD": "decrypted-encrypted-password",
+			},
+		},
+		{
+			name: "using nonexistent secret",
+			values: map[string]string{
+				"TOKEN": "{{ .encryptedSecrets.token }}",
+			},
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := RenderSecrets(tc.values, enc, dcr)
+			assert.Equal(t, tc.expectedErr, err != nil)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
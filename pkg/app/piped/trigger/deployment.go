@@ -40,6 +40,21 @@ func (t *Trigger) triggerDeployment(
 		return
 	}
 
+	// Record the concurrency mode configured at the triggered commit
+	// to let the controller know how to handle the other deployments of this application.
+	if repo, ok := t.gitRepos[app.GitPath.Repo.Id]; ok {
+		if cfg, e := loadDeploymentConfiguration(repo.GetPath(), app); e == nil {
+			deployment.Metadata = map[string]string{
+				model.DeploymentConcurrencyModeMetadataKey: string(cfg.Concurrency.Mode),
+			}
+		} else {
+			t.logger.Warn("failed to load deployment configuration to determine its concurrency mode",
+				zap.String("app-id", app.Id),
+				zap.Error(e),
+			)
+		}
+	}
+
 	defer func() {
 		if err != nil {
 			return
@@ -26,6 +26,7 @@ import (
 	"github.com/pipe-cd/pipe/pkg/filematcher"
 	"github.com/pipe-cd/pipe/pkg/git"
 	"github.com/pipe-cd/pipe/pkg/model"
+	"github.com/pipe-cd/pipe/pkg/regexpool"
 )
 
 type LastTriggeredCommitGetter interface {
@@ -36,6 +37,7 @@ type Determiner struct {
 	repo         git.Repo
 	targetCommit string
 	commitGetter LastTriggeredCommitGetter
+	tagsFetched  bool
 	logger       *zap.Logger
 }
 
@@ -50,6 +52,15 @@ func NewDeterminer(repo git.Repo, targetCommit string, cg LastTriggeredCommitGet
 
 // ShouldTrigger decides whether a given application should be triggered or not.
 func (d *Determiner) ShouldTrigger(ctx context.Context, app *model.Application) (bool, error) {
+	shouldTrigger, _, err := d.determine(ctx, app)
+	return shouldTrigger, err
+}
+
+// determine decides whether a given application should be triggered or not.
+// The returned waiting is true when the application was touched by the new commits
+// but the target commit is not tagged as required yet. In that case, the target commit
+// must not be treated as handled so that the changes are deployed once it was tagged.
+func (d *Determiner) determine(ctx context.Context, app *model.Application) (shouldTrigger, waiting bool, err error) {
 	logger := d.logger.With(
 		zap.String("app", app.Name),
 		zap.String("app-id", app.Id),
@@ -59,45 +70,112 @@ func (d *Determiner) ShouldTrigger(ctx context.Context, app *model.Application)
 	preCommit, err := d.commitGetter.Get(ctx, app.Id)
 	if err != nil {
 		logger.Error("failed to get last triggered commit", zap.Error(err))
-		return false, err
-	}
-
-	// There is no previous deployment so we don't need to check anymore.
-	// Just do it.
-	if preCommit == "" {
-		logger.Info("no previously triggered deployment was found")
-		return true, nil
+		return false, false, err
 	}
 
 	// Check whether the most recently applied one is the target commit or not.
 	// If so, nothing to do for this time.
 	if preCommit == d.targetCommit {
 		logger.Info(fmt.Sprintf("no update to sync for application, hash: %s", d.targetCommit))
-		return false, nil
+		return false, false, nil
 	}
 
-	// List the changed files between those two commits and
-	// determine whether this application was touch by those changed files.
-	changedFiles, err := d.repo.ChangedFiles(ctx, preCommit, d.targetCommit)
+	deployConfig, err := loadDeploymentConfiguration(d.repo.GetPath(), app)
 	if err != nil {
-		return false, err
+		// There is no previous deployment so we just trigger it
+		// to let the deployment report the configuration error.
+		if preCommit == "" {
+			logger.Info("no previously triggered deployment was found")
+			return true, false, nil
+		}
+		return false, false, err
 	}
 
-	deployConfig, err := loadDeploymentConfiguration(d.repo.GetPath(), app)
+	if deployConfig.Trigger.OnCommit.Disabled {
+		logger.Info("application will not be triggered by new commits because onCommit was disabled")
+		return false, false, nil
+	}
+
+	if skip := deployConfig.CommitMatcher.Skip; skip != "" {
+		matched, err := d.matchNewCommitMessages(ctx, preCommit, skip)
+		if err != nil {
+			return false, false, err
+		}
+		if matched {
+			logger.Info(fmt.Sprintf("application will not be triggered because all new commit messages were matching %q", skip))
+			return false, false, nil
+		}
+	}
+
+	if preCommit == "" {
+		logger.Info("no previously triggered deployment was found")
+	} else {
+		// List the changed files between those two commits and
+		// determine whether this application was touch by those changed files.
+		changedFiles, err := d.repo.ChangedFiles(ctx, preCommit, d.targetCommit)
+		if err != nil {
+			return false, false, err
+		}
+
+		touched, err := isTouchedByChangedFiles(app.GitPath.Path, deployConfig.TriggerPaths, changedFiles)
+		if err != nil {
+			return false, false, err
+		}
+
+		if !touched {
+			logger.Info("application was not touched by any new commits", zap.String("last-triggered-commit", preCommit))
+			return false, false, nil
+		}
+	}
+
+	if len(deployConfig.Trigger.OnCommit.Tags) > 0 {
+		// The tags pushed after cloning are not fetched by pulling the branch.
+		if !d.tagsFetched {
+			if err := d.repo.FetchTags(ctx); err != nil {
+				return false, false, err
+			}
+			d.tagsFetched = true
+		}
+		tags, err := d.repo.ListTags(ctx, d.targetCommit)
+		if err != nil {
+			return false, false, err
+		}
+		if !deployConfig.Trigger.OnCommit.MatchTags(tags) {
+			logger.Info("application is waiting for the target commit to be tagged", zap.Strings("tags", tags))
+			return false, true, nil
+		}
+	}
+
+	return true, false, nil
+}
+
+// matchNewCommitMessages reports whether the messages of all commits
+// since the last triggered one until the target one match the given pattern.
+// Only the target commit is checked when there is no last triggered commit.
+func (d *Determiner) matchNewCommitMessages(ctx context.Context, preCommit, pattern string) (bool, error) {
+	regex, err := regexpool.DefaultPool().Get(pattern)
 	if err != nil {
-		return false, err
+		return false, fmt.Errorf("failed to compile commitMatcher.skip(%s): %w", pattern, err)
 	}
 
-	touched, err := isTouchedByChangedFiles(app.GitPath.Path, deployConfig.TriggerPaths, changedFiles)
+	// The "^!" suffix limits the log to the target commit only.
+	revisionRange := d.targetCommit + "^!"
+	if preCommit != "" {
+		revisionRange = preCommit + ".." + d.targetCommit
+	}
+	commits, err := d.repo.ListCommits(ctx, revisionRange)
 	if err != nil {
 		return false, err
 	}
-
-	if !touched {
-		logger.Info("application was not touched by any new commits", zap.String("last-triggered-commit", preCommit))
+	if len(commits) == 0 {
 		return false, nil
 	}
 
+	for _, c := range commits {
+		if !regex.MatchString(c.Message) {
+			return false, nil
+		}
+	}
 	return true, nil
 }
 
@@ -15,9 +15,20 @@
 package trigger
 
 import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/pipe-cd/pipe/pkg/git"
+	"github.com/pipe-cd/pipe/pkg/git/gittest"
+	"github.com/pipe-cd/pipe/pkg/model"
 )
 
 func TestIsTouchedByChangedFiles(t *testing.T) {
@@ -77,3 +88,164 @@ func TestIsTouchedByChangedFiles(t *testing.T) {
 		})
 	}
 }
+
+type fakeCommitGetter map[string]string
+
+func (g fakeCommitGetter) Get(_ context.Context, applicationID string) (string, error) {
+	return g[applicationID], nil
+}
+
+func TestDetermine(t *testing.T) {
+	const (
+		preCommit    = "pre-commit"
+		targetCommit = "target-commit"
+	)
+	testcases := []struct {
+		name            string
+		config          string
+		preCommit       string
+		commitMessages  []string
+		changedFiles    []string
+		tags            []string
+		expectedTrigger bool
+		expectedWaiting bool
+	}{
+		{
+			name:            "no previous deployment",
+			config:          "spec: {}",
+			expectedTrigger: true,
+		},
+		{
+			name:         "already triggered",
+			config:       "spec: {}",
+			preCommit:    targetCommit,
+			changedFiles: []string{"app/deployment.yaml"},
+		},
+		{
+			name:            "touched",
+			config:          "spec: {}",
+			preCommit:       preCommit,
+			changedFiles:    []string{"app/deployment.yaml"},
+			expectedTrigger: true,
+		},
+		{
+			name:         "not touched",
+			config:       "spec: {}",
+			preCommit:    preCommit,
+			changedFiles: []string{"other/deployment.yaml"},
+		},
+		{
+			name: "onCommit was disabled",
+			config: `spec:
+  trigger:
+    onCommit:
+      disabled: true`,
+			preCommit:    preCommit,
+			changedFiles: []string{"app/deployment.yaml"},
+		},
+		{
+			name: "skipped by commit message",
+			config: `spec:
+  commitMatcher:
+    skip: \[skip deploy\]`,
+			preCommit:      preCommit,
+			commitMessages: []string{"Update image [skip deploy]", "Update config [skip deploy]"},
+			changedFiles:   []string{"app/deployment.yaml"},
+		},
+		{
+			name: "not skipped by commit message",
+			config: `spec:
+  commitMatcher:
+    skip: \[skip deploy\]`,
+			preCommit:       preCommit,
+			commitMessages:  []string{"Update image"},
+			changedFiles:    []string{"app/deployment.yaml"},
+			expectedTrigger: true,
+		},
+		{
+			name: "skip commit on top of other changes",
+			config: `spec:
+  commitMatcher:
+    skip: \[skip deploy\]`,
+			preCommit:       preCommit,
+			commitMessages:  []string{"Update docs [skip deploy]", "Update image"},
+			changedFiles:    []string{"app/deployment.yaml"},
+			expectedTrigger: true,
+		},
+		{
+			name: "waiting for tag",
+			config: `spec:
+  trigger:
+    onCommit:
+      tags: ["v*"]`,
+			preCommit:       preCommit,
+			changedFiles:    []string{"app/deployment.yaml"},
+			tags:            []string{"latest"},
+			expectedWaiting: true,
+		},
+		{
+			name: "tagged",
+			config: `spec:
+  trigger:
+    onCommit:
+      tags: ["v*"]`,
+			preCommit:       preCommit,
+			changedFiles:    []string{"app/deployment.yaml"},
+			tags:            []string{"latest", "v1.0.0"},
+			expectedTrigger: true,
+		},
+		{
+			name: "tag is not required for untouched application",
+			config: `spec:
+  trigger:
+    onCommit:
+      tags: ["v*"]`,
+			preCommit:    preCommit,
+			changedFiles: []string{"other/deployment.yaml"},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "determiner")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, "app"), 0755))
+			config := "apiVersion: pipecd.dev/v1beta1\nkind: KubernetesApp\n" + tc.config
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "app", model.DefaultDeploymentConfigFileName), []byte(config), 0644))
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := gittest.NewMockRepo(ctrl)
+			repo.EXPECT().GetPath().Return(dir).AnyTimes()
+			repo.EXPECT().ChangedFiles(gomock.Any(), tc.preCommit, targetCommit).Return(tc.changedFiles, nil).AnyTimes()
+			revisionRange := targetCommit + "^!"
+			if tc.preCommit != "" {
+				revisionRange = tc.preCommit + ".." + targetCommit
+			}
+			commits := make([]git.Commit, 0, len(tc.commitMessages))
+			for _, m := range tc.commitMessages {
+				commits = append(commits, git.Commit{Message: m})
+			}
+			repo.EXPECT().ListCommits(gomock.Any(), revisionRange).Return(commits, nil).AnyTimes()
+			repo.EXPECT().FetchTags(gomock.Any()).Return(nil).MaxTimes(1)
+			repo.EXPECT().ListTags(gomock.Any(), targetCommit).Return(tc.tags, nil).AnyTimes()
+
+			app := &model.Application{
+				Id:   "app-id",
+				Name: "app",
+				Kind: model.ApplicationKind_KUBERNETES,
+				GitPath: &model.ApplicationGitPath{
+					Path: "app",
+				},
+			}
+			d := NewDeterminer(repo, targetCommit, fakeCommitGetter{app.Id: tc.preCommit}, zap.NewNop())
+
+			shouldTrigger, waiting, err := d.determine(context.Background(), app)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedTrigger, shouldTrigger)
+			assert.Equal(t, tc.expectedWaiting, waiting)
+		})
+	}
+}
@@ -78,6 +78,8 @@ type Trigger struct {
 	config            *config.PipedSpec
 	commitStore       *lastTriggeredCommitStore
 	gitRepos          map[string]git.Repo
+	// The time when the out-of-sync application was synced lastly by this trigger.
+	outOfSyncSyncedAt map[string]time.Time
 	gracePeriod       time.Duration
 	logger            *zap.Logger
 }
@@ -114,6 +116,7 @@ func NewTrigger(
 		config:            cfg,
 		commitStore:       commitStore,
 		gitRepos:          make(map[string]git.Repo, len(cfg.Repositories)),
+		outOfSyncSyncedAt: make(map[string]time.Time),
 		gracePeriod:       gracePeriod,
 		logger:            logger.Named("trigger"),
 	}
@@ -155,6 +158,7 @@ L:
 
 		case <-commitTicker.C:
 			t.checkNewCommits(ctx)
+			t.checkOutOfSyncApplications(ctx)
 
 		case <-ctx.Done():
 			break L
@@ -171,8 +175,14 @@ func (t *Trigger) GetLastTriggeredCommitGetter() LastTriggeredCommitGetter {
 
 func (t *Trigger) checkNewCommands(ctx context.Context) error {
 	commands := t.commandLister.ListApplicationCommands()
+	repoCommands := make(map[string][]model.ReportableCommand)
 
 	for _, cmd := range commands {
+		if repoCmd := cmd.GetRepositoryUpdated(); repoCmd != nil {
+			repoCommands[repoCmd.RepositoryId] = append(repoCommands[repoCmd.RepositoryId], cmd)
+			continue
+		}
+
 		syncCmd := cmd.GetSyncApplication()
 		if syncCmd == nil {
 			continue
@@ -208,9 +218,44 @@ func (t *Trigger) checkNewCommands(ctx context.Context) error {
 		}
 	}
 
+	if len(repoCommands) > 0 {
+		t.checkUpdatedRepositories(ctx, repoCommands)
+	}
+
 	return nil
 }
 
+// checkUpdatedRepositories checks the new commits of the repositories
+// those were notified as updated by the control-plane (e.g. through a Git webhook)
+// without waiting for the next periodic check.
+// Multiple notifications for the same repository are handled by a single check.
+func (t *Trigger) checkUpdatedRepositories(ctx context.Context, repoCommands map[string][]model.ReportableCommand) {
+	applications := t.listApplications()
+
+	for repoID, commands := range repoCommands {
+		status := model.CommandStatus_COMMAND_SUCCEEDED
+		if _, ok := t.gitRepos[repoID]; !ok {
+			t.logger.Warn("detected a RepositoryUpdated command for an unregistered repository",
+				zap.String("repo-id", repoID),
+			)
+			status = model.CommandStatus_COMMAND_FAILED
+		} else {
+			t.logger.Info("checking new commits because the repository was notified as updated",
+				zap.String("repo-id", repoID),
+			)
+			if err := t.checkRepositoryCommits(ctx, repoID, applications[repoID]); err != nil {
+				status = model.CommandStatus_COMMAND_FAILED
+			}
+		}
+
+		for _, cmd := range commands {
+			if err := cmd.Report(ctx, status, nil, nil); err != nil {
+				t.logger.Error("failed to report command status", zap.Error(err))
+			}
+		}
+	}
+}
+
 func (t *Trigger) checkNewCommits(ctx context.Context) error {
 	if len(t.gitRepos) == 0 {
 		t.logger.Info("no repositories were configured for this piped")
@@ -223,36 +268,121 @@ func (t *Trigger) checkNewCommits(ctx context.Context) error {
 
 	// ENHANCEMENT: We may want to apply worker model here to run them concurrently.
 	for repoID, apps := range applications {
-		gitRepo, branch, headCommit, err := t.updateRepoToLatest(ctx, repoID)
+		t.checkRepositoryCommits(ctx, repoID, apps)
+	}
+
+	return nil
+}
+
+// checkRepositoryCommits updates the given repository to its latest commit
+// and triggers a new deployment for each application affected by the new commits.
+func (t *Trigger) checkRepositoryCommits(ctx context.Context, repoID string, apps []*model.Application) error {
+	gitRepo, branch, headCommit, err := t.updateRepoToLatest(ctx, repoID)
+	if err != nil {
+		return err
+	}
+	d := NewDeterminer(gitRepo, headCommit.Hash, t.commitStore, t.logger)
+
+	var (
+		triggerApps []*model.Application
+		dependsOn   = make(map[string][]string)
+	)
+	for _, app := range apps {
+		shouldTrigger, waiting, err := d.determine(ctx, app)
 		if err != nil {
+			t.logger.Error(fmt.Sprintf("failed to check application: %s", app.Id), zap.Error(err))
 			continue
 		}
-		d := NewDeterminer(gitRepo, headCommit.Hash, t.commitStore, t.logger)
-
-		for _, app := range apps {
-			shouldTrigger, err := d.ShouldTrigger(ctx, app)
-			if err != nil {
-				t.logger.Error(fmt.Sprintf("failed to check application: %s", app.Id), zap.Error(err))
-				continue
-			}
 
-			if !shouldTrigger {
-				t.commitStore.Put(app.Id, headCommit.Hash)
-				continue
-			}
+		// Keep the last triggered commit as is to not lose
+		// the changes those are waiting for the required tag.
+		if waiting {
+			continue
+		}
 
-			// Build deployment model and send a request to API to create a new deployment.
-			t.logger.Info("application should be synced because of the new commit")
-			if _, err := t.triggerDeployment(ctx, app, branch, headCommit, "", model.SyncStrategy_AUTO); err != nil {
-				t.logger.Error(fmt.Sprintf("failed to trigger application: %s", app.Id), zap.Error(err))
-			}
+		if !shouldTrigger {
 			t.commitStore.Put(app.Id, headCommit.Hash)
+			continue
+		}
+
+		triggerApps = append(triggerApps, app)
+		if cfg, err := loadDeploymentConfiguration(gitRepo.GetPath(), app); err == nil {
+			dependsOn[app.Id] = cfg.DependsOn
+		}
+	}
+
+	// Trigger the applications after the ones they depend on
+	// to let their deployments be handled in that order.
+	for _, app := range sortByDependencies(triggerApps, dependsOn) {
+		// Build deployment model and send a request to API to create a new deployment.
+		t.logger.Info("application should be synced because of the new commit")
+		if _, err := t.triggerDeployment(ctx, app, branch, headCommit, "", model.SyncStrategy_AUTO); err != nil {
+			t.logger.Error(fmt.Sprintf("failed to trigger application: %s", app.Id), zap.Error(err))
 		}
+		t.commitStore.Put(app.Id, headCommit.Hash)
 	}
 
 	return nil
 }
 
+// checkOutOfSyncApplications triggers a new deployment for each OUT_OF_SYNC application
+// those enabled onOutOfSync trigger to resolve its configuration drift.
+func (t *Trigger) checkOutOfSyncApplications(ctx context.Context) {
+	now := time.Now()
+
+	for _, app := range t.applicationLister.List() {
+		if app.SyncState == nil || app.SyncState.Status != model.ApplicationSyncStatus_OUT_OF_SYNC || app.Deploying {
+			continue
+		}
+		repo, ok := t.gitRepos[app.GitPath.Repo.Id]
+		if !ok {
+			continue
+		}
+
+		logger := t.logger.With(
+			zap.String("app", app.Name),
+			zap.String("app-id", app.Id),
+		)
+		cfg, err := loadDeploymentConfiguration(repo.GetPath(), app)
+		if err != nil {
+			logger.Error("failed to load deployment configuration", zap.Error(err))
+			continue
+		}
+		if !cfg.Trigger.OnOutOfSync.Enabled {
+			continue
+		}
+
+		// Give the drift a chance to be resolved by the running or just triggered deployments
+		// before syncing it again since the application state is not updated immediately.
+		window := time.Duration(cfg.Trigger.OnOutOfSync.MinWindow)
+		if now.Sub(time.Unix(app.SyncState.Timestamp, 0)) < window {
+			continue
+		}
+		if d := app.MostRecentlyTriggeredDeployment; d != nil && now.Sub(time.Unix(d.StartedAt, 0)) < window {
+			continue
+		}
+		if syncedAt, ok := t.outOfSyncSyncedAt[app.Id]; ok && now.Sub(syncedAt) < window {
+			continue
+		}
+
+		headCommit, err := repo.GetLatestCommit(ctx)
+		if err != nil {
+			logger.Error("failed to get head commit", zap.Error(err))
+			continue
+		}
+
+		logger.Info("application will be synced because it was detected as OUT_OF_SYNC",
+			zap.String("reason", app.SyncState.ShortReason),
+		)
+		if _, err := t.triggerDeployment(ctx, app, repo.GetClonedBranch(), headCommit, "", model.SyncStrategy_AUTO); err != nil {
+			logger.Error("failed to trigger application", zap.Error(err))
+			continue
+		}
+		t.outOfSyncSyncedAt[app.Id] = now
+		t.commitStore.Put(app.Id, headCommit.Hash)
+	}
+}
+
 func (t *Trigger) syncApplication(ctx context.Context, app *model.Application, commander string, syncStrategy model.SyncStrategy) (*model.Deployment, error) {
 	_, branch, headCommit, err := t.updateRepoToLatest(ctx, app.GitPath.Repo.Id)
 	if err != nil {
@@ -328,3 +458,35 @@ func (t *Trigger) listApplications() map[string][]*model.Application {
 	}
 	return m
 }
+
+// sortByDependencies sorts the given applications to place every application
+// after the ones it depends on. The dependencies are specified by the application names
+// in the same environment. A dependency cycle is broken at the application visited first.
+func sortByDependencies(apps []*model.Application, dependsOn map[string][]string) []*model.Application {
+	var (
+		sorted  = make([]*model.Application, 0, len(apps))
+		visited = make(map[string]struct{}, len(apps))
+		byName  = make(map[string]*model.Application, len(apps))
+		visit   func(app *model.Application)
+	)
+	for _, app := range apps {
+		byName[app.EnvId+"/"+app.Name] = app
+	}
+
+	visit = func(app *model.Application) {
+		if _, ok := visited[app.Id]; ok {
+			return
+		}
+		visited[app.Id] = struct{}{}
+		for _, name := range dependsOn[app.Id] {
+			if dep, ok := byName[app.EnvId+"/"+name]; ok {
+				visit(dep)
+			}
+		}
+		sorted = append(sorted, app)
+	}
+	for _, app := range apps {
+		visit(app)
+	}
+	return sorted
+}
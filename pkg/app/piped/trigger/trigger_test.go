@@ -0,0 +1,84 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trigger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+func TestSortByDependencies(t *testing.T) {
+	var (
+		migration = &model.Application{Id: "migration-id", Name: "migration", EnvId: "env"}
+		backend   = &model.Application{Id: "backend-id", Name: "backend", EnvId: "env"}
+		frontend  = &model.Application{Id: "frontend-id", Name: "frontend", EnvId: "env"}
+		other     = &model.Application{Id: "other-id", Name: "backend", EnvId: "other-env"}
+	)
+	testcases := []struct {
+		name      string
+		apps      []*model.Application
+		dependsOn map[string][]string
+		expected  []*model.Application
+	}{
+		{
+			name:     "no dependency",
+			apps:     []*model.Application{frontend, backend, migration},
+			expected: []*model.Application{frontend, backend, migration},
+		},
+		{
+			name: "chained dependencies",
+			apps: []*model.Application{frontend, backend, migration},
+			dependsOn: map[string][]string{
+				frontend.Id: {"backend"},
+				backend.Id:  {"migration"},
+			},
+			expected: []*model.Application{migration, backend, frontend},
+		},
+		{
+			name: "dependency in another environment",
+			apps: []*model.Application{other, frontend, backend},
+			dependsOn: map[string][]string{
+				other.Id: {"frontend"},
+			},
+			expected: []*model.Application{other, frontend, backend},
+		},
+		{
+			name: "dependency not being triggered",
+			apps: []*model.Application{frontend, migration},
+			dependsOn: map[string][]string{
+				frontend.Id: {"backend"},
+			},
+			expected: []*model.Application{frontend, migration},
+		},
+		{
+			name: "dependency cycle",
+			apps: []*model.Application{frontend, backend},
+			dependsOn: map[string][]string{
+				frontend.Id: {"backend"},
+				backend.Id:  {"frontend"},
+			},
+			expected: []*model.Application{backend, frontend},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := sortByDependencies(tc.apps, tc.dependsOn)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
@@ -94,7 +94,8 @@ export const ApplicationStateView: FC<ApplicationStateViewProps> = memo(
     }
 
     switch (liveState.kind) {
-      case ApplicationKind.KUBERNETES: {
+      case ApplicationKind.KUBERNETES:
+      case ApplicationKind.CROSSPLANE: {
         const resources = liveState.kubernetes?.resourcesList || [];
         return <KubernetesStateView resources={resources} />;
       }
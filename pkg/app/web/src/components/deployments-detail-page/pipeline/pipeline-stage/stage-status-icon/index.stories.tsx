@@ -20,6 +20,9 @@ Failure.args = { status: StageStatus.STAGE_FAILURE };
 export const NotStartedYet = Template.bind({});
 NotStartedYet.args = { status: StageStatus.STAGE_NOT_STARTED_YET };
 
+export const Skipped = Template.bind({});
+Skipped.args = { status: StageStatus.STAGE_SKIPPED };
+
 export const Running = Template.bind({});
 Running.args = { status: StageStatus.STAGE_RUNNING };
 
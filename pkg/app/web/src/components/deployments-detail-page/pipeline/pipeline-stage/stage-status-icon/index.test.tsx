@@ -0,0 +1,39 @@
+import { render, screen } from "~~/test-utils";
+import { StageStatus } from "~/modules/deployments";
+import { StageStatusIcon } from "./";
+
+test("STAGE_SUCCESS", () => {
+  render(<StageStatusIcon status={StageStatus.STAGE_SUCCESS} />, {});
+
+  expect(screen.getByTestId("stage-success-icon")).toBeInTheDocument();
+});
+
+test("STAGE_FAILURE", () => {
+  render(<StageStatusIcon status={StageStatus.STAGE_FAILURE} />, {});
+
+  expect(screen.getByTestId("stage-error-icon")).toBeInTheDocument();
+});
+
+test("STAGE_CANCELLED", () => {
+  render(<StageStatusIcon status={StageStatus.STAGE_CANCELLED} />, {});
+
+  expect(screen.getByTestId("stage-cancel-icon")).toBeInTheDocument();
+});
+
+test("STAGE_NOT_STARTED_YET", () => {
+  render(<StageStatusIcon status={StageStatus.STAGE_NOT_STARTED_YET} />, {});
+
+  expect(screen.getByTestId("stage-not-started-icon")).toBeInTheDocument();
+});
+
+test("STAGE_RUNNING", () => {
+  render(<StageStatusIcon status={StageStatus.STAGE_RUNNING} />, {});
+
+  expect(screen.getByTestId("stage-running-icon")).toBeInTheDocument();
+});
+
+test("STAGE_SKIPPED", () => {
+  render(<StageStatusIcon status={StageStatus.STAGE_SKIPPED} />, {});
+
+  expect(screen.getByTestId("stage-skip-icon")).toBeInTheDocument();
+});
@@ -4,6 +4,7 @@ import {
   CheckCircle,
   Error,
   IndeterminateCheckBox,
+  SkipNext,
   Stop,
 } from "@material-ui/icons";
 import { FC } from "react";
@@ -26,6 +27,9 @@ const useStyles = makeStyles((theme) => ({
   [StageStatus.STAGE_NOT_STARTED_YET]: {
     color: theme.palette.grey[500],
   },
+  [StageStatus.STAGE_SKIPPED]: {
+    color: theme.palette.grey[500],
+  },
   "@keyframes running": {
     "0%": {
       transform: "rotate(0deg)",
@@ -45,14 +49,34 @@ export const StageStatusIcon: FC<StageStatusIconProps> = ({ status }) => {
 
   switch (status) {
     case StageStatus.STAGE_SUCCESS:
-      return <CheckCircle className={classes[status]} />;
+      return (
+        <CheckCircle
+          className={classes[status]}
+          data-testid="stage-success-icon"
+        />
+      );
     case StageStatus.STAGE_FAILURE:
-      return <Error className={classes[status]} />;
+      return (
+        <Error className={classes[status]} data-testid="stage-error-icon" />
+      );
     case StageStatus.STAGE_CANCELLED:
-      return <Stop className={classes[status]} />;
+      return (
+        <Stop className={classes[status]} data-testid="stage-cancel-icon" />
+      );
     case StageStatus.STAGE_NOT_STARTED_YET:
-      return <IndeterminateCheckBox className={classes[status]} />;
+      return (
+        <IndeterminateCheckBox
+          className={classes[status]}
+          data-testid="stage-not-started-icon"
+        />
+      );
     case StageStatus.STAGE_RUNNING:
-      return <Cached className={classes[status]} />;
+      return (
+        <Cached className={classes[status]} data-testid="stage-running-icon" />
+      );
+    case StageStatus.STAGE_SKIPPED:
+      return (
+        <SkipNext className={classes[status]} data-testid="stage-skip-icon" />
+      );
   }
 };
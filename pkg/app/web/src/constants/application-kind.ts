@@ -7,6 +7,8 @@ export const APPLICATION_KIND_TEXT: Record<ApplicationKind, string> = {
   [ApplicationKind.LAMBDA]: "LAMBDA",
   [ApplicationKind.CLOUDRUN]: "CLOUDRUN",
   [ApplicationKind.ECS]: "ECS",
+  [ApplicationKind.CLOUDFUNCTIONS]: "CLOUDFUNCTIONS",
+  [ApplicationKind.NOMAD]: "NOMAD",
 };
 
 export const APPLICATION_KIND_BY_NAME: Record<string, ApplicationKind> = {
@@ -18,4 +20,7 @@ export const APPLICATION_KIND_BY_NAME: Record<string, ApplicationKind> = {
   [APPLICATION_KIND_TEXT[ApplicationKind.LAMBDA]]: ApplicationKind.LAMBDA,
   [APPLICATION_KIND_TEXT[ApplicationKind.CLOUDRUN]]: ApplicationKind.CLOUDRUN,
   [APPLICATION_KIND_TEXT[ApplicationKind.ECS]]: ApplicationKind.ECS,
+  [APPLICATION_KIND_TEXT[ApplicationKind.CLOUDFUNCTIONS]]:
+    ApplicationKind.CLOUDFUNCTIONS,
+  [APPLICATION_KIND_TEXT[ApplicationKind.NOMAD]]: ApplicationKind.NOMAD,
 };
@@ -28,6 +28,10 @@ describe("applicationCountsSlice reducer", () => {
       })
     ).toEqual({
       counts: {
+        CLOUDFUNCTIONS: {
+          DISABLED: 0,
+          ENABLED: 0,
+        },
         CLOUDRUN: {
           DISABLED: 0,
           ENABLED: 0,
@@ -48,6 +52,10 @@ describe("applicationCountsSlice reducer", () => {
           DISABLED: 0,
           ENABLED: 0,
         },
+        NOMAD: {
+          DISABLED: 0,
+          ENABLED: 0,
+        },
         TERRAFORM: {
           DISABLED: 0,
           ENABLED: 0,
@@ -66,6 +74,10 @@ test("fetchApplicationCount", async () => {
   expect(store.getState().applicationCounts).toEqual(
     expect.objectContaining({
       counts: {
+        CLOUDFUNCTIONS: {
+          DISABLED: 0,
+          ENABLED: 0,
+        },
         CLOUDRUN: {
           DISABLED: 0,
           ENABLED: 0,
@@ -86,6 +98,10 @@ test("fetchApplicationCount", async () => {
           DISABLED: 0,
           ENABLED: 0,
         },
+        NOMAD: {
+          DISABLED: 0,
+          ENABLED: 0,
+        },
         TERRAFORM: {
           DISABLED: 2,
           ENABLED: 75,
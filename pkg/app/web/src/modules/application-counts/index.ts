@@ -36,6 +36,8 @@ const createInitialCounts = (): Record<string, Record<string, number>> => ({
   [APPLICATION_KIND_TEXT[ApplicationKind.LAMBDA]]: createInitialCount(),
   [APPLICATION_KIND_TEXT[ApplicationKind.CLOUDRUN]]: createInitialCount(),
   [APPLICATION_KIND_TEXT[ApplicationKind.ECS]]: createInitialCount(),
+  [APPLICATION_KIND_TEXT[ApplicationKind.CLOUDFUNCTIONS]]: createInitialCount(),
+  [APPLICATION_KIND_TEXT[ApplicationKind.NOMAD]]: createInitialCount(),
 });
 
 const initialState: ApplicationCounts = {
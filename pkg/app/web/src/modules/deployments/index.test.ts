@@ -44,6 +44,7 @@ test("isStageRunning", () => {
   expect(isStageRunning(StageStatus.STAGE_CANCELLED)).toBeFalsy();
   expect(isStageRunning(StageStatus.STAGE_FAILURE)).toBeFalsy();
   expect(isStageRunning(StageStatus.STAGE_SUCCESS)).toBeFalsy();
+  expect(isStageRunning(StageStatus.STAGE_SKIPPED)).toBeFalsy();
   expect(isStageRunning(StageStatus.STAGE_NOT_STARTED_YET)).toBeTruthy();
   expect(isStageRunning(StageStatus.STAGE_RUNNING)).toBeTruthy();
 });
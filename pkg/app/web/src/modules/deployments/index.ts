@@ -58,6 +58,7 @@ export const isStageRunning = (status: StageStatus): boolean => {
     case StageStatus.STAGE_SUCCESS:
     case StageStatus.STAGE_FAILURE:
     case StageStatus.STAGE_CANCELLED:
+    case StageStatus.STAGE_SKIPPED:
       return false;
   }
 };
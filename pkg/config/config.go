@@ -51,6 +51,10 @@ const (
 	KindCloudRunApp Kind = "CloudRunApp"
 	// KindECSApp represents deployment configuration for an AWS ECS.
 	KindECSApp Kind = "ECSApp"
+	// KindCloudFunctionsApp represents deployment configuration for a Google Cloud Functions (2nd gen) application.
+	KindCloudFunctionsApp Kind = "CloudFunctionsApp"
+	// KindNomadApp represents deployment configuration for a HashiCorp Nomad job.
+	KindNomadApp Kind = "NomadApp"
 	// KindSealedSecret represents a sealed secret.
 	KindSealedSecret Kind = "SealedSecret"
 )
@@ -80,11 +84,14 @@ type Config struct {
 	APIVersion string
 	spec       interface{}
 
-	KubernetesDeploymentSpec *KubernetesDeploymentSpec
-	TerraformDeploymentSpec  *TerraformDeploymentSpec
-	CloudRunDeploymentSpec   *CloudRunDeploymentSpec
-	LambdaDeploymentSpec     *LambdaDeploymentSpec
-	ECSDeploymentSpec        *ECSDeploymentSpec
+	KubernetesDeploymentSpec     *KubernetesDeploymentSpec
+	TerraformDeploymentSpec      *TerraformDeploymentSpec
+	CloudRunDeploymentSpec       *CloudRunDeploymentSpec
+	LambdaDeploymentSpec         *LambdaDeploymentSpec
+	ECSDeploymentSpec            *ECSDeploymentSpec
+	CrossplaneDeploymentSpec     *CrossplaneDeploymentSpec
+	CloudFunctionsDeploymentSpec *CloudFunctionsDeploymentSpec
+	NomadDeploymentSpec          *NomadDeploymentSpec
 
 	PipedSpec            *PipedSpec
 	ControlPlaneSpec     *ControlPlaneSpec
@@ -113,6 +120,10 @@ func (c *Config) init(kind Kind, apiVersion string) error {
 		c.TerraformDeploymentSpec = &TerraformDeploymentSpec{}
 		c.spec = c.TerraformDeploymentSpec
 
+	case KindCrossplaneApp:
+		c.CrossplaneDeploymentSpec = &CrossplaneDeploymentSpec{}
+		c.spec = c.CrossplaneDeploymentSpec
+
 	case KindCloudRunApp:
 		c.CloudRunDeploymentSpec = &CloudRunDeploymentSpec{}
 		c.spec = c.CloudRunDeploymentSpec
@@ -125,6 +136,14 @@ func (c *Config) init(kind Kind, apiVersion string) error {
 		c.ECSDeploymentSpec = &ECSDeploymentSpec{}
 		c.spec = c.ECSDeploymentSpec
 
+	case KindCloudFunctionsApp:
+		c.CloudFunctionsDeploymentSpec = &CloudFunctionsDeploymentSpec{}
+		c.spec = c.CloudFunctionsDeploymentSpec
+
+	case KindNomadApp:
+		c.NomadDeploymentSpec = &NomadDeploymentSpec{}
+		c.spec = c.NomadDeploymentSpec
+
 	case KindPiped:
 		c.PipedSpec = &PipedSpec{}
 		c.spec = c.PipedSpec
@@ -246,6 +265,10 @@ func ToApplicationKind(k Kind) (model.ApplicationKind, bool) {
 		return model.ApplicationKind_CLOUDRUN, true
 	case KindECSApp:
 		return model.ApplicationKind_ECS, true
+	case KindCloudFunctionsApp:
+		return model.ApplicationKind_CLOUDFUNCTIONS, true
+	case KindNomadApp:
+		return model.ApplicationKind_NOMAD, true
 	}
 	return model.ApplicationKind_KUBERNETES, false
 }
@@ -256,12 +279,18 @@ func (c *Config) GetGenericDeployment() (GenericDeploymentSpec, bool) {
 		return c.KubernetesDeploymentSpec.GenericDeploymentSpec, true
 	case KindTerraformApp:
 		return c.TerraformDeploymentSpec.GenericDeploymentSpec, true
+	case KindCrossplaneApp:
+		return c.CrossplaneDeploymentSpec.GenericDeploymentSpec, true
 	case KindCloudRunApp:
 		return c.CloudRunDeploymentSpec.GenericDeploymentSpec, true
 	case KindLambdaApp:
 		return c.LambdaDeploymentSpec.GenericDeploymentSpec, true
 	case KindECSApp:
 		return c.ECSDeploymentSpec.GenericDeploymentSpec, true
+	case KindCloudFunctionsApp:
+		return c.CloudFunctionsDeploymentSpec.GenericDeploymentSpec, true
+	case KindNomadApp:
+		return c.NomadDeploymentSpec.GenericDeploymentSpec, true
 	}
 	return GenericDeploymentSpec{}, false
 }
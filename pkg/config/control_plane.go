@@ -45,6 +45,10 @@ type ControlPlaneSpec struct {
 	Projects []ControlPlaneProject `json:"projects"`
 	// List of shared SSO configurations that can be used by any projects.
 	SharedSSOConfigs []SharedSSOConfig `json:"sharedSSOConfigs"`
+	// The configuration of the webhook endpoint receiving push events from Git providers.
+	// Pipeds watching the pushed repository are notified right away
+	// instead of waiting for their next periodic check.
+	GitWebhook ControlPlaneGitWebhook `json:"gitWebhook"`
 }
 
 func (s *ControlPlaneSpec) Validate() error {
@@ -67,6 +71,14 @@ type ProjectStaticUser struct {
 	PasswordHash string `json:"passwordHash"`
 }
 
+type ControlPlaneGitWebhook struct {
+	// The secret used to verify the incoming webhook requests.
+	// For GitHub and Bitbucket it is used to check the HMAC signature of the payload,
+	// for GitLab it is compared with the sent secret token.
+	// The webhook endpoint is disabled while this is empty.
+	Secret string `json:"secret"`
+}
+
 type SharedSSOConfig struct {
 	model.ProjectSSOConfig `json:",inline"`
 	Name                   string `json:"name"`
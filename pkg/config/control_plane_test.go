@@ -80,6 +80,9 @@ func TestControlPlaneConfig(t *testing.T) {
 				Cache: ControlPlaneCache{
 					TTL: Duration(5 * time.Minute),
 				},
+				GitWebhook: ControlPlaneGitWebhook{
+					Secret: "webhook-secret",
+				},
 				InsightCollector: ControlPlaneInsightCollector{
 					Application: InsightCollectorApplication{
 						Enabled:  true,
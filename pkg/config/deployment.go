@@ -17,6 +17,8 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"path"
+	"strings"
 	"time"
 
 	"github.com/pipe-cd/pipe/pkg/model"
@@ -24,7 +26,9 @@ import (
 
 const (
 	defaultWaitApprovalTimeout  = Duration(6 * time.Hour)
+	defaultMinApproverNum       = 1
 	defaultAnalysisQueryTimeout = Duration(30 * time.Second)
+	defaultScriptRunTimeout     = Duration(6 * time.Hour)
 )
 
 type GenericDeploymentSpec struct {
@@ -42,16 +46,50 @@ type GenericDeploymentSpec struct {
 	Timeout Duration `json:"timeout,omitempty" default:"6h"`
 	// List of encrypted secrets and targets that should be decoded before using.
 	Encryption *SecretEncryption `json:"encryption"`
+	// The time windows in which the deployments are allowed to run.
+	// A deployment triggered out of these windows is held until a window opens.
+	DeploymentWindows *DeploymentWindows `json:"deploymentWindows"`
+	// List of promotions to be done after the deployment succeeded,
+	// e.g. to update the configuration of the same application in the next environment.
+	// If the pipeline contains PROMOTE stages they are done by those stages instead.
+	Promotions []Promotion `json:"promotions"`
+	// How to handle a new deployment while other deployments of the same application
+	// are waiting or running.
+	Concurrency DeploymentConcurrency `json:"concurrency"`
+	// Configuration for the events those trigger new deployments of the application.
+	Trigger DeploymentTrigger `json:"trigger"`
+	// List of the names of applications in the same environment this application depends on.
+	// The deployment is held until the deployments of those applications
+	// for the same or a later commit were completed successfully.
+	DependsOn []string `json:"dependsOn"`
 }
 
 func (s *GenericDeploymentSpec) Validate() error {
 	if s.Pipeline != nil {
+		if err := s.Pipeline.Validate(); err != nil {
+			return err
+		}
 		for _, stage := range s.Pipeline.Stages {
+			if stage.WaitApprovalStageOptions != nil {
+				if err := stage.WaitApprovalStageOptions.Validate(); err != nil {
+					return err
+				}
+			}
 			if stage.AnalysisStageOptions != nil {
 				if err := stage.AnalysisStageOptions.Validate(); err != nil {
 					return err
 				}
 			}
+			if stage.ScriptRunStageOptions != nil {
+				if err := stage.ScriptRunStageOptions.Validate(); err != nil {
+					return err
+				}
+			}
+			if stage.PromoteStageOptions != nil {
+				if err := stage.PromoteStageOptions.Validate(s.Promotions); err != nil {
+					return err
+				}
+			}
 		}
 	}
 
@@ -61,9 +99,71 @@ func (s *GenericDeploymentSpec) Validate() error {
 		}
 	}
 
+	if w := s.DeploymentWindows; w != nil {
+		if err := w.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if err := validatePromotions(s.Promotions); err != nil {
+		return err
+	}
+
+	if err := s.Concurrency.Validate(); err != nil {
+		return err
+	}
+
+	if err := s.Trigger.Validate(); err != nil {
+		return err
+	}
+
+	names := make(map[string]struct{}, len(s.DependsOn))
+	for _, name := range s.DependsOn {
+		if name == "" {
+			return fmt.Errorf("dependsOn must not contain an empty application name")
+		}
+		if _, ok := names[name]; ok {
+			return fmt.Errorf("duplicated application %s in dependsOn", name)
+		}
+		names[name] = struct{}{}
+	}
+
 	return nil
 }
 
+// ValidateDependsOn checks that the given application does not depend on itself
+// directly or through a cycle of dependencies.
+// The dependencies are given as a map from an application name to the names of the applications it depends on.
+func ValidateDependsOn(app string, dependsOn map[string][]string) error {
+	var (
+		visiting = make(map[string]bool)
+		visited  = make(map[string]bool)
+		visit    func(name string, path []string) error
+	)
+	visit = func(name string, path []string) error {
+		path = append(path, name)
+		if visiting[name] {
+			return fmt.Errorf("circular dependency %s", strings.Join(path, " -> "))
+		}
+		if visited[name] {
+			return nil
+		}
+		visiting[name] = true
+		for _, dep := range dependsOn[name] {
+			if dep == name {
+				return fmt.Errorf("application %s must not depend on itself", name)
+			}
+			if err := visit(dep, path); err != nil {
+				return err
+			}
+		}
+		visiting[name] = false
+		visited[name] = true
+		return nil
+	}
+	return visit(app, nil)
+}
+
 func (s GenericDeploymentSpec) GetStage(index int32) (PipelineStage, bool) {
 	if s.Pipeline == nil {
 		return PipelineStage{}, false
@@ -93,6 +193,106 @@ type DeploymentCommitMatcher struct {
 	QuickSync string `json:"quickSync"`
 	// It makes sure to perform pipeline if the commit message matches this regular expression.
 	Pipeline string `json:"pipeline"`
+	// It makes sure to not trigger any deployment if the messages of all new commits
+	// since the last triggered one match this regular expression.
+	// e.g. \[skip deploy\]
+	Skip string `json:"skip"`
+}
+
+type DeploymentConcurrencyMode string
+
+const (
+	// Run the deployments one by one in the order they were triggered.
+	DeploymentConcurrencyModeQueue DeploymentConcurrencyMode = "queue"
+	// Cancel the waiting deployments in favor of the newest one.
+	// The running deployment is left to complete.
+	DeploymentConcurrencyModeSupersede DeploymentConcurrencyMode = "supersede"
+	// Cancel the waiting deployments as well as the running one (with rollback)
+	// so that the newest deployment can start as soon as possible.
+	DeploymentConcurrencyModeCancelRunning DeploymentConcurrencyMode = "cancel-running"
+)
+
+// DeploymentConcurrency represents the policy of handling multiple deployments
+// of the same application. The policy configured at the newest deployment is applied.
+type DeploymentConcurrency struct {
+	// The concurrency mode. Default is queue.
+	Mode DeploymentConcurrencyMode `json:"mode" default:"queue"`
+}
+
+func (c *DeploymentConcurrency) Validate() error {
+	switch c.Mode {
+	case "", DeploymentConcurrencyModeQueue, DeploymentConcurrencyModeSupersede, DeploymentConcurrencyModeCancelRunning:
+		return nil
+	default:
+		return fmt.Errorf("unsupported concurrency mode %q, must be one of %s, %s, %s",
+			c.Mode,
+			DeploymentConcurrencyModeQueue,
+			DeploymentConcurrencyModeSupersede,
+			DeploymentConcurrencyModeCancelRunning,
+		)
+	}
+}
+
+// DeploymentTrigger represents the configuration of
+// which events should trigger new deployments of the application.
+type DeploymentTrigger struct {
+	// Configuration for the deployments triggered by new commits.
+	OnCommit OnCommit `json:"onCommit"`
+	// Configuration for the deployments triggered
+	// when the application was detected as OUT_OF_SYNC.
+	OnOutOfSync OnOutOfSync `json:"onOutOfSync"`
+}
+
+func (t *DeploymentTrigger) Validate() error {
+	for _, tag := range t.OnCommit.Tags {
+		if _, err := path.Match(tag, ""); err != nil {
+			return fmt.Errorf("invalid tag pattern %q: %w", tag, err)
+		}
+	}
+	if t.OnOutOfSync.MinWindow < 0 {
+		return fmt.Errorf("onOutOfSync.minWindow must not be negative")
+	}
+	return nil
+}
+
+// OnCommit represents the configuration for triggering
+// new deployments by the commits pushed to the repository.
+type OnCommit struct {
+	// Whether to stop triggering the deployments by new commits.
+	// The application can still be synced manually from the web console.
+	// Default is false.
+	Disabled bool `json:"disabled"`
+	// List of tag patterns (e.g. v*) the new commit must be tagged with to be deployed.
+	// Empty means every new commit is deployed.
+	Tags []string `json:"tags"`
+}
+
+// MatchTags reports whether any of the given tags matches the configured patterns.
+// This always returns true when no pattern was configured.
+func (c OnCommit) MatchTags(tags []string) bool {
+	if len(c.Tags) == 0 {
+		return true
+	}
+	for _, pattern := range c.Tags {
+		for _, tag := range tags {
+			if ok, _ := path.Match(pattern, tag); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// OnOutOfSync represents the configuration for triggering
+// new deployments to resolve the configuration drift.
+type OnOutOfSync struct {
+	// Whether to automatically sync the application when it was detected as OUT_OF_SYNC.
+	// Default is false.
+	Enabled bool `json:"enabled"`
+	// The minimum duration since the drift was detected
+	// or the latest deployment was triggered before syncing it.
+	// Default is 5m.
+	MinWindow Duration `json:"minWindow" default:"5m"`
 }
 
 // DeploymentPipeline represents the way to deploy the application.
@@ -103,6 +303,31 @@ type DeploymentPipeline struct {
 	Stages []PipelineStage `json:"stages"`
 }
 
+// Validate checks that the needs of every stage refer to its preceding stages.
+// This guarantees that the stages form a directed acyclic graph
+// whose topological order is the order they were declared.
+func (p *DeploymentPipeline) Validate() error {
+	ids := make(map[string]struct{}, len(p.Stages))
+	for _, s := range p.Stages {
+		for _, n := range s.Needs {
+			if n == s.Id {
+				return fmt.Errorf("stage %s must not need itself", s.Id)
+			}
+			if _, ok := ids[n]; !ok {
+				return fmt.Errorf("stage %q needed by stage %q must be one of its preceding stages", n, s.Name)
+			}
+		}
+		if s.Id == "" {
+			continue
+		}
+		if _, ok := ids[s.Id]; ok {
+			return fmt.Errorf("duplicated stage id %s", s.Id)
+		}
+		ids[s.Id] = struct{}{}
+	}
+	return nil
+}
+
 // PipelineStage represents a single stage of a pipeline.
 // This is used as a generic struct for all stage type.
 type PipelineStage struct {
@@ -110,10 +335,15 @@ type PipelineStage struct {
 	Name    model.Stage
 	Desc    string
 	Timeout Duration
+	// The IDs of the preceding stages which must be completed successfully
+	// before running this stage. Empty means the stage right before this one.
+	Needs []string
 
 	WaitStageOptions         *WaitStageOptions
 	WaitApprovalStageOptions *WaitApprovalStageOptions
 	AnalysisStageOptions     *AnalysisStageOptions
+	ScriptRunStageOptions    *ScriptRunStageOptions
+	PromoteStageOptions      *PromoteStageOptions
 
 	K8sPrimaryRolloutStageOptions  *K8sPrimaryRolloutStageOptions
 	K8sCanaryRolloutStageOptions   *K8sCanaryRolloutStageOptions
@@ -138,6 +368,15 @@ type PipelineStage struct {
 	ECSPrimaryRolloutStageOptions *ECSPrimaryRolloutStageOptions
 	ECSCanaryCleanStageOptions    *ECSCanaryCleanStageOptions
 	ECSTrafficRoutingStageOptions *ECSTrafficRoutingStageOptions
+
+	CloudFunctionsSyncStageOptions    *CloudFunctionsSyncStageOptions
+	CloudFunctionsPromoteStageOptions *CloudFunctionsPromoteStageOptions
+
+	CrossplaneSyncStageOptions *CrossplaneSyncStageOptions
+
+	NomadSyncStageOptions          *NomadSyncStageOptions
+	NomadCanaryRolloutStageOptions *NomadCanaryRolloutStageOptions
+	NomadPromoteStageOptions       *NomadPromoteStageOptions
 }
 
 type genericPipelineStage struct {
@@ -145,6 +384,7 @@ type genericPipelineStage struct {
 	Name    model.Stage     `json:"name"`
 	Desc    string          `json:"desc,omitempty"`
 	Timeout Duration        `json:"timeout"`
+	Needs   []string        `json:"needs"`
 	With    json.RawMessage `json:"with"`
 }
 
@@ -158,6 +398,7 @@ func (s *PipelineStage) UnmarshalJSON(data []byte) error {
 	s.Name = gs.Name
 	s.Desc = gs.Desc
 	s.Timeout = gs.Timeout
+	s.Needs = gs.Needs
 
 	switch s.Name {
 	case model.StageWait:
@@ -173,6 +414,9 @@ func (s *PipelineStage) UnmarshalJSON(data []byte) error {
 		if s.WaitApprovalStageOptions.Timeout <= 0 {
 			s.WaitApprovalStageOptions.Timeout = defaultWaitApprovalTimeout
 		}
+		if s.WaitApprovalStageOptions.MinApproverNum == 0 {
+			s.WaitApprovalStageOptions.MinApproverNum = defaultMinApproverNum
+		}
 	case model.StageAnalysis:
 		s.AnalysisStageOptions = &AnalysisStageOptions{}
 		if len(gs.With) > 0 {
@@ -183,6 +427,19 @@ func (s *PipelineStage) UnmarshalJSON(data []byte) error {
 				s.AnalysisStageOptions.Metrics[i].Timeout = defaultAnalysisQueryTimeout
 			}
 		}
+	case model.StageScriptRun:
+		s.ScriptRunStageOptions = &ScriptRunStageOptions{}
+		if len(gs.With) > 0 {
+			err = json.Unmarshal(gs.With, s.ScriptRunStageOptions)
+		}
+		if s.ScriptRunStageOptions.Timeout <= 0 {
+			s.ScriptRunStageOptions.Timeout = defaultScriptRunTimeout
+		}
+	case model.StagePromote:
+		s.PromoteStageOptions = &PromoteStageOptions{}
+		if len(gs.With) > 0 {
+			err = json.Unmarshal(gs.With, s.PromoteStageOptions)
+		}
 	case model.StageK8sPrimaryRollout:
 		s.K8sPrimaryRolloutStageOptions = &K8sPrimaryRolloutStageOptions{}
 		if len(gs.With) > 0 {
@@ -283,6 +540,39 @@ func (s *PipelineStage) UnmarshalJSON(data []byte) error {
 			err = json.Unmarshal(gs.With, s.ECSTrafficRoutingStageOptions)
 		}
 
+	case model.StageCloudFunctionsSync:
+		s.CloudFunctionsSyncStageOptions = &CloudFunctionsSyncStageOptions{}
+		if len(gs.With) > 0 {
+			err = json.Unmarshal(gs.With, s.CloudFunctionsSyncStageOptions)
+		}
+	case model.StageCloudFunctionsPromote:
+		s.CloudFunctionsPromoteStageOptions = &CloudFunctionsPromoteStageOptions{}
+		if len(gs.With) > 0 {
+			err = json.Unmarshal(gs.With, s.CloudFunctionsPromoteStageOptions)
+		}
+
+	case model.StageCrossplaneSync:
+		s.CrossplaneSyncStageOptions = &CrossplaneSyncStageOptions{}
+		if len(gs.With) > 0 {
+			err = json.Unmarshal(gs.With, s.CrossplaneSyncStageOptions)
+		}
+
+	case model.StageNomadSync:
+		s.NomadSyncStageOptions = &NomadSyncStageOptions{}
+		if len(gs.With) > 0 {
+			err = json.Unmarshal(gs.With, s.NomadSyncStageOptions)
+		}
+	case model.StageNomadCanaryRollout:
+		s.NomadCanaryRolloutStageOptions = &NomadCanaryRolloutStageOptions{}
+		if len(gs.With) > 0 {
+			err = json.Unmarshal(gs.With, s.NomadCanaryRolloutStageOptions)
+		}
+	case model.StageNomadPromote:
+		s.NomadPromoteStageOptions = &NomadPromoteStageOptions{}
+		if len(gs.With) > 0 {
+			err = json.Unmarshal(gs.With, s.NomadPromoteStageOptions)
+		}
+
 	default:
 		err = fmt.Errorf("unsupported stage name: %s", s.Name)
 	}
@@ -298,8 +588,21 @@ type WaitStageOptions struct {
 type WaitApprovalStageOptions struct {
 	// The maximum length of time to wait before giving up.
 	// Defaults to 6h.
-	Timeout   Duration `json:"timeout"`
+	Timeout Duration `json:"timeout"`
+	// List of users or teams who can approve or reject the stage.
+	// A team is specified in "org/team" format.
+	// Empty means anyone in the project who has Editor or Admin role.
 	Approvers []string `json:"approvers"`
+	// The number of distinct approvers required to continue.
+	// Defaults to 1.
+	MinApproverNum int `json:"minApproverNum"`
+}
+
+func (o *WaitApprovalStageOptions) Validate() error {
+	if o.MinApproverNum < 0 {
+		return fmt.Errorf("minApproverNum of the WAIT_APPROVAL stage must not be negative")
+	}
+	return nil
 }
 
 // AnalysisStageOptions contains all configurable values for a K8S_ANALYSIS stage.
@@ -322,6 +625,44 @@ func (a *AnalysisStageOptions) Validate() error {
 	return nil
 }
 
+// ScriptRunStageOptions contains all configurable values for a SCRIPT_RUN stage.
+type ScriptRunStageOptions struct {
+	// The script to run. It is executed by sh in the application directory.
+	Run string `json:"run"`
+	// The environment variables for running the script.
+	// The encrypted secrets can be used in the value like {{ .encryptedSecrets.password }}.
+	Env map[string]string `json:"env"`
+	// The maximum length of time to run the script before giving up.
+	// Defaults to 6h.
+	Timeout Duration `json:"timeout"`
+	// The script to run while rolling back the deployment.
+	// Empty means nothing to do for this stage while rolling back.
+	OnRollback string `json:"onRollback"`
+}
+
+func (s *ScriptRunStageOptions) Validate() error {
+	if s.Run == "" {
+		return fmt.Errorf("the SCRIPT_RUN stage requires run field")
+	}
+	return nil
+}
+
+// PromoteStageOptions contains all configurable values for a PROMOTE stage.
+type PromoteStageOptions struct {
+	// The names of the promotions to be done by this stage.
+	// Empty means all promotions defined in the deployment configuration.
+	Promotions []string `json:"promotions"`
+}
+
+func (s *PromoteStageOptions) Validate(promotions []Promotion) error {
+	for _, name := range s.Promotions {
+		if _, ok := FindPromotion(promotions, name); !ok {
+			return fmt.Errorf("the PROMOTE stage refers to an undefined promotion %q", name)
+		}
+	}
+	return nil
+}
+
 type AnalysisTemplateRef struct {
 	Name string            `json:"name"`
 	Args map[string]string `json:"args"`
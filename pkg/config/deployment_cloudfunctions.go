@@ -0,0 +1,51 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+// CloudFunctionsDeploymentSpec represents a deployment configuration for Cloud Functions (2nd gen) application.
+type CloudFunctionsDeploymentSpec struct {
+	GenericDeploymentSpec
+	// Input for Cloud Functions deployment such as where to fetch source code...
+	Input CloudFunctionsDeploymentInput `json:"input"`
+	// Configuration for quick sync.
+	QuickSync CloudFunctionsSyncStageOptions `json:"quickSync"`
+}
+
+// Validate returns an error if any wrong configuration value was found.
+func (s *CloudFunctionsDeploymentSpec) Validate() error {
+	if err := s.GenericDeploymentSpec.Validate(); err != nil {
+		return err
+	}
+	return nil
+}
+
+type CloudFunctionsDeploymentInput struct {
+	// The name of function manifest file placing in application directory.
+	// Default is function.yaml
+	FunctionManifestFile string `json:"functionManifestFile"`
+	// Automatically reverts to the previous state when the deployment is failed.
+	// Default is true.
+	AutoRollback bool `json:"autoRollback" default:"true"`
+}
+
+// CloudFunctionsSyncStageOptions contains all configurable values for a CLOUDFUNCTIONS_SYNC stage.
+type CloudFunctionsSyncStageOptions struct {
+}
+
+// CloudFunctionsPromoteStageOptions contains all configurable values for a CLOUDFUNCTIONS_PROMOTE stage.
+type CloudFunctionsPromoteStageOptions struct {
+	// Percentage of traffic should be routed to the new version.
+	Percent Percentage `json:"percent"`
+}
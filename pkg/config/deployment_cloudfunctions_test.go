@@ -0,0 +1,114 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+func TestCloudFunctionsDeploymentConfig(t *testing.T) {
+	testcases := []struct {
+		fileName           string
+		expectedKind       Kind
+		expectedAPIVersion string
+		expectedSpec       interface{}
+		expectedError      error
+	}{
+		{
+			fileName:           "testdata/application/cloudfunctions-app.yaml",
+			expectedKind:       KindCloudFunctionsApp,
+			expectedAPIVersion: "pipecd.dev/v1beta1",
+			expectedSpec: &CloudFunctionsDeploymentSpec{
+				GenericDeploymentSpec: GenericDeploymentSpec{
+					Timeout: Duration(6 * time.Hour),
+					Concurrency: DeploymentConcurrency{
+						Mode: DeploymentConcurrencyModeQueue,
+					},
+					Trigger: DeploymentTrigger{
+						OnOutOfSync: OnOutOfSync{
+							MinWindow: Duration(5 * time.Minute),
+						},
+					},
+				},
+				Input: CloudFunctionsDeploymentInput{
+					FunctionManifestFile: "function.yaml",
+					AutoRollback:         true,
+				},
+			},
+			expectedError: nil,
+		},
+		{
+			fileName:           "testdata/application/cloudfunctions-app-canary.yaml",
+			expectedKind:       KindCloudFunctionsApp,
+			expectedAPIVersion: "pipecd.dev/v1beta1",
+			expectedSpec: &CloudFunctionsDeploymentSpec{
+				GenericDeploymentSpec: GenericDeploymentSpec{
+					Timeout: Duration(6 * time.Hour),
+					Concurrency: DeploymentConcurrency{
+						Mode: DeploymentConcurrencyModeQueue,
+					},
+					Trigger: DeploymentTrigger{
+						OnOutOfSync: OnOutOfSync{
+							MinWindow: Duration(5 * time.Minute),
+						},
+					},
+					Pipeline: &DeploymentPipeline{
+						Stages: []PipelineStage{
+							{
+								Name: model.StageCloudFunctionsPromote,
+								CloudFunctionsPromoteStageOptions: &CloudFunctionsPromoteStageOptions{
+									Percent: Percentage{Number: 10},
+								},
+							},
+							{
+								Name: model.StageWait,
+								WaitStageOptions: &WaitStageOptions{
+									Duration: Duration(10 * time.Minute),
+								},
+							},
+							{
+								Name: model.StageCloudFunctionsPromote,
+								CloudFunctionsPromoteStageOptions: &CloudFunctionsPromoteStageOptions{
+									Percent: Percentage{Number: 100},
+								},
+							},
+						},
+					},
+				},
+				Input: CloudFunctionsDeploymentInput{
+					AutoRollback: true,
+				},
+			},
+			expectedError: nil,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.fileName, func(t *testing.T) {
+			cfg, err := LoadFromYAML(tc.fileName)
+			require.Equal(t, tc.expectedError, err)
+			if err == nil {
+				assert.Equal(t, tc.expectedKind, cfg.Kind)
+				assert.Equal(t, tc.expectedAPIVersion, cfg.APIVersion)
+				assert.Equal(t, tc.expectedSpec, cfg.spec)
+			}
+		})
+	}
+}
@@ -14,6 +14,14 @@
 
 package config
 
+import (
+	"fmt"
+	"regexp"
+)
+
+// The tag is used as a part of the URL of the revision.
+var cloudRunTrafficTagRegex = regexp.MustCompile(`^[a-z]([-a-z0-9]*[a-z0-9])?$`)
+
 // CloudRunDeploymentSpec represents a deployment configuration for CloudRun application.
 type CloudRunDeploymentSpec struct {
 	GenericDeploymentSpec
@@ -28,6 +36,9 @@ func (s *CloudRunDeploymentSpec) Validate() error {
 	if err := s.GenericDeploymentSpec.Validate(); err != nil {
 		return err
 	}
+	if tag := s.Input.CanaryTrafficTag; tag != "" && !cloudRunTrafficTagRegex.MatchString(tag) {
+		return fmt.Errorf("canaryTrafficTag %q must consist of lower case letters, digits and '-', and start with a letter", tag)
+	}
 	return nil
 }
 
@@ -38,6 +49,11 @@ type CloudRunDeploymentInput struct {
 	// Automatically reverts to the previous state when the deployment is failed.
 	// Default is true.
 	AutoRollback bool `json:"autoRollback" default:"true"`
+	// The traffic tag to be given to the new revision while promoting it.
+	// The revision can be accessed directly via the URL of the tag,
+	// e.g. https://canary---service-name-xxx.a.run.app for "canary".
+	// Empty means no tag is given.
+	CanaryTrafficTag string `json:"canaryTrafficTag"`
 }
 
 // CloudRunSyncStageOptions contains all configurable values for a CLOUDRUN_SYNC stage.
@@ -37,6 +37,14 @@ func TestCloudRunDeploymentConfig(t *testing.T) {
 			expectedSpec: &CloudRunDeploymentSpec{
 				GenericDeploymentSpec: GenericDeploymentSpec{
 					Timeout: Duration(6 * time.Hour),
+					Concurrency: DeploymentConcurrency{
+						Mode: DeploymentConcurrencyModeQueue,
+					},
+					Trigger: DeploymentTrigger{
+						OnOutOfSync: OnOutOfSync{
+							MinWindow: Duration(5 * time.Minute),
+						},
+					},
 				},
 				Input: CloudRunDeploymentInput{
 					AutoRollback: true,
@@ -0,0 +1,42 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+// CrossplaneDeploymentSpec represents a deployment configuration for Crossplane application.
+// The Crossplane resources are rendered and applied in the same way as the Kubernetes manifests.
+type CrossplaneDeploymentSpec struct {
+	GenericDeploymentSpec
+	// Input for Crossplane deployment such as kubectl version, manifests filter...
+	Input KubernetesDeploymentInput `json:"input"`
+	// Configuration for quick sync.
+	QuickSync CrossplaneSyncStageOptions `json:"quickSync"`
+}
+
+// Validate returns an error if any wrong configuration value was found.
+func (s *CrossplaneDeploymentSpec) Validate() error {
+	if err := s.GenericDeploymentSpec.Validate(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// CrossplaneSyncStageOptions contains all configurable values for a CROSSPLANE_SYNC stage.
+type CrossplaneSyncStageOptions struct {
+	// Whether the resources that are no longer defined in Git should be removed or not.
+	Prune bool `json:"prune"`
+	// How long to wait for the applied resources to be ready and synced.
+	// Default is 30m.
+	Timeout Duration `json:"timeout" default:"30m"`
+}
@@ -0,0 +1,118 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+func TestCrossplaneDeploymentConfig(t *testing.T) {
+	testcases := []struct {
+		fileName           string
+		expectedKind       Kind
+		expectedAPIVersion string
+		expectedSpec       interface{}
+		expectedError      error
+	}{
+		{
+			fileName:           "testdata/application/crossplane-app.yaml",
+			expectedKind:       KindCrossplaneApp,
+			expectedAPIVersion: "pipecd.dev/v1beta1",
+			expectedSpec: &CrossplaneDeploymentSpec{
+				GenericDeploymentSpec: GenericDeploymentSpec{
+					Timeout: Duration(6 * time.Hour),
+					Concurrency: DeploymentConcurrency{
+						Mode: DeploymentConcurrencyModeQueue,
+					},
+					Trigger: DeploymentTrigger{
+						OnOutOfSync: OnOutOfSync{
+							MinWindow: Duration(5 * time.Minute),
+						},
+					},
+				},
+				Input: KubernetesDeploymentInput{
+					Manifests:      []string{"bucket.yaml", "database.yaml"},
+					KubectlVersion: "1.18.5",
+					AutoRollback:   true,
+				},
+				QuickSync: CrossplaneSyncStageOptions{
+					Prune:   true,
+					Timeout: Duration(30 * time.Minute),
+				},
+			},
+			expectedError: nil,
+		},
+		{
+			fileName:           "testdata/application/crossplane-app-with-approval.yaml",
+			expectedKind:       KindCrossplaneApp,
+			expectedAPIVersion: "pipecd.dev/v1beta1",
+			expectedSpec: &CrossplaneDeploymentSpec{
+				GenericDeploymentSpec: GenericDeploymentSpec{
+					Timeout: Duration(6 * time.Hour),
+					Concurrency: DeploymentConcurrency{
+						Mode: DeploymentConcurrencyModeQueue,
+					},
+					Trigger: DeploymentTrigger{
+						OnOutOfSync: OnOutOfSync{
+							MinWindow: Duration(5 * time.Minute),
+						},
+					},
+					Pipeline: &DeploymentPipeline{
+						Stages: []PipelineStage{
+							{
+								Name: model.StageWaitApproval,
+								WaitApprovalStageOptions: &WaitApprovalStageOptions{
+									Approvers:      []string{"foo"},
+									Timeout:        defaultWaitApprovalTimeout,
+									MinApproverNum: defaultMinApproverNum,
+								},
+							},
+							{
+								Name: model.StageCrossplaneSync,
+								CrossplaneSyncStageOptions: &CrossplaneSyncStageOptions{
+									Timeout: Duration(time.Hour),
+								},
+							},
+						},
+					},
+				},
+				Input: KubernetesDeploymentInput{
+					AutoRollback: true,
+				},
+				QuickSync: CrossplaneSyncStageOptions{
+					Timeout: Duration(30 * time.Minute),
+				},
+			},
+			expectedError: nil,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.fileName, func(t *testing.T) {
+			cfg, err := LoadFromYAML(tc.fileName)
+			require.Equal(t, tc.expectedError, err)
+			if err == nil {
+				assert.Equal(t, tc.expectedKind, cfg.Kind)
+				assert.Equal(t, tc.expectedAPIVersion, cfg.APIVersion)
+				assert.Equal(t, tc.expectedSpec, cfg.spec)
+			}
+		})
+	}
+}
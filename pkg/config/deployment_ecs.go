@@ -14,7 +14,12 @@
 
 package config
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const maxTaskDefinitionRevisionHistoryLimit = 25
 
 // ECSDeploymentSpec represents a deployment configuration for ECS application.
 type ECSDeploymentSpec struct {
@@ -30,6 +35,13 @@ func (s *ECSDeploymentSpec) Validate() error {
 	if err := s.GenericDeploymentSpec.Validate(); err != nil {
 		return err
 	}
+	if s.Input.TaskDefinitionRevisionHistoryLimit < 0 {
+		return fmt.Errorf("taskDefinitionRevisionHistoryLimit must not be negative")
+	}
+	// The revisions are recorded in a tag of the service whose value is limited to 256 characters.
+	if s.Input.TaskDefinitionRevisionHistoryLimit > maxTaskDefinitionRevisionHistoryLimit {
+		return fmt.Errorf("taskDefinitionRevisionHistoryLimit must not be greater than %d", maxTaskDefinitionRevisionHistoryLimit)
+	}
 	return nil
 }
 
@@ -45,6 +57,11 @@ type ECSDeploymentInput struct {
 	// Automatically reverts all changes from all stages when one of them failed.
 	// Default is true.
 	AutoRollback bool `json:"autoRollback" default:"true"`
+	// The number of latest successful deployments whose task definition revisions should be kept.
+	// After a deployment succeeded, the other revisions of the task definition family
+	// older than the newest kept one are deregistered.
+	// Zero means all revisions are kept. Maximum is 25. Default is 0.
+	TaskDefinitionRevisionHistoryLimit int `json:"taskDefinitionRevisionHistoryLimit"`
 }
 
 type ECSTargetGroups struct {
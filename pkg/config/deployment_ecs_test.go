@@ -38,6 +38,14 @@ func TestECSDeploymentConfig(t *testing.T) {
 			expectedSpec: &ECSDeploymentSpec{
 				GenericDeploymentSpec: GenericDeploymentSpec{
 					Timeout: Duration(6 * time.Hour),
+					Concurrency: DeploymentConcurrency{
+						Mode: DeploymentConcurrencyModeQueue,
+					},
+					Trigger: DeploymentTrigger{
+						OnOutOfSync: OnOutOfSync{
+							MinWindow: Duration(5 * time.Minute),
+						},
+					},
 				},
 				Input: ECSDeploymentInput{
 					ServiceDefinitionFile: "/path/to/servicedef.yaml",
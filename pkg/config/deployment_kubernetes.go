@@ -14,6 +14,12 @@
 
 package config
 
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
 // KubernetesDeploymentSpec represents a deployment configuration for Kubernetes application.
 type KubernetesDeploymentSpec struct {
 	GenericDeploymentSpec
@@ -34,6 +40,12 @@ type KubernetesDeploymentSpec struct {
 	Workloads []K8sResourceReference `json:"workloads"`
 	// Which method should be used for traffic routing.
 	TrafficRouting *KubernetesTrafficRouting `json:"trafficRouting"`
+	// List of clusters where the application should be deployed to.
+	// Empty means the application is deployed only to the cloud provider
+	// specified while registering the application.
+	Targets []KubernetesDeploymentTarget `json:"targets"`
+	// How the stages should be executed across the targets.
+	TargetsExecution KubernetesTargetsExecution `json:"targetsExecution"`
 }
 
 // Validate returns an error if any wrong configuration value was found.
@@ -41,9 +53,101 @@ func (s *KubernetesDeploymentSpec) Validate() error {
 	if err := s.GenericDeploymentSpec.Validate(); err != nil {
 		return err
 	}
+	names := make(map[string]struct{}, len(s.Targets))
+	for _, t := range s.Targets {
+		if err := t.Validate(); err != nil {
+			return err
+		}
+		if _, ok := names[t.Name]; ok {
+			return fmt.Errorf("duplicate target name %s", t.Name)
+		}
+		names[t.Name] = struct{}{}
+	}
+	if err := s.TargetsExecution.Validate(); err != nil {
+		return err
+	}
 	return nil
 }
 
+// KubernetesDeploymentTarget represents a cluster where the application is deployed to
+// and the overrides applied to the input for that cluster.
+type KubernetesDeploymentTarget struct {
+	// The unique name of the target.
+	Name string `json:"name"`
+	// The name of the Kubernetes cloud provider configured in the piped.
+	CloudProvider string `json:"cloudProvider"`
+	// The namespace where manifests will be applied.
+	// Empty means the namespace specified in the input.
+	Namespace string `json:"namespace"`
+	// Relative path from the application directory to the kustomize overlay directory
+	// used to render the manifests for this target.
+	KustomizeDir string `json:"kustomizeDir"`
+	// List of helm value files used for this target instead of the ones specified in the input.
+	HelmValueFiles []string `json:"helmValueFiles"`
+}
+
+func (t KubernetesDeploymentTarget) Validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("name field of target must not be empty")
+	}
+	if t.CloudProvider == "" {
+		return fmt.Errorf("cloudProvider field of target %s must not be empty", t.Name)
+	}
+	if t.KustomizeDir != "" {
+		dir := filepath.Clean(t.KustomizeDir)
+		if filepath.IsAbs(dir) || dir == ".." || strings.HasPrefix(dir, "../") {
+			return fmt.Errorf("kustomizeDir of target %s must be a relative path inside the application directory", t.Name)
+		}
+	}
+	return nil
+}
+
+// ApplyTo returns a copy of the given input with the overrides of this target applied.
+func (t KubernetesDeploymentTarget) ApplyTo(in KubernetesDeploymentInput) KubernetesDeploymentInput {
+	if t.Namespace != "" {
+		in.Namespace = t.Namespace
+	}
+	if len(t.HelmValueFiles) > 0 {
+		opts := InputHelmOptions{}
+		if in.HelmOptions != nil {
+			opts = *in.HelmOptions
+		}
+		opts.ValueFiles = t.HelmValueFiles
+		in.HelmOptions = &opts
+	}
+	return in
+}
+
+type KubernetesTargetsFailurePolicy string
+
+const (
+	// Stop executing the stage on the remaining targets as soon as it failed on one of them.
+	KubernetesTargetsFailurePolicyFailFast KubernetesTargetsFailurePolicy = "FAIL_FAST"
+	// Continue executing the stage on the remaining targets even if it failed on one of them.
+	KubernetesTargetsFailurePolicyContinue KubernetesTargetsFailurePolicy = "CONTINUE"
+)
+
+// KubernetesTargetsExecution represents how each stage is executed across the targets.
+// The stage is marked as failure when it failed on any target.
+type KubernetesTargetsExecution struct {
+	// Whether the stage should be executed on all targets at the same time.
+	// Default is false, it means the targets are handled one by one in the specified order.
+	Parallel bool `json:"parallel"`
+	// What to do when the stage failed on a target.
+	// Available values are "FAIL_FAST" and "CONTINUE".
+	// Default is "FAIL_FAST".
+	FailurePolicy KubernetesTargetsFailurePolicy `json:"failurePolicy" default:"FAIL_FAST"`
+}
+
+func (e KubernetesTargetsExecution) Validate() error {
+	switch e.FailurePolicy {
+	case KubernetesTargetsFailurePolicyFailFast, KubernetesTargetsFailurePolicyContinue:
+		return nil
+	default:
+		return fmt.Errorf("unsupported failurePolicy %q of targetsExecution", e.FailurePolicy)
+	}
+}
+
 // KubernetesDeploymentInput represents needed input for triggering a Kubernetes deployment.
 type KubernetesDeploymentInput struct {
 	// List of manifest files in the application directory used to deploy.
@@ -76,6 +76,14 @@ func TestKubernetesDeploymentConfig(t *testing.T) {
 						},
 					},
 					Timeout: Duration(6 * time.Hour),
+					Concurrency: DeploymentConcurrency{
+						Mode: DeploymentConcurrencyModeQueue,
+					},
+					Trigger: DeploymentTrigger{
+						OnOutOfSync: OnOutOfSync{
+							MinWindow: Duration(5 * time.Minute),
+						},
+					},
 				},
 				Input: KubernetesDeploymentInput{
 					AutoRollback: true,
@@ -83,6 +91,49 @@ func TestKubernetesDeploymentConfig(t *testing.T) {
 				TrafficRouting: &KubernetesTrafficRouting{
 					Method: KubernetesTrafficRoutingMethodPodSelector,
 				},
+				TargetsExecution: KubernetesTargetsExecution{
+					FailurePolicy: KubernetesTargetsFailurePolicyFailFast,
+				},
+			},
+			expectedError: nil,
+		},
+		{
+			fileName:           "testdata/application/k8s-app-multi-cluster.yaml",
+			expectedKind:       KindKubernetesApp,
+			expectedAPIVersion: "pipecd.dev/v1beta1",
+			expectedSpec: &KubernetesDeploymentSpec{
+				GenericDeploymentSpec: GenericDeploymentSpec{
+					Timeout: Duration(6 * time.Hour),
+					Concurrency: DeploymentConcurrency{
+						Mode: DeploymentConcurrencyModeQueue,
+					},
+					Trigger: DeploymentTrigger{
+						OnOutOfSync: OnOutOfSync{
+							MinWindow: Duration(5 * time.Minute),
+						},
+					},
+				},
+				Input: KubernetesDeploymentInput{
+					Namespace:    "default",
+					AutoRollback: true,
+				},
+				Targets: []KubernetesDeploymentTarget{
+					{
+						Name:          "asia",
+						CloudProvider: "kubernetes-asia",
+						KustomizeDir:  "overlays/asia",
+					},
+					{
+						Name:           "us",
+						CloudProvider:  "kubernetes-us",
+						Namespace:      "us",
+						HelmValueFiles: []string{"values-us.yaml"},
+					},
+				},
+				TargetsExecution: KubernetesTargetsExecution{
+					Parallel:      true,
+					FailurePolicy: KubernetesTargetsFailurePolicyContinue,
+				},
 			},
 			expectedError: nil,
 		},
@@ -133,3 +184,91 @@ func TestKubernetesDeploymentConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestKubernetesDeploymentSpecValidate(t *testing.T) {
+	testcases := []struct {
+		name    string
+		spec    KubernetesDeploymentSpec
+		wantErr bool
+	}{
+		{
+			name: "no target",
+			spec: KubernetesDeploymentSpec{
+				TargetsExecution: KubernetesTargetsExecution{FailurePolicy: KubernetesTargetsFailurePolicyFailFast},
+			},
+		},
+		{
+			name: "valid targets",
+			spec: KubernetesDeploymentSpec{
+				Targets: []KubernetesDeploymentTarget{
+					{Name: "asia", CloudProvider: "kubernetes-asia", KustomizeDir: "overlays/asia"},
+					{Name: "us", CloudProvider: "kubernetes-us"},
+				},
+				TargetsExecution: KubernetesTargetsExecution{FailurePolicy: KubernetesTargetsFailurePolicyContinue},
+			},
+		},
+		{
+			name: "duplicate target name",
+			spec: KubernetesDeploymentSpec{
+				Targets: []KubernetesDeploymentTarget{
+					{Name: "asia", CloudProvider: "kubernetes-asia"},
+					{Name: "asia", CloudProvider: "kubernetes-us"},
+				},
+				TargetsExecution: KubernetesTargetsExecution{FailurePolicy: KubernetesTargetsFailurePolicyFailFast},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing cloud provider",
+			spec: KubernetesDeploymentSpec{
+				Targets: []KubernetesDeploymentTarget{
+					{Name: "asia"},
+				},
+				TargetsExecution: KubernetesTargetsExecution{FailurePolicy: KubernetesTargetsFailurePolicyFailFast},
+			},
+			wantErr: true,
+		},
+		{
+			name: "kustomize dir outside the application directory",
+			spec: KubernetesDeploymentSpec{
+				Targets: []KubernetesDeploymentTarget{
+					{Name: "asia", CloudProvider: "kubernetes-asia", KustomizeDir: "../overlays/asia"},
+				},
+				TargetsExecution: KubernetesTargetsExecution{FailurePolicy: KubernetesTargetsFailurePolicyFailFast},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported failure policy",
+			spec: KubernetesDeploymentSpec{
+				TargetsExecution: KubernetesTargetsExecution{FailurePolicy: "IGNORE"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.spec.Validate()
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
+
+func TestKubernetesDeploymentTargetApplyTo(t *testing.T) {
+	in := KubernetesDeploymentInput{
+		Namespace: "default",
+		HelmOptions: &InputHelmOptions{
+			ReleaseName: "release",
+			ValueFiles:  []string{"values.yaml"},
+		},
+	}
+
+	got := KubernetesDeploymentTarget{Name: "asia"}.ApplyTo(in)
+	assert.Equal(t, in, got)
+
+	got = KubernetesDeploymentTarget{Name: "us", Namespace: "us", HelmValueFiles: []string{"values-us.yaml"}}.ApplyTo(in)
+	assert.Equal(t, "us", got.Namespace)
+	assert.Equal(t, &InputHelmOptions{ReleaseName: "release", ValueFiles: []string{"values-us.yaml"}}, got.HelmOptions)
+	// The given input must not be modified.
+	assert.Equal(t, []string{"values.yaml"}, in.HelmOptions.ValueFiles)
+}
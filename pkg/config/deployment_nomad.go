@@ -0,0 +1,54 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+// NomadDeploymentSpec represents a deployment configuration for Nomad application.
+type NomadDeploymentSpec struct {
+	GenericDeploymentSpec
+	// Input for Nomad deployment such as where to find the job file...
+	Input NomadDeploymentInput `json:"input"`
+	// Configuration for quick sync.
+	QuickSync NomadSyncStageOptions `json:"quickSync"`
+}
+
+// Validate returns an error if any wrong configuration value was found.
+func (s *NomadDeploymentSpec) Validate() error {
+	if err := s.GenericDeploymentSpec.Validate(); err != nil {
+		return err
+	}
+	return nil
+}
+
+type NomadDeploymentInput struct {
+	// The name of job file placing in application directory.
+	// Both HCL and JSON formats are supported.
+	// Default is job.nomad
+	JobFile string `json:"jobFile"`
+	// Automatically reverts to the previous state when the deployment is failed.
+	// Default is true.
+	AutoRollback bool `json:"autoRollback" default:"true"`
+}
+
+// NomadSyncStageOptions contains all configurable values for a NOMAD_SYNC stage.
+type NomadSyncStageOptions struct {
+}
+
+// NomadCanaryRolloutStageOptions contains all configurable values for a NOMAD_CANARY_ROLLOUT stage.
+type NomadCanaryRolloutStageOptions struct {
+}
+
+// NomadPromoteStageOptions contains all configurable values for a NOMAD_PROMOTE stage.
+type NomadPromoteStageOptions struct {
+}
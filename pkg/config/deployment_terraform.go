@@ -14,6 +14,12 @@
 
 package config
 
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
 // TerraformDeploymentSpec represents a deployment configuration for Terraform application.
 type TerraformDeploymentSpec struct {
 	GenericDeploymentSpec
@@ -28,6 +34,15 @@ func (s *TerraformDeploymentSpec) Validate() error {
 	if err := s.GenericDeploymentSpec.Validate(); err != nil {
 		return err
 	}
+	if s.Pipeline != nil {
+		for _, stage := range s.Pipeline.Stages {
+			if stage.TerraformPlanStageOptions != nil {
+				if err := stage.TerraformPlanStageOptions.Validate(); err != nil {
+					return err
+				}
+			}
+		}
+	}
 	return nil
 }
 
@@ -59,6 +74,59 @@ type TerraformSyncStageOptions struct {
 
 // TerraformPlanStageOptions contains all configurable values for a TERRAFORM_PLAN stage.
 type TerraformPlanStageOptions struct {
+	// List of policies the planned changes must satisfy.
+	// The stage fails when any of them was violated.
+	Policies []TerraformPlanPolicy `json:"policies"`
+}
+
+func (o *TerraformPlanStageOptions) Validate() error {
+	for _, p := range o.Policies {
+		if err := p.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const (
+	TerraformActionCreate = "create"
+	TerraformActionUpdate = "update"
+	TerraformActionDelete = "delete"
+)
+
+// TerraformPlanPolicy represents a policy written in CEL to check the planned changes.
+// The policy is satisfied when its expression evaluates to true.
+// The planned changes can be referred as "plan" variable in the expression,
+// which is the output of "terraform show -json" command against the saved plan file.
+// e.g. plan.resource_changes.filter(c, "delete" in c.change.actions).size() <= 3
+type TerraformPlanPolicy struct {
+	// The name of the policy.
+	Name string `json:"name"`
+	// The CEL expression of the policy.
+	Condition string `json:"condition"`
+	// The relative path from the application directory to the file
+	// containing the CEL expression of the policy.
+	// Either condition or file must be specified.
+	File string `json:"file"`
+}
+
+func (p TerraformPlanPolicy) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("name field of terraform plan policy must not be empty")
+	}
+	if (p.Condition == "") == (p.File == "") {
+		return fmt.Errorf("either condition or file of terraform plan policy %s must be specified", p.Name)
+	}
+	if p.File == "" {
+		return nil
+	}
+	if path.IsAbs(p.File) {
+		return fmt.Errorf("file %s of terraform plan policy %s must be a relative path", p.File, p.Name)
+	}
+	if f := path.Clean(p.File); f == ".." || strings.HasPrefix(f, "../") {
+		return fmt.Errorf("file %s of terraform plan policy %s must not point outside of the application directory", p.File, p.Name)
+	}
+	return nil
 }
 
 // TerraformApplyStageOptions contains all configurable values for a TERRAFORM_APPLY stage.
@@ -39,6 +39,14 @@ func TestTerraformDeploymentConfig(t *testing.T) {
 			expectedSpec: &TerraformDeploymentSpec{
 				GenericDeploymentSpec: GenericDeploymentSpec{
 					Timeout: Duration(6 * time.Hour),
+					Concurrency: DeploymentConcurrency{
+						Mode: DeploymentConcurrencyModeQueue,
+					},
+					Trigger: DeploymentTrigger{
+						OnOutOfSync: OnOutOfSync{
+							MinWindow: Duration(5 * time.Minute),
+						},
+					},
 				},
 				Input: TerraformDeploymentInput{},
 			},
@@ -51,6 +59,14 @@ func TestTerraformDeploymentConfig(t *testing.T) {
 			expectedSpec: &TerraformDeploymentSpec{
 				GenericDeploymentSpec: GenericDeploymentSpec{
 					Timeout: Duration(6 * time.Hour),
+					Concurrency: DeploymentConcurrency{
+						Mode: DeploymentConcurrencyModeQueue,
+					},
+					Trigger: DeploymentTrigger{
+						OnOutOfSync: OnOutOfSync{
+							MinWindow: Duration(5 * time.Minute),
+						},
+					},
 				},
 				Input: TerraformDeploymentInput{
 					Workspace:        "dev",
@@ -73,6 +89,14 @@ func TestTerraformDeploymentConfig(t *testing.T) {
 						},
 					},
 					Timeout: Duration(6 * time.Hour),
+					Concurrency: DeploymentConcurrency{
+						Mode: DeploymentConcurrencyModeQueue,
+					},
+					Trigger: DeploymentTrigger{
+						OnOutOfSync: OnOutOfSync{
+							MinWindow: Duration(5 * time.Minute),
+						},
+					},
 				},
 				Input: TerraformDeploymentInput{
 					Workspace:        "dev",
@@ -98,7 +122,8 @@ func TestTerraformDeploymentConfig(t *testing.T) {
 								WaitApprovalStageOptions: &WaitApprovalStageOptions{
 									Approvers: []string{"foo", "bar"},
 									// Use defaultWaitApprovalTimeout on unset timeout value for WaitApprovalStage.
-									Timeout: defaultWaitApprovalTimeout,
+									Timeout:        defaultWaitApprovalTimeout,
+									MinApproverNum: 2,
 								},
 							},
 							{
@@ -108,6 +133,14 @@ func TestTerraformDeploymentConfig(t *testing.T) {
 						},
 					},
 					Timeout: Duration(6 * time.Hour),
+					Concurrency: DeploymentConcurrency{
+						Mode: DeploymentConcurrencyModeQueue,
+					},
+					Trigger: DeploymentTrigger{
+						OnOutOfSync: OnOutOfSync{
+							MinWindow: Duration(5 * time.Minute),
+						},
+					},
 				},
 				Input: TerraformDeploymentInput{
 					Workspace:        "dev",
@@ -129,3 +162,51 @@ func TestTerraformDeploymentConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestTerraformPlanPolicyValidate(t *testing.T) {
+	testcases := []struct {
+		name        string
+		policy      TerraformPlanPolicy
+		expectedErr bool
+	}{
+		{
+			name:   "condition",
+			policy: TerraformPlanPolicy{Name: "no-destroy", Condition: "true"},
+		},
+		{
+			name:   "file",
+			policy: TerraformPlanPolicy{Name: "no-destroy", File: "policies/../no-destroy.cel"},
+		},
+		{
+			name:        "missing name",
+			policy:      TerraformPlanPolicy{Condition: "true"},
+			expectedErr: true,
+		},
+		{
+			name:        "both condition and file",
+			policy:      TerraformPlanPolicy{Name: "no-destroy", Condition: "true", File: "no-destroy.cel"},
+			expectedErr: true,
+		},
+		{
+			name:        "neither condition nor file",
+			policy:      TerraformPlanPolicy{Name: "no-destroy"},
+			expectedErr: true,
+		},
+		{
+			name:        "absolute file",
+			policy:      TerraformPlanPolicy{Name: "no-destroy", File: "/etc/passwd"},
+			expectedErr: true,
+		},
+		{
+			name:        "file outside of the application directory",
+			policy:      TerraformPlanPolicy{Name: "no-destroy", File: "policies/../../secret.cel"},
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.policy.Validate()
+			assert.Equal(t, tc.expectedErr, err != nil)
+		})
+	}
+}
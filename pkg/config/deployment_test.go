@@ -15,9 +15,12 @@
 package config
 
 import (
+	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/pipe-cd/pipe/pkg/model"
 )
@@ -71,3 +74,289 @@ func TestHasStage(t *testing.T) {
 		})
 	}
 }
+
+func TestScriptRunStageOptions(t *testing.T) {
+	testcases := []struct {
+		name        string
+		data        string
+		expected    *ScriptRunStageOptions
+		expectedErr bool
+	}{
+		{
+			name: "default timeout",
+			data: `{"name": "SCRIPT_RUN", "with": {"run": "make smoke-test"}}`,
+			expected: &ScriptRunStageOptions{
+				Run:     "make smoke-test",
+				Timeout: Duration(6 * time.Hour),
+			},
+		},
+		{
+			name: "all fields",
+			data: `{"name": "SCRIPT_RUN", "with": {"run": "./release.sh", "env": {"TOKEN": "{{ .encryptedSecrets.token }}"}, "timeout": "10m", "onRollback": "./unrelease.sh"}}`,
+			expected: &ScriptRunStageOptions{
+				Run: "./release.sh",
+				Env: map[string]string{
+					"TOKEN": "{{ .encryptedSecrets.token }}",
+				},
+				Timeout:    Duration(10 * time.Minute),
+				OnRollback: "./unrelease.sh",
+			},
+		},
+		{
+			name:        "missing run",
+			data:        `{"name": "SCRIPT_RUN", "with": {"onRollback": "./unrelease.sh"}}`,
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var stage PipelineStage
+			require.NoError(t, json.Unmarshal([]byte(tc.data), &stage))
+
+			err := stage.ScriptRunStageOptions.Validate()
+			assert.Equal(t, tc.expectedErr, err != nil)
+			if err == nil {
+				assert.Equal(t, tc.expected, stage.ScriptRunStageOptions)
+			}
+		})
+	}
+}
+
+func TestDeploymentPipelineValidate(t *testing.T) {
+	testcases := []struct {
+		name        string
+		data        string
+		expectedErr bool
+	}{
+		{
+			name: "sequential stages",
+			data: `{"stages": [{"name": "K8S_CANARY_ROLLOUT"}, {"name": "K8S_PRIMARY_ROLLOUT"}]}`,
+		},
+		{
+			name: "parallel stages",
+			data: `{"stages": [
+				{"id": "canary", "name": "K8S_CANARY_ROLLOUT"},
+				{"id": "analysis", "name": "ANALYSIS", "needs": ["canary"]},
+				{"id": "smoke-test", "name": "SCRIPT_RUN", "needs": ["canary"], "with": {"run": "make smoke-test"}},
+				{"name": "K8S_PRIMARY_ROLLOUT", "needs": ["analysis", "smoke-test"]}
+			]}`,
+		},
+		{
+			name: "need a following stage",
+			data: `{"stages": [
+				{"id": "canary", "name": "K8S_CANARY_ROLLOUT", "needs": ["primary"]},
+				{"id": "primary", "name": "K8S_PRIMARY_ROLLOUT"}
+			]}`,
+			expectedErr: true,
+		},
+		{
+			name: "need itself",
+			data: `{"stages": [
+				{"id": "canary", "name": "K8S_CANARY_ROLLOUT", "needs": ["canary"]}
+			]}`,
+			expectedErr: true,
+		},
+		{
+			name: "duplicated stage id",
+			data: `{"stages": [
+				{"id": "canary", "name": "K8S_CANARY_ROLLOUT"},
+				{"id": "canary", "name": "K8S_PRIMARY_ROLLOUT"}
+			]}`,
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var p DeploymentPipeline
+			require.NoError(t, json.Unmarshal([]byte(tc.data), &p))
+
+			err := p.Validate()
+			assert.Equal(t, tc.expectedErr, err != nil)
+		})
+	}
+}
+
+func TestDeploymentConcurrencyValidate(t *testing.T) {
+	testcases := []struct {
+		mode        DeploymentConcurrencyMode
+		expectedErr bool
+	}{
+		{mode: ""},
+		{mode: DeploymentConcurrencyModeQueue},
+		{mode: DeploymentConcurrencyModeSupersede},
+		{mode: DeploymentConcurrencyModeCancelRunning},
+		{mode: "cancel", expectedErr: true},
+	}
+	for _, tc := range testcases {
+		t.Run(string(tc.mode), func(t *testing.T) {
+			c := DeploymentConcurrency{Mode: tc.mode}
+			err := c.Validate()
+			assert.Equal(t, tc.expectedErr, err != nil)
+		})
+	}
+}
+
+func TestDeploymentTriggerValidate(t *testing.T) {
+	testcases := []struct {
+		name        string
+		trigger     DeploymentTrigger
+		expectedErr bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "valid tag patterns",
+			trigger: DeploymentTrigger{
+				OnCommit: OnCommit{Tags: []string{"v*", "release-[0-9]*"}},
+			},
+		},
+		{
+			name: "invalid tag pattern",
+			trigger: DeploymentTrigger{
+				OnCommit: OnCommit{Tags: []string{"v[0-9"}},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "negative min window",
+			trigger: DeploymentTrigger{
+				OnOutOfSync: OnOutOfSync{Enabled: true, MinWindow: Duration(-time.Minute)},
+			},
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.trigger.Validate()
+			assert.Equal(t, tc.expectedErr, err != nil)
+		})
+	}
+}
+
+func TestOnCommitMatchTags(t *testing.T) {
+	testcases := []struct {
+		name     string
+		patterns []string
+		tags     []string
+		expected bool
+	}{
+		{
+			name:     "no pattern",
+			expected: true,
+		},
+		{
+			name:     "no tag",
+			patterns: []string{"v*"},
+		},
+		{
+			name:     "matched",
+			patterns: []string{"release-*", "v*"},
+			tags:     []string{"latest", "v1.0.0"},
+			expected: true,
+		},
+		{
+			name:     "unmatched",
+			patterns: []string{"v*"},
+			tags:     []string{"latest", "release-1.0"},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := OnCommit{Tags: tc.patterns}
+			assert.Equal(t, tc.expected, c.MatchTags(tc.tags))
+		})
+	}
+}
+
+func TestValidateDependsOn(t *testing.T) {
+	testcases := []struct {
+		name        string
+		dependsOn   []string
+		expectedErr bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:      "valid",
+			dependsOn: []string{"backend", "migration"},
+		},
+		{
+			name:        "empty name",
+			dependsOn:   []string{"backend", ""},
+			expectedErr: true,
+		},
+		{
+			name:        "duplicated name",
+			dependsOn:   []string{"backend", "backend"},
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := GenericDeploymentSpec{DependsOn: tc.dependsOn}
+			err := s.Validate()
+			assert.Equal(t, tc.expectedErr, err != nil)
+		})
+	}
+}
+
+func TestValidateDependsOnCycle(t *testing.T) {
+	testcases := []struct {
+		name        string
+		app         string
+		dependsOn   map[string][]string
+		expectedErr string
+	}{
+		{
+			name: "no dependency",
+			app:  "frontend",
+		},
+		{
+			name: "chained dependencies",
+			app:  "frontend",
+			dependsOn: map[string][]string{
+				"frontend": {"backend", "migration"},
+				"backend":  {"migration"},
+			},
+		},
+		{
+			name: "self reference",
+			app:  "frontend",
+			dependsOn: map[string][]string{
+				"frontend": {"frontend"},
+			},
+			expectedErr: "application frontend must not depend on itself",
+		},
+		{
+			name: "cycle",
+			app:  "frontend",
+			dependsOn: map[string][]string{
+				"frontend":  {"backend"},
+				"backend":   {"migration"},
+				"migration": {"frontend"},
+			},
+			expectedErr: "circular dependency frontend -> backend -> migration -> frontend",
+		},
+		{
+			name: "cycle not reachable from the application",
+			app:  "frontend",
+			dependsOn: map[string][]string{
+				"frontend": {"backend"},
+				"worker":   {"queue"},
+				"queue":    {"worker"},
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateDependsOn(tc.app, tc.dependsOn)
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tc.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// DeploymentWindows represents the time windows
+// in which the deployments of an application are allowed to run.
+type DeploymentWindows struct {
+	// The IANA time zone name used to evaluate the cron expressions.
+	// Default is UTC.
+	TimeZone string `json:"timeZone"`
+	// Deployments are allowed only while at least one of these windows is open.
+	// Empty means deployments are allowed at any time except the deny windows.
+	Allow []DeploymentWindow `json:"allow"`
+	// Deployments are not allowed while any of these windows is open.
+	Deny []DeploymentWindow `json:"deny"`
+}
+
+// DeploymentWindow represents a recurring time window.
+type DeploymentWindow struct {
+	// The standard cron expression specifying when the window opens.
+	// e.g. "0 17 * * FRI"
+	Cron string `json:"cron"`
+	// How long the window stays open after each opening.
+	Duration Duration `json:"duration"`
+	// The reason shown while deployments are held by this window.
+	Reason string `json:"reason"`
+}
+
+func (w *DeploymentWindows) Validate() error {
+	if _, err := time.LoadLocation(w.TimeZone); err != nil {
+		return fmt.Errorf("invalid timeZone %q: %w", w.TimeZone, err)
+	}
+	for _, r := range w.Allow {
+		if err := r.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, r := range w.Deny {
+		if err := r.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *DeploymentWindow) Validate() error {
+	if w.Cron == "" {
+		return errors.New("cron must be set for deployment window")
+	}
+	if _, err := cron.ParseStandard(w.Cron); err != nil {
+		return fmt.Errorf("invalid cron %q for deployment window: %w", w.Cron, err)
+	}
+	if w.Duration <= 0 {
+		return fmt.Errorf("duration of deployment window %q must be positive", w.Cron)
+	}
+	return nil
+}
+
+// HoldReason returns the reason why deployments are not allowed at the given time.
+// The returned boolean is false when deployments are allowed.
+func (w *DeploymentWindows) HoldReason(t time.Time) (string, bool) {
+	if loc, err := time.LoadLocation(w.TimeZone); err == nil {
+		t = t.In(loc)
+	}
+
+	for _, r := range w.Deny {
+		if !r.isOpen(t) {
+			continue
+		}
+		if r.Reason != "" {
+			return r.Reason, true
+		}
+		return fmt.Sprintf("Deny window %q is open", r.Cron), true
+	}
+
+	if len(w.Allow) == 0 {
+		return "", false
+	}
+	for _, r := range w.Allow {
+		if r.isOpen(t) {
+			return "", false
+		}
+	}
+	return "Out of the allowed deployment windows", true
+}
+
+// isOpen reports whether the window is open at the given time.
+// That means the window has opened within the last duration.
+func (w *DeploymentWindow) isOpen(t time.Time) bool {
+	schedule, err := cron.ParseStandard(w.Cron)
+	if err != nil {
+		return false
+	}
+	opened := schedule.Next(t.Add(-w.Duration.Duration()))
+	return !opened.After(t)
+}
@@ -0,0 +1,122 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeploymentWindowsValidate(t *testing.T) {
+	testcases := []struct {
+		name        string
+		data        string
+		expectedErr bool
+	}{
+		{
+			name: "valid",
+			data: `{"timeZone": "Asia/Tokyo", "allow": [{"cron": "0 9 * * MON-FRI", "duration": "9h"}], "deny": [{"cron": "0 17 * * FRI", "duration": "64h"}]}`,
+		},
+		{
+			name:        "invalid time zone",
+			data:        `{"timeZone": "Mars/Olympus", "deny": [{"cron": "0 17 * * FRI", "duration": "64h"}]}`,
+			expectedErr: true,
+		},
+		{
+			name:        "invalid cron",
+			data:        `{"deny": [{"cron": "every friday", "duration": "64h"}]}`,
+			expectedErr: true,
+		},
+		{
+			name:        "missing duration",
+			data:        `{"allow": [{"cron": "0 9 * * MON-FRI"}]}`,
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var w DeploymentWindows
+			require.NoError(t, json.Unmarshal([]byte(tc.data), &w))
+
+			err := w.Validate()
+			assert.Equal(t, tc.expectedErr, err != nil)
+		})
+	}
+}
+
+func TestDeploymentWindowsHoldReason(t *testing.T) {
+	w := DeploymentWindows{
+		TimeZone: "Asia/Tokyo",
+		Allow: []DeploymentWindow{
+			{
+				Cron:     "0 9 * * MON-FRI",
+				Duration: Duration(9 * time.Hour),
+			},
+		},
+		Deny: []DeploymentWindow{
+			{
+				Cron:     "0 17 * * FRI",
+				Duration: Duration(64 * time.Hour),
+				Reason:   "No deployment on Friday evenings and weekends",
+			},
+		},
+	}
+	jst := time.FixedZone("JST", 9*60*60)
+
+	testcases := []struct {
+		name           string
+		time           time.Time
+		expectedHeld   bool
+		expectedReason string
+	}{
+		{
+			name: "in an allow window",
+			time: time.Date(2021, 6, 4, 10, 0, 0, 0, jst),
+		},
+		{
+			name:           "in a deny window",
+			time:           time.Date(2021, 6, 4, 18, 0, 0, 0, jst),
+			expectedHeld:   true,
+			expectedReason: "No deployment on Friday evenings and weekends",
+		},
+		{
+			name:           "in a deny window evaluated in another time zone",
+			time:           time.Date(2021, 6, 6, 3, 0, 0, 0, time.UTC),
+			expectedHeld:   true,
+			expectedReason: "No deployment on Friday evenings and weekends",
+		},
+		{
+			name: "right after a deny window closed",
+			time: time.Date(2021, 6, 7, 9, 30, 0, 0, jst),
+		},
+		{
+			name:           "out of all allow windows",
+			time:           time.Date(2021, 6, 8, 20, 0, 0, 0, jst),
+			expectedHeld:   true,
+			expectedReason: "Out of the allowed deployment windows",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			reason, held := w.HoldReason(tc.time)
+			assert.Equal(t, tc.expectedHeld, held)
+			assert.Equal(t, tc.expectedReason, reason)
+		})
+	}
+}
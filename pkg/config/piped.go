@@ -281,6 +281,9 @@ type PipedCloudProvider struct {
 	CloudRunConfig   *CloudProviderCloudRunConfig
 	LambdaConfig     *CloudProviderLambdaConfig
 	ECSConfig        *CloudProviderECSConfig
+
+	CloudFunctionsConfig *CloudProviderCloudFunctionsConfig
+	NomadConfig          *CloudProviderNomadConfig
 }
 
 type genericPipedCloudProvider struct {
@@ -324,6 +327,16 @@ func (p *PipedCloudProvider) UnmarshalJSON(data []byte) error {
 		if len(gp.Config) > 0 {
 			err = json.Unmarshal(gp.Config, p.ECSConfig)
 		}
+	case model.CloudProviderCloudFunctions:
+		p.CloudFunctionsConfig = &CloudProviderCloudFunctionsConfig{}
+		if len(gp.Config) > 0 {
+			err = json.Unmarshal(gp.Config, p.CloudFunctionsConfig)
+		}
+	case model.CloudProviderNomad:
+		p.NomadConfig = &CloudProviderNomadConfig{}
+		if len(gp.Config) > 0 {
+			err = json.Unmarshal(gp.Config, p.NomadConfig)
+		}
 	default:
 		err = fmt.Errorf("unsupported cloud provider type: %s", p.Name)
 	}
@@ -377,6 +390,30 @@ type CloudProviderCloudRunConfig struct {
 	CredentialsFile string `json:"credentialsFile"`
 }
 
+type CloudProviderCloudFunctionsConfig struct {
+	// The GCP project hosting the functions.
+	Project string `json:"project"`
+	// The region where the functions are deployed.
+	Region string `json:"region"`
+	// The path to the service account file for accessing Cloud Functions and Cloud Run services.
+	CredentialsFile string `json:"credentialsFile"`
+}
+
+type CloudProviderNomadConfig struct {
+	// The address of the Nomad HTTP API.
+	// e.g. http://127.0.0.1:4646
+	Address string `json:"address"`
+	// The path to the file containing the ACL token used to access the Nomad API.
+	// Empty means no token is sent.
+	TokenFile string `json:"tokenFile"`
+	// The namespace where the jobs are registered.
+	// Empty means the default namespace.
+	Namespace string `json:"namespace"`
+	// The region where the jobs are registered.
+	// Empty means the region of the Nomad agent.
+	Region string `json:"region"`
+}
+
 type CloudProviderLambdaConfig struct {
 	// The region to send requests to. This parameter is required.
 	// e.g. "us-west-2"
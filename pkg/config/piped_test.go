@@ -121,6 +121,16 @@ func TestPipedConfig(t *testing.T) {
 							CredentialsFile: "/etc/piped-secret/gcp-service-account.json",
 						},
 					},
+					{
+						Name: "nomad",
+						Type: model.CloudProviderNomad,
+						NomadConfig: &CloudProviderNomadConfig{
+							Address:   "http://nomad.example.com:4646",
+							TokenFile: "/etc/piped-secret/nomad-token",
+							Namespace: "apps",
+							Region:    "global",
+						},
+					},
 					{
 						Name: "lambda",
 						Type: model.CloudProviderLambda,
@@ -128,6 +138,15 @@ func TestPipedConfig(t *testing.T) {
 							Region: "us-east-1",
 						},
 					},
+					{
+						Name: "cloudfunctions",
+						Type: model.CloudProviderCloudFunctions,
+						CloudFunctionsConfig: &CloudProviderCloudFunctionsConfig{
+							Project:         "gcp-project-id",
+							Region:          "asia-northeast1",
+							CredentialsFile: "/etc/piped-secret/gcp-service-account.json",
+						},
+					},
 				},
 				AnalysisProviders: []PipedAnalysisProvider{
 					{
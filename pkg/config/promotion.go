@@ -0,0 +1,115 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Promotion defines how a successfully deployed release of an application
+// is promoted to the configuration of another application, e.g. the one of the next environment.
+type Promotion struct {
+	// The unique name of the promotion.
+	Name string `json:"name"`
+	// The ID of the repository where the configuration files to be updated are placed.
+	// Default is the repository of this application.
+	RepoID string `json:"repoId"`
+	// Whether to push the promotion commit to a new branch instead of
+	// the configured branch of the repository, so that it can be merged through a pull request.
+	NewBranch bool `json:"newBranch"`
+	// The message of the promotion commit.
+	// Default is generated from the promotion name and the promoted commit.
+	CommitMessage string `json:"commitMessage"`
+	// List of values to be copied from this application to the target configuration files.
+	Replacements []PromotionReplacement `json:"replacements"`
+}
+
+// PromotionReplacement copies a value from a file of this application to a file of the target repository.
+type PromotionReplacement struct {
+	// Where the value is read from. The file path is relative to the application directory.
+	Source PromotionField `json:"source"`
+	// Where the value is written to. The file path is relative to the root of the target repository.
+	Target PromotionField `json:"target"`
+}
+
+type PromotionField struct {
+	// The relative path to the file.
+	// It must not be absolute or point outside of its root directory.
+	File string `json:"file"`
+	// The YAML path to the field. It requires to start
+	// with `$` which represents the root element. e.g. `$.foo.bar[0].baz`.
+	YAMLField string `json:"yamlField"`
+}
+
+// FindPromotion returns the promotion of the given name.
+func FindPromotion(promotions []Promotion, name string) (Promotion, bool) {
+	for _, p := range promotions {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Promotion{}, false
+}
+
+func validatePromotions(promotions []Promotion) error {
+	names := make(map[string]struct{}, len(promotions))
+	for _, p := range promotions {
+		if err := p.Validate(); err != nil {
+			return err
+		}
+		if _, ok := names[p.Name]; ok {
+			return fmt.Errorf("duplicated promotion name %q", p.Name)
+		}
+		names[p.Name] = struct{}{}
+	}
+	return nil
+}
+
+func (p *Promotion) Validate() error {
+	if p.Name == "" {
+		return errors.New("promotion name must not be empty")
+	}
+	if len(p.Replacements) == 0 {
+		return fmt.Errorf("promotion %q must have at least one replacement", p.Name)
+	}
+	for _, r := range p.Replacements {
+		if err := r.Source.Validate(); err != nil {
+			return fmt.Errorf("invalid source of promotion %q: %w", p.Name, err)
+		}
+		if err := r.Target.Validate(); err != nil {
+			return fmt.Errorf("invalid target of promotion %q: %w", p.Name, err)
+		}
+	}
+	return nil
+}
+
+func (f *PromotionField) Validate() error {
+	if f.File == "" {
+		return errors.New("file must not be empty")
+	}
+	if path.IsAbs(f.File) {
+		return fmt.Errorf("file %s must be a relative path", f.File)
+	}
+	if p := path.Clean(f.File); p == ".." || strings.HasPrefix(p, "../") {
+		return fmt.Errorf("file %s must not point outside of its root directory", f.File)
+	}
+	if f.YAMLField == "" {
+		return errors.New("yamlField must not be empty")
+	}
+	return nil
+}
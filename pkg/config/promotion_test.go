@@ -0,0 +1,116 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePromotions(t *testing.T) {
+	testcases := []struct {
+		name        string
+		data        string
+		expectedErr bool
+	}{
+		{
+			name: "valid",
+			data: `[{"name": "to-prod", "replacements": [{"source": {"file": "deployment.yaml", "yamlField": "$.spec.template.spec.containers[0].image"}, "target": {"file": "prod/deployment.yaml", "yamlField": "$.spec.template.spec.containers[0].image"}}]}]`,
+		},
+		{
+			name:        "missing name",
+			data:        `[{"replacements": [{"source": {"file": "a.yaml", "yamlField": "$.a"}, "target": {"file": "b.yaml", "yamlField": "$.a"}}]}]`,
+			expectedErr: true,
+		},
+		{
+			name:        "missing replacements",
+			data:        `[{"name": "to-prod"}]`,
+			expectedErr: true,
+		},
+		{
+			name:        "missing target field",
+			data:        `[{"name": "to-prod", "replacements": [{"source": {"file": "a.yaml", "yamlField": "$.a"}, "target": {"file": "b.yaml"}}]}]`,
+			expectedErr: true,
+		},
+		{
+			name:        "absolute target file",
+			data:        `[{"name": "to-prod", "replacements": [{"source": {"file": "a.yaml", "yamlField": "$.a"}, "target": {"file": "/etc/b.yaml", "yamlField": "$.a"}}]}]`,
+			expectedErr: true,
+		},
+		{
+			name:        "target file outside of the repository",
+			data:        `[{"name": "to-prod", "replacements": [{"source": {"file": "a.yaml", "yamlField": "$.a"}, "target": {"file": "prod/../../b.yaml", "yamlField": "$.a"}}]}]`,
+			expectedErr: true,
+		},
+		{
+			name:        "source file outside of the application directory",
+			data:        `[{"name": "to-prod", "replacements": [{"source": {"file": "../a.yaml", "yamlField": "$.a"}, "target": {"file": "b.yaml", "yamlField": "$.a"}}]}]`,
+			expectedErr: true,
+		},
+		{
+			name: "target file with a parent directory inside the repository",
+			data: `[{"name": "to-prod", "replacements": [{"source": {"file": "a.yaml", "yamlField": "$.a"}, "target": {"file": "prod/../staging/b.yaml", "yamlField": "$.a"}}]}]`,
+		},
+		{
+			name:        "duplicated name",
+			data:        `[{"name": "to-prod", "replacements": [{"source": {"file": "a.yaml", "yamlField": "$.a"}, "target": {"file": "b.yaml", "yamlField": "$.a"}}]}, {"name": "to-prod", "replacements": [{"source": {"file": "a.yaml", "yamlField": "$.a"}, "target": {"file": "c.yaml", "yamlField": "$.a"}}]}]`,
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var ps []Promotion
+			require.NoError(t, json.Unmarshal([]byte(tc.data), &ps))
+
+			err := validatePromotions(ps)
+			assert.Equal(t, tc.expectedErr, err != nil)
+		})
+	}
+}
+
+func TestPromoteStageOptionsValidate(t *testing.T) {
+	promotions := []Promotion{
+		{Name: "to-staging"},
+		{Name: "to-prod"},
+	}
+	testcases := []struct {
+		name        string
+		opts        PromoteStageOptions
+		expectedErr bool
+	}{
+		{
+			name: "all promotions",
+			opts: PromoteStageOptions{},
+		},
+		{
+			name: "defined promotion",
+			opts: PromoteStageOptions{Promotions: []string{"to-prod"}},
+		},
+		{
+			name:        "undefined promotion",
+			opts:        PromoteStageOptions{Promotions: []string{"to-prod", "to-dev"}},
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.opts.Validate(promotions)
+			assert.Equal(t, tc.expectedErr, err != nil)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+const DeploymentFreezeModelKind = "DeploymentFreeze"
+
+var (
+	deploymentFreezeFactory = func() interface{} {
+		return &model.DeploymentFreeze{}
+	}
+)
+
+type DeploymentFreezeStore interface {
+	AddDeploymentFreeze(ctx context.Context, f *model.DeploymentFreeze) error
+	ListDeploymentFreezes(ctx context.Context, opts ListOptions) ([]*model.DeploymentFreeze, error)
+	// EndDeploymentFreeze lifts the specified freeze by ending it right now.
+	EndDeploymentFreeze(ctx context.Context, id, projectID string) error
+}
+
+type deploymentFreezeStore struct {
+	backend
+	nowFunc func() time.Time
+}
+
+func NewDeploymentFreezeStore(ds DataStore) DeploymentFreezeStore {
+	return &deploymentFreezeStore{
+		backend: backend{
+			ds: ds,
+		},
+		nowFunc: time.Now,
+	}
+}
+
+func (s *deploymentFreezeStore) AddDeploymentFreeze(ctx context.Context, f *model.DeploymentFreeze) error {
+	now := s.nowFunc().Unix()
+	if f.CreatedAt == 0 {
+		f.CreatedAt = now
+	}
+	if f.UpdatedAt == 0 {
+		f.UpdatedAt = now
+	}
+	if err := f.Validate(); err != nil {
+		return err
+	}
+	return s.ds.Create(ctx, DeploymentFreezeModelKind, f.Id, f)
+}
+
+func (s *deploymentFreezeStore) ListDeploymentFreezes(ctx context.Context, opts ListOptions) ([]*model.DeploymentFreeze, error) {
+	it, err := s.ds.Find(ctx, DeploymentFreezeModelKind, opts)
+	if err != nil {
+		return nil, err
+	}
+	fs := make([]*model.DeploymentFreeze, 0)
+	for {
+		var f model.DeploymentFreeze
+		err := it.Next(&f)
+		if err == ErrIteratorDone {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		fs = append(fs, &f)
+	}
+	return fs, nil
+}
+
+func (s *deploymentFreezeStore) EndDeploymentFreeze(ctx context.Context, id, projectID string) error {
+	now := s.nowFunc().Unix()
+	return s.ds.Update(ctx, DeploymentFreezeModelKind, id, deploymentFreezeFactory, func(e interface{}) error {
+		f := e.(*model.DeploymentFreeze)
+		if f.ProjectId != projectID {
+			return fmt.Errorf("invalid project id, expected %s, got %s", f.ProjectId, projectID)
+		}
+
+		if f.EndedAt > now {
+			f.EndedAt = now
+		}
+		if f.StartedAt > now {
+			f.StartedAt = now
+		}
+		f.UpdatedAt = now
+		return f.Validate()
+	})
+}
@@ -0,0 +1,127 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+func TestAddDeploymentFreeze(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testcases := []struct {
+		name      string
+		freeze    *model.DeploymentFreeze
+		dsFactory func(*model.DeploymentFreeze) DataStore
+		wantErr   bool
+	}{
+		{
+			name:      "Invalid deployment freeze",
+			freeze:    &model.DeploymentFreeze{},
+			dsFactory: func(f *model.DeploymentFreeze) DataStore { return nil },
+			wantErr:   true,
+		},
+		{
+			name: "Valid deployment freeze",
+			freeze: &model.DeploymentFreeze{
+				Id:        "id",
+				ProjectId: "project-id",
+				Reason:    "Year-end holidays",
+				StartedAt: 1,
+				EndedAt:   2,
+				Creator:   "user",
+				CreatedAt: 1,
+				UpdatedAt: 1,
+			},
+			dsFactory: func(f *model.DeploymentFreeze) DataStore {
+				ds := NewMockDataStore(ctrl)
+				ds.EXPECT().Create(gomock.Any(), "DeploymentFreeze", f.Id, f)
+				return ds
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewDeploymentFreezeStore(tc.dsFactory(tc.freeze))
+			err := s.AddDeploymentFreeze(context.Background(), tc.freeze)
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
+
+func TestListDeploymentFreezes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testcases := []struct {
+		name    string
+		opts    ListOptions
+		ds      DataStore
+		wantErr error
+	}{
+		{
+			name: "iterator done",
+			opts: ListOptions{},
+			ds: func() DataStore {
+				it := NewMockIterator(ctrl)
+				it.EXPECT().
+					Next(&model.DeploymentFreeze{}).
+					Return(ErrIteratorDone)
+
+				ds := NewMockDataStore(ctrl)
+				ds.EXPECT().
+					Find(gomock.Any(), "DeploymentFreeze", ListOptions{}).
+					Return(it, nil)
+				return ds
+			}(),
+			wantErr: nil,
+		},
+		{
+			name: "unexpected error occurred",
+			opts: ListOptions{},
+			ds: func() DataStore {
+				it := NewMockIterator(ctrl)
+				it.EXPECT().
+					Next(&model.DeploymentFreeze{}).
+					Return(errors.New("test-error"))
+
+				ds := NewMockDataStore(ctrl)
+				ds.EXPECT().
+					Find(gomock.Any(), "DeploymentFreeze", ListOptions{}).
+					Return(it, nil)
+				return ds
+			}(),
+			wantErr: errors.New("test-error"),
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewDeploymentFreezeStore(tc.ds)
+			_, err := s.ListDeploymentFreezes(context.Background(), tc.opts)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
@@ -86,6 +86,60 @@ var (
 			return fmt.Errorf("stage id %s not found: %w", stageID, ErrInvalidArgument)
 		}
 	}
+
+	// DeploymentStageRetriedUpdater brings a failed deployment back to RUNNING
+	// so that piped can resume it from the given failed stage.
+	// The retried count of that stage is incremented to keep the logs of the previous attempts.
+	// The other stages which were failed or cancelled while running in parallel with that stage
+	// and the rollback stages are also reset to be able to run again.
+	DeploymentStageRetriedUpdater = func(stageID, statusReason string) func(*model.Deployment) error {
+		return func(d *model.Deployment) error {
+			if d.Status != model.DeploymentStatus_DEPLOYMENT_FAILURE {
+				return fmt.Errorf("deployment status %s is not failure: %w", d.Status, ErrInvalidArgument)
+			}
+
+			var found bool
+			for _, s := range d.Stages {
+				if s.Id == stageID {
+					if s.Status != model.StageStatus_STAGE_FAILURE {
+						return fmt.Errorf("stage status %s is not failure: %w", s.Status, ErrInvalidArgument)
+					}
+					found = true
+					s.Status = model.StageStatus_STAGE_NOT_STARTED_YET
+					s.StatusReason = ""
+					s.Metadata = nil
+					s.RetriedCount++
+					s.CompletedAt = 0
+					continue
+				}
+				if s.Name == model.StageRollback.String() || s.Name == model.StageScriptRunRollback.String() {
+					if s.Status != model.StageStatus_STAGE_NOT_STARTED_YET {
+						s.RetriedCount++
+					}
+					s.Status = model.StageStatus_STAGE_NOT_STARTED_YET
+					s.StatusReason = ""
+					s.Visible = false
+					s.CompletedAt = 0
+					continue
+				}
+				if s.Status == model.StageStatus_STAGE_FAILURE || s.Status == model.StageStatus_STAGE_CANCELLED {
+					s.Status = model.StageStatus_STAGE_NOT_STARTED_YET
+					s.StatusReason = ""
+					s.Metadata = nil
+					s.RetriedCount++
+					s.CompletedAt = 0
+				}
+			}
+			if !found {
+				return fmt.Errorf("stage id %s not found: %w", stageID, ErrInvalidArgument)
+			}
+
+			d.Status = model.DeploymentStatus_DEPLOYMENT_RUNNING
+			d.StatusReason = statusReason
+			d.CompletedAt = 0
+			return nil
+		}
+	}
 )
 
 type DeploymentStore interface {
@@ -295,6 +295,155 @@ func TestStageStatusChangedUpdater(t *testing.T) {
 	}
 }
 
+func TestDeploymentStageRetriedUpdater(t *testing.T) {
+	testcases := []struct {
+		name       string
+		deployment model.Deployment
+		stageID    string
+
+		expectedDeployment model.Deployment
+		expectedErr        error
+	}{
+		{
+			name: "deployment is not failed",
+			deployment: model.Deployment{
+				Id:     "deployment-id",
+				Status: model.DeploymentStatus_DEPLOYMENT_SUCCESS,
+				Stages: []*model.PipelineStage{
+					{
+						Id:     "stage-id1",
+						Status: model.StageStatus_STAGE_SUCCESS,
+					},
+				},
+			},
+			stageID:     "stage-id1",
+			expectedErr: ErrInvalidArgument,
+		},
+		{
+			name: "stage is not failed",
+			deployment: model.Deployment{
+				Id:     "deployment-id",
+				Status: model.DeploymentStatus_DEPLOYMENT_FAILURE,
+				Stages: []*model.PipelineStage{
+					{
+						Id:     "stage-id1",
+						Status: model.StageStatus_STAGE_SUCCESS,
+					},
+				},
+			},
+			stageID:     "stage-id1",
+			expectedErr: ErrInvalidArgument,
+		},
+		{
+			name: "stageID not found",
+			deployment: model.Deployment{
+				Id:     "deployment-id",
+				Status: model.DeploymentStatus_DEPLOYMENT_FAILURE,
+				Stages: []*model.PipelineStage{
+					{
+						Id:     "stage-id1",
+						Status: model.StageStatus_STAGE_FAILURE,
+					},
+				},
+			},
+			stageID:     "not-found-stage-id",
+			expectedErr: ErrInvalidArgument,
+		},
+		{
+			name: "reset failed, cancelled and rollback stages",
+			deployment: model.Deployment{
+				Id:           "deployment-id",
+				Status:       model.DeploymentStatus_DEPLOYMENT_FAILURE,
+				StatusReason: "Failed while executing stage stage-id2",
+				CompletedAt:  100,
+				Stages: []*model.PipelineStage{
+					{
+						Id:          "stage-id1",
+						Status:      model.StageStatus_STAGE_SUCCESS,
+						Visible:     true,
+						CompletedAt: 90,
+					},
+					{
+						Id:           "stage-id2",
+						Status:       model.StageStatus_STAGE_FAILURE,
+						StatusReason: "failed",
+						Visible:      true,
+						Metadata:     map[string]string{"meta": "value"},
+						CompletedAt:  95,
+					},
+					{
+						Id:     "stage-id3",
+						Status: model.StageStatus_STAGE_NOT_STARTED_YET,
+					},
+					{
+						Id:           "stage-id4",
+						Status:       model.StageStatus_STAGE_CANCELLED,
+						StatusReason: "cancelled",
+						Visible:      true,
+						CompletedAt:  95,
+					},
+					{
+						Id:          "rollback",
+						Name:        model.StageRollback.String(),
+						Status:      model.StageStatus_STAGE_SUCCESS,
+						Visible:     true,
+						CompletedAt: 100,
+					},
+				},
+			},
+			stageID: "stage-id2",
+			expectedDeployment: model.Deployment{
+				Id:           "deployment-id",
+				Status:       model.DeploymentStatus_DEPLOYMENT_RUNNING,
+				StatusReason: "Retrying",
+				Stages: []*model.PipelineStage{
+					{
+						Id:          "stage-id1",
+						Status:      model.StageStatus_STAGE_SUCCESS,
+						Visible:     true,
+						CompletedAt: 90,
+					},
+					{
+						Id:           "stage-id2",
+						Status:       model.StageStatus_STAGE_NOT_STARTED_YET,
+						Visible:      true,
+						RetriedCount: 1,
+					},
+					{
+						Id:     "stage-id3",
+						Status: model.StageStatus_STAGE_NOT_STARTED_YET,
+					},
+					{
+						Id:           "stage-id4",
+						Status:       model.StageStatus_STAGE_NOT_STARTED_YET,
+						Visible:      true,
+						RetriedCount: 1,
+					},
+					{
+						Id:           "rollback",
+						Name:         model.StageRollback.String(),
+						Status:       model.StageStatus_STAGE_NOT_STARTED_YET,
+						RetriedCount: 1,
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			updater := DeploymentStageRetriedUpdater(tc.stageID, "Retrying")
+			err := updater(&tc.deployment)
+			if tc.expectedErr != nil {
+				assert.True(t, errors.Is(err, tc.expectedErr))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedDeployment, tc.deployment)
+		})
+	}
+}
+
 func TestAddDeployment(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -90,3 +90,23 @@ CREATE INDEX event_key_name_project_id_created_at_desc ON Event (EventKey, Name,
 -- index on `ProjectId` ASC and `EnvIds` ASC
 ALTER TABLE Piped ADD COLUMN EnvIds JSON GENERATED ALWAYS AS (IFNULL(data ->> "$.env_ids", '[]')) VIRTUAL NOT NULL;
 CREATE INDEX piped_project_id_env_ids_asc ON Piped (ProjectId, (CAST(EnvIds AS CHAR(36) ARRAY)));
+
+--
+-- DeploymentFreeze table indexes
+--
+
+-- index on `ProjectId` ASC and `EndedAt` ASC
+ALTER TABLE DeploymentFreeze ADD COLUMN EndedAt INT(11) GENERATED ALWAYS AS (data->>"$.ended_at") VIRTUAL NOT NULL;
+CREATE INDEX deployment_freeze_project_id_ended_at_asc ON DeploymentFreeze (ProjectId, EndedAt);
+
+--
+-- Promotion table indexes
+--
+
+-- index on `ProjectId` ASC, `ApplicationId` ASC and `CreatedAt` DESC
+ALTER TABLE Promotion ADD COLUMN ApplicationId VARCHAR(36) GENERATED ALWAYS AS (data->>"$.application_id") VIRTUAL NOT NULL;
+CREATE INDEX promotion_project_id_application_id_created_at_desc ON Promotion (ProjectId, ApplicationId, CreatedAt DESC);
+
+-- index on `ProjectId` ASC, `DeploymentId` ASC and `CreatedAt` DESC
+ALTER TABLE Promotion ADD COLUMN DeploymentId VARCHAR(36) GENERATED ALWAYS AS (data->>"$.deployment_id") VIRTUAL NOT NULL;
+CREATE INDEX promotion_project_id_deployment_id_created_at_desc ON Promotion (ProjectId, DeploymentId, CreatedAt DESC);
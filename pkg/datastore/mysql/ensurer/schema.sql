@@ -103,3 +103,29 @@ CREATE TABLE IF NOT EXISTS Event (
   CreatedAt INT(11) GENERATED ALWAYS AS (data->>"$.created_at") STORED NOT NULL,
   UpdatedAt INT(11) GENERATED ALWAYS AS (data->>"$.updated_at") STORED NOT NULL
 ) ENGINE=InnoDB;
+
+--
+-- DeploymentFreeze table
+--
+
+CREATE TABLE IF NOT EXISTS DeploymentFreeze (
+  Id BINARY(16) PRIMARY KEY,
+  Data JSON NOT NULL,
+  ProjectId VARCHAR(50) GENERATED ALWAYS AS (data->>"$.project_id") STORED NOT NULL,
+  Extra VARCHAR(100) GENERATED ALWAYS AS (data->>"$._extra") STORED,
+  CreatedAt INT(11) GENERATED ALWAYS AS (data->>"$.created_at") STORED NOT NULL,
+  UpdatedAt INT(11) GENERATED ALWAYS AS (data->>"$.updated_at") STORED NOT NULL
+) ENGINE=InnoDB;
+
+--
+-- Promotion table
+--
+
+CREATE TABLE IF NOT EXISTS Promotion (
+  Id BINARY(16) PRIMARY KEY,
+  Data JSON NOT NULL,
+  ProjectId VARCHAR(50) GENERATED ALWAYS AS (data->>"$.project_id") STORED NOT NULL,
+  Extra VARCHAR(100) GENERATED ALWAYS AS (data->>"$._extra") STORED,
+  CreatedAt INT(11) GENERATED ALWAYS AS (data->>"$.created_at") STORED NOT NULL,
+  UpdatedAt INT(11) GENERATED ALWAYS AS (data->>"$.updated_at") STORED NOT NULL
+) ENGINE=InnoDB;
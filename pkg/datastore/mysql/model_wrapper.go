@@ -92,6 +92,22 @@ func wrapModel(entity interface{}) (interface{}, error) {
 			Event: *e,
 			Extra: e.Name,
 		}, nil
+	case *model.DeploymentFreeze:
+		if e == nil {
+			return nil, fmt.Errorf("nil entity given")
+		}
+		return &deploymentFreeze{
+			DeploymentFreeze: *e,
+			Extra:            e.Reason,
+		}, nil
+	case *model.Promotion:
+		if e == nil {
+			return nil, fmt.Errorf("nil entity given")
+		}
+		return &promotion{
+			Promotion: *e,
+			Extra:     e.Name,
+		}, nil
 	default:
 		return nil, fmt.Errorf("%T is not supported", e)
 	}
@@ -152,3 +168,13 @@ type event struct {
 	model.Event `json:",inline"`
 	Extra       string `json:"_extra"`
 }
+
+type deploymentFreeze struct {
+	model.DeploymentFreeze `json:",inline"`
+	Extra                  string `json:"_extra"`
+}
+
+type promotion struct {
+	model.Promotion `json:",inline"`
+	Extra           string `json:"_extra"`
+}
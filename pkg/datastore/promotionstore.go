@@ -0,0 +1,77 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"context"
+	"time"
+
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+const PromotionModelKind = "Promotion"
+
+type PromotionStore interface {
+	AddPromotion(ctx context.Context, p *model.Promotion) error
+	ListPromotions(ctx context.Context, opts ListOptions) ([]*model.Promotion, error)
+}
+
+type promotionStore struct {
+	backend
+	nowFunc func() time.Time
+}
+
+func NewPromotionStore(ds DataStore) PromotionStore {
+	return &promotionStore{
+		backend: backend{
+			ds: ds,
+		},
+		nowFunc: time.Now,
+	}
+}
+
+func (s *promotionStore) AddPromotion(ctx context.Context, p *model.Promotion) error {
+	now := s.nowFunc().Unix()
+	if p.CreatedAt == 0 {
+		p.CreatedAt = now
+	}
+	if p.UpdatedAt == 0 {
+		p.UpdatedAt = now
+	}
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	return s.ds.Create(ctx, PromotionModelKind, p.Id, p)
+}
+
+func (s *promotionStore) ListPromotions(ctx context.Context, opts ListOptions) ([]*model.Promotion, error) {
+	it, err := s.ds.Find(ctx, PromotionModelKind, opts)
+	if err != nil {
+		return nil, err
+	}
+	ps := make([]*model.Promotion, 0)
+	for {
+		var p model.Promotion
+		err := it.Next(&p)
+		if err == ErrIteratorDone {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		ps = append(ps, &p)
+	}
+	return ps, nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+func TestAddPromotion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testcases := []struct {
+		name      string
+		promotion *model.Promotion
+		dsFactory func(*model.Promotion) DataStore
+		wantErr   bool
+	}{
+		{
+			name:      "Invalid promotion",
+			promotion: &model.Promotion{},
+			dsFactory: func(p *model.Promotion) DataStore { return nil },
+			wantErr:   true,
+		},
+		{
+			name: "Valid promotion",
+			promotion: &model.Promotion{
+				Id:               "id",
+				Name:             "to-prod",
+				ProjectId:        "project-id",
+				PipedId:          "piped-id",
+				ApplicationId:    "app-id",
+				DeploymentId:     "deployment-id",
+				SourceCommitHash: "commit-hash",
+				RepoId:           "repo-id",
+				Status:           model.PromotionStatus_PROMOTION_SUCCESS,
+				CreatedAt:        1,
+				UpdatedAt:        1,
+			},
+			dsFactory: func(p *model.Promotion) DataStore {
+				ds := NewMockDataStore(ctrl)
+				ds.EXPECT().Create(gomock.Any(), "Promotion", p.Id, p)
+				return ds
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewPromotionStore(tc.dsFactory(tc.promotion))
+			err := s.AddPromotion(context.Background(), tc.promotion)
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
+
+func TestListPromotions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testcases := []struct {
+		name    string
+		opts    ListOptions
+		ds      DataStore
+		wantErr error
+	}{
+		{
+			name: "iterator done",
+			opts: ListOptions{},
+			ds: func() DataStore {
+				it := NewMockIterator(ctrl)
+				it.EXPECT().
+					Next(&model.Promotion{}).
+					Return(ErrIteratorDone)
+
+				ds := NewMockDataStore(ctrl)
+				ds.EXPECT().
+					Find(gomock.Any(), "Promotion", ListOptions{}).
+					Return(it, nil)
+				return ds
+			}(),
+			wantErr: nil,
+		},
+		{
+			name: "unexpected error occurred",
+			opts: ListOptions{},
+			ds: func() DataStore {
+				it := NewMockIterator(ctrl)
+				it.EXPECT().
+					Next(&model.Promotion{}).
+					Return(errors.New("test-error"))
+
+				ds := NewMockDataStore(ctrl)
+				ds.EXPECT().
+					Find(gomock.Any(), "Promotion", ListOptions{}).
+					Return(it, nil)
+				return ds
+			}(),
+			wantErr: errors.New("test-error"),
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewPromotionStore(tc.ds)
+			_, err := s.ListPromotions(context.Background(), tc.opts)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
@@ -39,6 +39,8 @@ type Repo interface {
 	GetLatestCommit(ctx context.Context) (Commit, error)
 	GetCommitHashForRev(ctx context.Context, rev string) (string, error)
 	ChangedFiles(ctx context.Context, from, to string) ([]string, error)
+	ListTags(ctx context.Context, commitish string) ([]string, error)
+	FetchTags(ctx context.Context) error
 	Checkout(ctx context.Context, commitish string) error
 	CheckoutPullRequest(ctx context.Context, number int, branch string) error
 	Clean() error
@@ -137,6 +139,25 @@ func (r *repo) GetCommitHashForRev(ctx context.Context, rev string) (string, err
 	return strings.TrimSpace(string(out)), nil
 }
 
+// ListTags returns the names of all tags pointing at the given commit.
+func (r *repo) ListTags(ctx context.Context, commitish string) ([]string, error) {
+	out, err := r.runGitCommand(ctx, "tag", "--points-at", commitish)
+	if err != nil {
+		return nil, formatCommandError(err, out)
+	}
+
+	var (
+		lines = strings.Split(string(out), "\n")
+		tags  = make([]string, 0, len(lines))
+	)
+	for _, t := range lines {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags, nil
+}
+
 // ChangedFiles returns a list of files those were touched between two commits.
 func (r *repo) ChangedFiles(ctx context.Context, from, to string) ([]string, error) {
 	out, err := r.runGitCommand(ctx, "diff", "--name-only", from, to)
@@ -186,6 +207,17 @@ func (r *repo) Pull(ctx context.Context, branch string) error {
 	return nil
 }
 
+// FetchTags fetches all tags from the remote.
+// This is required to see the tags pushed after cloning
+// because pulling a branch does not fetch the new tags pointing at already fetched commits.
+func (r *repo) FetchTags(ctx context.Context) error {
+	out, err := r.runGitCommand(ctx, "fetch", "--tags", "--force", r.remote)
+	if err != nil {
+		return formatCommandError(err, out)
+	}
+	return nil
+}
+
 // MergeRemoteBranch merges all commits until the given one
 // from a remote branch to current local branch.
 // This always adds a new merge commit into tree.
@@ -24,6 +24,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 )
 
 func TestGetCommitHashForRev(t *testing.T) {
@@ -104,6 +105,85 @@ func TestChangedFiles(t *testing.T) {
 	assert.Equal(t, expectedChangedFiles, changedFiles)
 }
 
+func TestListTags(t *testing.T) {
+	faker, err := newFaker()
+	require.NoError(t, err)
+	defer faker.clean()
+
+	var (
+		org      = "test-repo-org"
+		repoName = "repo-list-tags"
+		ctx      = context.Background()
+	)
+
+	err = faker.makeRepo(org, repoName)
+	require.NoError(t, err)
+	r := &repo{
+		dir:     faker.repoDir(org, repoName),
+		gitPath: faker.gitPath,
+	}
+
+	tags, err := r.ListTags(ctx, "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(tags))
+
+	out, err := r.runGitCommand(ctx, "tag", "v0.1.0")
+	require.NoError(t, err, string(out))
+	out, err = r.runGitCommand(ctx, "tag", "-a", "release-0.1", "-m", "Release 0.1")
+	require.NoError(t, err, string(out))
+
+	tags, err = r.ListTags(ctx, "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"release-0.1", "v0.1.0"}, tags)
+}
+
+func TestFetchTags(t *testing.T) {
+	faker, err := newFaker()
+	require.NoError(t, err)
+	defer faker.clean()
+
+	var (
+		org      = "test-repo-org"
+		repoName = "repo-fetch-tags"
+		ctx      = context.Background()
+	)
+	err = faker.makeRepo(org, repoName)
+	require.NoError(t, err)
+
+	c, err := NewClient("", "", zap.NewNop())
+	require.NoError(t, err)
+	defer c.Clean()
+
+	dir, err := ioutil.TempDir("", "fetch-tags")
+	require.NoError(t, err)
+	r, err := c.Clone(ctx, repoName, faker.repoDir(org, repoName), "master", dir)
+	require.NoError(t, err)
+	defer r.Clean()
+
+	// Push a new tag to the remote after cloning.
+	commander := gitCommander{
+		gitPath: faker.gitPath,
+		dir:     faker.dir,
+		org:     org,
+		repo:    repoName,
+	}
+	err = commander.runGitCommands([][]string{{"tag", "v0.1.0"}})
+	require.NoError(t, err)
+
+	// Pulling the branch does not fetch the new tag.
+	err = r.Pull(ctx, "master")
+	require.NoError(t, err)
+	tags, err := r.ListTags(ctx, "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(tags))
+
+	err = r.FetchTags(ctx)
+	require.NoError(t, err)
+	tags, err = r.ListTags(ctx, "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v0.1.0"}, tags)
+}
+
 func TestAddCommit(t *testing.T) {
 	faker, err := newFaker()
 	require.NoError(t, err)
@@ -126,6 +126,24 @@ func MakeFileCreationURL(repoURL, dir, branch, filename, value string) (string,
 	return u.String(), nil
 }
 
+// NormalizeRemoteURL returns a transport-independent form of the given repository URL
+// that can be used to check whether two remote URLs are pointing to the same repository.
+// e.g. Both "git@github.com:org/repo.git" and "https://github.com/org/repo" are normalized to "github.com/org/repo".
+func NormalizeRemoteURL(repoURL string) (string, error) {
+	u, err := parseGitURL(repoURL)
+	if err != nil {
+		return "", err
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("missing host in git url %q", repoURL)
+	}
+
+	repoPath := strings.Trim(u.Path, "/")
+	repoPath = strings.TrimSuffix(repoPath, ".git")
+
+	return fmt.Sprintf("%s/%s", strings.ToLower(u.Hostname()), repoPath), nil
+}
+
 var (
 	knownSchemes = map[string]interface{}{
 		"ssh":     struct{}{},
@@ -247,6 +247,53 @@ foo:
 	}
 }
 
+func TestNormalizeRemoteURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		repoURL string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "scp-like url",
+			repoURL: "git@github.com:org/repo.git",
+			want:    "github.com/org/repo",
+			wantErr: false,
+		},
+		{
+			name:    "ssh scheme",
+			repoURL: "ssh://git@github.com:22/org/repo.git",
+			want:    "github.com/org/repo",
+			wantErr: false,
+		},
+		{
+			name:    "https scheme",
+			repoURL: "https://GitHub.com/org/repo",
+			want:    "github.com/org/repo",
+			wantErr: false,
+		},
+		{
+			name:    "https scheme with user and trailing slash",
+			repoURL: "https://user@bitbucket.org/org/repo.git/",
+			want:    "bitbucket.org/org/repo",
+			wantErr: false,
+		},
+		{
+			name:    "unparseable url",
+			repoURL: "1234abcd",
+			want:    "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeRemoteURL(tt.repoURL)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func TestParseGitURL(t *testing.T) {
 	tests := []struct {
 		name    string
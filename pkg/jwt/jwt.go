@@ -36,6 +36,8 @@ type Claims struct {
 	jwtgo.StandardClaims
 	AvatarURL string     `json:"avatarUrl,omitempty"`
 	Role      model.Role `json:"role,omitempty"`
+	// The list of teams the user belongs to, e.g. "org/team".
+	Teams []string `json:"teams,omitempty"`
 }
 
 // NewClaims creates a new claims for a given github user.
@@ -62,7 +62,7 @@ func (s KubernetesResourceState) HasDiff(a KubernetesResourceState) bool {
 // DetermineAppHealthStatus updates its own health status, which is determined based on its resources status.
 func (s *ApplicationLiveStateSnapshot) DetermineAppHealthStatus() {
 	switch s.Kind {
-	case ApplicationKind_KUBERNETES:
+	case ApplicationKind_KUBERNETES, ApplicationKind_CROSSPLANE:
 		k := s.Kubernetes
 		if k == nil {
 			return
@@ -75,6 +75,48 @@ func (s *ApplicationLiveStateSnapshot) DetermineAppHealthStatus() {
 			}
 		}
 		s.HealthStatus = status
+	case ApplicationKind_CLOUDFUNCTIONS:
+		f := s.Cloudfunctions
+		if f == nil {
+			return
+		}
+		if f.State == "ACTIVE" {
+			s.HealthStatus = ApplicationLiveStateSnapshot_HEALTHY
+		} else {
+			s.HealthStatus = ApplicationLiveStateSnapshot_OTHER
+		}
+	case ApplicationKind_NOMAD:
+		n := s.Nomad
+		if n == nil {
+			return
+		}
+		// The job is considered as healthy while it is running
+		// and its latest deployment has not failed.
+		if n.Status == "running" && (n.LatestDeployment == nil || n.LatestDeployment.Status != "failed") {
+			s.HealthStatus = ApplicationLiveStateSnapshot_HEALTHY
+		} else {
+			s.HealthStatus = ApplicationLiveStateSnapshot_OTHER
+		}
+	case ApplicationKind_CLOUDRUN:
+		c := s.Cloudrun
+		if c == nil {
+			return
+		}
+		if c.HealthStatus == CloudRunApplicationLiveState_HEALTHY {
+			s.HealthStatus = ApplicationLiveStateSnapshot_HEALTHY
+		} else {
+			s.HealthStatus = ApplicationLiveStateSnapshot_OTHER
+		}
+	case ApplicationKind_LAMBDA:
+		l := s.Lambda
+		if l == nil {
+			return
+		}
+		if l.HealthStatus == LambdaApplicationLiveState_HEALTHY {
+			s.HealthStatus = ApplicationLiveStateSnapshot_HEALTHY
+		} else {
+			s.HealthStatus = ApplicationLiveStateSnapshot_OTHER
+		}
 	default:
 		// TODO: Determine health state of other than k8s app
 		return
@@ -17,11 +17,13 @@ package model
 type CloudProviderType string
 
 const (
-	CloudProviderKubernetes CloudProviderType = "KUBERNETES"
-	CloudProviderTerraform  CloudProviderType = "TERRAFORM"
-	CloudProviderCloudRun   CloudProviderType = "CLOUDRUN"
-	CloudProviderLambda     CloudProviderType = "LAMBDA"
-	CloudProviderECS        CloudProviderType = "ECS"
+	CloudProviderKubernetes     CloudProviderType = "KUBERNETES"
+	CloudProviderTerraform      CloudProviderType = "TERRAFORM"
+	CloudProviderCloudRun       CloudProviderType = "CLOUDRUN"
+	CloudProviderLambda         CloudProviderType = "LAMBDA"
+	CloudProviderECS            CloudProviderType = "ECS"
+	CloudProviderCloudFunctions CloudProviderType = "CLOUDFUNCTIONS"
+	CloudProviderNomad          CloudProviderType = "NOMAD"
 )
 
 func (t CloudProviderType) String() string {
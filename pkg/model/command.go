@@ -16,6 +16,15 @@ package model
 
 import "context"
 
+const (
+	// CommanderTeamsMetadataKey is the key of the command metadata
+	// that contains the comma-separated teams the commander belongs to.
+	CommanderTeamsMetadataKey = "CommanderTeams"
+	// StageSkippedByMetadataKey is the key of the stage metadata
+	// that contains the user who skipped the stage.
+	StageSkippedByMetadataKey = "SkippedBy"
+)
+
 type ReportableCommand struct {
 	*Command
 	Report func(ctx context.Context, status CommandStatus, metadata map[string]string, output []byte) error
@@ -20,6 +20,18 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
+const (
+	// DeploymentConcurrencyModeMetadataKey is the key of the deployment metadata
+	// that contains the concurrency mode configured at the triggered commit.
+	DeploymentConcurrencyModeMetadataKey = "ConcurrencyMode"
+	// DeploymentWaitingForMetadataKey is the key of the deployment metadata
+	// that contains the comma-separated IDs of the applications the deployment is waiting for.
+	DeploymentWaitingForMetadataKey = "WaitingFor"
+	// DeploymentCanaryURLMetadataKey is the key of the deployment metadata
+	// that contains the URL to access the canary version directly.
+	DeploymentCanaryURLMetadataKey = "CanaryURL"
+)
+
 var notCompletedDeploymentStatuses = []DeploymentStatus{
 	DeploymentStatus_DEPLOYMENT_PENDING,
 	DeploymentStatus_DEPLOYMENT_PLANNED,
@@ -57,6 +69,8 @@ func IsCompletedStage(status StageStatus) bool {
 		return true
 	case StageStatus_STAGE_CANCELLED:
 		return true
+	case StageStatus_STAGE_SKIPPED:
+		return true
 	}
 	return false
 }
@@ -100,6 +114,8 @@ func CanUpdateStageStatus(cur, next StageStatus) bool {
 		return cur <= StageStatus_STAGE_RUNNING
 	case StageStatus_STAGE_CANCELLED:
 		return cur <= StageStatus_STAGE_RUNNING
+	case StageStatus_STAGE_SKIPPED:
+		return cur <= StageStatus_STAGE_RUNNING
 	}
 	return false
 }
@@ -159,6 +175,16 @@ func (d *Deployment) CloudProviderType() CloudProviderType {
 	}
 }
 
+// FindStage finds the stage with the given ID in stage list.
+func (d *Deployment) FindStage(id string) (*PipelineStage, bool) {
+	for _, s := range d.Stages {
+		if s.Id == id {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
 // FindRollbackStage finds the rollback stage in stage list.
 func (d *Deployment) FindRollbackStage() (*PipelineStage, bool) {
 	for i := len(d.Stages) - 1; i >= 0; i-- {
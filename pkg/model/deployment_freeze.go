@@ -0,0 +1,28 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import "time"
+
+// IsActive reports whether the freeze is in effect at the given time.
+func (f *DeploymentFreeze) IsActive(t time.Time) bool {
+	now := t.Unix()
+	return f.StartedAt <= now && now < f.EndedAt
+}
+
+// AppliesTo reports whether the freeze is applied to the given application.
+func (f *DeploymentFreeze) AppliesTo(appID string) bool {
+	return f.ApplicationId == "" || f.ApplicationId == appID
+}
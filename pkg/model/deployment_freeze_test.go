@@ -0,0 +1,40 @@
+// Copyright 2021 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeploymentFreeze(t *testing.T) {
+	f := &DeploymentFreeze{
+		ApplicationId: "app-1",
+		StartedAt:     100,
+		EndedAt:       200,
+	}
+	assert.False(t, f.IsActive(time.Unix(99, 0)))
+	assert.True(t, f.IsActive(time.Unix(100, 0)))
+	assert.True(t, f.IsActive(time.Unix(199, 0)))
+	assert.False(t, f.IsActive(time.Unix(200, 0)))
+
+	assert.True(t, f.AppliesTo("app-1"))
+	assert.False(t, f.AppliesTo("app-2"))
+
+	f.ApplicationId = ""
+	assert.True(t, f.AppliesTo("app-2"))
+}
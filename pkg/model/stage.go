@@ -92,6 +92,39 @@ const (
 	// the CANARY variant resources has been cleaned.
 	StageECSCanaryClean Stage = "ECS_CANARY_CLEAN"
 
+	// StageCloudFunctionsSync does quick sync by deploying the new version
+	// and switching all traffic to it.
+	StageCloudFunctionsSync Stage = "CLOUDFUNCTIONS_SYNC"
+	// StageCloudFunctionsPromote promotes the new version to receive amount of traffic.
+	StageCloudFunctionsPromote Stage = "CLOUDFUNCTIONS_PROMOTE"
+
+	// StageCrossplaneSync does quick sync by applying all Crossplane resources
+	// and waiting until they became ready and synced.
+	StageCrossplaneSync Stage = "CROSSPLANE_SYNC"
+
+	// StageNomadSync does quick sync by registering the new version of the job
+	// and waiting until its deployment has been completed.
+	StageNomadSync Stage = "NOMAD_SYNC"
+	// StageNomadCanaryRollout represents the state where
+	// the canary allocations of the new version have been placed and became healthy.
+	StageNomadCanaryRollout Stage = "NOMAD_CANARY_ROLLOUT"
+	// StageNomadPromote represents the state where
+	// the canary allocations have been promoted and the new version was fully rolled out.
+	StageNomadPromote Stage = "NOMAD_PROMOTE"
+
+	// StageScriptRun represents the state where
+	// the specified script has been run.
+	StageScriptRun Stage = "SCRIPT_RUN"
+	// StageScriptRunRollback represents the state where
+	// the rollback script of a SCRIPT_RUN stage has been run.
+	// This stage is AUTOMATICALLY GENERATED and can not be used
+	// to specify in configuration file.
+	StageScriptRunRollback Stage = "SCRIPT_RUN_ROLLBACK"
+
+	// StagePromote represents the state where
+	// the deployed release has been promoted to the configuration of other applications.
+	StagePromote Stage = "PROMOTE"
+
 	// StageRollback represents a state where
 	// the all temporarily created stages will be reverted to
 	// bring back the pre-deploy stage.
@@ -103,3 +136,13 @@ const (
 func (s Stage) String() string {
 	return string(s)
 }
+
+// IsSkippable reports whether a running stage of this type
+// can be skipped by a SKIP_STAGE command.
+func (s Stage) IsSkippable() bool {
+	switch s {
+	case StageAnalysis, StageWait:
+		return true
+	}
+	return false
+}
@@ -118,9 +118,24 @@ func (c *OAuthClient) GetUser(ctx context.Context) (*model.User, error) {
 			ProjectId:   c.projectID,
 			ProjectRole: role,
 		},
+		Teams: makeTeamNames(teams),
 	}, nil
 }
 
+// makeTeamNames returns the names of the given teams in "org/team" format.
+func makeTeamNames(teams []*github.Team) []string {
+	names := make([]string, 0, len(teams))
+	for _, team := range teams {
+		slug := team.GetSlug()
+		org := team.Organization.GetLogin()
+		if org == "" || slug == "" {
+			continue
+		}
+		names = append(names, fmt.Sprintf("%s/%s", org, slug))
+	}
+	return names
+}
+
 func (c *OAuthClient) decideRole(user string, teams []*github.Team) (role model.Role_ProjectRole, err error) {
 	var found bool
 
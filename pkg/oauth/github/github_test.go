@@ -118,3 +118,20 @@ func TestDecideRole(t *testing.T) {
 		})
 	}
 }
+
+func TestMakeTeamNames(t *testing.T) {
+	teams := []*github.Team{
+		{
+			Organization: &github.Organization{Login: stringPointer("org")},
+			Slug:         stringPointer("team1"),
+		},
+		{
+			Slug: stringPointer("team-without-org"),
+		},
+		{
+			Organization: &github.Organization{Login: stringPointer("org")},
+			Slug:         stringPointer("team2"),
+		},
+	}
+	assert.Equal(t, []string{"org/team1", "org/team2"}, makeTeamNames(teams))
+}
@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"strconv"
 
 	goyaml "github.com/goccy/go-yaml"
 	"github.com/goccy/go-yaml/ast"
@@ -95,3 +96,24 @@ func ReplaceValue(yml []byte, path string, value string) ([]byte, error) {
 	_, err = io.Copy(buf, file)
 	return buf.Bytes(), err
 }
+
+// ToString converts a given scalar value returned by GetValue into a string.
+func ToString(value interface{}) (out string, err error) {
+	switch v := value.(type) {
+	case string:
+		out = v
+	case int:
+		out = strconv.Itoa(v)
+	case int64:
+		out = strconv.FormatInt(v, 10)
+	case uint64:
+		out = strconv.FormatUint(v, 10)
+	case float64:
+		out = strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		out = strconv.FormatBool(v)
+	default:
+		err = fmt.Errorf("failed to convert %T into string", v)
+	}
+	return
+}
@@ -20,6 +20,12 @@ def go_repositories():
         sum = "h1:Hs82Z41s6SdL1CELW+XaDYmOH4hkBN4/N9og/AsOv7E=",
         version = "v0.0.0-20190717042225-c3de453c63f4",
     )
+    go_repository(
+        name = "com_github_antlr_antlr4",
+        importpath = "github.com/antlr/antlr4",
+        sum = "h1:0cEys61Sr2hUBEXfNV8eyQP01oZuBgoMeHunebPirK8=",
+        version = "v0.0.0-20200503195918-621b933c7a7f",
+    )
     go_repository(
         name = "com_github_armon_consul_api",
         importpath = "github.com/armon/consul-api",
@@ -589,6 +595,21 @@ def go_repositories():
         version = "v1.0.0",
     )
 
+    go_repository(
+        name = "com_github_google_cel_go",
+        build_file_proto_mode = "disable_global",
+        importpath = "github.com/google/cel-go",
+        sum = "h1:Li+angxmgvzlwDsPuFc1/nbqnq3gc4K/X7NrWjOADFI=",
+        version = "v0.6.0",
+    )
+    go_repository(
+        name = "com_github_google_cel_spec",
+        build_file_proto_mode = "disable_global",
+        importpath = "github.com/google/cel-spec",
+        sum = "h1:HktvAjyBrKbDEZzD3oJQJ2khwAL1CEE1P7a5BNdVOMU=",
+        version = "v0.4.0",
+    )
+
     go_repository(
         name = "com_github_google_go_cmp",
         importpath = "github.com/google/go-cmp",